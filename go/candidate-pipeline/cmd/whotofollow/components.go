@@ -0,0 +1,358 @@
+package main
+
+import (
+	"context"
+	"log"
+	"math"
+	"sort"
+
+	"x-algorithm-go/candidate-pipeline/pipeline"
+)
+
+// Graph 是内存中的关注关系，代替线上的社交图谱服务
+type Graph struct {
+	follows map[int64][]int64
+}
+
+// NewGraph 按 user -> 关注的账号 创建 Graph
+func NewGraph(follows map[int64][]int64) *Graph {
+	return &Graph{follows: follows}
+}
+
+// Following 返回 userID 关注的账号
+func (g *Graph) Following(userID int64) []int64 {
+	return g.follows[userID]
+}
+
+// FollowerCount 返回关注 accountID 的人数
+func (g *Graph) FollowerCount(accountID int64) int64 {
+	var n int64
+	for _, following := range g.follows {
+		for _, id := range following {
+			if id == accountID {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// accounts 返回图中出现的全部账号（按 ID 排序）
+func (g *Graph) accounts() []int64 {
+	seen := make(map[int64]bool)
+	for user, following := range g.follows {
+		seen[user] = true
+		for _, id := range following {
+			seen[id] = true
+		}
+	}
+	ids := make([]int64, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// FollowingQueryHydrator 填充用户已关注的账号
+type FollowingQueryHydrator struct {
+	graph *Graph
+}
+
+// Hydrate 实现 QueryHydrator
+func (h *FollowingQueryHydrator) Hydrate(ctx context.Context, query *Query) (*Query, error) {
+	return &Query{Following: h.graph.Following(query.UserID)}, nil
+}
+
+// Name 实现 QueryHydrator
+func (h *FollowingQueryHydrator) Name() string { return "FollowingQueryHydrator" }
+
+// Enable 实现 QueryHydrator
+func (h *FollowingQueryHydrator) Enable(query *Query) bool { return true }
+
+// Update 实现 QueryHydrator
+func (h *FollowingQueryHydrator) Update(query *Query, hydrated *Query) {
+	query.Following = hydrated.Following
+}
+
+// FriendsOfFriendsSource 返回用户关注的人所关注的账号，按共同关注数排序
+type FriendsOfFriendsSource struct {
+	graph      *Graph
+	maxResults int
+}
+
+// GetCandidates 实现 Source
+func (s *FriendsOfFriendsSource) GetCandidates(ctx context.Context, query *Query) ([]*Candidate, error) {
+	mutual := make(map[int64]int)
+	for _, friend := range query.Following {
+		for _, id := range s.graph.Following(friend) {
+			mutual[id]++
+		}
+	}
+	candidates := make([]*Candidate, 0, len(mutual))
+	for id, n := range mutual {
+		candidates = append(candidates, &Candidate{AccountID: id, MutualFollows: n})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].MutualFollows != candidates[j].MutualFollows {
+			return candidates[i].MutualFollows > candidates[j].MutualFollows
+		}
+		return candidates[i].AccountID < candidates[j].AccountID
+	})
+	if len(candidates) > s.maxResults {
+		candidates = candidates[:s.maxResults]
+	}
+	for _, c := range candidates {
+		// 检索分数写入 Provenance[0].Score，管道会补齐来源和位置
+		score := float64(c.MutualFollows)
+		c.Provenance = []pipeline.SourceProvenance{{Score: &score}}
+	}
+	return candidates, nil
+}
+
+// Name 实现 Source
+func (s *FriendsOfFriendsSource) Name() string { return "FriendsOfFriendsSource" }
+
+// Enable 实现 Source
+func (s *FriendsOfFriendsSource) Enable(query *Query) bool { return true }
+
+// PopularAccountsSource 返回全站关注数最多的账号，用于冷启动
+type PopularAccountsSource struct {
+	graph      *Graph
+	maxResults int
+}
+
+// GetCandidates 实现 Source
+func (s *PopularAccountsSource) GetCandidates(ctx context.Context, query *Query) ([]*Candidate, error) {
+	ids := s.graph.accounts()
+	counts := make(map[int64]int64, len(ids))
+	for _, id := range ids {
+		counts[id] = s.graph.FollowerCount(id)
+	}
+	sort.SliceStable(ids, func(i, j int) bool { return counts[ids[i]] > counts[ids[j]] })
+	if len(ids) > s.maxResults {
+		ids = ids[:s.maxResults]
+	}
+	candidates := make([]*Candidate, len(ids))
+	for i, id := range ids {
+		candidates[i] = &Candidate{AccountID: id}
+	}
+	return candidates, nil
+}
+
+// Name 实现 Source
+func (s *PopularAccountsSource) Name() string { return "PopularAccountsSource" }
+
+// Enable 实现 Source
+func (s *PopularAccountsSource) Enable(query *Query) bool { return true }
+
+// FollowerCountHydrator 填充候选账号的关注数
+type FollowerCountHydrator struct {
+	graph *Graph
+}
+
+// Hydrate 实现 Hydrator
+func (h *FollowerCountHydrator) Hydrate(ctx context.Context, query *Query, candidates []*Candidate) ([]*Candidate, error) {
+	patches := pipeline.NewPatches[Candidate](len(candidates))
+	for i, c := range candidates {
+		patches[i].FollowerCount = h.graph.FollowerCount(c.AccountID)
+	}
+	return patches, nil
+}
+
+// Name 实现 Hydrator
+func (h *FollowerCountHydrator) Name() string { return "FollowerCountHydrator" }
+
+// Enable 实现 Hydrator
+func (h *FollowerCountHydrator) Enable(query *Query) bool { return true }
+
+// Update 实现 Hydrator
+func (h *FollowerCountHydrator) Update(candidate *Candidate, hydrated *Candidate) {
+	candidate.FollowerCount = hydrated.FollowerCount
+}
+
+// UpdateAll 实现 Hydrator
+func (h *FollowerCountHydrator) UpdateAll(candidates []*Candidate, hydrated []*Candidate) {
+	pipeline.DefaultUpdateAll(h, candidates, hydrated)
+}
+
+// ReadFields 实现 pipeline.FieldDependencies
+func (h *FollowerCountHydrator) ReadFields() []string { return []string{"AccountID"} }
+
+// WriteFields 实现 pipeline.FieldDependencies
+func (h *FollowerCountHydrator) WriteFields() []string { return []string{"FollowerCount"} }
+
+// 过滤原因
+const (
+	ReasonSelf             pipeline.RemovalReason = "self"
+	ReasonAlreadyFollowing pipeline.RemovalReason = "already_following"
+	ReasonDuplicate        pipeline.RemovalReason = "duplicate"
+)
+
+// AlreadyFollowingFilter 移除用户自己和已关注的账号
+type AlreadyFollowingFilter struct{}
+
+// Filter 实现 Filter
+func (f *AlreadyFollowingFilter) Filter(ctx context.Context, query *Query, candidates []*Candidate) (*FilterResult, error) {
+	result := &FilterResult{}
+	for _, c := range candidates {
+		switch {
+		case c.AccountID == query.UserID:
+			result.Removed = append(result.Removed, c)
+			result.Reasons = append(result.Reasons, ReasonSelf)
+		case query.follows(c.AccountID):
+			result.Removed = append(result.Removed, c)
+			result.Reasons = append(result.Reasons, ReasonAlreadyFollowing)
+		default:
+			result.Kept = append(result.Kept, c)
+		}
+	}
+	return result, nil
+}
+
+// Name 实现 Filter
+func (f *AlreadyFollowingFilter) Name() string { return "AlreadyFollowingFilter" }
+
+// Enable 实现 Filter
+func (f *AlreadyFollowingFilter) Enable(query *Query) bool { return true }
+
+// FailurePolicy 实现 pipeline.FailurePolicyProvider：无法判断关注关系时宁可不推荐
+func (f *AlreadyFollowingFilter) FailurePolicy() pipeline.FailurePolicy { return pipeline.FailClosed }
+
+// DuplicateFilter 移除多个 Source 返回的重复账号，保留第一次出现的候选
+type DuplicateFilter struct{}
+
+// Filter 实现 Filter
+func (f *DuplicateFilter) Filter(ctx context.Context, query *Query, candidates []*Candidate) (*FilterResult, error) {
+	result := &FilterResult{}
+	seen := make(map[int64]bool, len(candidates))
+	for _, c := range candidates {
+		if seen[c.AccountID] {
+			result.Removed = append(result.Removed, c)
+			result.Reasons = append(result.Reasons, ReasonDuplicate)
+			continue
+		}
+		seen[c.AccountID] = true
+		result.Kept = append(result.Kept, c)
+	}
+	return result, nil
+}
+
+// Name 实现 Filter
+func (f *DuplicateFilter) Name() string { return "DuplicateFilter" }
+
+// Enable 实现 Filter
+func (f *DuplicateFilter) Enable(query *Query) bool { return true }
+
+// SocialProofParams 是 SocialProofScorer 的参数
+type SocialProofParams struct {
+	MutualWeight     float64 `json:"mutual_weight"`
+	PopularityWeight float64 `json:"popularity_weight"`
+}
+
+// SocialProofScorer 按共同关注数和账号热度打分
+type SocialProofScorer struct {
+	params SocialProofParams
+}
+
+// Score 实现 Scorer
+func (s *SocialProofScorer) Score(ctx context.Context, query *Query, candidates []*Candidate) ([]*Candidate, error) {
+	patches := pipeline.NewPatches[Candidate](len(candidates))
+	for i, c := range candidates {
+		score := s.params.MutualWeight*float64(c.MutualFollows) +
+			s.params.PopularityWeight*math.Log1p(float64(c.FollowerCount))
+		patches[i].Score = &score
+	}
+	return patches, nil
+}
+
+// Name 实现 Scorer
+func (s *SocialProofScorer) Name() string { return "SocialProofScorer" }
+
+// Enable 实现 Scorer
+func (s *SocialProofScorer) Enable(query *Query) bool { return true }
+
+// Update 实现 Scorer
+func (s *SocialProofScorer) Update(candidate *Candidate, scored *Candidate) {
+	candidate.Score = scored.Score
+}
+
+// UpdateAll 实现 Scorer
+func (s *SocialProofScorer) UpdateAll(candidates []*Candidate, scored []*Candidate) {
+	pipeline.DefaultScorerUpdateAll(s, candidates, scored)
+}
+
+// ReadFields 实现 pipeline.FieldDependencies（构建时校验 FollowerCountHydrator 在它之前执行）
+func (s *SocialProofScorer) ReadFields() []string { return []string{"MutualFollows", "FollowerCount"} }
+
+// WriteFields 实现 pipeline.FieldDependencies
+func (s *SocialProofScorer) WriteFields() []string { return []string{"Score"} }
+
+// TopKSelector 按分数选择前 K 个账号，同分时按账号 ID 排序
+type TopKSelector struct {
+	k int
+}
+
+// Select 实现 Selector
+func (s *TopKSelector) Select(ctx context.Context, query *Query, candidates []*Candidate) []*Candidate {
+	sorted := s.Sort(candidates)
+	if s.k > 0 && len(sorted) > s.k {
+		sorted = sorted[:s.k]
+	}
+	return sorted
+}
+
+// Name 实现 Selector
+func (s *TopKSelector) Name() string { return "TopKSelector" }
+
+// Enable 实现 Selector
+func (s *TopKSelector) Enable(query *Query) bool { return true }
+
+// Score 实现 Selector
+func (s *TopKSelector) Score(candidate *Candidate) float64 {
+	if candidate.Score == nil {
+		return math.Inf(-1)
+	}
+	return *candidate.Score
+}
+
+// Sort 实现 Selector
+func (s *TopKSelector) Sort(candidates []*Candidate) []*Candidate {
+	sorted := make([]*Candidate, len(candidates))
+	copy(sorted, candidates)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		si, sj := s.Score(sorted[i]), s.Score(sorted[j])
+		if si != sj {
+			return si > sj
+		}
+		return sorted[i].AccountID < sorted[j].AccountID
+	})
+	return sorted
+}
+
+// Size 实现 Selector
+func (s *TopKSelector) Size() *int {
+	if s.k > 0 {
+		return &s.k
+	}
+	return nil
+}
+
+// ImpressionLogSideEffect 记录推荐给用户的账号（线上写入曝光日志，用于去重和训练）
+type ImpressionLogSideEffect struct{}
+
+// Run 实现 SideEffect
+func (s *ImpressionLogSideEffect) Run(ctx context.Context, query *Query, candidates []*Candidate) error {
+	ids := make([]int64, len(candidates))
+	for i, c := range candidates {
+		ids[i] = c.AccountID
+	}
+	log.Printf("request_id=%s stage=SideEffect component=%s recommended=%v", query.RequestID, s.Name(), ids)
+	return nil
+}
+
+// Name 实现 SideEffect
+func (s *ImpressionLogSideEffect) Name() string { return "ImpressionLogSideEffect" }
+
+// Enable 实现 SideEffect
+func (s *ImpressionLogSideEffect) Enable(query *Query) bool { return true }
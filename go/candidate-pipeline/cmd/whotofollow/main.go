@@ -0,0 +1,188 @@
+// whotofollow 是关注推荐（Who To Follow）的示例管道，演示在首页时间线之外复用候选管道框架
+//
+// 产品定义自己的查询和候选类型（嵌入 pipeline.RequestMeta / pipeline.CandidateMeta），
+// 组件实现 pipeline.SourceOf[*Query, *Candidate] 等泛型接口，注册到 pipeline.RegistryOf 后
+// 用与首页时间线相同格式的管道定义编译；阶段编排、超时、失败策略、实验分桶和 explain 都直接复用。
+//
+// 用法：
+//
+//	go run ./cmd/whotofollow -users 1,2,7 -explain
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"x-algorithm-go/candidate-pipeline/pipeline"
+)
+
+var (
+	users    = flag.String("users", "1,2,7", "逗号分隔的用户 ID")
+	explain  = flag.Bool("explain", false, "打印每个候选在管道中的轨迹")
+	pipeLogs = flag.Bool("pipeline_logs", false, "打印管道日志")
+)
+
+// definition 是关注推荐的管道定义；popularity 实验在 10% 的用户上提高账号热度的权重
+const definition = `{
+  "name": "who_to_follow",
+  "experiments": [
+    {"name": "popularity", "treatments": [{"name": "boost", "buckets": 100}]}
+  ],
+  "query_hydrators": [{"name": "FollowingQueryHydrator"}],
+  "sources": [
+    {"name": "FriendsOfFriendsSource", "params": {"max_results": 20}},
+    {"name": "PopularAccountsSource", "params": {"max_results": 5}}
+  ],
+  "hydrators": [{"name": "FollowerCountHydrator"}],
+  "filters": [{"name": "AlreadyFollowingFilter"}, {"name": "DuplicateFilter"}],
+  "scorers": [
+    {
+      "name": "SocialProofScorer",
+      "experiment": "popularity",
+      "treatment_params": {"boost": {"popularity_weight": 2}}
+    }
+  ],
+  "selector": {"name": "TopKSelector", "params": {"k": 3}},
+  "side_effects": [{"name": "ImpressionLogSideEffect"}],
+  "deadlines": {"default_component": "50ms"}
+}`
+
+// MaxResultsParams 是 Source 的参数
+type MaxResultsParams struct {
+	MaxResults int `json:"max_results"`
+}
+
+// TopKParams 是 TopKSelector 的参数
+type TopKParams struct {
+	K int `json:"k"`
+}
+
+// NewRegistry 注册关注推荐的全部组件
+func NewRegistry(graph *Graph) *Registry {
+	r := pipeline.NewRegistryOf[*Query, *Candidate]()
+	noParams := func() pipeline.NoParams { return pipeline.NoParams{} }
+	maxResults := func() MaxResultsParams { return MaxResultsParams{MaxResults: 10} }
+
+	pipeline.RegisterQueryHydrator(r, "FollowingQueryHydrator", noParams, func(pipeline.NoParams) (QueryHydrator, error) {
+		return &FollowingQueryHydrator{graph: graph}, nil
+	})
+	pipeline.RegisterSource(r, "FriendsOfFriendsSource", maxResults, func(p MaxResultsParams) (Source, error) {
+		return &FriendsOfFriendsSource{graph: graph, maxResults: p.MaxResults}, nil
+	})
+	pipeline.RegisterSource(r, "PopularAccountsSource", maxResults, func(p MaxResultsParams) (Source, error) {
+		return &PopularAccountsSource{graph: graph, maxResults: p.MaxResults}, nil
+	})
+	pipeline.RegisterHydrator(r, "FollowerCountHydrator", noParams, func(pipeline.NoParams) (Hydrator, error) {
+		return &FollowerCountHydrator{graph: graph}, nil
+	})
+	pipeline.RegisterFilter(r, "AlreadyFollowingFilter", noParams, func(pipeline.NoParams) (Filter, error) {
+		return &AlreadyFollowingFilter{}, nil
+	})
+	pipeline.RegisterFilter(r, "DuplicateFilter", noParams, func(pipeline.NoParams) (Filter, error) {
+		return &DuplicateFilter{}, nil
+	})
+	pipeline.RegisterScorer(r, "SocialProofScorer", func() SocialProofParams {
+		return SocialProofParams{MutualWeight: 1, PopularityWeight: 0.5}
+	}, func(p SocialProofParams) (Scorer, error) {
+		return &SocialProofScorer{params: p}, nil
+	})
+	pipeline.RegisterSelector(r, "TopKSelector", func() TopKParams { return TopKParams{K: 10} }, func(p TopKParams) (Selector, error) {
+		return &TopKSelector{k: p.K}, nil
+	})
+	pipeline.RegisterSideEffect(r, "ImpressionLogSideEffect", noParams, func(pipeline.NoParams) (SideEffect, error) {
+		return &ImpressionLogSideEffect{}, nil
+	})
+	return r
+}
+
+// sampleGraph 返回示例用的关注关系
+func sampleGraph() *Graph {
+	return NewGraph(map[int64][]int64{
+		1:  {2, 3, 4},
+		2:  {3, 5, 6, 10},
+		3:  {5, 6, 10},
+		4:  {6, 7, 10},
+		5:  {10, 11},
+		6:  {10, 12},
+		7:  {1, 10},
+		8:  {10, 11},
+		9:  {10},
+		11: {10, 12},
+	})
+}
+
+func main() {
+	flag.Parse()
+	if !*pipeLogs {
+		log.SetOutput(io.Discard)
+	}
+
+	def, err := pipeline.ParseDefinition([]byte(definition))
+	if err != nil {
+		fatalf("%v", err)
+	}
+	p, err := NewRegistry(sampleGraph()).Compile(def)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	p.Deadlines.Sourcing = 100 * time.Millisecond
+	if err := p.Build(); err != nil {
+		fatalf("%v", err)
+	}
+
+	for _, field := range strings.Split(*users, ",") {
+		userID, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+		if err != nil {
+			fatalf("invalid user id %q", field)
+		}
+		query := &Query{RequestMeta: pipeline.RequestMeta{UserID: userID, RequestID: fmt.Sprintf("wtf-%d", userID)}}
+		result, err := p.ExecuteWithOptions(context.Background(), query, pipeline.ExecuteOptions{Explain: *explain})
+		if err != nil {
+			fatalf("user=%d: %v", userID, err)
+		}
+		fmt.Printf("user=%d following=%v experiments=%s\n", userID, result.Query.Following, result.Query.Experiments)
+		for i, c := range result.SelectedCandidates {
+			fmt.Printf("  %d. account=%d score=%.3f mutual=%d followers=%d sources=%s\n",
+				i, c.AccountID, pipeline.FloatOrZero(c.Score), c.MutualFollows, c.FollowerCount, sources(c))
+		}
+		for _, ex := range result.Explanations {
+			printExplanation(ex)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := p.SideEffectExecutor.Drain(ctx); err != nil {
+		fatalf("drain side effects: %v", err)
+	}
+}
+
+func sources(c *Candidate) string {
+	names := make([]string, len(c.Provenance))
+	for i, p := range c.Provenance {
+		names[i] = p.Source
+	}
+	return strings.Join(names, ",")
+}
+
+func printExplanation(ex *pipeline.CandidateExplanationOf[*Candidate]) {
+	status := "selected"
+	if ex.Removal != nil {
+		status = fmt.Sprintf("removed stage=%s component=%s reason=%s", ex.Removal.Stage, ex.Removal.Component, ex.Removal.Reason)
+	} else if !ex.Selected {
+		status = "not_selected"
+	}
+	fmt.Printf("    explain account=%d source=%s %s\n", ex.ID, ex.Source, status)
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "whotofollow: "+format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,72 @@
+package main
+
+import "x-algorithm-go/candidate-pipeline/pipeline"
+
+// Query 是关注推荐的请求
+type Query struct {
+	pipeline.RequestMeta
+
+	// Following 是用户已关注的账号，由 FollowingQueryHydrator 填充
+	Following []int64
+}
+
+// Clone 实现 pipeline.PipelineQuery
+func (q *Query) Clone() *Query {
+	clone := &Query{RequestMeta: q.RequestMeta.Clone()}
+	if q.Following != nil {
+		clone.Following = make([]int64, len(q.Following))
+		copy(clone.Following, q.Following)
+	}
+	return clone
+}
+
+// follows 判断用户是否已关注 accountID
+func (q *Query) follows(accountID int64) bool {
+	for _, id := range q.Following {
+		if id == accountID {
+			return true
+		}
+	}
+	return false
+}
+
+// Candidate 是一个被推荐关注的账号
+type Candidate struct {
+	pipeline.CandidateMeta
+
+	AccountID int64
+
+	// MutualFollows 是用户关注的人中关注了该账号的人数，由 FriendsOfFriendsSource 填写
+	MutualFollows int
+
+	// FollowerCount 由 FollowerCountHydrator 填充
+	FollowerCount int64
+}
+
+// Key 实现 pipeline.PipelineCandidate
+func (c *Candidate) Key() int64 {
+	return c.AccountID
+}
+
+// Clone 实现 pipeline.PipelineCandidate
+func (c *Candidate) Clone() *Candidate {
+	return &Candidate{
+		CandidateMeta: c.CandidateMeta.Clone(),
+		AccountID:     c.AccountID,
+		MutualFollows: c.MutualFollows,
+		FollowerCount: c.FollowerCount,
+	}
+}
+
+// 关注推荐管道的组件类型
+type (
+	QueryHydrator = pipeline.QueryHydratorOf[*Query]
+	Source        = pipeline.SourceOf[*Query, *Candidate]
+	Hydrator      = pipeline.HydratorOf[*Query, *Candidate]
+	Filter        = pipeline.FilterOf[*Query, *Candidate]
+	Scorer        = pipeline.ScorerOf[*Query, *Candidate]
+	Selector      = pipeline.SelectorOf[*Query, *Candidate]
+	SideEffect    = pipeline.SideEffectOf[*Query, *Candidate]
+	FilterResult  = pipeline.FilterResultOf[*Candidate]
+	Registry      = pipeline.RegistryOf[*Query, *Candidate]
+)
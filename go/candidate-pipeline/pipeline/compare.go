@@ -0,0 +1,196 @@
+package pipeline
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// RemovalKey 标识移除候选的组件
+type RemovalKey struct {
+	Stage     string
+	Component string
+}
+
+// ResultComparison 是同一请求在两个管道上的执行结果的差异
+type ResultComparison struct {
+	K           int // 比较前 K 个选中的候选
+	PrimarySize int // 主管道选中的候选数
+	ShadowSize  int // 影子管道选中的候选数
+
+	// Overlap 是两边前 K 个候选中相同帖子的数量
+	Overlap int
+	// OverlapAtK = Overlap / min(K, max(PrimarySize, ShadowSize))，两边都为空时为 1
+	OverlapAtK float64
+
+	// Common 是两边都选中的帖子数（不限于前 K 个）
+	Common int
+	// RankCorrelation 是共同帖子在两边排名的 Spearman 相关系数，Common < 2 时为 NaN
+	RankCorrelation float64
+	// MeanAbsScoreDelta / MaxAbsScoreDelta 是共同帖子 |影子分数 - 主分数| 的均值和最大值
+	// 只统计两边都有分数的帖子
+	MeanAbsScoreDelta float64
+	MaxAbsScoreDelta  float64
+
+	// PrimaryRemovals / ShadowRemovals 是各组件移除的候选数
+	PrimaryRemovals map[RemovalKey]int
+	ShadowRemovals  map[RemovalKey]int
+}
+
+// CompareResults 比较主管道和影子管道对同一请求的执行结果，k <= 0 时比较全部选中的候选
+// 帖子按候选的 Key 对应，分数取 CandidateMeta.Score
+func CompareResults[Q any, C PipelineCandidate[C]](primary, shadow *PipelineResultOf[Q, C], k int) ResultComparison {
+	c := ResultComparison{
+		K:               k,
+		PrimarySize:     len(primary.SelectedCandidates),
+		ShadowSize:      len(shadow.SelectedCandidates),
+		PrimaryRemovals: countRemovals(primary.Removals),
+		ShadowRemovals:  countRemovals(shadow.Removals),
+	}
+
+	depth := c.PrimarySize
+	if c.ShadowSize > depth {
+		depth = c.ShadowSize
+	}
+	if k > 0 && k < depth {
+		depth = k
+	}
+	if depth == 0 {
+		c.OverlapAtK = 1
+	} else {
+		top := make(map[int64]bool, depth)
+		for _, cand := range headCandidates(primary.SelectedCandidates, depth) {
+			top[cand.Key()] = true
+		}
+		for _, cand := range headCandidates(shadow.SelectedCandidates, depth) {
+			if top[cand.Key()] {
+				c.Overlap++
+			}
+		}
+		c.OverlapAtK = float64(c.Overlap) / float64(depth)
+	}
+
+	primaryByID := make(map[int64]int, c.PrimarySize)
+	for i, cand := range primary.SelectedCandidates {
+		if _, ok := primaryByID[cand.Key()]; !ok {
+			primaryByID[cand.Key()] = i
+		}
+	}
+	var primaryRanks, shadowRanks []int
+	var deltaSum float64
+	scored := 0
+	seen := make(map[int64]bool, c.ShadowSize)
+	for i, cand := range shadow.SelectedCandidates {
+		id := cand.Key()
+		j, ok := primaryByID[id]
+		if !ok || seen[id] {
+			continue
+		}
+		seen[id] = true
+		primaryRanks = append(primaryRanks, j)
+		shadowRanks = append(shadowRanks, i)
+		if p, s := primary.SelectedCandidates[j].Meta().Score, cand.Meta().Score; p != nil && s != nil {
+			delta := math.Abs(*s - *p)
+			deltaSum += delta
+			if delta > c.MaxAbsScoreDelta {
+				c.MaxAbsScoreDelta = delta
+			}
+			scored++
+		}
+	}
+	c.Common = len(primaryRanks)
+	c.RankCorrelation = spearman(primaryRanks, shadowRanks)
+	if scored > 0 {
+		c.MeanAbsScoreDelta = deltaSum / float64(scored)
+	}
+	return c
+}
+
+// RemovalDeltas 返回各组件移除数的差值（影子 - 主），只包含不为 0 的组件
+func (c ResultComparison) RemovalDeltas() map[RemovalKey]int {
+	deltas := make(map[RemovalKey]int)
+	for key, n := range c.ShadowRemovals {
+		deltas[key] += n
+	}
+	for key, n := range c.PrimaryRemovals {
+		deltas[key] -= n
+	}
+	for key, d := range deltas {
+		if d == 0 {
+			delete(deltas, key)
+		}
+	}
+	return deltas
+}
+
+// String 返回用于日志的格式
+func (c ResultComparison) String() string {
+	deltas := c.RemovalDeltas()
+	keys := make([]RemovalKey, 0, len(deltas))
+	for key := range deltas {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Stage != keys[j].Stage {
+			return keys[i].Stage < keys[j].Stage
+		}
+		return keys[i].Component < keys[j].Component
+	})
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = fmt.Sprintf("%s/%s:%+d", key.Stage, key.Component, deltas[key])
+	}
+	removalDeltas := "none"
+	if len(parts) > 0 {
+		removalDeltas = strings.Join(parts, ",")
+	}
+	return fmt.Sprintf("overlap_at_k=%.3f k=%d primary=%d shadow=%d common=%d rank_corr=%.3f mean_score_delta=%.4f max_score_delta=%.4f removal_deltas=%s",
+		c.OverlapAtK, c.K, c.PrimarySize, c.ShadowSize, c.Common, c.RankCorrelation, c.MeanAbsScoreDelta, c.MaxAbsScoreDelta, removalDeltas)
+}
+
+func countRemovals[C any](removals []RemovedCandidateOf[C]) map[RemovalKey]int {
+	counts := make(map[RemovalKey]int)
+	for _, r := range removals {
+		counts[RemovalKey{Stage: r.Stage, Component: r.Component}]++
+	}
+	return counts
+}
+
+func headCandidates[C any](candidates []C, n int) []C {
+	if len(candidates) > n {
+		return candidates[:n]
+	}
+	return candidates
+}
+
+// spearman 返回两组排名的 Spearman 相关系数
+// 输入是共同帖子在各自列表中的位置，先压缩为 0..n-1 的排名再计算
+func spearman(a, b []int) float64 {
+	n := len(a)
+	if n < 2 {
+		return math.NaN()
+	}
+	ra, rb := denseRanks(a), denseRanks(b)
+	var d2 float64
+	for i := range ra {
+		d := float64(ra[i] - rb[i])
+		d2 += d * d
+	}
+	nf := float64(n)
+	return 1 - 6*d2/(nf*(nf*nf-1))
+}
+
+// denseRanks 把互不相同的位置映射为 0..n-1 的排名
+func denseRanks(positions []int) []int {
+	order := make([]int, len(positions))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return positions[order[i]] < positions[order[j]] })
+	ranks := make([]int, len(positions))
+	for rank, i := range order {
+		ranks[i] = rank
+	}
+	return ranks
+}
@@ -0,0 +1,127 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+)
+
+// Deadlines 配置管道各阶段的时间预算和单个组件的超时
+// 零值表示不额外限制（仍受调用方 ctx 的 deadline 约束，例如 gRPC 请求的 deadline）
+//
+// 超时的组件会被放弃：管道不再等待它，它之后返回的结果也会被丢弃。
+// 组件收到的 ctx 会在超时时被取消，组件应当尽快返回。交给 Query Hydrator 的查询，以及交给可能被放弃的
+// Hydrator 和 Scorer 的候选都是快照（见 abandonable），被放弃的组件之后继续读取输入，不会与管道对原查询和原候选的修改冲突。
+type Deadlines struct {
+	// 各阶段的总预算
+	QueryHydration         time.Duration
+	Sourcing               time.Duration
+	Hydration              time.Duration
+	PreRanking             time.Duration
+	Scoring                time.Duration
+	PostSelectionHydration time.Duration
+
+	// DefaultComponent 是未在 Component 中单独配置的组件的超时
+	DefaultComponent time.Duration
+	// Component 按组件名（Name()）配置超时，优先级高于 DefaultComponent
+	Component map[string]time.Duration
+}
+
+// componentTimeout 返回组件的超时时间，0 表示不限制
+func (d Deadlines) componentTimeout(name string) time.Duration {
+	if t, ok := d.Component[name]; ok {
+		return t
+	}
+	return d.DefaultComponent
+}
+
+// withBudget 在 ctx 上叠加时间预算，budget <= 0 时只继承 ctx 的 deadline
+func withBudget(ctx context.Context, budget time.Duration) (context.Context, context.CancelFunc) {
+	if budget <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, budget)
+}
+
+// outcome 表示一次组件调用的结果
+type outcome[T any] struct {
+	value    T
+	err      error
+	timedOut bool          // 组件在截止前未返回，value 已被丢弃
+	elapsed  time.Duration // 从调用开始到返回（或被放弃）的时间
+}
+
+// fanOut 并行调用 len(parents) 个组件，第 i 个组件的 ctx 派生自 parents[i]
+// （通常是带阶段预算、并由 Observer 加上追踪信息的 ctx），并额外受 timeoutOf(i) 约束
+// 返回的结果按下标排列，与完成顺序无关，保证合并顺序确定
+// 超时的组件不会被等待；它们的 goroutine 返回后结果会被直接丢弃
+func fanOut[T any](
+	parents []context.Context,
+	timeoutOf func(i int) time.Duration,
+	call func(ctx context.Context, i int) (T, error),
+) []outcome[T] {
+	n := len(parents)
+	type item struct {
+		i int
+		o outcome[T]
+	}
+	ch := make(chan item, n)
+
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			start := time.Now()
+			cctx, cancel := withBudget(parents[i], timeoutOf(i))
+			defer cancel()
+
+			// 组件本身在独立的 goroutine 中运行，便于在超时后立即放弃；panic 会被转换为错误
+			inner := make(chan outcome[T], 1)
+			go func() {
+				v, err := safeCall(func() (T, error) { return call(cctx, i) })
+				inner <- outcome[T]{value: v, err: err}
+			}()
+
+			select {
+			case o := <-inner:
+				o.elapsed = time.Since(start)
+				ch <- item{i: i, o: o}
+			case <-cctx.Done():
+				ch <- item{i: i, o: outcome[T]{err: cctx.Err(), timedOut: true, elapsed: time.Since(start)}}
+			}
+		}(i)
+	}
+
+	results := make([]outcome[T], n)
+	for k := 0; k < n; k++ {
+		it := <-ch
+		results[it.i] = it.o
+	}
+	return results
+}
+
+// abandonable 判断组件是否可能被放弃（或在对冲时留下仍在运行的调用）：
+// 配置了组件超时、ctx 带 deadline（阶段预算或调用方的 deadline），或启用了对冲
+func abandonable(ctx context.Context, timeout time.Duration, h *hedger) bool {
+	_, hasDeadline := ctx.Deadline()
+	return timeout > 0 || hasDeadline || h != nil
+}
+
+// snapshotCandidates 返回候选的深拷贝，作为可能被放弃的组件的输入
+// 被放弃的组件的 goroutine 可能仍在读取输入，而管道会继续把其他组件的补丁合并到原候选，
+// 因此不能把原候选直接交给它们（与 Query Hydrator 的查询快照相同）
+// abandon 为 false 时组件一定会被等待，直接返回原候选，避免每个组件一次深拷贝
+func snapshotCandidates[C PipelineCandidate[C]](candidates []C, abandon bool) []C {
+	if !abandon {
+		return candidates
+	}
+	snapshot := make([]C, len(candidates))
+	for i, c := range candidates {
+		snapshot[i] = c.Clone()
+	}
+	return snapshot
+}
+
+// callWithTimeout 在超时约束下调用单个组件（用于顺序执行的阶段）
+func callWithTimeout[T any](ctx context.Context, timeout time.Duration, call func(ctx context.Context) (T, error)) outcome[T] {
+	return fanOut([]context.Context{ctx}, func(int) time.Duration { return timeout }, func(ctx context.Context, _ int) (T, error) {
+		return call(ctx)
+	})[0]
+}
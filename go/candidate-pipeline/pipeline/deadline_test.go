@@ -0,0 +1,153 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFanOut(t *testing.T) {
+	// block 表示组件一直等到 ctx 被取消
+	const block = -1
+	tests := []struct {
+		name     string
+		delays   []time.Duration // 每个组件返回前的耗时
+		timeouts []time.Duration // 每个组件的超时，0 表示不限制
+		budget   time.Duration   // 父 ctx 的预算，0 表示不限制
+		panics   int             // 该下标的组件 panic，-1 表示没有
+		wantErr  []string        // 每个组件的错误，"" 表示成功
+		timedOut []bool
+	}{
+		{
+			name:     "all succeed in index order",
+			delays:   []time.Duration{20 * time.Millisecond, 0, 10 * time.Millisecond},
+			timeouts: []time.Duration{0, 0, 0},
+			panics:   -1,
+			wantErr:  []string{"", "", ""},
+			timedOut: []bool{false, false, false},
+		},
+		{
+			name:     "component timeout abandons only that component",
+			delays:   []time.Duration{block, 0},
+			timeouts: []time.Duration{20 * time.Millisecond, 0},
+			panics:   -1,
+			wantErr:  []string{context.DeadlineExceeded.Error(), ""},
+			timedOut: []bool{true, false},
+		},
+		{
+			name:     "parent budget abandons every slow component",
+			delays:   []time.Duration{block, block, 0},
+			timeouts: []time.Duration{0, time.Minute, 0},
+			budget:   20 * time.Millisecond,
+			panics:   -1,
+			wantErr:  []string{context.DeadlineExceeded.Error(), context.DeadlineExceeded.Error(), ""},
+			timedOut: []bool{true, true, false},
+		},
+		{
+			name:     "panic becomes an error",
+			delays:   []time.Duration{0, 0},
+			timeouts: []time.Duration{0, 0},
+			panics:   1,
+			wantErr:  []string{"", "boom"},
+			timedOut: []bool{false, false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent, cancel := withBudget(context.Background(), tt.budget)
+			defer cancel()
+			parents := make([]context.Context, len(tt.delays))
+			for i := range parents {
+				parents[i] = parent
+			}
+
+			start := time.Now()
+			results := fanOut(parents, func(i int) time.Duration { return tt.timeouts[i] }, func(ctx context.Context, i int) (int, error) {
+				if i == tt.panics {
+					panic("boom")
+				}
+				if tt.delays[i] == block {
+					<-ctx.Done()
+					// 模拟被放弃后才返回的组件：返回值应被丢弃
+					return -1, errors.New("returned after being abandoned")
+				}
+				time.Sleep(tt.delays[i])
+				return i * 10, nil
+			})
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Fatalf("fanOut took %s, want abandoned components not to be waited for", elapsed)
+			}
+
+			if len(results) != len(tt.delays) {
+				t.Fatalf("got %d results, want %d", len(results), len(tt.delays))
+			}
+			for i, r := range results {
+				if r.timedOut != tt.timedOut[i] {
+					t.Errorf("result %d: timedOut=%v, want %v", i, r.timedOut, tt.timedOut[i])
+				}
+				if tt.wantErr[i] == "" {
+					if r.err != nil || r.value != i*10 {
+						t.Errorf("result %d: value=%d err=%v, want %d", i, r.value, r.err, i*10)
+					}
+					continue
+				}
+				if r.err == nil || !strings.Contains(r.err.Error(), tt.wantErr[i]) {
+					t.Errorf("result %d: err=%v, want %q", i, r.err, tt.wantErr[i])
+				}
+				if r.value != 0 {
+					t.Errorf("result %d: value=%d, want the zero value for a failed component", i, r.value)
+				}
+			}
+		})
+	}
+}
+
+func TestSnapshotCandidates(t *testing.T) {
+	withDeadline, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		timeout  time.Duration
+		hedger   *hedger
+		wantCopy bool
+	}{
+		{name: "never abandoned", ctx: context.Background()},
+		{name: "component timeout", ctx: context.Background(), timeout: time.Second, wantCopy: true},
+		{name: "deadline", ctx: withDeadline, wantCopy: true},
+		{name: "hedged", ctx: context.Background(), hedger: &hedger{}, wantCopy: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates := []*testCandidate{{ID: 1}, {ID: 2}}
+			snapshot := snapshotCandidates(candidates, abandonable(tt.ctx, tt.timeout, tt.hedger))
+			if !tt.wantCopy {
+				// 一定会被等待的组件直接读取原候选
+				if &snapshot[0] != &candidates[0] {
+					t.Errorf("snapshot copied candidates that cannot be abandoned")
+				}
+				return
+			}
+			candidates[0].ID = 10
+			if snapshot[0].ID != 1 || snapshot[1].ID != 2 || snapshot[0] == candidates[0] {
+				t.Errorf("snapshot %+v shares state with the candidates", snapshot)
+			}
+		})
+	}
+}
+
+// testCandidate 是最小的 PipelineCandidate 实现
+type testCandidate struct {
+	CandidateMeta
+	ID int64
+}
+
+func (c *testCandidate) Clone() *testCandidate {
+	clone := *c
+	clone.CandidateMeta = c.CandidateMeta.Clone()
+	return &clone
+}
+
+func (c *testCandidate) Key() int64 { return c.ID }
@@ -0,0 +1,148 @@
+package pipeline
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FieldDependencies 由声明了读写字段的 Hydrator / QueryHydrator 实现（可选）
+// 字段名使用候选（Hydrator）或查询（QueryHydrator）类型的导出字段名，例如 "AuthorID"
+//
+// 管道在构建时根据这些声明生成依赖图（DAG）：
+// 读取某字段的组件会排在写入该字段的组件之后执行，互不依赖的组件仍然并行执行。
+// 未实现该接口的组件视为不依赖任何字段，与之前一样在第一层并行执行。
+//
+// Filter / Scorer 也可以实现该接口，Validate 据此检查读取的字段在它执行之前已经被写入；
+// 顺序执行的 Scorer 可以写入同一个字段（例如依次调整 Score）。
+type FieldDependencies interface {
+	// ReadFields 返回组件在 Hydrate 中读取的字段
+	ReadFields() []string
+
+	// WriteFields 返回组件在 Update 中写入的字段
+	WriteFields() []string
+}
+
+// buildLayers 根据字段读写声明把组件分层
+// 返回的每一层是组件在原列表中的下标，层内保持声明顺序
+//
+// 以下情况返回错误：
+//   - 声明了不存在的字段
+//   - 两个组件写入同一个字段
+//   - 依赖关系存在环
+func buildLayers(stage string, names []string, decls []FieldDependencies, fieldsOf reflect.Type) ([][]int, error) {
+	n := len(names)
+	writer := make(map[string]int)
+
+	for i, d := range decls {
+		if d == nil {
+			continue
+		}
+		for _, f := range d.ReadFields() {
+			if _, ok := fieldsOf.FieldByName(f); !ok {
+				return nil, fmt.Errorf("%s: %s reads unknown field %s.%s", stage, names[i], fieldsOf.Name(), f)
+			}
+		}
+		for _, f := range d.WriteFields() {
+			if _, ok := fieldsOf.FieldByName(f); !ok {
+				return nil, fmt.Errorf("%s: %s writes unknown field %s.%s", stage, names[i], fieldsOf.Name(), f)
+			}
+			if prev, ok := writer[f]; ok {
+				return nil, fmt.Errorf("%s: field %s.%s is written by both %s and %s", stage, fieldsOf.Name(), f, names[prev], names[i])
+			}
+			writer[f] = i
+		}
+	}
+
+	// 建图：writer -> reader
+	indegree := make([]int, n)
+	edges := make([][]int, n)
+	for i, d := range decls {
+		if d == nil {
+			continue
+		}
+		seen := make(map[int]bool)
+		for _, f := range d.ReadFields() {
+			w, ok := writer[f]
+			if !ok || w == i || seen[w] {
+				continue
+			}
+			seen[w] = true
+			edges[w] = append(edges[w], i)
+			indegree[i]++
+		}
+	}
+
+	// Kahn 拓扑排序，按层输出
+	var layers [][]int
+	var current []int
+	for i := 0; i < n; i++ {
+		if indegree[i] == 0 {
+			current = append(current, i)
+		}
+	}
+	placed := 0
+	for len(current) > 0 {
+		layers = append(layers, current)
+		placed += len(current)
+		var next []int
+		for _, i := range current {
+			for _, j := range edges[i] {
+				indegree[j]--
+				if indegree[j] == 0 {
+					next = append(next, j)
+				}
+			}
+		}
+		sort.Ints(next) // 层内保持声明顺序
+		current = next
+	}
+
+	if placed != n {
+		var cyclic []string
+		for i := 0; i < n; i++ {
+			if indegree[i] > 0 {
+				cyclic = append(cyclic, names[i])
+			}
+		}
+		return nil, fmt.Errorf("%s: dependency cycle among %s", stage, strings.Join(cyclic, ", "))
+	}
+
+	return layers, nil
+}
+
+// hydratorLayers 为 Hydrator 列表构建执行分层
+func hydratorLayers[Q any, C any](stage string, hydrators []HydratorOf[Q, C]) ([][]int, error) {
+	names := make([]string, len(hydrators))
+	decls := make([]FieldDependencies, len(hydrators))
+	for i, h := range hydrators {
+		names[i] = h.Name()
+		if d, ok := h.(FieldDependencies); ok {
+			decls[i] = d
+		}
+	}
+	return buildLayers(stage, names, decls, structType[C]())
+}
+
+// queryHydratorLayers 为 QueryHydrator 列表构建执行分层
+func queryHydratorLayers[Q any](stage string, hydrators []QueryHydratorOf[Q]) ([][]int, error) {
+	names := make([]string, len(hydrators))
+	decls := make([]FieldDependencies, len(hydrators))
+	for i, h := range hydrators {
+		names[i] = h.Name()
+		if d, ok := h.(FieldDependencies); ok {
+			decls[i] = d
+		}
+	}
+	return buildLayers(stage, names, decls, structType[Q]())
+}
+
+// structType 返回 T（或 T 指向的类型）的反射类型，用于按名称查找字段（包括嵌入结构体的字段）
+func structType[T any]() reflect.Type {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
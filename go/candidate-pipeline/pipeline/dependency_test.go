@@ -0,0 +1,125 @@
+package pipeline
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// layerFields 是 buildLayers 测试中组件读写的结构体
+type layerFields struct {
+	A, B, C, D int
+}
+
+// fieldDecl 按给定的字段声明实现 FieldDependencies
+type fieldDecl struct {
+	reads, writes []string
+}
+
+func (d fieldDecl) ReadFields() []string  { return d.reads }
+func (d fieldDecl) WriteFields() []string { return d.writes }
+
+func TestBuildLayers(t *testing.T) {
+	tests := []struct {
+		name    string
+		decls   []FieldDependencies
+		want    [][]int
+		wantErr string
+	}{
+		{
+			name:  "no declarations run in one layer",
+			decls: []FieldDependencies{nil, nil, nil},
+			want:  [][]int{{0, 1, 2}},
+		},
+		{
+			name: "reader runs after writer",
+			decls: []FieldDependencies{
+				fieldDecl{reads: []string{"A"}, writes: []string{"B"}},
+				fieldDecl{writes: []string{"A"}},
+			},
+			want: [][]int{{1}, {0}},
+		},
+		{
+			name: "chain and independent component",
+			decls: []FieldDependencies{
+				fieldDecl{writes: []string{"A"}},
+				fieldDecl{reads: []string{"A"}, writes: []string{"B"}},
+				fieldDecl{reads: []string{"B"}, writes: []string{"C"}},
+				nil,
+			},
+			want: [][]int{{0, 3}, {1}, {2}},
+		},
+		{
+			name: "layer keeps declaration order",
+			decls: []FieldDependencies{
+				fieldDecl{writes: []string{"A"}},
+				fieldDecl{reads: []string{"A"}, writes: []string{"C"}},
+				fieldDecl{reads: []string{"A"}, writes: []string{"B"}},
+			},
+			want: [][]int{{0}, {1, 2}},
+		},
+		{
+			name: "reading own field is not a dependency",
+			decls: []FieldDependencies{
+				fieldDecl{reads: []string{"A"}, writes: []string{"A"}},
+			},
+			want: [][]int{{0}},
+		},
+		{
+			name: "reading an unwritten field is not a dependency",
+			decls: []FieldDependencies{
+				fieldDecl{reads: []string{"D"}},
+				fieldDecl{writes: []string{"A"}},
+			},
+			want: [][]int{{0, 1}},
+		},
+		{
+			name:    "unknown read field",
+			decls:   []FieldDependencies{fieldDecl{reads: []string{"Missing"}}},
+			wantErr: "h0 reads unknown field layerFields.Missing",
+		},
+		{
+			name:    "unknown write field",
+			decls:   []FieldDependencies{fieldDecl{writes: []string{"Missing"}}},
+			wantErr: "h0 writes unknown field layerFields.Missing",
+		},
+		{
+			name: "conflicting writers",
+			decls: []FieldDependencies{
+				fieldDecl{writes: []string{"A"}},
+				fieldDecl{writes: []string{"A"}},
+			},
+			wantErr: "field layerFields.A is written by both h0 and h1",
+		},
+		{
+			name: "cycle",
+			decls: []FieldDependencies{
+				fieldDecl{reads: []string{"B"}, writes: []string{"A"}},
+				fieldDecl{reads: []string{"A"}, writes: []string{"B"}},
+				fieldDecl{writes: []string{"C"}},
+			},
+			wantErr: "dependency cycle among h0, h1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names := make([]string, len(tt.decls))
+			for i := range names {
+				names[i] = "h" + string(rune('0'+i))
+			}
+			got, err := buildLayers("Hydrator", names, tt.decls, reflect.TypeOf(layerFields{}))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err=%v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("layers=%v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// ExperimentBuckets 是每个实验的分桶数，Treatment.Buckets 以此为分母
+const ExperimentBuckets = 1000
+
+// Treatment 是实验中的一个分组
+type Treatment struct {
+	Name    string `json:"name"`
+	Buckets int    `json:"buckets"` // 分到该分组的桶数，例如 50 表示 5% 的用户
+}
+
+// Experiment 描述一个按 UserID 分桶的 A/B 实验
+//
+// 用户的桶由 hash(Salt, UserID) 决定，同一用户在同一实验中的分组是稳定的；
+// 不同实验使用不同的 Salt，分桶相互独立。Treatments 按顺序占用连续的桶，
+// 未被占用的桶不在实验中。
+type Experiment struct {
+	Name string `json:"name"`
+	// Salt 参与哈希，修改后所有用户会被重新分桶；为空时使用 Name
+	Salt       string      `json:"salt,omitempty"`
+	Treatments []Treatment `json:"treatments"`
+}
+
+// Validate 校验实验配置
+func (e Experiment) Validate() error {
+	if e.Name == "" {
+		return errors.New("experiment name is required")
+	}
+	if len(e.Treatments) == 0 {
+		return fmt.Errorf("experiment %q: at least one treatment is required", e.Name)
+	}
+	seen := make(map[string]bool, len(e.Treatments))
+	total := 0
+	for _, t := range e.Treatments {
+		if t.Name == "" {
+			return fmt.Errorf("experiment %q: treatment name is required", e.Name)
+		}
+		if seen[t.Name] {
+			return fmt.Errorf("experiment %q: duplicate treatment %q", e.Name, t.Name)
+		}
+		seen[t.Name] = true
+		if t.Buckets <= 0 {
+			return fmt.Errorf("experiment %q: treatment %q buckets must be > 0, got %d", e.Name, t.Name, t.Buckets)
+		}
+		total += t.Buckets
+	}
+	if total > ExperimentBuckets {
+		return fmt.Errorf("experiment %q: treatments use %d buckets, max %d", e.Name, total, ExperimentBuckets)
+	}
+	return nil
+}
+
+// HasTreatment 判断实验中是否存在该分组
+func (e Experiment) HasTreatment(name string) bool {
+	for _, t := range e.Treatments {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Bucket 返回用户在该实验中的桶（0 ~ ExperimentBuckets-1）
+func (e Experiment) Bucket(userID int64) int {
+	salt := e.Salt
+	if salt == "" {
+		salt = e.Name
+	}
+	h := fnv.New64a()
+	h.Write([]byte(salt))
+	h.Write([]byte{':'})
+	h.Write(strconv.AppendInt(nil, userID, 10))
+	return int(h.Sum64() % ExperimentBuckets)
+}
+
+// Assign 返回用户在该实验中的分组；用户的桶未被任何分组占用时返回 false
+func (e Experiment) Assign(userID int64) (ExperimentAssignment, bool) {
+	bucket := e.Bucket(userID)
+	upper := 0
+	for _, t := range e.Treatments {
+		upper += t.Buckets
+		if bucket < upper {
+			return ExperimentAssignment{Experiment: e.Name, Treatment: t.Name, Bucket: bucket}, true
+		}
+	}
+	return ExperimentAssignment{}, false
+}
+
+// ExperimentAssignment 表示用户在一个实验中被分到的分组
+type ExperimentAssignment struct {
+	Experiment string
+	Treatment  string
+	Bucket     int
+}
+
+// ExperimentAssignments 是一次请求的全部实验分组，按实验声明顺序排列
+type ExperimentAssignments []ExperimentAssignment
+
+// AssignExperiments 按 UserID 为请求分配所有实验的分组
+func AssignExperiments(experiments []Experiment, userID int64) ExperimentAssignments {
+	assignments := make(ExperimentAssignments, 0, len(experiments))
+	for _, e := range experiments {
+		if a, ok := e.Assign(userID); ok {
+			assignments = append(assignments, a)
+		}
+	}
+	return assignments
+}
+
+// Treatment 返回指定实验的分组，不在实验中时返回 false
+func (a ExperimentAssignments) Treatment(experiment string) (string, bool) {
+	for _, assignment := range a {
+		if assignment.Experiment == experiment {
+			return assignment.Treatment, true
+		}
+	}
+	return "", false
+}
+
+// String 返回用于日志的格式：experiment=treatment,...（不在任何实验中时为 "none"）
+func (a ExperimentAssignments) String() string {
+	if len(a) == 0 {
+		return "none"
+	}
+	parts := make([]string, len(a))
+	for i, assignment := range a {
+		parts[i] = assignment.Experiment + "=" + assignment.Treatment
+	}
+	return strings.Join(parts, ",")
+}
+
+// validateExperiments 校验一组实验配置，实验名不能重复
+func validateExperiments(experiments []Experiment) error {
+	seen := make(map[string]bool, len(experiments))
+	for _, e := range experiments {
+		if err := e.Validate(); err != nil {
+			return err
+		}
+		if seen[e.Name] {
+			return fmt.Errorf("duplicate experiment %q", e.Name)
+		}
+		seen[e.Name] = true
+	}
+	return nil
+}
+
+// assignExperiments 为请求按 UserID 分配实验分组
+// 调用方已经设置 Query.Experiments 时保持不变（例如排障时强制指定分组）
+// 拷贝后再写入，不修改调用方的 query
+func (p *CandidatePipelineOf[Q, C]) assignExperiments(query Q) Q {
+	meta := query.Meta()
+	if len(p.Experiments) == 0 || meta.Experiments != nil {
+		return query
+	}
+	assigned := query.Clone()
+	assigned.Meta().Experiments = AssignExperiments(p.Experiments, meta.UserID)
+	return assigned
+}
@@ -0,0 +1,207 @@
+package pipeline
+
+import "context"
+
+// experimentRoute 把同一组件的基础实例和按分组覆盖参数后的实例包装在一起，按请求的分组分流
+//
+// 路由后的组件使用基础实例的 Name、失败策略和字段依赖，因此日志、指标和 Deadlines.Component
+// 中的名称不随分组变化。不带 Query 的方法（Update、Selector.Score 等）使用基础实例：
+// 各实例来自同一个工厂，只有参数不同。
+type experimentRoute[Q PipelineQuery[Q], T any] struct {
+	experiment string
+	gate       map[string]bool // 非空时只对这些分组启用
+	base       T
+	variants   map[string]T
+}
+
+func newExperimentRoute[Q PipelineQuery[Q], T any](spec ComponentSpec, base T, variants map[string]T) *experimentRoute[Q, T] {
+	r := &experimentRoute[Q, T]{experiment: spec.Experiment, base: base, variants: variants}
+	if len(spec.Treatments) > 0 {
+		r.gate = make(map[string]bool, len(spec.Treatments))
+		for _, t := range spec.Treatments {
+			r.gate[t] = true
+		}
+	}
+	return r
+}
+
+// pick 返回请求所在分组的实例，没有为该分组覆盖参数时返回基础实例
+func (r *experimentRoute[Q, T]) pick(query Q) T {
+	if t := query.Meta().Treatment(r.experiment); t != "" {
+		if c, ok := r.variants[t]; ok {
+			return c
+		}
+	}
+	return r.base
+}
+
+// gated 判断请求所在分组是否启用该组件
+func (r *experimentRoute[Q, T]) gated(query Q) bool {
+	return r.gate == nil || r.gate[query.Meta().Treatment(r.experiment)]
+}
+
+// FailurePolicy 实现 FailurePolicyProvider
+func (r *experimentRoute[Q, T]) FailurePolicy() FailurePolicy {
+	return policyOf(r.base)
+}
+
+// ReadFields 实现 FieldDependencies
+func (r *experimentRoute[Q, T]) ReadFields() []string {
+	if d, ok := any(r.base).(FieldDependencies); ok {
+		return d.ReadFields()
+	}
+	return nil
+}
+
+// WriteFields 实现 FieldDependencies
+func (r *experimentRoute[Q, T]) WriteFields() []string {
+	if d, ok := any(r.base).(FieldDependencies); ok {
+		return d.WriteFields()
+	}
+	return nil
+}
+
+// routeExperiment 按组件类型包装路由后的组件
+func routeExperiment[Q PipelineQuery[Q], C any, T any](kind ComponentKind, spec ComponentSpec, base T, variants map[string]T) any {
+	switch kind {
+	case KindQueryHydrator:
+		return &experimentQueryHydrator[Q]{newExperimentRoute[Q](spec, any(base).(QueryHydratorOf[Q]), convertVariants[T, QueryHydratorOf[Q]](variants))}
+	case KindSource:
+		return &experimentSource[Q, C]{newExperimentRoute[Q](spec, any(base).(SourceOf[Q, C]), convertVariants[T, SourceOf[Q, C]](variants))}
+	case KindMerger:
+		return &experimentMerger[Q, C]{newExperimentRoute[Q](spec, any(base).(CandidateMergerOf[Q, C]), convertVariants[T, CandidateMergerOf[Q, C]](variants))}
+	case KindHydrator:
+		return &experimentHydrator[Q, C]{newExperimentRoute[Q](spec, any(base).(HydratorOf[Q, C]), convertVariants[T, HydratorOf[Q, C]](variants))}
+	case KindFilter:
+		return &experimentFilter[Q, C]{newExperimentRoute[Q](spec, any(base).(FilterOf[Q, C]), convertVariants[T, FilterOf[Q, C]](variants))}
+	case KindScorer:
+		return &experimentScorer[Q, C]{newExperimentRoute[Q](spec, any(base).(ScorerOf[Q, C]), convertVariants[T, ScorerOf[Q, C]](variants))}
+	case KindSelector:
+		return &experimentSelector[Q, C]{newExperimentRoute[Q](spec, any(base).(SelectorOf[Q, C]), convertVariants[T, SelectorOf[Q, C]](variants))}
+	case KindSideEffect:
+		return &experimentSideEffect[Q, C]{newExperimentRoute[Q](spec, any(base).(SideEffectOf[Q, C]), convertVariants[T, SideEffectOf[Q, C]](variants))}
+	}
+	return base
+}
+
+func convertVariants[From any, To any](variants map[string]From) map[string]To {
+	out := make(map[string]To, len(variants))
+	for t, v := range variants {
+		out[t] = any(v).(To)
+	}
+	return out
+}
+
+type experimentQueryHydrator[Q PipelineQuery[Q]] struct {
+	*experimentRoute[Q, QueryHydratorOf[Q]]
+}
+
+func (h *experimentQueryHydrator[Q]) Hydrate(ctx context.Context, query Q) (Q, error) {
+	return h.pick(query).Hydrate(ctx, query)
+}
+func (h *experimentQueryHydrator[Q]) Name() string { return h.base.Name() }
+func (h *experimentQueryHydrator[Q]) Enable(query Q) bool {
+	return h.gated(query) && h.pick(query).Enable(query)
+}
+func (h *experimentQueryHydrator[Q]) Update(query Q, hydrated Q) {
+	h.pick(query).Update(query, hydrated)
+}
+
+type experimentSource[Q PipelineQuery[Q], C any] struct {
+	*experimentRoute[Q, SourceOf[Q, C]]
+}
+
+func (s *experimentSource[Q, C]) GetCandidates(ctx context.Context, query Q) ([]C, error) {
+	return s.pick(query).GetCandidates(ctx, query)
+}
+func (s *experimentSource[Q, C]) Name() string { return s.base.Name() }
+func (s *experimentSource[Q, C]) Enable(query Q) bool {
+	return s.gated(query) && s.pick(query).Enable(query)
+}
+
+type experimentMerger[Q PipelineQuery[Q], C any] struct {
+	*experimentRoute[Q, CandidateMergerOf[Q, C]]
+}
+
+func (m *experimentMerger[Q, C]) Merge(ctx context.Context, query Q, candidates []C) ([]C, []C) {
+	if !m.gated(query) {
+		return candidates, nil
+	}
+	return m.pick(query).Merge(ctx, query, candidates)
+}
+func (m *experimentMerger[Q, C]) Name() string { return m.base.Name() }
+
+type experimentHydrator[Q PipelineQuery[Q], C any] struct {
+	*experimentRoute[Q, HydratorOf[Q, C]]
+}
+
+func (h *experimentHydrator[Q, C]) Hydrate(ctx context.Context, query Q, candidates []C) ([]C, error) {
+	return h.pick(query).Hydrate(ctx, query, candidates)
+}
+func (h *experimentHydrator[Q, C]) Name() string { return h.base.Name() }
+func (h *experimentHydrator[Q, C]) Enable(query Q) bool {
+	return h.gated(query) && h.pick(query).Enable(query)
+}
+func (h *experimentHydrator[Q, C]) Update(candidate C, hydrated C) {
+	h.base.Update(candidate, hydrated)
+}
+func (h *experimentHydrator[Q, C]) UpdateAll(candidates []C, hydrated []C) {
+	h.base.UpdateAll(candidates, hydrated)
+}
+
+type experimentFilter[Q PipelineQuery[Q], C any] struct {
+	*experimentRoute[Q, FilterOf[Q, C]]
+}
+
+func (f *experimentFilter[Q, C]) Filter(ctx context.Context, query Q, candidates []C) (*FilterResultOf[C], error) {
+	return f.pick(query).Filter(ctx, query, candidates)
+}
+func (f *experimentFilter[Q, C]) Name() string { return f.base.Name() }
+func (f *experimentFilter[Q, C]) Enable(query Q) bool {
+	return f.gated(query) && f.pick(query).Enable(query)
+}
+
+type experimentScorer[Q PipelineQuery[Q], C any] struct {
+	*experimentRoute[Q, ScorerOf[Q, C]]
+}
+
+func (s *experimentScorer[Q, C]) Score(ctx context.Context, query Q, candidates []C) ([]C, error) {
+	return s.pick(query).Score(ctx, query, candidates)
+}
+func (s *experimentScorer[Q, C]) Name() string { return s.base.Name() }
+func (s *experimentScorer[Q, C]) Enable(query Q) bool {
+	return s.gated(query) && s.pick(query).Enable(query)
+}
+func (s *experimentScorer[Q, C]) Update(candidate C, scored C) {
+	s.base.Update(candidate, scored)
+}
+func (s *experimentScorer[Q, C]) UpdateAll(candidates []C, scored []C) {
+	s.base.UpdateAll(candidates, scored)
+}
+
+type experimentSelector[Q PipelineQuery[Q], C any] struct {
+	*experimentRoute[Q, SelectorOf[Q, C]]
+}
+
+func (s *experimentSelector[Q, C]) Select(ctx context.Context, query Q, candidates []C) []C {
+	return s.pick(query).Select(ctx, query, candidates)
+}
+func (s *experimentSelector[Q, C]) Name() string { return s.base.Name() }
+func (s *experimentSelector[Q, C]) Enable(query Q) bool {
+	return s.gated(query) && s.pick(query).Enable(query)
+}
+func (s *experimentSelector[Q, C]) Score(candidate C) float64 { return s.base.Score(candidate) }
+func (s *experimentSelector[Q, C]) Sort(candidates []C) []C   { return s.base.Sort(candidates) }
+func (s *experimentSelector[Q, C]) Size() *int                { return s.base.Size() }
+
+type experimentSideEffect[Q PipelineQuery[Q], C any] struct {
+	*experimentRoute[Q, SideEffectOf[Q, C]]
+}
+
+func (s *experimentSideEffect[Q, C]) Run(ctx context.Context, query Q, candidates []C) error {
+	return s.pick(query).Run(ctx, query, candidates)
+}
+func (s *experimentSideEffect[Q, C]) Name() string { return s.base.Name() }
+func (s *experimentSideEffect[Q, C]) Enable(query Q) bool {
+	return s.gated(query) && s.pick(query).Enable(query)
+}
@@ -0,0 +1,201 @@
+package pipeline
+
+import "reflect"
+
+// CandidateExplanationOf 记录单个候选在管道中的完整轨迹（explain 模式）
+// 用于回答 "为什么我没有看到这条帖子" 之类的排障问题
+type CandidateExplanationOf[C any] struct {
+	ID         int64                  // 候选的 Key（首页时间线为 TweetID）
+	Candidate  C                      // 候选本身，反映执行结束时的状态（增强后的字段和分数）
+	Source     string                 // 产生该候选的 Source 名称
+	Hydrations []HydrationStep        // 每个 Hydrator 实际填充了哪些字段
+	Scores     []ScoreStep            // 每个 Scorer 执行后的分数
+	Removal    *RemovedCandidateOf[C] // 被移除的位置，nil 表示未被移除
+	Selected   bool                   // 是否出现在最终结果中
+}
+
+// HydrationStep 表示一个 Hydrator 对候选的一次增强
+type HydrationStep struct {
+	Stage     string
+	Component string
+	Fields    []string // 被修改的候选字段名
+}
+
+// ScoreStep 表示一个 Scorer 执行后候选的分数快照
+type ScoreStep struct {
+	Component    string
+	PreRankScore *float64
+	Score        *float64
+	Extra        map[string]float64 // 候选类型提供的其他分数（见 ScoreSnapshotter），例如首页的 WeightedScore
+}
+
+// ScoreSnapshotter 由 CandidateMeta 之外还有分数字段的候选类型实现（可选）
+// explain 模式下每个 Scorer 执行后记录一次，未实现该接口的候选只记录 PreRankScore 和 Score
+type ScoreSnapshotter interface {
+	// ScoreSnapshot 返回当前的分数字段，按字段名索引；没有值的分数不出现在结果中
+	ScoreSnapshot() map[string]float64
+}
+
+// explainer 在 explain 模式下收集候选轨迹
+// nil 的 explainer 上所有方法都是空操作，因此普通请求没有额外开销
+type explainer[C PipelineCandidate[C]] struct {
+	byCandidate map[C]*CandidateExplanationOf[C]
+	ordered     []*CandidateExplanationOf[C]
+}
+
+// newExplainer 创建 explainer，未开启 explain 时返回 nil
+func newExplainer[C PipelineCandidate[C]](enabled bool) *explainer[C] {
+	if !enabled {
+		return nil
+	}
+	return &explainer[C]{
+		byCandidate: make(map[C]*CandidateExplanationOf[C]),
+	}
+}
+
+// sourced 记录候选由哪个 Source 产生
+func (e *explainer[C]) sourced(source string, candidates []C) {
+	if e == nil {
+		return
+	}
+	for _, c := range candidates {
+		if _, ok := e.byCandidate[c]; ok {
+			continue
+		}
+		ex := &CandidateExplanationOf[C]{ID: c.Key(), Candidate: c, Source: source}
+		e.byCandidate[c] = ex
+		e.ordered = append(e.ordered, ex)
+	}
+}
+
+// snapshot 在 Update 之前拍下候选快照，用于之后计算被修改的字段
+// 未开启 explain 时返回零值
+func (e *explainer[C]) snapshot(c C) C {
+	if e == nil {
+		var zero C
+		return zero
+	}
+	return c.Clone()
+}
+
+// hydrated 对比快照与更新后的候选，记录被修改的字段
+func (e *explainer[C]) hydrated(stage, component string, before, after C) {
+	var zero C
+	if e == nil || before == zero {
+		return
+	}
+	ex, ok := e.byCandidate[after]
+	if !ok {
+		return
+	}
+	fields := changedFields(before, after)
+	if len(fields) == 0 {
+		return
+	}
+	// 同步 Key，Hydrator 理论上不会修改它，但以最终值为准
+	ex.ID = after.Key()
+	ex.Hydrations = append(ex.Hydrations, HydrationStep{
+		Stage:     stage,
+		Component: component,
+		Fields:    fields,
+	})
+}
+
+// scored 记录某个 Scorer 执行后所有候选的分数
+func (e *explainer[C]) scored(component string, candidates []C) {
+	if e == nil {
+		return
+	}
+	for _, c := range candidates {
+		ex, ok := e.byCandidate[c]
+		if !ok {
+			continue
+		}
+		meta := c.Meta()
+		step := ScoreStep{
+			Component:    component,
+			PreRankScore: copyFloat(meta.PreRankScore),
+			Score:        copyFloat(meta.Score),
+		}
+		if s, ok := any(c).(ScoreSnapshotter); ok {
+			step.Extra = s.ScoreSnapshot()
+		}
+		ex.Scores = append(ex.Scores, step)
+	}
+}
+
+// removed 记录候选被移除的位置
+func (e *explainer[C]) removed(r RemovedCandidateOf[C]) {
+	if e == nil {
+		return
+	}
+	ex, ok := e.byCandidate[r.Candidate]
+	if !ok || ex.Removal != nil {
+		return
+	}
+	removal := r
+	ex.Removal = &removal
+}
+
+// dropped 记录 before 中存在但 after 中不存在的候选（Selector 或截断造成的移除）
+func (e *explainer[C]) dropped(stage, component string, reason RemovalReason, before, after []C) {
+	if e == nil {
+		return
+	}
+	kept := make(map[C]bool, len(after))
+	for _, c := range after {
+		kept[c] = true
+	}
+	for _, c := range before {
+		if kept[c] {
+			continue
+		}
+		e.removed(RemovedCandidateOf[C]{Candidate: c, Stage: stage, Component: component, Reason: reason})
+	}
+}
+
+// finish 标记最终选中的候选，返回按检索顺序排列的轨迹
+func (e *explainer[C]) finish(selected []C) []*CandidateExplanationOf[C] {
+	if e == nil {
+		return nil
+	}
+	for _, c := range selected {
+		if ex, ok := e.byCandidate[c]; ok {
+			ex.Selected = true
+		}
+	}
+	return e.ordered
+}
+
+// changedFields 返回 before 与 after 之间值不同的候选字段名
+// 嵌入的结构体（例如 CandidateMeta）按其中的字段比较
+func changedFields(before, after any) []string {
+	return appendChangedFields(nil, reflect.Indirect(reflect.ValueOf(before)), reflect.Indirect(reflect.ValueOf(after)))
+}
+
+func appendChangedFields(fields []string, bv, av reflect.Value) []string {
+	t := bv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			fields = appendChangedFields(fields, bv.Field(i), av.Field(i))
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if !reflect.DeepEqual(bv.Field(i).Interface(), av.Field(i).Interface()) {
+			fields = append(fields, f.Name)
+		}
+	}
+	return fields
+}
+
+// copyFloat 复制 float64 指针，避免快照被后续 Scorer 修改
+func copyFloat(p *float64) *float64 {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
@@ -0,0 +1,144 @@
+package pipeline
+
+import (
+	"fmt"
+	"log"
+	"runtime/debug"
+)
+
+// FailurePolicy 表示组件失败（返回错误、超时或 panic）时管道的处理策略
+type FailurePolicy int
+
+const (
+	// FailOpen 记录日志并跳过该组件，候选保持原样继续执行（默认策略）
+	// 适用于增强类组件：缺少数据只会让结果变差，不会造成安全问题
+	FailOpen FailurePolicy = iota
+
+	// FailClosed 丢弃该组件无法评估的所有候选
+	// 适用于安全相关组件：例如 VF、屏蔽/静音过滤，宁可少出内容也不能漏过
+	//   - Hydrator / Filter / Scorer：丢弃本阶段的全部输入候选，原因代码为 ReasonFailClosed
+	//   - QueryHydrator：本次请求不产出任何候选
+	//   - Source：与 FailOpen 相同（缺少一个 Source 不会放过不安全的内容）
+	FailClosed
+
+	// Critical 终止整个请求，Execute 返回 *ComponentError
+	Critical
+)
+
+// String 返回策略名称（用于日志）
+func (fp FailurePolicy) String() string {
+	switch fp {
+	case FailOpen:
+		return "fail_open"
+	case FailClosed:
+		return "fail_closed"
+	case Critical:
+		return "critical"
+	default:
+		return fmt.Sprintf("FailurePolicy(%d)", int(fp))
+	}
+}
+
+// FailurePolicyProvider 由需要非默认失败策略的组件实现（可选）
+// 未实现该接口的组件使用 FailOpen
+type FailurePolicyProvider interface {
+	FailurePolicy() FailurePolicy
+}
+
+// ReasonFailClosed 表示候选因为 fail-closed 组件失败而被丢弃
+const ReasonFailClosed RemovalReason = "fail_closed"
+
+// policyOf 返回组件声明的失败策略
+func policyOf(component any) FailurePolicy {
+	if p, ok := component.(FailurePolicyProvider); ok {
+		return p.FailurePolicy()
+	}
+	return FailOpen
+}
+
+// ComponentError 表示 critical 组件失败导致请求终止
+type ComponentError struct {
+	Stage     string
+	Component string
+	Err       error
+}
+
+// Error 实现 error 接口
+func (e *ComponentError) Error() string {
+	return fmt.Sprintf("stage=%s component=%s failed: %v", e.Stage, e.Component, e.Err)
+}
+
+// Unwrap 返回底层错误，便于 errors.Is 判断超时等情况
+func (e *ComponentError) Unwrap() error {
+	return e.Err
+}
+
+// PanicError 表示组件发生 panic，已被管道恢复
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+// Error 实现 error 接口
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// recoverAsError 把 panic 转换为 *PanicError，写入 err
+// 用法：defer recoverAsError(&err)
+func recoverAsError(err *error) {
+	if r := recover(); r != nil {
+		*err = &PanicError{Value: r, Stack: debug.Stack()}
+	}
+}
+
+// failure 描述一次组件失败
+type failure struct {
+	stage     string
+	name      string
+	component any
+	err       error
+	timedOut  bool
+}
+
+// handle 按组件的失败策略记录日志，并返回策略
+// 策略为 Critical 时调用方应终止请求并返回 abortError()
+func (f failure) handle(requestID string) FailurePolicy {
+	policy := policyOf(f.component)
+	kind := "failed"
+	if f.timedOut {
+		kind = "timed_out"
+	}
+	log.Printf("request_id=%s stage=%s component=%s %s policy=%s: %v",
+		requestID, f.stage, f.name, kind, policy, f.err)
+	if pe, ok := f.err.(*PanicError); ok {
+		log.Printf("request_id=%s stage=%s component=%s panic stack:\n%s",
+			requestID, f.stage, f.name, pe.Stack)
+	}
+	return policy
+}
+
+// abortError 返回终止请求时的错误
+func (f failure) abortError() error {
+	return &ComponentError{Stage: f.stage, Component: f.name, Err: f.err}
+}
+
+// dropAll 把 candidates 全部记为被 fail-closed 组件移除
+func dropAll[C any](candidates []C, stage, component string) []RemovedCandidateOf[C] {
+	removed := make([]RemovedCandidateOf[C], len(candidates))
+	for i, c := range candidates {
+		removed[i] = RemovedCandidateOf[C]{
+			Candidate: c,
+			Stage:     stage,
+			Component: component,
+			Reason:    ReasonFailClosed,
+		}
+	}
+	return removed
+}
+
+// safeCall 调用组件，并把 panic 转换为错误
+func safeCall[T any](call func() (T, error)) (v T, err error) {
+	defer recoverAsError(&err)
+	return call()
+}
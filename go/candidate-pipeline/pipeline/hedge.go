@@ -0,0 +1,207 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Hedging 配置 Source 和 Hydrator 阶段的对冲请求
+//
+// 启用对冲的组件在调用超过对冲延迟仍未返回时，会再发起一次相同的调用，使用先成功返回的结果，
+// 并取消另一次调用。对冲延迟是组件最近成功调用耗时的 Percentile 分位数，
+// 因此只有尾部的慢调用会被对冲；样本不足时不对冲。
+// 对冲会增加下游的负载：MaxRatio 限制对冲请求占调用数的比例，下游整体变慢时不会把负载翻倍。
+//
+// 组件必须可以安全地重复调用（只读、无副作用），两次调用会并发执行且收到相同的输入。
+// 零值表示不对冲。
+type Hedging struct {
+	// Components 是启用对冲的组件名（Source 或 Hydrator 的 Name()）
+	Components []string
+	// Percentile 是对冲延迟使用的耗时分位数，(0, 1)，0 表示使用默认值 0.95
+	Percentile float64
+	// MinDelay 是对冲延迟的下限，避免对本来就很快的调用发起对冲
+	MinDelay time.Duration
+	// MaxRatio 是对冲请求数占调用数的上限，(0, 1]，0 表示使用默认值 0.1
+	MaxRatio float64
+}
+
+const (
+	defaultHedgePercentile = 0.95
+	defaultHedgeMaxRatio   = 0.1
+
+	// hedgeSamples 是计算分位数时保留的最近成功调用耗时的数量
+	hedgeSamples = 256
+	// hedgeMinSamples 是开始对冲前至少需要的样本数
+	hedgeMinSamples = 20
+	// hedgeMaxTokens 限制空闲期间累积的对冲额度，避免流量恢复时集中对冲
+	hedgeMaxTokens = 10
+)
+
+// validate 返回不合法的对冲配置；names 是可以对冲的组件名（Sources 和 Hydrators）
+func (h Hedging) validate(names map[string]bool) []error {
+	var errs []error
+	if h.Percentile < 0 || h.Percentile >= 1 {
+		errs = append(errs, fmt.Errorf("hedging: percentile must be in (0, 1), got %v", h.Percentile))
+	}
+	if h.MaxRatio < 0 || h.MaxRatio > 1 {
+		errs = append(errs, fmt.Errorf("hedging: max_ratio must be in (0, 1], got %v", h.MaxRatio))
+	}
+	if h.MinDelay < 0 {
+		errs = append(errs, fmt.Errorf("hedging: min_delay must be >= 0, got %s", h.MinDelay))
+	}
+	seen := make(map[string]bool, len(h.Components))
+	for _, name := range h.Components {
+		switch {
+		case !names[name]:
+			errs = append(errs, fmt.Errorf("hedging: component %s is not a source or hydrator", name))
+		case seen[name]:
+			errs = append(errs, fmt.Errorf("hedging: duplicate component %s", name))
+		}
+		seen[name] = true
+	}
+	return errs
+}
+
+// hedgers 为 Hedging.Components 中的每个组件创建对冲状态
+func (h Hedging) hedgers() map[string]*hedger {
+	if len(h.Components) == 0 {
+		return nil
+	}
+	percentile, ratio := h.Percentile, h.MaxRatio
+	if percentile == 0 {
+		percentile = defaultHedgePercentile
+	}
+	if ratio == 0 {
+		ratio = defaultHedgeMaxRatio
+	}
+	out := make(map[string]*hedger, len(h.Components))
+	for _, name := range h.Components {
+		out[name] = &hedger{percentile: percentile, minDelay: h.MinDelay, ratio: ratio}
+	}
+	return out
+}
+
+// hedgerFor 返回组件的对冲状态，不对冲时返回 nil
+// 只有 Source 和 Hydrator 阶段（请求延迟的关键路径）的组件会被对冲
+func (p *CandidatePipelineOf[Q, C]) hedgerFor(stage, name string) *hedger {
+	if stage != StageSource && stage != StageHydrator {
+		return nil
+	}
+	return p.hedgers[name]
+}
+
+// hedger 记录一个组件的调用耗时和对冲额度，并发安全
+type hedger struct {
+	percentile float64
+	minDelay   time.Duration
+	ratio      float64
+
+	mu      sync.Mutex
+	samples []time.Duration // 环形缓冲区
+	next    int
+	tokens  float64
+}
+
+// observe 记录一次成功调用的耗时
+func (h *hedger) observe(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.samples) < hedgeSamples {
+		h.samples = append(h.samples, d)
+		return
+	}
+	h.samples[h.next] = d
+	h.next = (h.next + 1) % hedgeSamples
+}
+
+// start 在一次调用开始时调用，返回对冲延迟；样本不足时返回 false
+func (h *hedger) start() (time.Duration, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.tokens += h.ratio; h.tokens > hedgeMaxTokens {
+		h.tokens = hedgeMaxTokens
+	}
+	if len(h.samples) < hedgeMinSamples {
+		return 0, false
+	}
+	sorted := append([]time.Duration(nil), h.samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	delay := sorted[int(h.percentile*float64(len(sorted)-1))]
+	if delay < h.minDelay {
+		delay = h.minDelay
+	}
+	return delay, true
+}
+
+// take 消耗一次对冲额度，额度不足时返回 false
+func (h *hedger) take() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.tokens < 1 {
+		return false
+	}
+	h.tokens--
+	return true
+}
+
+// hedgeCall 调用组件；h 不为 nil 时，调用超过对冲延迟仍未返回则再发起一次调用
+// 返回先成功的结果（都失败时返回最后一个错误），另一次调用的 ctx 会被取消；
+// hedged 记录是否发起了对冲（可能在超时后才写入，因此是原子的）
+func hedgeCall[T any](ctx context.Context, h *hedger, hedged *atomic.Bool, call func(ctx context.Context) (T, error)) (T, error) {
+	if h == nil {
+		return call(ctx)
+	}
+	delay, ok := h.start()
+
+	type attempt struct {
+		value   T
+		err     error
+		elapsed time.Duration
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan attempt, 2)
+	launch := func() {
+		go func() {
+			start := time.Now()
+			v, err := safeCall(func() (T, error) { return call(ctx) })
+			results <- attempt{value: v, err: err, elapsed: time.Since(start)}
+		}()
+	}
+
+	launch()
+	pending := 1
+	var timer <-chan time.Time
+	if ok {
+		t := time.NewTimer(delay)
+		defer t.Stop()
+		timer = t.C
+	}
+	var last attempt
+	for {
+		select {
+		case <-timer:
+			timer = nil
+			if h.take() {
+				hedged.Store(true)
+				launch()
+				pending++
+			}
+		case a := <-results:
+			pending--
+			if a.err == nil {
+				h.observe(a.elapsed)
+				return a.value, nil
+			}
+			last = a
+			// 还有调用在进行时等待它；只发起过一次调用时，失败不触发对冲（由组件的失败策略处理）
+			if pending == 0 {
+				return last.value, last.err
+			}
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package home
+
+import (
+	"context"
+	"fmt"
+
+	"x-algorithm-go/candidate-pipeline/pipeline"
+)
+
+// ServedType 表示候选的投放类型（与 ScoredPost.served_type 对应）
+type ServedType int32
+
+const (
+	// ServedTypeForYouInNetwork 表示站内候选（来自关注的作者，例如 Thunder）
+	ServedTypeForYouInNetwork ServedType = 0
+	// ServedTypeForYouPhoenixRetrieval 表示站外候选（来自 Phoenix 检索）
+	ServedTypeForYouPhoenixRetrieval ServedType = 1
+)
+
+var servedTypeNames = map[ServedType]string{
+	ServedTypeForYouInNetwork:        "for_you_in_network",
+	ServedTypeForYouPhoenixRetrieval: "for_you_phoenix_retrieval",
+}
+
+// String 返回 ServedType 的名称（用于日志和定义文件）
+func (t ServedType) String() string {
+	if name, ok := servedTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("served_type_%d", int32(t))
+}
+
+// MarshalText 实现 encoding.TextMarshaler
+func (t ServedType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText 实现 encoding.TextUnmarshaler
+func (t *ServedType) UnmarshalText(text []byte) error {
+	for v, name := range servedTypeNames {
+		if name == string(text) {
+			*t = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown served type %q", text)
+}
+
+// MergePolicy 配置 SourceMerger 如何在重复候选中选择主候选
+type MergePolicy struct {
+	// ServedTypePriority 按优先级排列的 ServedType，靠前的优先成为主候选
+	// 未列出的类型（以及没有 ServedType 的候选）排在最后
+	ServedTypePriority []ServedType `json:"served_type_priority"`
+}
+
+// DefaultMergePolicy 返回默认的合并策略：站内候选优先
+func DefaultMergePolicy() MergePolicy {
+	return MergePolicy{
+		ServedTypePriority: []ServedType{ServedTypeForYouInNetwork, ServedTypeForYouPhoenixRetrieval},
+	}
+}
+
+// SourceMerger 按 TweetID 合并重复候选
+//
+// 合并结果是确定的：每条帖子出现在它第一次出现的位置；主候选是 ServedType 优先级最高的候选，
+// 同优先级时取 Sources 声明顺序中靠前的。主候选的 Provenance 合并所有重复候选的来源，
+// 主候选缺失的关系字段（InReplyToTweetID / Ancestors 等）从其他候选补齐。
+type SourceMerger struct {
+	policy   MergePolicy
+	priority map[ServedType]int
+}
+
+// NewSourceMerger 创建按给定策略合并的 SourceMerger
+func NewSourceMerger(policy MergePolicy) *SourceMerger {
+	priority := make(map[ServedType]int, len(policy.ServedTypePriority))
+	for i, t := range policy.ServedTypePriority {
+		if _, ok := priority[t]; !ok {
+			priority[t] = i
+		}
+	}
+	return &SourceMerger{policy: policy, priority: priority}
+}
+
+// Merge 实现 CandidateMerger
+func (m *SourceMerger) Merge(ctx context.Context, query *Query, candidates []*Candidate) ([]*Candidate, []*Candidate) {
+	groups := make(map[int64][]*Candidate, len(candidates))
+	order := make([]int64, 0, len(candidates))
+	for _, c := range candidates {
+		if _, ok := groups[c.TweetID]; !ok {
+			order = append(order, c.TweetID)
+		}
+		groups[c.TweetID] = append(groups[c.TweetID], c)
+	}
+	if len(order) == len(candidates) {
+		return candidates, nil
+	}
+
+	merged := make([]*Candidate, 0, len(order))
+	var duplicates []*Candidate
+	for _, id := range order {
+		group := groups[id]
+		if len(group) == 1 {
+			merged = append(merged, group[0])
+			continue
+		}
+		primary := group[0]
+		for _, c := range group[1:] {
+			if m.rank(c) < m.rank(primary) {
+				primary = c
+			}
+		}
+		var provenance []pipeline.SourceProvenance
+		for _, c := range group {
+			provenance = append(provenance, c.Provenance...)
+			if c == primary {
+				continue
+			}
+			fillMissing(primary, c)
+			duplicates = append(duplicates, c)
+		}
+		primary.Provenance = provenance
+		merged = append(merged, primary)
+	}
+	return merged, duplicates
+}
+
+// rank 返回候选的 ServedType 优先级，越小越优先
+func (m *SourceMerger) rank(c *Candidate) int {
+	if c.ServedType != nil {
+		if r, ok := m.priority[*c.ServedType]; ok {
+			return r
+		}
+	}
+	return len(m.policy.ServedTypePriority)
+}
+
+// Name 实现 CandidateMerger
+func (m *SourceMerger) Name() string {
+	return "SourceMerger"
+}
+
+// fillMissing 用 other 补齐 primary 中 Source 可能给出、但 primary 缺失的字段
+func fillMissing(primary, other *Candidate) {
+	if primary.AuthorID == 0 {
+		primary.AuthorID = other.AuthorID
+	}
+	if primary.InReplyToTweetID == nil {
+		primary.InReplyToTweetID = other.InReplyToTweetID
+	}
+	if primary.RetweetedTweetID == nil {
+		primary.RetweetedTweetID = other.RetweetedTweetID
+	}
+	if primary.RetweetedUserID == nil {
+		primary.RetweetedUserID = other.RetweetedUserID
+	}
+	if len(primary.Ancestors) == 0 {
+		primary.Ancestors = other.Ancestors
+	}
+}
@@ -0,0 +1,50 @@
+package home
+
+import "x-algorithm-go/candidate-pipeline/pipeline"
+
+// CandidatePipeline 是首页时间线的候选管道
+type CandidatePipeline = pipeline.CandidatePipelineOf[*Query, *Candidate]
+
+// PipelineResult 是首页时间线管道的执行结果
+type PipelineResult = pipeline.PipelineResultOf[*Query, *Candidate]
+
+// RemovedCandidate 是首页时间线管道中被移除的候选
+type RemovedCandidate = pipeline.RemovedCandidateOf[*Candidate]
+
+// CandidateExplanation 是首页时间线管道中单个候选的轨迹
+type CandidateExplanation = pipeline.CandidateExplanationOf[*Candidate]
+
+// QueryHydrator 是首页时间线管道的查询增强器
+type QueryHydrator = pipeline.QueryHydratorOf[*Query]
+
+// Source 是首页时间线管道的候选源
+type Source = pipeline.SourceOf[*Query, *Candidate]
+
+// CandidateMerger 是首页时间线管道的候选合并器
+type CandidateMerger = pipeline.CandidateMergerOf[*Query, *Candidate]
+
+// Hydrator 是首页时间线管道的候选增强器
+type Hydrator = pipeline.HydratorOf[*Query, *Candidate]
+
+// Filter 是首页时间线管道的过滤器
+type Filter = pipeline.FilterOf[*Query, *Candidate]
+
+// FilterResult 是首页时间线管道中过滤器的执行结果
+type FilterResult = pipeline.FilterResultOf[*Candidate]
+
+// Scorer 是首页时间线管道的打分器
+type Scorer = pipeline.ScorerOf[*Query, *Candidate]
+
+// Selector 是首页时间线管道的选择器
+type Selector = pipeline.SelectorOf[*Query, *Candidate]
+
+// SideEffect 是首页时间线管道的副作用
+type SideEffect = pipeline.SideEffectOf[*Query, *Candidate]
+
+// Registry 是首页时间线管道的组件注册表
+type Registry = pipeline.RegistryOf[*Query, *Candidate]
+
+// NewRegistry 创建首页时间线管道的空组件注册表
+func NewRegistry() *Registry {
+	return pipeline.NewRegistryOf[*Query, *Candidate]()
+}
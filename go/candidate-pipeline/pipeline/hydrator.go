@@ -2,34 +2,41 @@ package pipeline
 
 import "context"
 
-// Hydrator 表示候选增强器接口
+// HydratorOf 表示候选增强器接口
 // Hydrators 并行执行，每个 hydrator 补充不同的数据
-type Hydrator interface {
+type HydratorOf[Q any, C any] interface {
 	// Hydrate 增强候选列表
 	// 执行异步操作，返回增强后的候选列表
 	// 
 	// 重要：返回的切片必须与输入的候选数量相同且顺序一致
 	// 不允许在 hydrator 中删除候选，应该使用 filter 阶段
-	Hydrate(ctx context.Context, query *Query, candidates []*Candidate) ([]*Candidate, error)
+	//
+	// 返回的候选是补丁：只需填写本 hydrator 负责的字段（通常用 NewPatches 分配），
+	// 管道通过 Update 合并到原候选；不要修改输入的候选，也不需要 Clone
+	//
+	// ctx 在组件超时或阶段预算用尽时被取消，此时管道已放弃该 hydrator，实现应尽快返回。
+	// 可能被放弃时，输入的候选是同层 hydrators 共享的快照，被放弃后继续读取不会与管道的合并冲突
+	Hydrate(ctx context.Context, query Q, candidates []C) ([]C, error)
 	
 	// Name 返回 Hydrator 的名称（用于日志和监控）
 	Name() string
 	
 	// Enable 决定这个 Hydrator 是否应该为给定的查询执行
 	// 默认返回 true，子类可以覆盖以实现条件执行
-	Enable(query *Query) bool
+	Enable(query Q) bool
 	
 	// Update 更新单个候选的增强字段
-	// 只应该复制这个 hydrator 负责的字段
-	Update(candidate *Candidate, hydrated *Candidate)
+	// 只应该复制这个 hydrator 负责的字段；hydrated 是 Hydrate 返回的补丁，其他字段为零值
+	Update(candidate C, hydrated C)
 	
 	// UpdateAll 批量更新候选的增强字段
 	// 默认实现遍历并调用 Update 方法
-	UpdateAll(candidates []*Candidate, hydrated []*Candidate)
+	UpdateAll(candidates []C, hydrated []C)
 }
 
+
 // DefaultUpdateAll 提供 UpdateAll 的默认实现
-func DefaultUpdateAll(hydrator Hydrator, candidates []*Candidate, hydrated []*Candidate) {
+func DefaultUpdateAll[C any](hydrator interface{ Update(C, C) }, candidates []C, hydrated []C) {
 	if len(candidates) != len(hydrated) {
 		return
 	}
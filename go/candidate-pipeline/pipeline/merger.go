@@ -0,0 +1,87 @@
+package pipeline
+
+import (
+	"context"
+	"log"
+)
+
+// SourceProvenance 记录候选由哪个 Source 返回以及在该 Source 结果中的位置
+type SourceProvenance struct {
+	Source string   // Source 名称
+	Rank   int      // 在该 Source 返回结果中的位置（从 0 开始）
+	Score  *float64 // Source 给出的检索分数（可选）
+}
+
+// CandidateMergerOf 在 Sourcing 之后合并多个 Source 返回的重复候选
+//
+// candidates 按 Sources 的声明顺序排列，每个候选带有一条 Provenance。
+// Merge 返回合并后的候选和被合并掉的重复候选；被合并掉的候选以 ReasonMerged 记入移除记录。
+// 与 Filter 相同，Merge 不能修改输入切片的顺序。
+type CandidateMergerOf[Q any, C any] interface {
+	Merge(ctx context.Context, query Q, candidates []C) (merged, duplicates []C)
+
+	// Name 返回 Merger 的名称（用于日志和监控）
+	Name() string
+}
+
+// ReasonMerged 表示候选与另一个 Source 返回的同一条帖子合并，由保留的候选代表
+const ReasonMerged RemovalReason = "merged"
+
+// stampProvenance 为 Source 返回的候选记录来源
+// Source 可以预先在 Provenance[0].Score 中给出检索分数，其余字段由管道填写
+func stampProvenance[C PipelineCandidate[C]](source string, candidates []C) {
+	for i, c := range candidates {
+		meta := c.Meta()
+		var score *float64
+		if len(meta.Provenance) > 0 {
+			score = meta.Provenance[0].Score
+		}
+		meta.Provenance = []SourceProvenance{{Source: source, Rank: i, Score: score}}
+	}
+}
+
+// mergeCandidates 执行 Merger；未配置时原样返回
+// Merger panic 时按其失败策略处理，fail-open 时保留未合并的候选
+func (p *CandidatePipelineOf[Q, C]) mergeCandidates(ctx context.Context, query Q, candidates []C, ex *explainer[C]) ([]C, []RemovedCandidateOf[C], error) {
+	if p.Merger == nil {
+		return candidates, nil, nil
+	}
+	name := p.Merger.Name()
+	requestID := query.Meta().RequestID
+	span := startComponent(p.observer(ctx), ctx, requestID, StageMerge, name, len(candidates))
+	type mergeResult struct{ merged, duplicates []C }
+	r, err := safeCall(func() (mergeResult, error) {
+		merged, duplicates := p.Merger.Merge(span.ctx, query, candidates)
+		return mergeResult{merged, duplicates}, nil
+	})
+	if err != nil {
+		f := failure{stage: StageMerge, name: name, component: p.Merger, err: err}
+		policy := f.handle(requestID)
+		switch policy {
+		case Critical:
+			span.fail(f, policy, 0, 0, 0)
+			return nil, nil, f.abortError()
+		case FailClosed:
+			dropped := dropAll(candidates, StageMerge, name)
+			for _, d := range dropped {
+				ex.removed(d)
+			}
+			span.fail(f, policy, 0, len(dropped), 0)
+			return []C{}, dropped, nil
+		}
+		span.fail(f, policy, len(candidates), 0, 0)
+		return candidates, nil, nil
+	}
+
+	if len(r.duplicates) > 0 {
+		log.Printf("request_id=%s stage=%s component=%s merged %d duplicates, %d candidates remain",
+			requestID, StageMerge, name, len(r.duplicates), len(r.merged))
+	}
+	removed := make([]RemovedCandidateOf[C], len(r.duplicates))
+	for i, c := range r.duplicates {
+		removed[i] = RemovedCandidateOf[C]{Candidate: c, Stage: StageMerge, Component: name, Reason: ReasonMerged}
+		ex.removed(removed[i])
+	}
+	span.succeed(len(r.merged), len(removed), 0)
+	return r.merged, removed, nil
+}
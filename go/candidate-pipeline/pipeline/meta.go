@@ -0,0 +1,140 @@
+package pipeline
+
+import "time"
+
+// PipelineQuery 是管道对查询类型的要求
+// 产品的查询类型嵌入 RequestMeta 并实现 Clone 即可：Meta 由 RequestMeta 提供
+type PipelineQuery[Q any] interface {
+	// Clone 返回查询的深拷贝，管道在交给可能被放弃的组件之前会拷贝查询
+	Clone() Q
+	// Meta 返回管道读写的请求字段
+	Meta() *RequestMeta
+}
+
+// PipelineCandidate 是管道对候选类型的要求（通常是指向结构体的指针）
+// 产品的候选类型嵌入 CandidateMeta 并实现 Clone 和 Key 即可：Meta 由 CandidateMeta 提供
+type PipelineCandidate[C any] interface {
+	comparable
+	// Clone 返回候选的深拷贝（explain 模式下用于计算被修改的字段）
+	Clone() C
+	// Meta 返回管道读写的候选字段
+	Meta() *CandidateMeta
+	// Key 返回候选的业务 ID（例如 TweetID），用于 explain 和结果比较
+	Key() int64
+}
+
+// RequestMeta 是管道本身读写的请求字段，由各产品的查询类型嵌入
+type RequestMeta struct {
+	UserID    int64
+	RequestID string
+
+	// RequestTimeMs 是请求的时间（Unix 毫秒），帖子年龄等与时间相关的计算都以它为准，
+	// 回放录制的请求时可以得到与线上相同的结果；为 0 时由管道在执行开始时填写
+	RequestTimeMs int64
+
+	// PreRankSize 本次请求进入重排（Scorers）的候选上限
+	// 覆盖 CandidatePipeline.PreRankSize，0 表示使用管道的默认值
+	PreRankSize int
+
+	// Experiments 是本次请求的实验分组，由管道按 UserID 分配（调用方也可以预先设置）
+	Experiments ExperimentAssignments
+
+	// MissingHydrations 记录未能成功增强该查询的 QueryHydrator 名称（超时、失败）
+	MissingHydrations []string
+}
+
+// Meta 实现 PipelineQuery
+func (m *RequestMeta) Meta() *RequestMeta {
+	return m
+}
+
+// HydrationMissing 判断指定 QueryHydrator 的数据是否缺失
+func (m *RequestMeta) HydrationMissing(name string) bool {
+	return containsString(m.MissingHydrations, name)
+}
+
+// RequestTime 返回请求的时间，未设置 RequestTimeMs 时返回当前时间
+func (m *RequestMeta) RequestTime() time.Time {
+	if m.RequestTimeMs == 0 {
+		return time.Now()
+	}
+	return time.UnixMilli(m.RequestTimeMs)
+}
+
+// Treatment 返回请求在指定实验中的分组，不在实验中时返回空字符串
+func (m *RequestMeta) Treatment(experiment string) string {
+	t, _ := m.Experiments.Treatment(experiment)
+	return t
+}
+
+// Clone 返回 RequestMeta 的深拷贝，供嵌入它的查询类型在 Clone 中使用
+func (m *RequestMeta) Clone() RequestMeta {
+	clone := *m
+	if m.Experiments != nil {
+		clone.Experiments = make(ExperimentAssignments, len(m.Experiments))
+		copy(clone.Experiments, m.Experiments)
+	}
+	if m.MissingHydrations != nil {
+		clone.MissingHydrations = make([]string, len(m.MissingHydrations))
+		copy(clone.MissingHydrations, m.MissingHydrations)
+	}
+	return clone
+}
+
+// CandidateMeta 是管道本身读写的候选字段，由各产品的候选类型嵌入
+type CandidateMeta struct {
+	PreRankScore *float64 // 级联排序第一阶段（PreRankers）给出的轻量分数
+	Score        *float64
+
+	// Provenance 记录返回该候选的所有 Source（合并重复候选后可能有多条），按 Sources 声明顺序排列
+	Provenance []SourceProvenance
+
+	// MissingHydrations 记录未能成功增强或打分该候选的 Hydrator / Scorer 名称（超时、失败或返回长度不一致）
+	// 后续的 Filter 和 Scorer 可以据此显式处理缺失的数据
+	MissingHydrations []string
+}
+
+// Meta 实现 PipelineCandidate
+func (m *CandidateMeta) Meta() *CandidateMeta {
+	return m
+}
+
+// MultiSource 判断候选是否被多个 Source 同时返回（可作为打分特征）
+func (m *CandidateMeta) MultiSource() bool {
+	return len(m.Provenance) > 1
+}
+
+// HydrationMissing 判断指定 Hydrator（或 Scorer）的数据是否缺失
+func (m *CandidateMeta) HydrationMissing(name string) bool {
+	return containsString(m.MissingHydrations, name)
+}
+
+// Clone 返回 CandidateMeta 的深拷贝，供嵌入它的候选类型在 Clone 中使用
+func (m *CandidateMeta) Clone() CandidateMeta {
+	clone := CandidateMeta{
+		PreRankScore: copyFloat(m.PreRankScore),
+		Score:        copyFloat(m.Score),
+	}
+	if m.Provenance != nil {
+		clone.Provenance = make([]SourceProvenance, len(m.Provenance))
+		for i, p := range m.Provenance {
+			clone.Provenance[i] = SourceProvenance{Source: p.Source, Rank: p.Rank, Score: copyFloat(p.Score)}
+		}
+	}
+	if m.MissingHydrations != nil {
+		clone.MissingHydrations = make([]string, len(m.MissingHydrations))
+		copy(clone.MissingHydrations, m.MissingHydrations)
+	}
+	return clone
+}
+
+// NewPatches 分配 n 个零值的 T，作为 Hydrator / Scorer 返回的补丁
+// 所有补丁共享一次分配；组件只需填写自己负责的字段，由管道通过 Update 合并到原候选
+func NewPatches[T any](n int) []*T {
+	backing := make([]T, n)
+	patches := make([]*T, n)
+	for i := range backing {
+		patches[i] = &backing[i]
+	}
+	return patches
+}
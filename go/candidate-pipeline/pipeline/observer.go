@@ -0,0 +1,251 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// 阶段名称（StageEvent.Stage / ComponentEvent.Stage 的取值）
+const (
+	StageQueryHydrator         = "QueryHydrator"
+	StageSource                = "Source"
+	StageMerge                 = "Merge"
+	StageHydrator              = "Hydrator"
+	StageFilter                = "Filter"
+	StagePreRanker             = "PreRanker"
+	StageScorer                = "Scorer"
+	StageSelector              = "Selector"
+	StagePostSelectionHydrator = "PostSelectionHydrator"
+	StagePostSelectionFilter   = "PostSelectionFilter"
+)
+
+// StageEvent 描述一个阶段的执行
+type StageEvent struct {
+	RequestID     string
+	Stage         string
+	CandidatesIn  int
+	CandidatesOut int           // 仅 StageEnd
+	Duration      time.Duration // 仅 StageEnd
+	Err           error         // 仅 StageEnd：导致请求终止的错误
+}
+
+// ComponentEvent 描述单个组件的一次调用
+type ComponentEvent struct {
+	RequestID     string
+	Stage         string
+	Component     string
+	CandidatesIn  int
+	CandidatesOut int           // 仅 ComponentEnd：Source 为产出数，Filter 为保留数，其余为输入数
+	Removed       int           // 仅 ComponentEnd：本组件移除的候选数
+	Duration      time.Duration // 仅 ComponentEnd
+	Err           error         // 仅 ComponentEnd：组件失败（错误、超时、panic 或长度不一致）
+	TimedOut      bool          // 仅 ComponentEnd
+	Policy        FailurePolicy // 仅 ComponentEnd 且 Err 不为 nil 时有意义
+	Hedged        bool          // 仅 ComponentEnd：调用超过对冲延迟，发起了对冲请求（见 Hedging）
+}
+
+// Observer 接收管道执行过程中的事件，用于指标、追踪和日志
+//
+// Start 方法返回的 ctx 会作为该阶段/组件后续执行的 ctx，并原样传给对应的 End 方法，
+// 追踪实现可以借此把 span 放入 ctx。不需要的实现直接返回传入的 ctx 即可。
+// 并行阶段中的方法会被并发调用，实现必须是并发安全的。
+type Observer interface {
+	StageStart(ctx context.Context, event StageEvent) context.Context
+	StageEnd(ctx context.Context, event StageEvent)
+	ComponentStart(ctx context.Context, event ComponentEvent) context.Context
+	ComponentEnd(ctx context.Context, event ComponentEvent)
+
+	// LengthMismatch 在 Hydrator / Scorer 返回的候选数与输入不一致时调用
+	// 之后同一次调用还会以 Err 不为 nil 的 ComponentEnd 结束
+	LengthMismatch(ctx context.Context, event ComponentEvent, expected, got int)
+}
+
+// NopObserver 忽略所有事件，可嵌入只关心部分事件的实现
+type NopObserver struct{}
+
+func (NopObserver) StageStart(ctx context.Context, _ StageEvent) context.Context         { return ctx }
+func (NopObserver) StageEnd(context.Context, StageEvent)                                 {}
+func (NopObserver) ComponentStart(ctx context.Context, _ ComponentEvent) context.Context { return ctx }
+func (NopObserver) ComponentEnd(context.Context, ComponentEvent)                         {}
+func (NopObserver) LengthMismatch(context.Context, ComponentEvent, int, int)             {}
+
+// MultiObserver 按顺序把事件分发给多个 Observer
+type MultiObserver []Observer
+
+func (m MultiObserver) StageStart(ctx context.Context, event StageEvent) context.Context {
+	for _, o := range m {
+		ctx = o.StageStart(ctx, event)
+	}
+	return ctx
+}
+
+func (m MultiObserver) StageEnd(ctx context.Context, event StageEvent) {
+	for _, o := range m {
+		o.StageEnd(ctx, event)
+	}
+}
+
+func (m MultiObserver) ComponentStart(ctx context.Context, event ComponentEvent) context.Context {
+	for _, o := range m {
+		ctx = o.ComponentStart(ctx, event)
+	}
+	return ctx
+}
+
+func (m MultiObserver) ComponentEnd(ctx context.Context, event ComponentEvent) {
+	for _, o := range m {
+		o.ComponentEnd(ctx, event)
+	}
+}
+
+func (m MultiObserver) LengthMismatch(ctx context.Context, event ComponentEvent, expected, got int) {
+	for _, o := range m {
+		o.LengthMismatch(ctx, event, expected, got)
+	}
+}
+
+// executionObserverKey 是 ExecuteOptions.Observer 在 ctx 中的键
+type executionObserverKey struct{}
+
+// withExecutionObserver 把本次执行的 Observer 放入 ctx，阶段和组件的 ctx 都由它派生
+func withExecutionObserver(ctx context.Context, obs Observer) context.Context {
+	if obs == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, executionObserverKey{}, obs)
+}
+
+// observer 返回本次执行的 Observer：配置的 Observer 加上 ExecuteOptions.Observer，都未配置时返回 NopObserver
+func (p *CandidatePipelineOf[Q, C]) observer(ctx context.Context) Observer {
+	extra, _ := ctx.Value(executionObserverKey{}).(Observer)
+	switch {
+	case extra == nil && p.Observer == nil:
+		return NopObserver{}
+	case extra == nil:
+		return p.Observer
+	case p.Observer == nil:
+		return extra
+	}
+	return MultiObserver{p.Observer, extra}
+}
+
+// EventRecorder 记录一次执行中所有阶段和组件的结束事件（例如用于调试接口展示各阶段耗时），并发安全
+type EventRecorder struct {
+	NopObserver
+
+	mu         sync.Mutex
+	stages     []StageEvent
+	components []ComponentEvent
+}
+
+func (r *EventRecorder) StageEnd(_ context.Context, event StageEvent) {
+	r.mu.Lock()
+	r.stages = append(r.stages, event)
+	r.mu.Unlock()
+}
+
+func (r *EventRecorder) ComponentEnd(_ context.Context, event ComponentEvent) {
+	r.mu.Lock()
+	r.components = append(r.components, event)
+	r.mu.Unlock()
+}
+
+// Stages 返回按结束顺序排列的阶段事件
+func (r *EventRecorder) Stages() []StageEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]StageEvent(nil), r.stages...)
+}
+
+// Components 返回按结束顺序排列的组件事件
+func (r *EventRecorder) Components() []ComponentEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]ComponentEvent(nil), r.components...)
+}
+
+// stageSpan 表示一个正在执行的阶段
+type stageSpan struct {
+	obs   Observer
+	ctx   context.Context
+	event StageEvent
+	start time.Time
+}
+
+// startStage 通知阶段开始，返回的 span 的 ctx 用于执行该阶段
+func (p *CandidatePipelineOf[Q, C]) startStage(ctx context.Context, requestID, stage string, in int) *stageSpan {
+	s := &stageSpan{
+		obs:   p.observer(ctx),
+		event: StageEvent{RequestID: requestID, Stage: stage, CandidatesIn: in},
+		start: time.Now(),
+	}
+	s.ctx = s.obs.StageStart(ctx, s.event)
+	return s
+}
+
+// end 通知阶段结束
+func (s *stageSpan) end(out int, err error) {
+	s.event.CandidatesOut = out
+	s.event.Duration = time.Since(s.start)
+	s.event.Err = err
+	s.obs.StageEnd(s.ctx, s.event)
+}
+
+// componentSpan 表示一次正在执行的组件调用
+type componentSpan struct {
+	obs   Observer
+	ctx   context.Context
+	event ComponentEvent
+	start time.Time
+}
+
+// startComponent 通知组件开始，返回的 span 的 ctx 用于调用该组件
+func startComponent(obs Observer, ctx context.Context, requestID, stage, name string, in int) *componentSpan {
+	c := &componentSpan{
+		obs:   obs,
+		event: ComponentEvent{RequestID: requestID, Stage: stage, Component: name, CandidatesIn: in},
+		start: time.Now(),
+	}
+	c.ctx = obs.ComponentStart(ctx, c.event)
+	return c
+}
+
+// lengthMismatch 通知组件返回的候选数与输入不一致
+func (c *componentSpan) lengthMismatch(expected, got int) {
+	c.obs.LengthMismatch(c.ctx, c.event, expected, got)
+}
+
+// succeed 通知组件成功结束；elapsed 为 0 时使用从开始到现在的时间
+func (c *componentSpan) succeed(out, removed int, elapsed time.Duration) {
+	c.event.CandidatesOut = out
+	c.event.Removed = removed
+	c.finish(elapsed)
+}
+
+// fail 通知组件失败结束；out / removed 是按失败策略处理后的结果
+func (c *componentSpan) fail(f failure, policy FailurePolicy, out, removed int, elapsed time.Duration) {
+	c.event.CandidatesOut = out
+	c.event.Err = f.err
+	c.event.TimedOut = f.timedOut
+	c.event.Policy = policy
+	c.event.Removed = removed
+	c.finish(elapsed)
+}
+
+func (c *componentSpan) finish(elapsed time.Duration) {
+	if elapsed <= 0 {
+		elapsed = time.Since(c.start)
+	}
+	c.event.Duration = elapsed
+	c.obs.ComponentEnd(c.ctx, c.event)
+}
+
+// spanContexts 返回各组件调用的 ctx，用作 fanOut 的父 ctx
+func spanContexts(spans []*componentSpan) []context.Context {
+	ctxs := make([]context.Context, len(spans))
+	for i, s := range spans {
+		ctxs[i] = s.ctx
+	}
+	return ctxs
+}
@@ -2,127 +2,335 @@ package pipeline
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
-// CandidatePipeline 表示候选管道
+// CandidatePipelineOf 表示候选管道
 // 协调整个推荐流程，包括查询增强、候选获取、增强、过滤、打分、选择等阶段
-type CandidatePipeline struct {
+//
+// 管道对查询类型 Q 和候选类型 C 泛型：各产品定义自己的查询和候选（嵌入 RequestMeta / CandidateMeta），
+// 复用同一套阶段编排、超时、失败策略、实验和可观测性。首页时间线使用 home.CandidatePipeline。
+type CandidatePipelineOf[Q PipelineQuery[Q], C PipelineCandidate[C]] struct {
 	// 组件列表
-	QueryHydrators        []QueryHydrator
-	Sources               []Source
-	Hydrators             []Hydrator
-	Filters               []Filter
-	Scorers               []Scorer
-	Selector              Selector
-	PostSelectionHydrators []Hydrator
-	PostSelectionFilters   []Filter
-	SideEffects           []SideEffect
+	QueryHydrators        []QueryHydratorOf[Q]
+	Sources               []SourceOf[Q, C]
+	Merger                CandidateMergerOf[Q, C] // 合并多个 Source 返回的重复候选，为 nil 时不合并
+	Hydrators             []HydratorOf[Q, C]
+	Filters               []FilterOf[Q, C]
+	PreRankers            []ScorerOf[Q, C] // 级联排序的轻量打分器，在 Scorers 之前为所有候选写入 PreRankScore
+	Scorers               []ScorerOf[Q, C]
+	Selector              SelectorOf[Q, C]
+	PostSelectionHydrators []HydratorOf[Q, C]
+	PostSelectionFilters   []FilterOf[Q, C]
+	SideEffects           []SideEffectOf[Q, C]
 	
 	// 配置
 	ResultSize            int // 最终返回的候选数量，0 表示不限制
+	PreRankSize           int // 按 PreRankScore 进入重排（Scorers）的候选上限，0 表示不截断；Query.PreRankSize 可按请求覆盖
+	Deadlines             Deadlines // 各阶段预算和组件超时，零值表示只受调用方 ctx 约束
+	Hedging               Hedging   // Source / Hydrator 的对冲请求，零值表示不对冲
+
+	// SideEffectExecutor 执行 Side Effects 的有界执行器
+	// 为 nil 时 Build 会创建一个默认配置的执行器；需要在关闭时 Drain 的调用方应自行创建并持有
+	SideEffectExecutor *SideEffectExecutor
+
+	// Observer 接收阶段和组件的执行事件（指标、追踪、日志），为 nil 时不上报
+	Observer Observer
+
+	// Experiments 是按 UserID 分桶的实验，执行时为请求分配分组（写入 Query.Experiments）
+	Experiments []Experiment
+
+	// 构建产物（由 Build 生成）
+	buildOnce                   sync.Once
+	buildErr                    error
+	queryHydratorLayers         [][]int // QueryHydrators 的依赖分层
+	hydratorLayers              [][]int // Hydrators 的依赖分层
+	postSelectionHydratorLayers [][]int // PostSelectionHydrators 的依赖分层
+	hedgers                     map[string]*hedger // 启用对冲的组件的耗时样本和对冲额度，跨请求共享
+}
+
+
+// Build 校验管道配置（见 Validate）并根据组件声明的字段依赖构建执行计划
+// 在组件列表配置完成后调用一次；配置不合法时返回包含全部问题的错误。
+// 未显式调用时，第一次 Execute 会自动构建。
+func (p *CandidatePipelineOf[Q, C]) Build() error {
+	p.buildOnce.Do(func() {
+		if p.SideEffectExecutor == nil && len(p.SideEffects) > 0 {
+			p.SideEffectExecutor = NewSideEffectExecutor(DefaultSideEffectExecutorConfig())
+		}
+
+		var err error
+		if err = p.Validate(); err != nil {
+			p.buildErr = err
+			return
+		}
+		if p.queryHydratorLayers, err = queryHydratorLayers("QueryHydrator", p.QueryHydrators); err != nil {
+			p.buildErr = err
+			return
+		}
+		if p.hydratorLayers, err = hydratorLayers("Hydrator", p.Hydrators); err != nil {
+			p.buildErr = err
+			return
+		}
+		if p.postSelectionHydratorLayers, err = hydratorLayers("PostSelectionHydrator", p.PostSelectionHydrators); err != nil {
+			p.buildErr = err
+			return
+		}
+		p.hedgers = p.Hedging.hedgers()
+	})
+	return p.buildErr
+}
+
+// ExecuteOptions 控制单次管道执行的行为
+type ExecuteOptions struct {
+	// Explain 为 true 时记录每个候选的完整轨迹，写入 PipelineResult.Explanations
+	// 会为每次 Update 额外拍快照，仅用于排障，不应在全量流量上开启
+	Explain bool
+
+	// SkipSideEffects 为 true 时不提交 Side Effects
+	// 用于影子执行、离线回放等不应产生写入的执行
+	SkipSideEffects bool
+
+	// Observer 只接收本次执行的事件（在 CandidatePipeline.Observer 之外），例如用 EventRecorder 收集单个请求的阶段耗时
+	Observer Observer
 }
 
 // Execute 执行完整的管道流程
 // 这是管道的主入口方法，协调各个阶段的执行
-func (p *CandidatePipeline) Execute(ctx context.Context, query *Query) (*PipelineResult, error) {
+func (p *CandidatePipelineOf[Q, C]) Execute(ctx context.Context, query Q) (*PipelineResultOf[Q, C], error) {
+	return p.ExecuteWithOptions(ctx, query, ExecuteOptions{})
+}
+
+// ExecuteWithOptions 按给定选项执行完整的管道流程
+// 只有 Critical 组件失败时返回 *ComponentError，其他失败按组件的 FailurePolicy 处理
+func (p *CandidatePipelineOf[Q, C]) ExecuteWithOptions(ctx context.Context, query Q, opts ExecuteOptions) (*PipelineResultOf[Q, C], error) {
+	if err := p.Build(); err != nil {
+		return nil, err
+	}
+	ctx = withExecutionObserver(ctx, opts.Observer)
+	ex := newExplainer[C](opts.Explain)
+	query = p.assignExperiments(stampRequestTime(query))
+	requestID := query.Meta().RequestID
+
 	// 1) Query Hydration（并行）
-	hydratedQuery := p.hydrateQuery(ctx, query)
+	stage := p.startStage(ctx, requestID, StageQueryHydrator, 0)
+	hydratedQuery, closed, err := p.hydrateQuery(stage.ctx, query)
+	stage.end(0, err)
+	if err != nil {
+		return nil, err
+	}
 	
 	// 2) Candidate Sourcing（并行）
-	candidates := p.fetchCandidates(ctx, hydratedQuery)
-	
-	// 3) Candidate Hydration（并行）
-	hydratedCandidates := p.hydrateCandidates(ctx, hydratedQuery, candidates)
-	
-	// 4) Pre-Scoring Filtering（顺序）
-	keptCandidates, filteredCandidates := p.filterCandidates(ctx, hydratedQuery, hydratedCandidates)
+	// fail-closed 的 Query Hydrator 失败时，无法安全评估任何候选，直接跳过后续阶段
+	var candidates []C
+	if !closed {
+		stage = p.startStage(ctx, requestID, StageSource, 0)
+		candidates, err = p.fetchCandidates(stage.ctx, hydratedQuery, ex)
+		stage.end(len(candidates), err)
+		if err != nil {
+			return nil, err
+		}
+	}
 	
-	// 5) Scoring（顺序）
-	scoredCandidates := p.scoreCandidates(ctx, hydratedQuery, keptCandidates)
+	retrievedCandidates := candidates
 	
-	// 6) Selection（排序/截断）
-	selectedCandidates := p.selectCandidates(ctx, hydratedQuery, scoredCandidates)
+	// 3) Merge：合并多个 Source 返回的同一条帖子
+	stage = p.startStage(ctx, requestID, StageMerge, len(candidates))
+	candidates, removals, err := p.mergeCandidates(stage.ctx, hydratedQuery, candidates, ex)
+	stage.end(len(candidates), err)
+	if err != nil {
+		return nil, err
+	}
 	
-	// 7) Post-Selection Hydration（并行）
-	postHydrated := p.hydratePostSelection(ctx, hydratedQuery, selectedCandidates)
+	// 4) Candidate Hydration（并行）
+	stage = p.startStage(ctx, requestID, StageHydrator, len(candidates))
+	keptCandidates, hydrationRemovals, err := p.hydrateCandidates(stage.ctx, hydratedQuery, candidates, ex)
+	stage.end(len(keptCandidates), err)
+	if err != nil {
+		return nil, err
+	}
+	removals = append(removals, hydrationRemovals...)
+	
+	// 5) Pre-Scoring Filtering（顺序）
+	stage = p.startStage(ctx, requestID, StageFilter, len(keptCandidates))
+	keptCandidates, filterRemovals, err := p.filterCandidates(stage.ctx, hydratedQuery, keptCandidates, ex)
+	stage.end(len(keptCandidates), err)
+	if err != nil {
+		return nil, err
+	}
+	removals = append(removals, filterRemovals...)
+	
+	// 6) Pre-Ranking（顺序）：轻量打分，只有前 PreRankSize 个候选进入重排，控制 Scorers 的成本
+	stage = p.startStage(ctx, requestID, StagePreRanker, len(keptCandidates))
+	keptCandidates, preRankRemovals, err := p.preRankCandidates(stage.ctx, hydratedQuery, keptCandidates, ex)
+	stage.end(len(keptCandidates), err)
+	if err != nil {
+		return nil, err
+	}
+	removals = append(removals, preRankRemovals...)
+	
+	// 7) Scoring（顺序）
+	stage = p.startStage(ctx, requestID, StageScorer, len(keptCandidates))
+	scoredCandidates, scoreRemovals, err := p.scoreCandidates(stage.ctx, hydratedQuery, keptCandidates, ex)
+	stage.end(len(scoredCandidates), err)
+	if err != nil {
+		return nil, err
+	}
+	removals = append(removals, scoreRemovals...)
 	
-	// 8) Post-Selection Filtering（顺序）
-	finalCandidates, postFiltered := p.filterPostSelection(ctx, hydratedQuery, postHydrated)
-	filteredCandidates = append(filteredCandidates, postFiltered...)
+	// 8) - 12) Selection 及之后的阶段
+	result, err := p.selectAndFinish(ctx, hydratedQuery, scoredCandidates, removals, ex, opts)
+	if err != nil {
+		return nil, err
+	}
+	result.RetrievedCandidates = retrievedCandidates
+	return result, nil
+}
+
+// selectAndFinish 执行 Selection 及之后的阶段（Post-Selection Hydration / Filtering、截断、Side Effects），
+// 并汇总执行结果；removals 是之前阶段移除的候选。RetrievedCandidates 由调用方填写。
+func (p *CandidatePipelineOf[Q, C]) selectAndFinish(ctx context.Context, query Q, scoredCandidates []C, removals []RemovedCandidateOf[C], ex *explainer[C], opts ExecuteOptions) (*PipelineResultOf[Q, C], error) {
+	requestID := query.Meta().RequestID
+	
+	// 8) Selection（排序/截断）
+	stage := p.startStage(ctx, requestID, StageSelector, len(scoredCandidates))
+	selectedCandidates, err := p.selectCandidates(stage.ctx, query, scoredCandidates)
+	stage.end(len(selectedCandidates), err)
+	if err != nil {
+		return nil, err
+	}
+	ex.dropped(StageSelector, p.Selector.Name(), ReasonNotSelected, scoredCandidates, selectedCandidates)
+	
+	// 9) Post-Selection Hydration（并行）
+	stage = p.startStage(ctx, requestID, StagePostSelectionHydrator, len(selectedCandidates))
+	postHydrated, postHydrationRemovals, err := p.hydratePostSelection(stage.ctx, query, selectedCandidates, ex)
+	stage.end(len(postHydrated), err)
+	if err != nil {
+		return nil, err
+	}
+	removals = append(removals, postHydrationRemovals...)
+	
+	// 10) Post-Selection Filtering（顺序）
+	stage = p.startStage(ctx, requestID, StagePostSelectionFilter, len(postHydrated))
+	finalCandidates, postRemovals, err := p.filterPostSelection(stage.ctx, query, postHydrated, ex)
+	stage.end(len(finalCandidates), err)
+	if err != nil {
+		return nil, err
+	}
+	removals = append(removals, postRemovals...)
 	
-	// 9) 截断到结果大小
+	// 11) 截断到结果大小
 	if p.ResultSize > 0 && len(finalCandidates) > p.ResultSize {
+		ex.dropped("ResultSize", "CandidatePipeline", ReasonTruncated, finalCandidates, finalCandidates[:p.ResultSize])
 		finalCandidates = finalCandidates[:p.ResultSize]
 	}
 	
-	// 10) Side Effects（异步，不阻塞主链路）
-	// 使用 context.Background() 确保 side effects 不会因为主请求取消而中断
-	go p.runSideEffects(context.Background(), hydratedQuery, finalCandidates)
+	// 12) Side Effects（异步，不阻塞主链路）
+	// 放入有界队列，由执行器使用独立的 ctx 执行，不会因为主请求取消而中断
+	if !opts.SkipSideEffects {
+		p.runSideEffects(query, finalCandidates)
+	}
+	
+	filteredCandidates := make([]C, len(removals))
+	for i, r := range removals {
+		filteredCandidates[i] = r.Candidate
+	}
 	
-	return &PipelineResult{
-		RetrievedCandidates: hydratedCandidates,
+	return &PipelineResultOf[Q, C]{
 		FilteredCandidates:  filteredCandidates,
+		ScoredCandidates:    scoredCandidates,
 		SelectedCandidates:  finalCandidates,
-		Query:               hydratedQuery,
+		Query:               query,
+		Removals:            removals,
+		Explanations:        ex.finish(finalCandidates),
 	}, nil
 }
 
-// hydrateQuery 并行执行所有 Query Hydrators，并合并结果到 query
-func (p *CandidatePipeline) hydrateQuery(ctx context.Context, query *Query) *Query {
-	hydrated := query.Clone()
-	
-	// 筛选启用的 hydrators
-	hydrators := make([]QueryHydrator, 0, len(p.QueryHydrators))
-	for _, h := range p.QueryHydrators {
-		if h.Enable(query) {
-			hydrators = append(hydrators, h)
+// hydrateQuery 按依赖分层执行 Query Hydrators，层内并行，并合并结果到 query
+// closed 为 true 表示有 fail-closed 的 Query Hydrator 失败，本次请求不应产出候选
+func (p *CandidatePipelineOf[Q, C]) hydrateQuery(ctx context.Context, query Q) (hydrated Q, closed bool, err error) {
+	ctx, cancel := withBudget(ctx, p.Deadlines.QueryHydration)
+	defer cancel()
+	
+	hydrated = query.Clone()
+	
+	for _, layer := range p.queryHydratorLayers {
+		// 筛选启用的 hydrators
+		hydrators := make([]QueryHydratorOf[Q], 0, len(layer))
+		for _, i := range layer {
+			if h := p.QueryHydrators[i]; h.Enable(query) {
+				hydrators = append(hydrators, h)
+			}
+		}
+		layerClosed, err := p.runQueryHydratorLayer(ctx, hydrated, hydrators)
+		if err != nil {
+			var zero Q
+			return zero, false, err
 		}
+		closed = closed || layerClosed
 	}
 	
+	return hydrated, closed, nil
+}
+
+// runQueryHydratorLayer 并行执行同一层的 Query Hydrators
+// 同层组件看到的是上一层合并后的 query
+func (p *CandidatePipelineOf[Q, C]) runQueryHydratorLayer(ctx context.Context, hydrated Q, hydrators []QueryHydratorOf[Q]) (closed bool, err error) {
 	if len(hydrators) == 0 {
-		return hydrated
-	}
-	
-	// 并行执行
-	type item struct {
-		h QueryHydrator
-		r *Query
-		e error
+		return false, nil
 	}
-	ch := make(chan item, len(hydrators))
-	var wg sync.WaitGroup
 	
-	for _, h := range hydrators {
-		wg.Add(1)
-		go func(hydrator QueryHydrator) {
-			defer wg.Done()
-			res, err := hydrator.Hydrate(ctx, query)
-			ch <- item{h: hydrator, r: res, e: err}
-		}(h)
+	obs := p.observer(ctx)
+	spans := make([]*componentSpan, len(hydrators))
+	for i, h := range hydrators {
+		spans[i] = startComponent(obs, ctx, hydrated.Meta().RequestID, StageQueryHydrator, h.Name(), 0)
 	}
 	
-	wg.Wait()
-	close(ch)
-	
-	// 合并结果
-	for it := range ch {
-		if it.e != nil {
-			log.Printf("request_id=%s stage=QueryHydrator component=%s failed: %v",
-				query.RequestID, it.h.Name(), it.e)
+	// 被放弃的组件可能仍在读取输入，因此每层传入独立的快照
+	input := hydrated.Clone()
+	results := fanOut(spanContexts(spans),
+		func(i int) time.Duration { return p.Deadlines.componentTimeout(hydrators[i].Name()) },
+		func(ctx context.Context, i int) (Q, error) { return hydrators[i].Hydrate(ctx, input) },
+	)
+	
+	// 按声明顺序合并结果
+	// critical 组件失败时仍然先结束所有组件的事件，再终止请求
+	for i, r := range results {
+		h := hydrators[i]
+		if r.err != nil {
+			f := failure{stage: StageQueryHydrator, name: h.Name(), component: h, err: r.err, timedOut: r.timedOut}
+			policy := f.handle(hydrated.Meta().RequestID)
+			spans[i].fail(f, policy, 0, 0, r.elapsed)
+			switch policy {
+			case Critical:
+				if err == nil {
+					err = f.abortError()
+				}
+			case FailClosed:
+				closed = true
+			}
+			hydrated.Meta().MissingHydrations = append(hydrated.Meta().MissingHydrations, h.Name())
 			continue
 		}
-		it.h.Update(hydrated, it.r)
+		h.Update(hydrated, r.value)
+		spans[i].succeed(0, 0, r.elapsed)
 	}
-	
-	return hydrated
+	if err != nil {
+		return false, err
+	}
+	return closed, nil
 }
 
 // fetchCandidates 并行执行所有 Sources，并收集所有候选
-func (p *CandidatePipeline) fetchCandidates(ctx context.Context, query *Query) []*Candidate {
+// 超出 Sourcing 预算的 Source 会被放弃，只使用按时返回的候选
+func (p *CandidatePipelineOf[Q, C]) fetchCandidates(ctx context.Context, query Q, ex *explainer[C]) ([]C, error) {
 	// 筛选启用的 sources
-	sources := make([]Source, 0, len(p.Sources))
+	sources := make([]SourceOf[Q, C], 0, len(p.Sources))
 	for _, s := range p.Sources {
 		if s.Enable(query) {
 			sources = append(sources, s)
@@ -130,223 +338,453 @@ func (p *CandidatePipeline) fetchCandidates(ctx context.Context, query *Query) [
 	}
 	
 	if len(sources) == 0 {
-		return []*Candidate{}
+		return []C{}, nil
 	}
 	
-	// 并行执行
-	type item struct {
-		s Source
-		c []*Candidate
-		e error
-	}
-	ch := make(chan item, len(sources))
-	var wg sync.WaitGroup
+	ctx, cancel := withBudget(ctx, p.Deadlines.Sourcing)
+	defer cancel()
 	
-	for _, s := range sources {
-		wg.Add(1)
-		go func(source Source) {
-			defer wg.Done()
-			cs, err := source.GetCandidates(ctx, query)
-			ch <- item{s: source, c: cs, e: err}
-		}(s)
+	obs := p.observer(ctx)
+	spans := make([]*componentSpan, len(sources))
+	for i, s := range sources {
+		spans[i] = startComponent(obs, ctx, query.Meta().RequestID, StageSource, s.Name(), 0)
 	}
 	
-	wg.Wait()
-	close(ch)
-	
-	// 收集结果
-	var collected []*Candidate
-	for it := range ch {
-		if it.e != nil {
-			log.Printf("request_id=%s stage=Source component=%s failed: %v",
-				query.RequestID, it.s.Name(), it.e)
+	// 并行执行，启用对冲的 Source 在慢调用时再发起一次
+	hedged := make([]atomic.Bool, len(sources))
+	results := fanOut(spanContexts(spans),
+		func(i int) time.Duration { return p.Deadlines.componentTimeout(sources[i].Name()) },
+		func(ctx context.Context, i int) ([]C, error) {
+			return hedgeCall(ctx, p.hedgerFor(StageSource, sources[i].Name()), &hedged[i], func(ctx context.Context) ([]C, error) {
+				return sources[i].GetCandidates(ctx, query)
+			})
+		},
+	)
+	
+	// 按声明顺序收集结果
+	var collected []C
+	var abortErr error
+	for i, r := range results {
+		s := sources[i]
+		spans[i].event.Hedged = hedged[i].Load()
+		if r.err != nil {
+			f := failure{stage: StageSource, name: s.Name(), component: s, err: r.err, timedOut: r.timedOut}
+			policy := f.handle(query.Meta().RequestID)
+			spans[i].fail(f, policy, 0, 0, r.elapsed)
+			if policy == Critical && abortErr == nil {
+				abortErr = f.abortError()
+			}
 			continue
 		}
 		log.Printf("request_id=%s stage=Source component=%s fetched %d candidates",
-			query.RequestID, it.s.Name(), len(it.c))
-		collected = append(collected, it.c...)
+			query.Meta().RequestID, s.Name(), len(r.value))
+		spans[i].succeed(len(r.value), 0, r.elapsed)
+		stampProvenance(s.Name(), r.value)
+		ex.sourced(s.Name(), r.value)
+		collected = append(collected, r.value...)
+	}
+	if abortErr != nil {
+		return nil, abortErr
 	}
 	
-	return collected
+	return collected, nil
 }
 
-// hydrateCandidates 并行执行所有 Hydrators，并合并结果到 candidates
-func (p *CandidatePipeline) hydrateCandidates(ctx context.Context, query *Query, candidates []*Candidate) []*Candidate {
-	return p.runHydrators(ctx, query, candidates, p.Hydrators, "Hydrator")
+// hydrateCandidates 按依赖分层执行所有 Hydrators，并合并结果到 candidates
+func (p *CandidatePipelineOf[Q, C]) hydrateCandidates(ctx context.Context, query Q, candidates []C, ex *explainer[C]) ([]C, []RemovedCandidateOf[C], error) {
+	ctx, cancel := withBudget(ctx, p.Deadlines.Hydration)
+	defer cancel()
+	return p.runHydrators(ctx, query, candidates, p.Hydrators, p.hydratorLayers, StageHydrator, ex)
 }
 
-// hydratePostSelection 并行执行所有 Post-Selection Hydrators
-func (p *CandidatePipeline) hydratePostSelection(ctx context.Context, query *Query, candidates []*Candidate) []*Candidate {
-	return p.runHydrators(ctx, query, candidates, p.PostSelectionHydrators, "PostSelectionHydrator")
+// hydratePostSelection 按依赖分层执行所有 Post-Selection Hydrators
+func (p *CandidatePipelineOf[Q, C]) hydratePostSelection(ctx context.Context, query Q, candidates []C, ex *explainer[C]) ([]C, []RemovedCandidateOf[C], error) {
+	ctx, cancel := withBudget(ctx, p.Deadlines.PostSelectionHydration)
+	defer cancel()
+	return p.runHydrators(ctx, query, candidates, p.PostSelectionHydrators, p.postSelectionHydratorLayers, StagePostSelectionHydrator, ex)
 }
 
 // runHydrators 执行 hydrators 的共享辅助方法
-func (p *CandidatePipeline) runHydrators(
+// 按依赖分层顺序执行，后一层的 hydrator 能看到前一层合并后的字段
+// fail-closed 的 hydrator 失败时，本阶段的所有候选都被丢弃
+func (p *CandidatePipelineOf[Q, C]) runHydrators(
 	ctx context.Context,
-	query *Query,
-	candidates []*Candidate,
-	hydrators []Hydrator,
+	query Q,
+	candidates []C,
+	hydrators []HydratorOf[Q, C],
+	layers [][]int,
 	stageName string,
-) []*Candidate {
-	// 筛选启用的 hydrators
-	enabledHydrators := make([]Hydrator, 0, len(hydrators))
-	for _, h := range hydrators {
-		if h.Enable(query) {
-			enabledHydrators = append(enabledHydrators, h)
+	ex *explainer[C],
+) ([]C, []RemovedCandidateOf[C], error) {
+	for _, layer := range layers {
+		if len(candidates) == 0 {
+			break
+		}
+		// 筛选启用的 hydrators
+		enabledHydrators := make([]HydratorOf[Q, C], 0, len(layer))
+		for _, i := range layer {
+			if h := hydrators[i]; h.Enable(query) {
+				enabledHydrators = append(enabledHydrators, h)
+			}
+		}
+		closedBy, err := p.runHydratorLayer(ctx, query, candidates, enabledHydrators, stageName, ex)
+		if err != nil {
+			return nil, nil, err
+		}
+		if closedBy != "" {
+			removed := dropAll(candidates, stageName, closedBy)
+			for _, r := range removed {
+				ex.removed(r)
+			}
+			return []C{}, removed, nil
 		}
 	}
-	
+	return candidates, nil, nil
+}
+
+// runHydratorLayer 并行执行同一层的 hydrators，并逐个合并结果
+// 返回失败的 fail-closed hydrator 名称（没有则为空），critical hydrator 失败时返回错误
+func (p *CandidatePipelineOf[Q, C]) runHydratorLayer(
+	ctx context.Context,
+	query Q,
+	candidates []C,
+	enabledHydrators []HydratorOf[Q, C],
+	stageName string,
+	ex *explainer[C],
+) (closedBy string, err error) {
 	if len(enabledHydrators) == 0 {
-		return candidates
+		return "", nil
 	}
 	
 	expectedLen := len(candidates)
 	
-	// 并行执行
-	type item struct {
-		h Hydrator
-		r []*Candidate
-		e error
+	obs := p.observer(ctx)
+	spans := make([]*componentSpan, len(enabledHydrators))
+	for i, h := range enabledHydrators {
+		spans[i] = startComponent(obs, ctx, query.Meta().RequestID, stageName, h.Name(), expectedLen)
 	}
-	ch := make(chan item, len(enabledHydrators))
-	var wg sync.WaitGroup
 	
+	// 并行执行，启用对冲的 Hydrator 在慢调用时再发起一次
+	// 同层的 hydrators 共享一份候选快照（Hydrate 不修改输入），合并只写原候选；
+	// 同层没有可能被放弃的 hydrator 时不需要快照
+	abandon := false
 	for _, h := range enabledHydrators {
-		wg.Add(1)
-		go func(hydrator Hydrator) {
-			defer wg.Done()
-			hydrated, err := hydrator.Hydrate(ctx, query, candidates)
-			ch <- item{h: hydrator, r: hydrated, e: err}
-		}(h)
-	}
-	
-	wg.Wait()
-	close(ch)
-	
-	// 合并结果
-	for it := range ch {
-		if it.e != nil {
-			log.Printf("request_id=%s stage=%s component=%s failed: %v",
-				query.RequestID, stageName, it.h.Name(), it.e)
-			continue
+		abandon = abandon || abandonable(ctx, p.Deadlines.componentTimeout(h.Name()), p.hedgerFor(stageName, h.Name()))
+	}
+	input := snapshotCandidates(candidates, abandon)
+	hedged := make([]atomic.Bool, len(enabledHydrators))
+	results := fanOut(spanContexts(spans),
+		func(i int) time.Duration { return p.Deadlines.componentTimeout(enabledHydrators[i].Name()) },
+		func(ctx context.Context, i int) ([]C, error) {
+			h := enabledHydrators[i]
+			return hedgeCall(ctx, p.hedgerFor(stageName, h.Name()), &hedged[i], func(ctx context.Context) ([]C, error) {
+				return h.Hydrate(ctx, query, input)
+			})
+		},
+	)
+	
+	// 按声明顺序合并结果
+	// critical 组件失败时仍然先结束所有组件的事件，再终止请求
+	for k, r := range results {
+		h := enabledHydrators[k]
+		spans[k].event.Hedged = hedged[k].Load()
+		hErr := r.err
+		if hErr == nil && len(r.value) != expectedLen {
+			spans[k].lengthMismatch(expectedLen, len(r.value))
+			hErr = fmt.Errorf("length_mismatch expected=%d got=%d", expectedLen, len(r.value))
 		}
-		if len(it.r) != expectedLen {
-			log.Printf("request_id=%s stage=%s component=%s skipped: length_mismatch expected=%d got=%d",
-				query.RequestID, stageName, it.h.Name(), expectedLen, len(it.r))
+		if hErr != nil {
+			f := failure{stage: stageName, name: h.Name(), component: h, err: hErr, timedOut: r.timedOut}
+			policy := f.handle(query.Meta().RequestID)
+			switch policy {
+			case Critical:
+				spans[k].fail(f, policy, 0, 0, r.elapsed)
+				if err == nil {
+					err = f.abortError()
+				}
+			case FailClosed:
+				spans[k].fail(f, policy, 0, expectedLen, r.elapsed)
+				if closedBy == "" {
+					closedBy = h.Name()
+				}
+			default:
+				spans[k].fail(f, policy, expectedLen, 0, r.elapsed)
+			}
+			markHydrationMissing(candidates, h.Name())
 			continue
 		}
 		// merge：逐个 candidate update
 		for i := 0; i < expectedLen; i++ {
-			it.h.Update(candidates[i], it.r[i])
+			before := ex.snapshot(candidates[i])
+			h.Update(candidates[i], r.value[i])
+			ex.hydrated(stageName, h.Name(), before, candidates[i])
 		}
+		spans[k].succeed(expectedLen, 0, r.elapsed)
+	}
+	if err != nil {
+		return "", err
+	}
+	return closedBy, nil
+}
+
+// markHydrationMissing 把 hydrator（或 fail-open 的 scorer）记为所有候选缺失的增强
+func markHydrationMissing[C PipelineCandidate[C]](candidates []C, name string) {
+	for _, c := range candidates {
+		c.Meta().MissingHydrations = append(c.Meta().MissingHydrations, name)
 	}
-	
-	return candidates
 }
 
 // filterCandidates 顺序执行所有 Filters
-func (p *CandidatePipeline) filterCandidates(ctx context.Context, query *Query, candidates []*Candidate) (kept []*Candidate, removed []*Candidate) {
-	return p.runFilters(ctx, query, candidates, p.Filters, "Filter")
+func (p *CandidatePipelineOf[Q, C]) filterCandidates(ctx context.Context, query Q, candidates []C, ex *explainer[C]) ([]C, []RemovedCandidateOf[C], error) {
+	return p.runFilters(ctx, query, candidates, p.Filters, StageFilter, ex)
 }
 
 // filterPostSelection 顺序执行所有 Post-Selection Filters
-func (p *CandidatePipeline) filterPostSelection(ctx context.Context, query *Query, candidates []*Candidate) (kept []*Candidate, removed []*Candidate) {
-	return p.runFilters(ctx, query, candidates, p.PostSelectionFilters, "PostSelectionFilter")
+func (p *CandidatePipelineOf[Q, C]) filterPostSelection(ctx context.Context, query Q, candidates []C, ex *explainer[C]) ([]C, []RemovedCandidateOf[C], error) {
+	return p.runFilters(ctx, query, candidates, p.PostSelectionFilters, StagePostSelectionFilter, ex)
 }
 
 // runFilters 执行 filters 的共享辅助方法
-func (p *CandidatePipeline) runFilters(
+// Filter 不修改输入（见 Filter 接口），因此不需要备份候选：
+// 失败时 fail-open 沿用调用前的 kept 继续，fail-closed 丢弃全部输入，critical 终止请求
+func (p *CandidatePipelineOf[Q, C]) runFilters(
 	ctx context.Context,
-	query *Query,
-	candidates []*Candidate,
-	filters []Filter,
+	query Q,
+	candidates []C,
+	filters []FilterOf[Q, C],
 	stageName string,
-) (kept []*Candidate, removed []*Candidate) {
+	ex *explainer[C],
+) (kept []C, removed []RemovedCandidateOf[C], err error) {
 	kept = candidates
-	removed = []*Candidate{}
+	removed = []RemovedCandidateOf[C]{}
+	obs := p.observer(ctx)
 	
 	for _, f := range filters {
 		if !f.Enable(query) {
 			continue
 		}
 		
-		// 备份，以防失败
-		backup := make([]*Candidate, len(kept))
-		for i, c := range kept {
-			backup[i] = c.Clone()
-		}
-		
-		res, err := f.Filter(ctx, query, kept)
-		if err != nil {
-			log.Printf("request_id=%s stage=%s component=%s failed: %v",
-				query.RequestID, stageName, f.Name(), err)
-			kept = backup // 恢复备份
+		span := startComponent(obs, ctx, query.Meta().RequestID, stageName, f.Name(), len(kept))
+		res, fErr := safeCall(func() (*FilterResultOf[C], error) { return f.Filter(span.ctx, query, kept) })
+		if fErr != nil {
+			fl := failure{stage: stageName, name: f.Name(), component: f, err: fErr}
+			policy := fl.handle(query.Meta().RequestID)
+			switch policy {
+			case Critical:
+				span.fail(fl, policy, 0, 0, 0)
+				return nil, nil, fl.abortError()
+			case FailClosed:
+				dropped := dropAll(kept, stageName, f.Name())
+				for _, r := range dropped {
+					ex.removed(r)
+				}
+				span.fail(fl, policy, 0, len(dropped), 0)
+				removed = append(removed, dropped...)
+				kept = []C{}
+			default:
+				span.fail(fl, policy, len(kept), 0, 0)
+			}
 			continue
 		}
 		
 		kept = res.Kept
-		removed = append(removed, res.Removed...)
+		for i, c := range res.Removed {
+			r := RemovedCandidateOf[C]{
+				Candidate: c,
+				Stage:     stageName,
+				Component: f.Name(),
+				Reason:    res.ReasonAt(i),
+			}
+			ex.removed(r)
+			removed = append(removed, r)
+		}
+		span.succeed(len(res.Kept), len(res.Removed), 0)
 	}
 	
 	log.Printf("request_id=%s stage=%s kept %d, removed %d",
-		query.RequestID, stageName, len(kept), len(removed))
+		query.Meta().RequestID, stageName, len(kept), len(removed))
 	
-	return kept, removed
+	return kept, removed, nil
+}
+
+// preRankCandidates 顺序执行所有 PreRankers，再按 PreRankScore 保留前 preRankSize 个候选
+// 没有 PreRankScore 的候选排在最后；同分时保持原顺序。保留的候选维持输入中的相对顺序，
+// 其余候选以 ReasonPreRankTruncated 移除。未配置 PreRankers 时不截断。
+func (p *CandidatePipelineOf[Q, C]) preRankCandidates(ctx context.Context, query Q, candidates []C, ex *explainer[C]) ([]C, []RemovedCandidateOf[C], error) {
+	if len(p.PreRankers) == 0 {
+		return candidates, nil, nil
+	}
+	scored, removed, err := p.runScorers(ctx, query, StagePreRanker, p.PreRankers, p.Deadlines.PreRanking, candidates, ex)
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	size := p.preRankSize(query)
+	if size <= 0 || len(scored) <= size {
+		return scored, removed, nil
+	}
+	kept, truncated := topByPreRankScore(scored, size)
+	for _, c := range truncated {
+		r := RemovedCandidateOf[C]{Candidate: c, Stage: StagePreRanker, Component: "CandidatePipeline", Reason: ReasonPreRankTruncated}
+		ex.removed(r)
+		removed = append(removed, r)
+	}
+	
+	log.Printf("request_id=%s stage=%s pre_rank_size=%d kept %d, truncated %d",
+		query.Meta().RequestID, StagePreRanker, size, len(kept), len(truncated))
+	
+	return kept, removed, nil
+}
+
+// preRankSize 返回本次请求进入重排的候选上限，Query 上的设置优先
+func (p *CandidatePipelineOf[Q, C]) preRankSize(query Q) int {
+	if query.Meta().PreRankSize > 0 {
+		return query.Meta().PreRankSize
+	}
+	return p.PreRankSize
+}
+
+// topByPreRankScore 把候选分为 PreRankScore 最高的 size 个（保持原顺序）和其余候选
+func topByPreRankScore[C PipelineCandidate[C]](candidates []C, size int) (kept, truncated []C) {
+	order := make([]int, len(candidates))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		sa, sb := candidates[order[a]].Meta().PreRankScore, candidates[order[b]].Meta().PreRankScore
+		if sa == nil || sb == nil {
+			return sa != nil && sb == nil
+		}
+		return *sa > *sb
+	})
+	
+	keep := make([]bool, len(candidates))
+	for _, i := range order[:size] {
+		keep[i] = true
+	}
+	kept = make([]C, 0, size)
+	truncated = make([]C, 0, len(candidates)-size)
+	for i, c := range candidates {
+		if keep[i] {
+			kept = append(kept, c)
+		} else {
+			truncated = append(truncated, c)
+		}
+	}
+	return kept, truncated
 }
 
 // scoreCandidates 顺序执行所有 Scorers
-func (p *CandidatePipeline) scoreCandidates(ctx context.Context, query *Query, candidates []*Candidate) []*Candidate {
+// 整个阶段受 Scoring 预算约束，单个 Scorer 超时后被放弃，候选保留之前的分数
+func (p *CandidatePipelineOf[Q, C]) scoreCandidates(ctx context.Context, query Q, candidates []C, ex *explainer[C]) ([]C, []RemovedCandidateOf[C], error) {
+	return p.runScorers(ctx, query, StageScorer, p.Scorers, p.Deadlines.Scoring, candidates, ex)
+}
+
+// runScorers 在 budget 内顺序执行一组 Scorer（Scorers 或 PreRankers）
+func (p *CandidatePipelineOf[Q, C]) runScorers(
+	ctx context.Context,
+	query Q,
+	stageName string,
+	scorers []ScorerOf[Q, C],
+	budget time.Duration,
+	candidates []C,
+	ex *explainer[C],
+) ([]C, []RemovedCandidateOf[C], error) {
 	expectedLen := len(candidates)
 	
-	for _, s := range p.Scorers {
+	ctx, cancel := withBudget(ctx, budget)
+	defer cancel()
+	obs := p.observer(ctx)
+	
+	for _, s := range scorers {
 		if !s.Enable(query) {
 			continue
 		}
 		
-		scored, err := s.Score(ctx, query, candidates)
-		if err != nil {
-			log.Printf("request_id=%s stage=Scorer component=%s failed: %v",
-				query.RequestID, s.Name(), err)
-			continue
+		span := startComponent(obs, ctx, query.Meta().RequestID, stageName, s.Name(), expectedLen)
+		timeout := p.Deadlines.componentTimeout(s.Name())
+		input := snapshotCandidates(candidates, abandonable(ctx, timeout, nil))
+		r := callWithTimeout(span.ctx, timeout, func(ctx context.Context) ([]C, error) {
+			return s.Score(ctx, query, input)
+		})
+		sErr := r.err
+		if sErr == nil && len(r.value) != expectedLen {
+			span.lengthMismatch(expectedLen, len(r.value))
+			sErr = fmt.Errorf("length_mismatch expected=%d got=%d", expectedLen, len(r.value))
 		}
-		
-		if len(scored) != expectedLen {
-			log.Printf("request_id=%s stage=Scorer component=%s skipped: length_mismatch expected=%d got=%d",
-				query.RequestID, s.Name(), expectedLen, len(scored))
+		if sErr != nil {
+			f := failure{stage: stageName, name: s.Name(), component: s, err: sErr, timedOut: r.timedOut}
+			policy := f.handle(query.Meta().RequestID)
+			switch policy {
+			case Critical:
+				span.fail(f, policy, 0, 0, r.elapsed)
+				return nil, nil, f.abortError()
+			case FailClosed:
+				dropped := dropAll(candidates, stageName, s.Name())
+				for _, d := range dropped {
+					ex.removed(d)
+				}
+				span.fail(f, policy, 0, len(dropped), r.elapsed)
+				return []C{}, dropped, nil
+			}
+			span.fail(f, policy, expectedLen, 0, r.elapsed)
+			markHydrationMissing(candidates, s.Name())
 			continue
 		}
 		
 		// 更新每个 candidate
 		for i := 0; i < expectedLen; i++ {
-			s.Update(candidates[i], scored[i])
+			s.Update(candidates[i], r.value[i])
 		}
+		ex.scored(s.Name(), candidates)
+		span.succeed(expectedLen, 0, r.elapsed)
 	}
 	
-	return candidates
+	return candidates, nil, nil
 }
 
 // selectCandidates 执行 Selector 选择候选
-func (p *CandidatePipeline) selectCandidates(ctx context.Context, query *Query, candidates []*Candidate) []*Candidate {
+// Selector panic 时按其失败策略处理，fail-open 时保留原顺序
+func (p *CandidatePipelineOf[Q, C]) selectCandidates(ctx context.Context, query Q, candidates []C) ([]C, error) {
 	if !p.Selector.Enable(query) {
-		return candidates
+		return candidates, nil
+	}
+	span := startComponent(p.observer(ctx), ctx, query.Meta().RequestID, StageSelector, p.Selector.Name(), len(candidates))
+	selected, err := safeCall(func() ([]C, error) { return p.Selector.Select(span.ctx, query, candidates), nil })
+	if err != nil {
+		f := failure{stage: StageSelector, name: p.Selector.Name(), component: p.Selector, err: err}
+		policy := f.handle(query.Meta().RequestID)
+		switch policy {
+		case Critical:
+			span.fail(f, policy, 0, 0, 0)
+			return nil, f.abortError()
+		case FailClosed:
+			span.fail(f, policy, 0, len(candidates), 0)
+			return []C{}, nil
+		}
+		span.fail(f, policy, len(candidates), 0, 0)
+		return candidates, nil
 	}
-	return p.Selector.Select(ctx, query, candidates)
+	span.succeed(len(selected), len(candidates)-len(selected), 0)
+	return selected, nil
 }
 
-// runSideEffects 异步执行所有 Side Effects（不阻塞主链路）
-func (p *CandidatePipeline) runSideEffects(ctx context.Context, query *Query, candidates []*Candidate) {
+// runSideEffects 把所有启用的 Side Effects 提交给执行器（不阻塞主链路）
+// 队列已满时任务被丢弃并计入 SideEffectStats.Dropped
+func (p *CandidatePipelineOf[Q, C]) runSideEffects(query Q, candidates []C) {
 	if len(p.SideEffects) == 0 {
 		return
 	}
-	
-	// 在 goroutine 中执行，不阻塞
-	go func() {
-		for _, se := range p.SideEffects {
-			if !se.Enable(query) {
-				continue
-			}
-			// 异步执行，忽略错误（side effect 不应该影响主流程）
-			_ = se.Run(ctx, query, candidates)
-		}
-	}()
+	SubmitSideEffects(p.SideEffectExecutor, query, candidates, p.SideEffects)
+}
+
+// stampRequestTime 为未设置 RequestTimeMs 的请求填写当前时间
+// 拷贝后再写入，不修改调用方的 query
+func stampRequestTime[Q PipelineQuery[Q]](query Q) Q {
+	if query.Meta().RequestTimeMs != 0 {
+		return query
+	}
+	stamped := query.Clone()
+	stamped.Meta().RequestTimeMs = time.Now().UnixMilli()
+	return stamped
 }
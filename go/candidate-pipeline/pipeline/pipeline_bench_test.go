@@ -0,0 +1,272 @@
+package pipeline_test
+
+// 管道执行的基准测试：一次请求在管道中的耗时和内存分配
+//
+// 使用合成组件（5 个 Hydrator、10 个 Filter、4 个 Scorer），分别以两种风格实现：
+//   - clone：Hydrator / Scorer 为每个候选调用 Clone() 再设置字段（旧写法）
+//   - patch：Hydrator / Scorer 只返回包含自己字段的补丁（home.NewCandidatePatches）
+//
+// 用法：
+//
+//	go test ./pipeline -run '^$' -bench Execute -benchmem
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"testing"
+
+	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
+)
+
+const (
+	benchCandidates = 1000 // 每次请求的候选数
+	benchResultSize = 50   // 返回的候选数
+)
+
+func BenchmarkExecute(b *testing.B) {
+	// 管道按请求打印日志，基准只测量管道本身
+	log.SetOutput(io.Discard)
+	b.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	for _, style := range []string{"clone", "patch"} {
+		b.Run(style, func(b *testing.B) {
+			p := newPipeline(b, style)
+			query := &home.Query{RequestMeta: pipeline.RequestMeta{UserID: 1, RequestID: "bench"}}
+			source := p.Sources[0].(*source)
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				source.reset(benchCandidates)
+				b.StartTimer()
+				if _, err := p.Execute(context.Background(), query); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func newPipeline(b *testing.B, style string) *home.CandidatePipeline {
+	clone := style == "clone"
+	p := &home.CandidatePipeline{
+		Sources: []home.Source{&source{}},
+		Hydrators: []home.Hydrator{
+			&hydrator{name: "InNetwork", clone: clone, set: func(c, in *home.Candidate) {
+				v := in.AuthorID%3 == 0
+				c.InNetwork = &v
+			}, update: func(c, h *home.Candidate) { c.InNetwork = h.InNetwork }},
+			&hydrator{name: "VideoDuration", clone: clone, set: func(c, in *home.Candidate) {
+				v := int32(in.TweetID % 60000)
+				c.VideoDurationMs = &v
+			}, update: func(c, h *home.Candidate) { c.VideoDurationMs = h.VideoDurationMs }},
+			&hydrator{name: "Subscription", clone: clone, set: func(c, in *home.Candidate) {
+				if in.TweetID%7 == 0 {
+					v := in.AuthorID
+					c.SubscriptionAuthorID = &v
+				}
+			}, update: func(c, h *home.Candidate) {
+				if h.SubscriptionAuthorID != nil {
+					c.SubscriptionAuthorID = h.SubscriptionAuthorID
+				}
+			}},
+			&hydrator{name: "Gizmoduck", clone: clone, set: func(c, in *home.Candidate) {
+				name := "user"
+				followers := int32(in.AuthorID % 10000)
+				c.AuthorScreenName = &name
+				c.AuthorFollowersCount = &followers
+			}, update: func(c, h *home.Candidate) {
+				c.AuthorScreenName = h.AuthorScreenName
+				c.AuthorFollowersCount = h.AuthorFollowersCount
+			}},
+			&hydrator{name: "Visibility", clone: clone, set: func(c, in *home.Candidate) {
+				if in.TweetID%97 == 0 {
+					v := "spam"
+					c.VisibilityReason = &v
+				}
+			}, update: func(c, h *home.Candidate) {
+				if h.VisibilityReason != nil {
+					c.VisibilityReason = h.VisibilityReason
+				}
+			}},
+		},
+		Scorers: []home.Scorer{
+			&scorer{name: "Phoenix", clone: clone, set: func(c, in *home.Candidate) {
+				fav := float64(in.TweetID%100) / 100
+				c.PhoenixScores = &home.PhoenixScores{FavoriteScore: &fav}
+			}, update: func(c, s *home.Candidate) { c.PhoenixScores = s.PhoenixScores }},
+			&scorer{name: "Weighted", clone: clone, set: func(c, in *home.Candidate) {
+				v := 0.0
+				if in.PhoenixScores != nil && in.PhoenixScores.FavoriteScore != nil {
+					v = *in.PhoenixScores.FavoriteScore
+				}
+				c.WeightedScore = &v
+			}, update: func(c, s *home.Candidate) { c.WeightedScore = s.WeightedScore }},
+			&scorer{name: "AuthorDiversity", clone: clone, set: func(c, in *home.Candidate) {
+				if in.WeightedScore != nil {
+					v := *in.WeightedScore * 0.9
+					c.Score = &v
+				}
+			}, update: func(c, s *home.Candidate) {
+				if s.Score != nil {
+					c.Score = s.Score
+				}
+			}},
+			&scorer{name: "OON", clone: clone, set: func(c, in *home.Candidate) {
+				if in.Score != nil && in.InNetwork != nil && !*in.InNetwork {
+					v := *in.Score * 0.9
+					c.Score = &v
+				}
+			}, update: func(c, s *home.Candidate) {
+				if s.Score != nil {
+					c.Score = s.Score
+				}
+			}},
+		},
+		Selector:   &topK{k: benchResultSize},
+		ResultSize: benchResultSize,
+	}
+	// 每个 Filter 移除约 1% 的候选
+	for i := 0; i < 10; i++ {
+		p.Filters = append(p.Filters, &filter{name: fmt.Sprintf("Filter%d", i), mod: int64(89 + i)})
+	}
+	if err := p.Build(); err != nil {
+		b.Fatal(err)
+	}
+	return p
+}
+
+// source 每次返回预先生成的候选
+type source struct {
+	candidates []*home.Candidate
+}
+
+// reset 重新生成 n 个候选（不计入测量）
+func (s *source) reset(n int) {
+	s.candidates = make([]*home.Candidate, n)
+	for i := range s.candidates {
+		replyTo := uint64(i)
+		s.candidates[i] = &home.Candidate{
+			TweetID:          int64(1_000_000 + i),
+			AuthorID:         uint64(i % 200),
+			TweetText:        "benchmark candidate",
+			InReplyToTweetID: &replyTo,
+			Ancestors:        []uint64{replyTo},
+		}
+	}
+}
+
+func (s *source) GetCandidates(ctx context.Context, query *home.Query) ([]*home.Candidate, error) {
+	return s.candidates, nil
+}
+func (s *source) Name() string                  { return "BenchSource" }
+func (s *source) Enable(query *home.Query) bool { return true }
+
+// hydrator 设置一组字段；clone 为 true 时按旧写法先克隆整个候选
+type hydrator struct {
+	name   string
+	clone  bool
+	set    func(out, in *home.Candidate)
+	update func(c, hydrated *home.Candidate)
+}
+
+func (h *hydrator) Hydrate(ctx context.Context, query *home.Query, candidates []*home.Candidate) ([]*home.Candidate, error) {
+	return apply(h.clone, candidates, h.set), nil
+}
+func (h *hydrator) Name() string                       { return h.name }
+func (h *hydrator) Enable(query *home.Query) bool      { return true }
+func (h *hydrator) Update(c, hydrated *home.Candidate) { h.update(c, hydrated) }
+func (h *hydrator) UpdateAll(candidates, hydrated []*home.Candidate) {
+	pipeline.DefaultUpdateAll(h, candidates, hydrated)
+}
+
+// scorer 与 hydrator 相同，只是实现 Scorer 接口
+type scorer struct {
+	name   string
+	clone  bool
+	set    func(out, in *home.Candidate)
+	update func(c, scored *home.Candidate)
+}
+
+func (s *scorer) Score(ctx context.Context, query *home.Query, candidates []*home.Candidate) ([]*home.Candidate, error) {
+	return apply(s.clone, candidates, s.set), nil
+}
+func (s *scorer) Name() string                     { return s.name }
+func (s *scorer) Enable(query *home.Query) bool    { return true }
+func (s *scorer) Update(c, scored *home.Candidate) { s.update(c, scored) }
+func (s *scorer) UpdateAll(candidates, scored []*home.Candidate) {
+	pipeline.DefaultScorerUpdateAll(s, candidates, scored)
+}
+
+// apply 为每个候选生成输出：clone 风格克隆整个候选，patch 风格只分配一次补丁
+func apply(clone bool, candidates []*home.Candidate, set func(out, in *home.Candidate)) []*home.Candidate {
+	var out []*home.Candidate
+	if clone {
+		out = make([]*home.Candidate, len(candidates))
+		for i, c := range candidates {
+			out[i] = c.Clone()
+		}
+	} else {
+		out = home.NewCandidatePatches(len(candidates))
+	}
+	for i, c := range candidates {
+		set(out[i], c)
+	}
+	return out
+}
+
+// filter 移除 TweetID 能被 mod 整除的候选
+type filter struct {
+	name string
+	mod  int64
+}
+
+func (f *filter) Filter(ctx context.Context, query *home.Query, candidates []*home.Candidate) (*home.FilterResult, error) {
+	kept := make([]*home.Candidate, 0, len(candidates))
+	var removed []*home.Candidate
+	for _, c := range candidates {
+		if c.TweetID%f.mod == 0 {
+			removed = append(removed, c)
+		} else {
+			kept = append(kept, c)
+		}
+	}
+	return &home.FilterResult{Kept: kept, Removed: removed}, nil
+}
+func (f *filter) Name() string                  { return f.name }
+func (f *filter) Enable(query *home.Query) bool { return true }
+
+// topK 按 Score 降序选择前 k 个
+type topK struct {
+	k int
+}
+
+func (s *topK) Select(ctx context.Context, query *home.Query, candidates []*home.Candidate) []*home.Candidate {
+	sorted := s.Sort(candidates)
+	if len(sorted) > s.k {
+		sorted = sorted[:s.k]
+	}
+	return sorted
+}
+func (s *topK) Name() string                  { return "TopK" }
+func (s *topK) Enable(query *home.Query) bool { return true }
+func (s *topK) Size() *int                    { return &s.k }
+
+func (s *topK) Score(c *home.Candidate) float64 {
+	if c.Score == nil {
+		return 0
+	}
+	return *c.Score
+}
+
+func (s *topK) Sort(candidates []*home.Candidate) []*home.Candidate {
+	sorted := make([]*home.Candidate, len(candidates))
+	copy(sorted, candidates)
+	sort.SliceStable(sorted, func(i, j int) bool { return s.Score(sorted[i]) > s.Score(sorted[j]) })
+	return sorted
+}
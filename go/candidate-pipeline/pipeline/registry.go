@@ -0,0 +1,420 @@
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ComponentKind 表示组件类型（决定组件可以出现在定义的哪些列表中）
+type ComponentKind string
+
+const (
+	KindQueryHydrator ComponentKind = "query_hydrator"
+	KindSource        ComponentKind = "source"
+	KindMerger        ComponentKind = "merger"
+	KindHydrator      ComponentKind = "hydrator"
+	KindFilter        ComponentKind = "filter"
+	KindScorer        ComponentKind = "scorer"
+	KindSelector      ComponentKind = "selector"
+	KindSideEffect    ComponentKind = "side_effect"
+)
+
+// ParamsValidator 由需要额外校验的参数类型实现（可选）
+// 参数解码完成后调用，返回错误时编译失败
+type ParamsValidator interface {
+	Validate() error
+}
+
+// NoParams 是不接受任何参数的组件的参数类型
+type NoParams struct{}
+
+// Duration 是定义文件中使用的时长，以字符串表示（例如 "150ms"、"168h"）
+type Duration time.Duration
+
+// UnmarshalJSON 实现 json.Unmarshaler
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"150ms\": %s", data)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalJSON 实现 json.Marshaler
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// factory 根据原始参数构造组件
+type factory struct {
+	kind  ComponentKind
+	build func(layers ...json.RawMessage) (any, error)
+}
+
+// RegistryOf 保存按名称注册的组件工厂
+// 每个组件注册一个带类型的参数结构体，定义文件中的 params 会被严格解码到该结构体
+// （未知字段报错），未给出的字段保留注册时提供的默认值。
+// 注册表只接受查询类型为 Q、候选类型为 C 的组件，编译出 CandidatePipelineOf[Q, C]。
+type RegistryOf[Q PipelineQuery[Q], C PipelineCandidate[C]] struct {
+	factories map[ComponentKind]map[string]factory
+}
+
+// NewRegistryOf 创建空的组件注册表
+func NewRegistryOf[Q PipelineQuery[Q], C PipelineCandidate[C]]() *RegistryOf[Q, C] {
+	return &RegistryOf[Q, C]{factories: make(map[ComponentKind]map[string]factory)}
+}
+
+// Names 返回某类组件已注册的名称（按字母序）
+func (r *RegistryOf[Q, C]) Names(kind ComponentKind) []string {
+	names := make([]string, 0, len(r.factories[kind]))
+	for name := range r.factories[kind] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// register 注册组件工厂；同一类型下重复注册同名组件属于编程错误，直接 panic
+func register[P any, T any](factories map[ComponentKind]map[string]factory, kind ComponentKind, name string, defaults func() P, build func(P) (T, error)) {
+	if factories[kind] == nil {
+		factories[kind] = make(map[string]factory)
+	}
+	if _, ok := factories[kind][name]; ok {
+		panic(fmt.Sprintf("pipeline: %s %q registered twice", kind, name))
+	}
+	factories[kind][name] = factory{
+		kind: kind,
+		build: func(layers ...json.RawMessage) (any, error) {
+			var params P
+			if defaults != nil {
+				params = defaults()
+			}
+			for _, raw := range layers {
+				if err := decodeParams(raw, &params); err != nil {
+					return nil, err
+				}
+			}
+			if v, ok := any(&params).(ParamsValidator); ok {
+				if err := v.Validate(); err != nil {
+					return nil, fmt.Errorf("invalid params: %w", err)
+				}
+			}
+			return build(params)
+		},
+	}
+}
+
+// decodeParams 把 raw 严格解码到 params，raw 为空时保持默认值
+func decodeParams(raw json.RawMessage, params any) error {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(params); err != nil {
+		return fmt.Errorf("invalid params: %w", err)
+	}
+	return nil
+}
+
+// RegisterQueryHydrator 注册 QueryHydrator
+func RegisterQueryHydrator[P any, Q PipelineQuery[Q], C PipelineCandidate[C]](r *RegistryOf[Q, C], name string, defaults func() P, build func(P) (QueryHydratorOf[Q], error)) {
+	register(r.factories, KindQueryHydrator, name, defaults, build)
+}
+
+// RegisterSource 注册 Source
+func RegisterSource[P any, Q PipelineQuery[Q], C PipelineCandidate[C]](r *RegistryOf[Q, C], name string, defaults func() P, build func(P) (SourceOf[Q, C], error)) {
+	register(r.factories, KindSource, name, defaults, build)
+}
+
+// RegisterMerger 注册 CandidateMerger
+func RegisterMerger[P any, Q PipelineQuery[Q], C PipelineCandidate[C]](r *RegistryOf[Q, C], name string, defaults func() P, build func(P) (CandidateMergerOf[Q, C], error)) {
+	register(r.factories, KindMerger, name, defaults, build)
+}
+
+// RegisterHydrator 注册 Hydrator（可用于 hydrators 和 post_selection_hydrators）
+func RegisterHydrator[P any, Q PipelineQuery[Q], C PipelineCandidate[C]](r *RegistryOf[Q, C], name string, defaults func() P, build func(P) (HydratorOf[Q, C], error)) {
+	register(r.factories, KindHydrator, name, defaults, build)
+}
+
+// RegisterFilter 注册 Filter（可用于 filters 和 post_selection_filters）
+func RegisterFilter[P any, Q PipelineQuery[Q], C PipelineCandidate[C]](r *RegistryOf[Q, C], name string, defaults func() P, build func(P) (FilterOf[Q, C], error)) {
+	register(r.factories, KindFilter, name, defaults, build)
+}
+
+// RegisterScorer 注册 Scorer
+func RegisterScorer[P any, Q PipelineQuery[Q], C PipelineCandidate[C]](r *RegistryOf[Q, C], name string, defaults func() P, build func(P) (ScorerOf[Q, C], error)) {
+	register(r.factories, KindScorer, name, defaults, build)
+}
+
+// RegisterSelector 注册 Selector
+func RegisterSelector[P any, Q PipelineQuery[Q], C PipelineCandidate[C]](r *RegistryOf[Q, C], name string, defaults func() P, build func(P) (SelectorOf[Q, C], error)) {
+	register(r.factories, KindSelector, name, defaults, build)
+}
+
+// RegisterSideEffect 注册 SideEffect
+func RegisterSideEffect[P any, Q PipelineQuery[Q], C PipelineCandidate[C]](r *RegistryOf[Q, C], name string, defaults func() P, build func(P) (SideEffectOf[Q, C], error)) {
+	register(r.factories, KindSideEffect, name, defaults, build)
+}
+
+// ComponentSpec 是定义文件中的一个组件：注册名 + 参数
+type ComponentSpec struct {
+	Name   string          `json:"name"`
+	Params json.RawMessage `json:"params,omitempty"`
+
+	// Experiment 非空时组件随该实验的分组变化（实验需在定义的 experiments 中声明）：
+	//   - Treatments 非空时组件只对分到这些分组的请求启用
+	//   - TreatmentParams 按分组在 Params 之上覆盖参数，每个分组构造一个独立实例
+	Experiment      string                     `json:"experiment,omitempty"`
+	Treatments      []string                   `json:"treatments,omitempty"`
+	TreatmentParams map[string]json.RawMessage `json:"treatment_params,omitempty"`
+}
+
+// DeadlinesDefinition 是定义文件中的 Deadlines
+type DeadlinesDefinition struct {
+	QueryHydration         Duration            `json:"query_hydration,omitempty"`
+	Sourcing               Duration            `json:"sourcing,omitempty"`
+	Hydration              Duration            `json:"hydration,omitempty"`
+	PreRanking             Duration            `json:"pre_ranking,omitempty"`
+	Scoring                Duration            `json:"scoring,omitempty"`
+	PostSelectionHydration Duration            `json:"post_selection_hydration,omitempty"`
+	DefaultComponent       Duration            `json:"default_component,omitempty"`
+	Component              map[string]Duration `json:"component,omitempty"`
+}
+
+// Deadlines 转换为管道使用的 Deadlines
+func (d *DeadlinesDefinition) Deadlines() Deadlines {
+	out := Deadlines{
+		QueryHydration:         time.Duration(d.QueryHydration),
+		Sourcing:               time.Duration(d.Sourcing),
+		Hydration:              time.Duration(d.Hydration),
+		PreRanking:             time.Duration(d.PreRanking),
+		Scoring:                time.Duration(d.Scoring),
+		PostSelectionHydration: time.Duration(d.PostSelectionHydration),
+		DefaultComponent:       time.Duration(d.DefaultComponent),
+	}
+	if len(d.Component) > 0 {
+		out.Component = make(map[string]time.Duration, len(d.Component))
+		for name, t := range d.Component {
+			out.Component[name] = time.Duration(t)
+		}
+	}
+	return out
+}
+
+// HedgingDefinition 是定义文件中的 Hedging
+type HedgingDefinition struct {
+	Components []string `json:"components,omitempty"`
+	Percentile float64  `json:"percentile,omitempty"`
+	MinDelay   Duration `json:"min_delay,omitempty"`
+	MaxRatio   float64  `json:"max_ratio,omitempty"`
+}
+
+// Hedging 转换为管道使用的 Hedging
+func (h *HedgingDefinition) Hedging() Hedging {
+	return Hedging{
+		Components: h.Components,
+		Percentile: h.Percentile,
+		MinDelay:   time.Duration(h.MinDelay),
+		MaxRatio:   h.MaxRatio,
+	}
+}
+
+// PipelineDefinition 声明式地描述一个管道变体
+// 列表中的顺序即执行顺序（Filters / PreRankers / Scorers 顺序执行；Hydrators 在依赖分层内并行）
+// PreRankers 与 Scorers 使用同一批注册的 Scorer
+type PipelineDefinition struct {
+	Name                   string               `json:"name"`
+	Experiments            []Experiment         `json:"experiments,omitempty"`
+	QueryHydrators         []ComponentSpec      `json:"query_hydrators,omitempty"`
+	Sources                []ComponentSpec      `json:"sources"`
+	Merger                 *ComponentSpec       `json:"merger,omitempty"`
+	Hydrators              []ComponentSpec      `json:"hydrators,omitempty"`
+	Filters                []ComponentSpec      `json:"filters,omitempty"`
+	PreRankers             []ComponentSpec      `json:"pre_rankers,omitempty"`
+	PreRankSize            int                  `json:"pre_rank_size,omitempty"`
+	Scorers                []ComponentSpec      `json:"scorers,omitempty"`
+	Selector               *ComponentSpec       `json:"selector"`
+	PostSelectionHydrators []ComponentSpec      `json:"post_selection_hydrators,omitempty"`
+	PostSelectionFilters   []ComponentSpec      `json:"post_selection_filters,omitempty"`
+	SideEffects            []ComponentSpec      `json:"side_effects,omitempty"`
+	ResultSize             int                  `json:"result_size,omitempty"`
+	Deadlines              *DeadlinesDefinition `json:"deadlines,omitempty"`
+	Hedging                *HedgingDefinition   `json:"hedging,omitempty"`
+}
+
+// ParseDefinition 严格解析 JSON 格式的管道定义（未知字段报错）
+func ParseDefinition(data []byte) (*PipelineDefinition, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var def PipelineDefinition
+	if err := dec.Decode(&def); err != nil {
+		return nil, fmt.Errorf("parse pipeline definition: %w", err)
+	}
+	return &def, nil
+}
+
+// Compile 校验定义并构造 CandidatePipelineOf
+// 所有问题（未知组件、类型不符、参数错误、缺少 Selector）会一次性合并返回。
+// 返回的管道尚未 Build：调用方可以继续设置 Deadlines、SideEffectExecutor 等字段后再调用 Build。
+func (r *RegistryOf[Q, C]) Compile(def *PipelineDefinition) (*CandidatePipelineOf[Q, C], error) {
+	var errs []error
+	c := compiler[Q, C]{registry: r, errs: &errs, experiments: make(map[string]Experiment, len(def.Experiments))}
+	if err := validateExperiments(def.Experiments); err != nil {
+		errs = append(errs, fmt.Errorf("experiments: %w", err))
+	}
+	for _, e := range def.Experiments {
+		c.experiments[e.Name] = e
+	}
+
+	p := &CandidatePipelineOf[Q, C]{
+		QueryHydrators:         compileList[QueryHydratorOf[Q]](c, KindQueryHydrator, "query_hydrators", def.QueryHydrators),
+		Sources:                compileList[SourceOf[Q, C]](c, KindSource, "sources", def.Sources),
+		Hydrators:              compileList[HydratorOf[Q, C]](c, KindHydrator, "hydrators", def.Hydrators),
+		Filters:                compileList[FilterOf[Q, C]](c, KindFilter, "filters", def.Filters),
+		PreRankers:             compileList[ScorerOf[Q, C]](c, KindScorer, "pre_rankers", def.PreRankers),
+		PreRankSize:            def.PreRankSize,
+		Scorers:                compileList[ScorerOf[Q, C]](c, KindScorer, "scorers", def.Scorers),
+		PostSelectionHydrators: compileList[HydratorOf[Q, C]](c, KindHydrator, "post_selection_hydrators", def.PostSelectionHydrators),
+		PostSelectionFilters:   compileList[FilterOf[Q, C]](c, KindFilter, "post_selection_filters", def.PostSelectionFilters),
+		SideEffects:            compileList[SideEffectOf[Q, C]](c, KindSideEffect, "side_effects", def.SideEffects),
+		ResultSize:             def.ResultSize,
+		Experiments:            def.Experiments,
+	}
+
+	if len(def.Sources) == 0 {
+		errs = append(errs, errors.New("sources: at least one source is required"))
+	}
+	if def.Selector == nil {
+		errs = append(errs, errors.New("selector: required"))
+	} else if s, ok := compileOne[SelectorOf[Q, C]](c, KindSelector, "selector", *def.Selector); ok {
+		p.Selector = s
+	}
+	if def.Merger != nil {
+		if m, ok := compileOne[CandidateMergerOf[Q, C]](c, KindMerger, "merger", *def.Merger); ok {
+			p.Merger = m
+		}
+	}
+	if def.ResultSize < 0 {
+		errs = append(errs, fmt.Errorf("result_size: must be >= 0, got %d", def.ResultSize))
+	}
+	if def.PreRankSize < 0 {
+		errs = append(errs, fmt.Errorf("pre_rank_size: must be >= 0, got %d", def.PreRankSize))
+	}
+	if def.PreRankSize > 0 && len(def.PreRankers) == 0 {
+		errs = append(errs, errors.New("pre_rank_size: requires at least one pre_ranker"))
+	}
+	if def.Deadlines != nil {
+		p.Deadlines = def.Deadlines.Deadlines()
+	}
+	if def.Hedging != nil {
+		p.Hedging = def.Hedging.Hedging()
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("pipeline %q: %w", def.Name, errors.Join(errs...))
+	}
+	return p, nil
+}
+
+// compiler 在编译过程中收集错误
+type compiler[Q PipelineQuery[Q], C PipelineCandidate[C]] struct {
+	registry    *RegistryOf[Q, C]
+	errs        *[]error
+	experiments map[string]Experiment
+}
+
+// compileOne 构造单个组件，失败时记录错误并返回 false
+func compileOne[T any, Q PipelineQuery[Q], C PipelineCandidate[C]](c compiler[Q, C], kind ComponentKind, path string, spec ComponentSpec) (T, bool) {
+	var zero T
+	f, ok := c.registry.factories[kind][spec.Name]
+	if !ok {
+		*c.errs = append(*c.errs, fmt.Errorf("%s: unknown %s %q (registered: %v)", path, kind, spec.Name, c.registry.Names(kind)))
+		return zero, false
+	}
+	component, ok := buildComponent[T](c, f, path, spec, spec.Params)
+	if !ok {
+		return zero, false
+	}
+	if spec.Experiment == "" {
+		if len(spec.Treatments) > 0 || len(spec.TreatmentParams) > 0 {
+			*c.errs = append(*c.errs, fmt.Errorf("%s (%s): treatments / treatment_params require experiment", path, spec.Name))
+			return zero, false
+		}
+		return component, true
+	}
+
+	experiment, ok := c.experiments[spec.Experiment]
+	if !ok {
+		*c.errs = append(*c.errs, fmt.Errorf("%s (%s): unknown experiment %q", path, spec.Name, spec.Experiment))
+		return zero, false
+	}
+	valid := true
+	for _, t := range spec.Treatments {
+		if !experiment.HasTreatment(t) {
+			*c.errs = append(*c.errs, fmt.Errorf("%s (%s): experiment %q has no treatment %q", path, spec.Name, spec.Experiment, t))
+			valid = false
+		}
+	}
+	treatments := make([]string, 0, len(spec.TreatmentParams))
+	for t := range spec.TreatmentParams {
+		treatments = append(treatments, t)
+	}
+	sort.Strings(treatments)
+	variants := make(map[string]T, len(treatments))
+	for _, t := range treatments {
+		params := spec.TreatmentParams[t]
+		if !experiment.HasTreatment(t) {
+			*c.errs = append(*c.errs, fmt.Errorf("%s (%s): experiment %q has no treatment %q", path, spec.Name, spec.Experiment, t))
+			valid = false
+			continue
+		}
+		variant, ok := buildComponent[T](c, f, fmt.Sprintf("%s treatment_params[%s]", path, t), spec, spec.Params, params)
+		if !ok {
+			valid = false
+			continue
+		}
+		variants[t] = variant
+	}
+	if !valid {
+		return zero, false
+	}
+	return routeExperiment[Q, C](f.kind, spec, component, variants).(T), true
+}
+
+// buildComponent 依次叠加参数层构造组件实例
+func buildComponent[T any, Q PipelineQuery[Q], C PipelineCandidate[C]](c compiler[Q, C], f factory, path string, spec ComponentSpec, layers ...json.RawMessage) (T, bool) {
+	var zero T
+	v, err := f.build(layers...)
+	if err != nil {
+		*c.errs = append(*c.errs, fmt.Errorf("%s (%s): %w", path, spec.Name, err))
+		return zero, false
+	}
+	component, ok := v.(T)
+	if !ok {
+		*c.errs = append(*c.errs, fmt.Errorf("%s (%s): factory returned %T, not a %s", path, spec.Name, v, f.kind))
+		return zero, false
+	}
+	return component, true
+}
+
+// compileList 按顺序构造一组组件
+func compileList[T any, Q PipelineQuery[Q], C PipelineCandidate[C]](c compiler[Q, C], kind ComponentKind, path string, specs []ComponentSpec) []T {
+	out := make([]T, 0, len(specs))
+	for i, spec := range specs {
+		if component, ok := compileOne[T](c, kind, fmt.Sprintf("%s[%d]", path, i), spec); ok {
+			out = append(out, component)
+		}
+	}
+	return out
+}
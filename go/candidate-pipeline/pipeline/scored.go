@@ -0,0 +1,47 @@
+package pipeline
+
+import "context"
+
+// ExecuteScored 在已经打分的候选上执行管道的后半段：Filters、Selection、Post-Selection Hydration / Filtering
+// 和 Side Effects，跳过 Query Hydration、Sourcing、Hydration、Pre-Ranking 和 Scoring。
+//
+// 用于分页等场景：第一页的 PipelineResult.ScoredCandidates 和增强后的 Query 被缓存后，
+// 后续页面以缓存的候选和（更新了 ServedIDs 等分页字段的）Query 调用 ExecuteScored，
+// Filters 会按新的 Query 重新过滤，避免为每一页重新检索和打分。
+//
+// 管道会修改传入的候选（例如 Post-Selection Hydrator 的 Update），调用方需要传入拷贝。
+// 返回结果的 RetrievedCandidates 为传入的候选。
+func (p *CandidatePipelineOf[Q, C]) ExecuteScored(ctx context.Context, query Q, candidates []C, opts ExecuteOptions) (*PipelineResultOf[Q, C], error) {
+	if err := p.Build(); err != nil {
+		return nil, err
+	}
+	ctx = withExecutionObserver(ctx, opts.Observer)
+	ex := newExplainer[C](opts.Explain)
+	query = p.assignExperiments(stampRequestTime(query))
+	requestID := query.Meta().RequestID
+	for _, c := range candidates {
+		ex.sourced(primarySource(c), []C{c})
+	}
+
+	stage := p.startStage(ctx, requestID, StageFilter, len(candidates))
+	kept, removals, err := p.filterCandidates(stage.ctx, query, candidates, ex)
+	stage.end(len(kept), err)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := p.selectAndFinish(ctx, query, kept, removals, ex, opts)
+	if err != nil {
+		return nil, err
+	}
+	result.RetrievedCandidates = candidates
+	return result, nil
+}
+
+// primarySource 返回候选的第一个来源，没有来源记录时返回空字符串
+func primarySource[C PipelineCandidate[C]](c C) string {
+	if p := c.Meta().Provenance; len(p) > 0 {
+		return p[0].Source
+	}
+	return ""
+}
@@ -2,34 +2,42 @@ package pipeline
 
 import "context"
 
-// Scorer 表示打分器接口
+// ScorerOf 表示打分器接口
 // Scorers 顺序执行，每个 scorer 基于前一个 scorer 的结果
-type Scorer interface {
+type ScorerOf[Q any, C any] interface {
 	// Score 为候选列表打分
 	// 执行异步操作，返回打分后的候选列表
 	//
 	// 重要：返回的切片必须与输入的候选数量相同且顺序一致
 	// 不允许在 scorer 中删除候选，应该使用 filter 阶段
-	Score(ctx context.Context, query *Query, candidates []*Candidate) ([]*Candidate, error)
+	//
+	// 与 Hydrator 相同，返回的候选是只包含打分字段的补丁（通常用 NewPatches 分配），
+	// 不要修改输入的候选，也不需要 Clone
+	//
+	// ctx 在组件超时或 Scoring 预算用尽时被取消，超时的 scorer 被跳过，候选保留之前的分数，
+	// 并把 scorer 记入候选的 MissingHydrations（与 Hydrator 相同）。
+	// 可能被放弃时，输入的候选是快照，被放弃后继续读取不会与之后 scorer 的合并冲突
+	Score(ctx context.Context, query Q, candidates []C) ([]C, error)
 	
 	// Name 返回 Scorer 的名称（用于日志和监控）
 	Name() string
 	
 	// Enable 决定这个 Scorer 是否应该为给定的查询执行
 	// 默认返回 true，子类可以覆盖以实现条件执行
-	Enable(query *Query) bool
+	Enable(query Q) bool
 	
 	// Update 更新单个候选的打分字段
-	// 只应该复制这个 scorer 负责的字段
-	Update(candidate *Candidate, scored *Candidate)
+	// 只应该复制这个 scorer 负责的字段；scored 是 Score 返回的补丁，其他字段为零值
+	Update(candidate C, scored C)
 	
 	// UpdateAll 批量更新候选的打分字段
 	// 默认实现遍历并调用 Update 方法
-	UpdateAll(candidates []*Candidate, scored []*Candidate)
+	UpdateAll(candidates []C, scored []C)
 }
 
+
 // DefaultScorerUpdateAll 提供 UpdateAll 的默认实现
-func DefaultScorerUpdateAll(scorer Scorer, candidates []*Candidate, scored []*Candidate) {
+func DefaultScorerUpdateAll[C any](scorer interface{ Update(C, C) }, candidates []C, scored []C) {
 	if len(candidates) != len(scored) {
 		return
 	}
@@ -0,0 +1,267 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SideEffectExecutorConfig 配置 Side Effect 执行器
+type SideEffectExecutorConfig struct {
+	QueueSize      int           // 队列容量，队列满时新任务被丢弃
+	Workers        int           // 工作 goroutine 数量
+	MaxRetries     int           // 每个 Side Effect 失败后的最大重试次数（不含首次执行）
+	InitialBackoff time.Duration // 首次重试前的等待时间，之后指数增长
+	MaxBackoff     time.Duration // 重试等待时间上限
+	AttemptTimeout time.Duration // 单次执行的超时，0 表示不限制
+}
+
+// DefaultSideEffectExecutorConfig 返回默认配置
+func DefaultSideEffectExecutorConfig() SideEffectExecutorConfig {
+	return SideEffectExecutorConfig{
+		QueueSize:      1024,
+		Workers:        8,
+		MaxRetries:     2,
+		InitialBackoff: 50 * time.Millisecond,
+		MaxBackoff:     1 * time.Second,
+		AttemptTimeout: 2 * time.Second,
+	}
+}
+
+// SideEffectStats 是执行器的累计计数
+type SideEffectStats struct {
+	Submitted uint64 // 进入队列的任务数
+	Dropped   uint64 // 因队列已满或执行器已关闭而丢弃的任务数
+	Succeeded uint64 // 最终成功的任务数
+	Failed    uint64 // 重试耗尽后仍失败的任务数
+	Retried   uint64 // 重试次数
+	Panicked  uint64 // 发生 panic 的执行次数
+	Pending   int64  // 当前排队和执行中的任务数
+}
+
+// sideEffectTask 表示一个待执行的 Side Effect
+// run 已绑定请求和候选，执行器因此不依赖具体的查询和候选类型
+type sideEffectTask struct {
+	requestID string
+	name      string
+	run       func(ctx context.Context) error
+}
+
+// SideEffectExecutor 使用有界队列和固定数量的 worker 执行 Side Effects
+// 队列满时直接丢弃新任务，保证在高负载下 goroutine 数量有上限；
+// 关闭时通过 Drain 在预算内执行完队列中的任务。
+type SideEffectExecutor struct {
+	config SideEffectExecutorConfig
+	queue  chan sideEffectTask
+
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+
+	// abort 在 Drain 超时后取消，正在等待重试的任务会立即放弃
+	abortCtx context.Context
+	abort    context.CancelFunc
+
+	submitted atomic.Uint64
+	dropped   atomic.Uint64
+	succeeded atomic.Uint64
+	failed    atomic.Uint64
+	retried   atomic.Uint64
+	panicked  atomic.Uint64
+	pending   atomic.Int64
+}
+
+// NewSideEffectExecutor 创建执行器并启动 worker
+func NewSideEffectExecutor(config SideEffectExecutorConfig) *SideEffectExecutor {
+	defaults := DefaultSideEffectExecutorConfig()
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaults.QueueSize
+	}
+	if config.Workers <= 0 {
+		config.Workers = defaults.Workers
+	}
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = defaults.InitialBackoff
+	}
+	if config.MaxBackoff < config.InitialBackoff {
+		config.MaxBackoff = config.InitialBackoff
+	}
+
+	abortCtx, abort := context.WithCancel(context.Background())
+	e := &SideEffectExecutor{
+		config:   config,
+		queue:    make(chan sideEffectTask, config.QueueSize),
+		abortCtx: abortCtx,
+		abort:    abort,
+	}
+	for i := 0; i < config.Workers; i++ {
+		e.wg.Add(1)
+		go e.worker()
+	}
+	return e
+}
+
+// SubmitSideEffects 把启用的 Side Effects 放入 e 的队列，不阻塞调用方
+// 返回被丢弃的任务数
+func SubmitSideEffects[Q PipelineQuery[Q], C any](e *SideEffectExecutor, query Q, candidates []C, effects []SideEffectOf[Q, C]) int {
+	tasks := make([]sideEffectTask, 0, len(effects))
+	for _, se := range effects {
+		if !se.Enable(query) {
+			continue
+		}
+		tasks = append(tasks, sideEffectTask{
+			requestID: query.Meta().RequestID,
+			name:      se.Name(),
+			run: func(ctx context.Context) error {
+				return se.Run(ctx, query, candidates)
+			},
+		})
+	}
+	return e.enqueue(tasks)
+}
+
+// enqueue 把任务放入队列，队列已满或执行器已关闭时丢弃
+func (e *SideEffectExecutor) enqueue(tasks []sideEffectTask) int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	dropped := 0
+	for _, task := range tasks {
+		if e.closed {
+			dropped++
+			continue
+		}
+		select {
+		case e.queue <- task:
+			e.submitted.Add(1)
+			e.pending.Add(1)
+		default:
+			dropped++
+		}
+	}
+	if dropped > 0 {
+		e.dropped.Add(uint64(dropped))
+	}
+	return dropped
+}
+
+// Drain 停止接收新任务，并等待队列中的任务执行完毕
+// ctx 结束时放弃剩余任务（包括正在等待重试的任务）并返回 ctx 的错误
+func (e *SideEffectExecutor) Drain(ctx context.Context) error {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.queue)
+	}
+	e.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		e.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		e.abort()
+		log.Printf("stage=SideEffect drain aborted with %d pending tasks: %v", e.pending.Load(), ctx.Err())
+		return ctx.Err()
+	}
+}
+
+// Stats 返回累计计数的快照
+func (e *SideEffectExecutor) Stats() SideEffectStats {
+	return SideEffectStats{
+		Submitted: e.submitted.Load(),
+		Dropped:   e.dropped.Load(),
+		Succeeded: e.succeeded.Load(),
+		Failed:    e.failed.Load(),
+		Retried:   e.retried.Load(),
+		Panicked:  e.panicked.Load(),
+		Pending:   e.pending.Load(),
+	}
+}
+
+// worker 从队列中取任务执行，直到队列关闭且为空
+func (e *SideEffectExecutor) worker() {
+	defer e.wg.Done()
+	for task := range e.queue {
+		e.run(task)
+		e.pending.Add(-1)
+	}
+}
+
+// run 执行单个任务，失败时按指数退避重试
+func (e *SideEffectExecutor) run(task sideEffectTask) {
+	backoff := e.config.InitialBackoff
+	var err error
+	for attempt := 0; attempt <= e.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			e.retried.Add(1)
+			if !e.sleep(jitter(backoff)) {
+				break
+			}
+			backoff *= 2
+			if backoff > e.config.MaxBackoff {
+				backoff = e.config.MaxBackoff
+			}
+		}
+
+		err = e.attempt(task)
+		if err == nil {
+			e.succeeded.Add(1)
+			return
+		}
+		var pe *PanicError
+		if errors.As(err, &pe) {
+			e.panicked.Add(1)
+			log.Printf("request_id=%s stage=SideEffect component=%s %v\n%s",
+				task.requestID, task.name, pe, pe.Stack)
+		}
+	}
+
+	e.failed.Add(1)
+	log.Printf("request_id=%s stage=SideEffect component=%s failed after retries: %v",
+		task.requestID, task.name, err)
+}
+
+// attempt 执行一次 Side Effect
+// 使用独立于请求的 ctx，确保 side effect 不会因为主请求结束而被取消
+func (e *SideEffectExecutor) attempt(task sideEffectTask) error {
+	ctx, cancel := withBudget(e.abortCtx, e.config.AttemptTimeout)
+	defer cancel()
+	_, err := safeCall(func() (struct{}, error) {
+		return struct{}{}, task.run(ctx)
+	})
+	return err
+}
+
+// sleep 等待 d，执行器被中止时提前返回 false
+func (e *SideEffectExecutor) sleep(d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-e.abortCtx.Done():
+		return false
+	}
+}
+
+// jitter 在 [d/2, d) 范围内随机化等待时间，避免重试同时打到下游
+func jitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)))
+}
@@ -51,3 +51,13 @@ func StringOrEmpty(p *string) string {
 	}
 	return *p
 }
+
+// containsString 判断切片中是否包含指定字符串
+func containsString(xs []string, s string) bool {
+	for _, x := range xs {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,247 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Validate 在接收流量之前检查管道配置，返回包含全部问题的合并错误（errors.Join），没有问题时返回 nil
+//
+// 检查的内容：
+//   - 缺少 Source / Selector，或组件列表中存在 nil 组件
+//   - 同一列表中组件名重复（名称用于日志、指标、Deadlines.Component 和 MissingHydrations）
+//   - 字段依赖：Hydrator 的依赖存在环或多个 Hydrator 写同一字段；声明了不存在的字段；
+//     组件读取的字段只由在它之后执行的组件写入（例如 Scorer 排在写入其输入分数的 Scorer 之前）
+//   - 参数：ResultSize / PreRankSize 为负，PreRankSize 没有 PreRankers，
+//     ResultSize 小于 Selector 的 Size（多选出的候选会被直接截断），Deadlines 为负，
+//     Hedging 的参数越界或引用了不存在的 Source / Hydrator，实验配置不合法
+//
+// Build 会先调用 Validate，因此通常不需要单独调用。
+func (p *CandidatePipelineOf[Q, C]) Validate() error {
+	var errs []error
+	add := func(err error) { errs = append(errs, err) }
+
+	if len(p.Sources) == 0 {
+		add(errors.New("sources: at least one source is required"))
+	}
+	if isNil(p.Selector) {
+		add(errors.New("selector: required"))
+	}
+	if p.Merger != nil && isNil(p.Merger) {
+		add(errors.New("merger: nil component"))
+	}
+	ok := checkComponents(add, "query_hydrators", p.QueryHydrators)
+	ok = checkComponents(add, "sources", p.Sources) && ok
+	ok = checkComponents(add, "hydrators", p.Hydrators) && ok
+	ok = checkComponents(add, "filters", p.Filters) && ok
+	ok = checkComponents(add, "pre_rankers", p.PreRankers) && ok
+	ok = checkComponents(add, "scorers", p.Scorers) && ok
+	ok = checkComponents(add, "post_selection_hydrators", p.PostSelectionHydrators) && ok
+	ok = checkComponents(add, "post_selection_filters", p.PostSelectionFilters) && ok
+	ok = checkComponents(add, "side_effects", p.SideEffects) && ok
+
+	if p.ResultSize < 0 {
+		add(fmt.Errorf("result_size: must be >= 0, got %d", p.ResultSize))
+	}
+	if p.PreRankSize < 0 {
+		add(fmt.Errorf("pre_rank_size: must be >= 0, got %d", p.PreRankSize))
+	}
+	if p.PreRankSize > 0 && len(p.PreRankers) == 0 {
+		add(errors.New("pre_rank_size: requires at least one pre_ranker"))
+	}
+	if p.ResultSize > 0 && !isNil(p.Selector) {
+		if size := p.Selector.Size(); size != nil && *size > p.ResultSize {
+			add(fmt.Errorf("result_size: %d is smaller than selector %s size %d, selected candidates would be truncated",
+				p.ResultSize, p.Selector.Name(), *size))
+		}
+	}
+	for _, err := range p.Deadlines.validate() {
+		add(err)
+	}
+	if ok {
+		names := make(map[string]bool, len(p.Sources)+len(p.Hydrators))
+		for _, s := range p.Sources {
+			names[s.Name()] = true
+		}
+		for _, h := range p.Hydrators {
+			names[h.Name()] = true
+		}
+		for _, err := range p.Hedging.validate(names) {
+			add(err)
+		}
+	}
+	if err := validateExperiments(p.Experiments); err != nil {
+		add(fmt.Errorf("experiments: %w", err))
+	}
+
+	// 存在 nil 组件时无法读取字段声明
+	if ok {
+		if _, err := queryHydratorLayers("QueryHydrator", p.QueryHydrators); err != nil {
+			add(err)
+		}
+		if _, err := hydratorLayers("Hydrator", p.Hydrators); err != nil {
+			add(err)
+		}
+		if _, err := hydratorLayers("PostSelectionHydrator", p.PostSelectionHydrators); err != nil {
+			add(err)
+		}
+		errs = append(errs, p.fieldOrderErrors()...)
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkComponents 检查组件列表中的 nil 组件和重复的组件名，列表中存在 nil 组件时返回 false
+func checkComponents[T interface{ Name() string }](add func(error), path string, components []T) bool {
+	ok := true
+	seen := make(map[string]int, len(components))
+	for i, c := range components {
+		if isNil(c) {
+			add(fmt.Errorf("%s[%d]: nil component", path, i))
+			ok = false
+			continue
+		}
+		name := c.Name()
+		if prev, dup := seen[name]; dup {
+			add(fmt.Errorf("%s[%d]: duplicate component %s (also at %s[%d])", path, i, name, path, prev))
+			continue
+		}
+		seen[name] = i
+	}
+	return ok
+}
+
+// isNil 判断组件是否为 nil，包括装在接口中的 nil 指针
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// validate 返回为负的预算和超时
+func (d Deadlines) validate() []error {
+	var errs []error
+	check := func(name string, t time.Duration) {
+		if t < 0 {
+			errs = append(errs, fmt.Errorf("deadlines: %s must be >= 0, got %s", name, t))
+		}
+	}
+	check("query_hydration", d.QueryHydration)
+	check("sourcing", d.Sourcing)
+	check("hydration", d.Hydration)
+	check("pre_ranking", d.PreRanking)
+	check("scoring", d.Scoring)
+	check("post_selection_hydration", d.PostSelectionHydration)
+	check("default_component", d.DefaultComponent)
+	names := make([]string, 0, len(d.Component))
+	for name := range d.Component {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		check("component."+name, d.Component[name])
+	}
+	return errs
+}
+
+// fieldStep 是候选字段读写顺序中的一步
+// 同一个 Hydrator 阶段的组件属于同一步（由依赖分层保证层内顺序），顺序执行的组件各占一步
+type fieldStep struct {
+	step   int
+	path   string
+	name   string
+	reads  []string
+	writes []string
+
+	// layered 表示组件属于 Hydrator 阶段，未知字段已经由依赖分层检查过
+	layered bool
+}
+
+// fieldOrderErrors 检查候选字段的读写顺序：组件读取的字段如果只由在它之后执行的组件写入，
+// 执行时读到的总是零值，返回对应的错误。没有任何组件写入的字段视为由 Source 提供。
+func (p *CandidatePipelineOf[Q, C]) fieldOrderErrors() []error {
+	var steps []fieldStep
+	step := 0
+	addSteps := func(path string, components []any, sameStep bool) {
+		for i, c := range components {
+			d, ok := c.(FieldDependencies)
+			if !ok {
+				continue
+			}
+			s := fieldStep{step: step, path: fmt.Sprintf("%s[%d]", path, i), name: c.(interface{ Name() string }).Name(),
+				reads: d.ReadFields(), writes: d.WriteFields(), layered: sameStep}
+			if !sameStep {
+				s.step = step + i
+			}
+			steps = append(steps, s)
+		}
+		if sameStep {
+			step++
+		} else {
+			step += len(components)
+		}
+	}
+	addSteps("hydrators", toAny(p.Hydrators), true)
+	addSteps("filters", toAny(p.Filters), false)
+	addSteps("pre_rankers", toAny(p.PreRankers), false)
+	addSteps("scorers", toAny(p.Scorers), false)
+	addSteps("post_selection_hydrators", toAny(p.PostSelectionHydrators), true)
+	addSteps("post_selection_filters", toAny(p.PostSelectionFilters), false)
+
+	fields := structType[C]()
+	var errs []error
+	writers := make(map[string][]fieldStep)
+	for _, s := range steps {
+		for _, f := range s.writes {
+			if _, ok := fields.FieldByName(f); !ok {
+				if !s.layered {
+					errs = append(errs, fmt.Errorf("%s (%s): writes unknown field %s.%s", s.path, s.name, fields.Name(), f))
+				}
+				continue
+			}
+			writers[f] = append(writers[f], s)
+		}
+	}
+	for _, s := range steps {
+		for _, f := range s.reads {
+			if _, ok := fields.FieldByName(f); !ok {
+				if !s.layered {
+					errs = append(errs, fmt.Errorf("%s (%s): reads unknown field %s.%s", s.path, s.name, fields.Name(), f))
+				}
+				continue
+			}
+			var before bool
+			var later *fieldStep
+			for i, w := range writers[f] {
+				switch {
+				case w.step < s.step || (w.step == s.step && w.path != s.path):
+					before = true
+				case w.step > s.step && later == nil:
+					later = &writers[f][i]
+				}
+			}
+			if !before && later != nil {
+				errs = append(errs, fmt.Errorf("%s (%s): reads %s.%s before it is written by %s (%s)",
+					s.path, s.name, fields.Name(), f, later.path, later.name))
+			}
+		}
+	}
+	return errs
+}
+
+func toAny[T any](components []T) []any {
+	out := make([]any, len(components))
+	for i, c := range components {
+		out[i] = c
+	}
+	return out
+}
@@ -0,0 +1,214 @@
+// calibrate 根据记录的 Phoenix 预测和互动标签拟合按动作的校准器，输出 home-mixer 的校准文件（-calibration）
+//
+// 输入是 JSON Lines，每行是一次曝光的预测和用户实际发生的动作（动作名见 home.PhoenixActions）：
+//
+//	{"predictions": {"favorite": 0.12, "report": 0.0004}, "engagements": ["favorite"]}
+//
+// 没有出现在 engagements 中的动作视为没有发生；其他字段被忽略。
+// 每个动作按 -holdout 留出一部分样本，只在其余样本上拟合，并在留出的样本上比较校准前后的 log loss 和 ECE；
+// 留出样本上 log loss 没有下降的校准器不写入校准文件。样本或正样本不足的动作跳过（不校准）。
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
+	"x-algorithm-go/home-mixer/internal/calibration"
+)
+
+var (
+	in           = flag.String("in", "", "记录的预测和互动标签（JSON Lines），- 表示标准输入")
+	out          = flag.String("out", "", "输出的校准文件（.yaml/.yml/.json）")
+	version      = flag.String("version", "", "校准文件的版本")
+	method       = flag.String("method", calibration.TypeIsotonic, "校准方法：isotonic 或 platt")
+	actions      = flag.String("actions", "", "要校准的动作（逗号分隔），为空时校准输入中出现的全部概率动作")
+	bins         = flag.Int("bins", 50, "isotonic 校准表的最多点数（拟合前按预测分为等样本数的组）")
+	minPositives = flag.Int("min_positives", 50, "拟合一个动作至少需要的正样本数（只计拟合样本）")
+	holdout      = flag.Float64("holdout", 0.2, "留出用于评估的样本比例 [0, 1)，0 表示在拟合样本上评估")
+	evalBins     = flag.Int("eval_bins", 10, "计算 ECE 的分组数")
+)
+
+// record 是输入中的一行
+type record struct {
+	Predictions map[string]float64 `json:"predictions"`
+	Engagements []string           `json:"engagements"`
+}
+
+// dataset 是一个动作的拟合样本和评估样本
+type dataset struct {
+	train []calibration.Sample
+	eval  []calibration.Sample
+}
+
+func main() {
+	flag.Parse()
+	if *in == "" || *out == "" || *version == "" {
+		fmt.Fprintln(os.Stderr, "usage: calibrate -in predictions.jsonl -out calibration.yaml -version <version> [-method isotonic|platt]")
+		os.Exit(2)
+	}
+	if *method != calibration.TypeIsotonic && *method != calibration.TypePlatt {
+		fatalf("unknown method %q (want %s or %s)", *method, calibration.TypeIsotonic, calibration.TypePlatt)
+	}
+	if *holdout < 0 || *holdout >= 1 {
+		fatalf("holdout must be in [0, 1), got %v", *holdout)
+	}
+	wanted := make(map[string]bool)
+	for _, action := range strings.Split(*actions, ",") {
+		if action = strings.TrimSpace(action); action == "" {
+			continue
+		}
+		if !calibration.Calibratable(action) {
+			fatalf("%s is not a calibratable action", action)
+		}
+		wanted[action] = true
+	}
+
+	input := os.Stdin
+	if *in != "-" {
+		f, err := os.Open(*in)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		defer f.Close()
+		input = f
+	}
+	data, lines, err := readDatasets(input, wanted)
+	if err != nil {
+		fatalf("read %s: %v", *in, err)
+	}
+	fmt.Printf("records=%d method=%s holdout=%v\n", lines, *method, *holdout)
+
+	calibrators := make(map[string]calibration.Calibrator)
+	for _, action := range home.PhoenixActions {
+		d, ok := data[action]
+		if !ok {
+			continue
+		}
+		c, summary := fit(d)
+		fmt.Printf("action=%s %s\n", action, summary)
+		if c != nil {
+			calibrators[action] = c
+		}
+	}
+	if len(calibrators) == 0 {
+		fatalf("no action could be calibrated")
+	}
+
+	set, err := calibration.NewSet(*version, calibrators)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	encoded, err := calibration.Encode(set, strings.TrimPrefix(strings.ToLower(filepath.Ext(*out)), "."))
+	if err != nil {
+		fatalf("encode %s: %v", *out, err)
+	}
+	if err := os.WriteFile(*out, encoded, 0o644); err != nil {
+		fatalf("%v", err)
+	}
+	fmt.Printf("wrote %s version=%s actions=%d\n", *out, *version, len(calibrators))
+}
+
+// readDatasets 读取输入，按动作拆分样本；第 i 条记录按 i 决定进入拟合还是评估样本，结果可以复现
+func readDatasets(r io.Reader, wanted map[string]bool) (map[string]*dataset, int, error) {
+	every := 0 // 每 every 条记录留出一条用于评估，0 表示不留出
+	if *holdout > 0 {
+		every = int(1/(*holdout) + 0.5)
+	}
+	data := make(map[string]*dataset)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 1<<20), 1<<20)
+	lines := 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var rec record
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			return nil, 0, fmt.Errorf("line %d: %w", lines+1, err)
+		}
+		engaged := make(map[string]bool, len(rec.Engagements))
+		for _, action := range rec.Engagements {
+			engaged[action] = true
+		}
+		for action, p := range rec.Predictions {
+			if !calibration.Calibratable(action) || (len(wanted) > 0 && !wanted[action]) {
+				continue
+			}
+			if p < 0 || p > 1 {
+				return nil, 0, fmt.Errorf("line %d: %s prediction must be in [0, 1], got %v", lines+1, action, p)
+			}
+			d := data[action]
+			if d == nil {
+				d = &dataset{}
+				data[action] = d
+			}
+			s := calibration.Sample{Prediction: p, Label: engaged[action]}
+			if every > 0 && lines%every == every-1 {
+				d.eval = append(d.eval, s)
+			} else {
+				d.train = append(d.train, s)
+			}
+		}
+		lines++
+	}
+	return data, lines, scanner.Err()
+}
+
+// fit 拟合一个动作的校准器，返回校准器（不校准时为 nil）和结果摘要
+func fit(d *dataset) (calibration.Calibrator, string) {
+	positives := 0
+	for _, s := range d.train {
+		if s.Label {
+			positives++
+		}
+	}
+	if positives < *minPositives {
+		return nil, fmt.Sprintf("skipped: samples=%d positives=%d < min_positives=%d", len(d.train), positives, *minPositives)
+	}
+
+	var c calibration.Calibrator
+	var err error
+	var shape string
+	switch *method {
+	case calibration.TypeIsotonic:
+		var iso *calibration.Isotonic
+		if iso, err = calibration.FitIsotonic(d.train, *bins); err == nil {
+			c, shape = iso, fmt.Sprintf("points=%d", len(iso.X))
+		}
+	case calibration.TypePlatt:
+		var platt *calibration.Platt
+		if platt, err = calibration.FitPlatt(d.train); err == nil {
+			c, shape = platt, fmt.Sprintf("a=%.4f b=%.4f", platt.A, platt.B)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Sprintf("skipped: %v", err)
+	}
+
+	eval := d.eval
+	if len(eval) == 0 {
+		eval = d.train
+	}
+	before := calibration.Evaluate(eval, nil, *evalBins)
+	after := calibration.Evaluate(eval, c, *evalBins)
+	summary := fmt.Sprintf("samples=%d positives=%d %s eval_samples=%d rate=%.6f mean_prediction=%.6f->%.6f log_loss=%.6f->%.6f ece=%.6f->%.6f",
+		len(d.train), positives, shape, after.Samples, after.PositiveRate,
+		before.MeanPrediction, after.MeanPrediction, before.LogLoss, after.LogLoss, before.ECE, after.ECE)
+	if after.LogLoss >= before.LogLoss {
+		return nil, "skipped: log loss did not improve: " + summary
+	}
+	return c, summary
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "calibrate: "+format+"\n", args...)
+	os.Exit(1)
+}
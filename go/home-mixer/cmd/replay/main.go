@@ -0,0 +1,180 @@
+// replay 离线回放录制的请求（见 home-mixer 的 -record_dir）
+//
+// 只给出 -definition 时，用该管道定义回放每个请求并与录制时的线上结果逐位比较，用于复现排序问题；
+// 同时给出 -compare 时，用两个管道定义回放同一批请求并比较它们的输出，用于评估管道改动。
+// 存在差异时以状态码 1 退出。
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
+	"x-algorithm-go/home-mixer/internal/calibration"
+	"x-algorithm-go/home-mixer/internal/mixer"
+	"x-algorithm-go/home-mixer/internal/replay"
+	"x-algorithm-go/home-mixer/internal/scorers"
+)
+
+var (
+	in         = flag.String("in", "", "回放文件或包含回放文件（*.json）的目录")
+	definition = flag.String("definition", "", "管道定义文件，为空时使用内置默认定义")
+	compare    = flag.String("compare", "", "用于对比的第二个管道定义文件，为空时与录制的线上结果比较")
+	weights    = flag.String("action_weights", "", "动作权重文件，为空时使用内置默认权重；与录制时的权重版本不同会报告差异")
+	calibrate  = flag.String("calibration", "", "分数校准文件，应与录制时服务使用的校准文件相同，为空时不校准")
+	verbose    = flag.Bool("verbose", false, "打印每个请求的输出")
+	pipeLogs   = flag.Bool("pipeline_logs", false, "打印管道日志")
+)
+
+func main() {
+	flag.Parse()
+	if *in == "" {
+		fmt.Fprintln(os.Stderr, "usage: replay -in <file|dir> [-definition a.yaml] [-compare b.yaml]")
+		os.Exit(2)
+	}
+	if !*pipeLogs {
+		log.SetOutput(io.Discard)
+	}
+
+	files, err := replayFiles(*in)
+	if err != nil {
+		fatalf("read %s: %v", *in, err)
+	}
+	store := scorers.NewWeightsStore(nil)
+	if *weights != "" {
+		loaded, err := mixer.LoadActionWeights(*weights)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		store = scorers.NewWeightsStore(loaded)
+	}
+	var calibrators *calibration.Set
+	if *calibrate != "" {
+		if calibrators, err = calibration.Load(*calibrate); err != nil {
+			fatalf("%v", err)
+		}
+	}
+	base, err := newReplayPipeline(*definition, store, calibrators)
+	if err != nil {
+		fatalf("build pipeline %q: %v", *definition, err)
+	}
+	var other *home.CandidatePipeline
+	if *compare != "" {
+		if other, err = newReplayPipeline(*compare, store, calibrators); err != nil {
+			fatalf("build pipeline %q: %v", *compare, err)
+		}
+	}
+
+	differing := 0
+	for _, path := range files {
+		rec, err := replay.Load(path)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		want := rec.Result
+		got := run(base, rec)
+		if other != nil {
+			want, got = got, run(other, rec)
+		}
+		if want == nil {
+			fmt.Printf("file=%s request_id=%s status=no_result posts=%d\n", path, rec.Query.RequestID, len(got.Posts))
+			printPosts(got)
+			continue
+		}
+		diffs := replay.Diff(want, got)
+		status := "same"
+		if len(diffs) > 0 {
+			status = "diff"
+			differing++
+		}
+		fmt.Printf("file=%s request_id=%s status=%s posts=%d/%d\n", path, rec.Query.RequestID, status, len(want.Posts), len(got.Posts))
+		for _, d := range diffs {
+			fmt.Printf("  %s\n", d)
+		}
+		printPosts(got)
+	}
+
+	fmt.Printf("replayed=%d differing=%d\n", len(files), differing)
+	if differing > 0 {
+		os.Exit(1)
+	}
+}
+
+// newReplayPipeline 用回放客户端编译管道，其余配置与 home-mixer 服务一致
+func newReplayPipeline(definitionPath string, weights *scorers.WeightsStore, calibrators *calibration.Set) (*home.CandidatePipeline, error) {
+	config := &mixer.PipelineConfig{
+		ThunderMaxResults: 500,
+		PhoenixMaxResults: 500,
+		TopK:              50,
+		MaxAge:            7 * 24 * time.Hour,
+		Deadlines:         mixer.DefaultDeadlines(),
+		Weights:           weights,
+		Calibration:       calibrators,
+	}
+	if definitionPath != "" {
+		definition, err := mixer.LoadPipelineDefinition(definitionPath)
+		if err != nil {
+			return nil, err
+		}
+		config.Definition = definition
+	}
+	mixer.ReplayClients(config)
+	p, err := mixer.NewPhoenixCandidatePipeline(config)
+	if err != nil {
+		return nil, err
+	}
+	return p.Pipeline, nil
+}
+
+// run 用录制的请求执行一次管道，每次执行使用请求的独立拷贝
+func run(p *home.CandidatePipeline, rec *replay.Recording) *replay.Result {
+	ctx := replay.WithRecording(context.Background(), rec)
+	result, err := p.Execute(ctx, rec.Query.Clone())
+	return replay.NewResult(result, err)
+}
+
+// replayFiles 返回 path 本身，或目录下按文件名排序的全部 *.json
+func replayFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			files = append(files, filepath.Join(path, e.Name()))
+		}
+	}
+	return files, nil
+}
+
+func printPosts(r *replay.Result) {
+	if !*verbose {
+		return
+	}
+	if r.Error != "" {
+		fmt.Printf("  error: %s\n", r.Error)
+	}
+	for i := range r.Posts {
+		fmt.Printf("  %d. %s\n", i, &r.Posts[i])
+	}
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "replay: "+format+"\n", args...)
+	os.Exit(1)
+}
@@ -4,29 +4,40 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/home-mixer/internal/calibration"
 	"x-algorithm-go/home-mixer/internal/clients"
 	"x-algorithm-go/home-mixer/internal/mixer"
+	"x-algorithm-go/home-mixer/internal/replay"
+	"x-algorithm-go/home-mixer/internal/scorers"
+	"x-algorithm-go/home-mixer/internal/telemetry"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 
-	// 这里假设 proto 生成的代码在 proto 包中
-	// 实际使用时需要先运行 protoc 生成代码
 	pb "x-algorithm-go/proto"
+	"x-algorithm-go/proto/gateway"
 )
 
 var (
 	grpcPort     = flag.Int("grpc_port", 50051, "gRPC 服务器端口")
-	metricsPort  = flag.Int("metrics_port", 9090, "HTTP 指标服务器端口")
+	metricsPort  = flag.Int("metrics_port", 9090, "HTTP 服务器端口（健康检查、指标和 HTTP/JSON 接口）")
 	
-	// 服务地址
+	// 服务地址：逗号分隔的多个地址在后端之间负载均衡，也可以使用 dns:///host:port 由 DNS 解析
 	thunderAddr         = flag.String("thunder_addr", "localhost:50052", "Thunder 服务地址")
 	phoenixRetrievalAddr = flag.String("phoenix_retrieval_addr", "localhost:50053", "Phoenix 检索服务地址")
 	phoenixRankingAddr   = flag.String("phoenix_ranking_addr", "localhost:50054", "Phoenix 排序服务地址")
@@ -35,6 +46,74 @@ var (
 	stratoAddr          = flag.String("strato_addr", "localhost:50057", "Strato 服务地址")
 	uasAddr             = flag.String("uas_addr", "localhost:50058", "UAS 服务地址")
 	vfAddr              = flag.String("vf_addr", "localhost:50059", "VF 服务地址")
+
+	// 下游连接（所有下游服务共用）
+	downstreamLoadBalancing  = flag.String("downstream_load_balancing", clients.LoadBalancingRoundRobin, "多个后端之间的负载均衡策略（round_robin / pick_first）")
+	downstreamCallTimeout    = flag.Duration("downstream_call_timeout", time.Second, "单次下游调用（包括重试）的超时，实际超时不超过所在阶段的剩余预算")
+	downstreamMaxAttempts    = flag.Int("downstream_max_attempts", 3, "幂等下游调用的最多尝试次数，1 表示不重试")
+	downstreamInitialBackoff = flag.Duration("downstream_initial_backoff", 10*time.Millisecond, "第一次重试前的退避（带抖动的指数退避）")
+	downstreamMaxBackoff     = flag.Duration("downstream_max_backoff", 100*time.Millisecond, "重试退避上限")
+	downstreamTLS            = flag.Bool("downstream_tls", false, "使用 TLS 连接下游服务")
+	downstreamTLSCAFile      = flag.String("downstream_tls_ca_file", "", "校验下游服务证书的 CA 文件，为空时使用系统 CA")
+	downstreamTLSCertFile    = flag.String("downstream_tls_cert_file", "", "客户端证书文件（mTLS）")
+	downstreamTLSKeyFile     = flag.String("downstream_tls_key_file", "", "客户端私钥文件（mTLS）")
+	downstreamBreaker        = flag.Bool("downstream_breaker", true, "为每个下游服务启用熔断器，失败率或慢调用比例过高时直接跳过调用")
+	breakerErrorRate         = flag.Float64("downstream_breaker_error_rate", 0.5, "熔断器打开的失败比例阈值 (0, 1]")
+	breakerSlowCall          = flag.Duration("downstream_breaker_slow_call", 500*time.Millisecond, "熔断器的慢调用阈值，0 表示不按延迟熔断")
+	breakerSlowCallRate      = flag.Float64("downstream_breaker_slow_call_rate", 0.5, "熔断器打开的慢调用比例阈值 (0, 1]")
+	breakerOpenDuration      = flag.Duration("downstream_breaker_open_duration", 5*time.Second, "熔断器打开后多久放行探测调用")
+
+	// 对冲请求（Source / Hydrator 阶段）
+	hedgeComponents = flag.String("hedge_components", "", "启用对冲请求的 Source / Hydrator（逗号分隔的组件名），为空时不对冲；管道定义中的 hedging 优先")
+	hedgePercentile = flag.Float64("hedge_percentile", 0.95, "对冲延迟使用的组件耗时分位数")
+	hedgeMinDelay   = flag.Duration("hedge_min_delay", 10*time.Millisecond, "对冲延迟的下限")
+	hedgeMaxRatio   = flag.Float64("hedge_max_ratio", 0.1, "对冲请求占调用数的上限")
+
+	// Side Effect 执行器
+	sideEffectQueueSize = flag.Int("side_effect_queue_size", 1024, "Side Effect 队列容量，队列满时丢弃新任务")
+	sideEffectWorkers   = flag.Int("side_effect_workers", 8, "Side Effect 工作 goroutine 数量")
+	sideEffectRetries   = flag.Int("side_effect_max_retries", 2, "Side Effect 失败后的最大重试次数")
+	sideEffectDrain     = flag.Duration("side_effect_drain_timeout", 10*time.Second, "shutdown_timeout 中为执行完排队 Side Effects 保留的时间，必须小于 shutdown_timeout；服务器提前停止时 Drain 使用剩余的全部时间")
+
+	// 优雅关闭
+	shutdownTimeout = flag.Duration("shutdown_timeout", 30*time.Second, "关闭的总预算：等待 gRPC 和 HTTP 在途请求结束并执行完排队的 Side Effects，超时后强制停止")
+
+	// 管道定义
+	pipelineDefinition = flag.String("pipeline_definition", "", "管道定义文件（.yaml/.yml/.json），为空时使用内置默认定义")
+
+	// 动作权重
+	actionWeights               = flag.String("action_weights", "", "WeightedScorer 的动作权重文件（.yaml/.yml/.json，带版本），为空时使用内置默认权重")
+	actionWeightsReloadInterval = flag.Duration("action_weights_reload_interval", 10*time.Second, "检查权重文件变化的间隔，0 表示不热加载")
+
+	// 分数校准（用 cmd/calibrate 拟合）
+	calibrationFile = flag.String("calibration", "", "CalibrationScorer 的按动作校准文件（.yaml/.yml/.json），为空时不校准")
+
+	// 追踪
+	traceExporter    = flag.String("trace_exporter", "none", "追踪导出方式：none 或 stdout")
+	traceSampleRatio = flag.Float64("trace_sample_ratio", 0.01, "追踪采样比例（0-1）")
+
+	// 请求录制（用 cmd/replay 离线回放）
+	recordDir         = flag.String("record_dir", "", "回放文件目录，为空时不录制")
+	recordSampleRatio = flag.Float64("record_sample_ratio", 0.001, "录制采样比例（0-1）")
+
+	// 影子管道
+	shadowPipelineDefinition = flag.String("shadow_pipeline_definition", "", "影子管道定义文件，为空时不执行影子管道")
+	shadowSampleRatio        = flag.Float64("shadow_sample_ratio", 0.01, "执行影子管道的请求比例（0-1）")
+	shadowTimeout            = flag.Duration("shadow_timeout", time.Second, "单次影子执行的超时")
+	shadowMaxInFlight        = flag.Int("shadow_max_in_flight", 16, "同时执行的影子请求上限")
+
+	// 分页会话缓存
+	sessionCacheTTL         = flag.Duration("session_cache_ttl", 0, "会话第一页排序结果的有效期，0 表示不缓存（分页请求总是执行完整管道）")
+	sessionCacheMaxSessions = flag.Int("session_cache_max_sessions", 100000, "缓存的会话数上限")
+
+	// 准入控制
+	maxConcurrentRequests = flag.Int("max_concurrent_requests", 256, "同时执行的管道上限，超过时返回 RESOURCE_EXHAUSTED，0 表示不限制")
+	bottomRequestShare    = flag.Float64("bottom_request_share", 0.8, "分页请求最多占用的并发比例（0-1]")
+	overloadRetryAfter    = flag.Duration("overload_retry_after", 200*time.Millisecond, "过载时建议客户端等待的时间（分页请求加倍）")
+	coalesceRequests      = flag.Bool("coalesce_requests", true, "合并同一用户完全相同的在途请求")
+
+	// 调试接口
+	debugTokens = flag.String("debug_tokens", "", "允许调用 GetScoredPostsDebug 的 token（name=token，逗号分隔），为空时关闭调试接口")
 )
 
 func main() {
@@ -42,32 +121,53 @@ func main() {
 
 	log.Printf("启动 Home Mixer 服务器，gRPC 端口: %d，指标端口: %d", *grpcPort, *metricsPort)
 
-	// 1) 初始化客户端
-	thunderClient, err := clients.NewThunderClient(*thunderAddr)
+	metricsRegistry := prometheus.NewRegistry()
+	metricsRegistry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	breakerObserver, err := telemetry.NewPrometheusBreakerObserver(metricsRegistry)
+	if err != nil {
+		log.Fatalf("注册熔断器指标失败: %v", err)
+	}
+
+	// 1) 初始化客户端（超时、重试、熔断、负载均衡和 TLS 由 downstream_* 参数统一配置）
+	downstreamConfig := func(service, addresses string) clients.ConnConfig {
+		config := newDownstreamConfig(service, addresses)
+		config.BreakerObserver = breakerObserver
+		return config
+	}
+	thunderClient, err := clients.NewThunderClient(downstreamConfig("thunder", *thunderAddr))
 	if err != nil {
 		log.Fatalf("创建 Thunder 客户端失败: %v", err)
 	}
 	defer thunderClient.(*clients.ThunderClientImpl).Close()
 
-	phoenixRetrievalClient, err := clients.NewPhoenixRetrievalClient(*phoenixRetrievalAddr)
+	phoenixRetrievalClient, err := clients.NewPhoenixRetrievalClient(downstreamConfig("phoenix_retrieval", *phoenixRetrievalAddr))
 	if err != nil {
 		log.Printf("警告: 创建 Phoenix 检索客户端失败: %v", err)
 		phoenixRetrievalClient = nil
 	}
 	if phoenixRetrievalClient != nil {
-		defer phoenixRetrievalClient.(*clients.PhoenixRetrievalClientImpl).Close()
+		defer phoenixRetrievalClient.(io.Closer).Close()
 	}
 
-	tesClient, err := clients.NewTESClient(*tesAddr)
+	phoenixRankingClient, err := clients.NewPhoenixRankingClient(downstreamConfig("phoenix_ranking", *phoenixRankingAddr))
+	if err != nil {
+		log.Printf("警告: 创建 Phoenix 排序客户端失败: %v", err)
+		phoenixRankingClient = nil
+	}
+	if phoenixRankingClient != nil {
+		defer phoenixRankingClient.(io.Closer).Close()
+	}
+
+	tesClient, err := clients.NewTESClient(downstreamConfig("tes", *tesAddr))
 	if err != nil {
 		log.Printf("警告: 创建 TES 客户端失败: %v", err)
 		tesClient = nil
 	}
 	if tesClient != nil {
-		defer tesClient.(*clients.TESClientImpl).Close()
+		defer tesClient.(io.Closer).Close()
 	}
 
-	gizmoduckClient, err := clients.NewGizmoduckClient(*gizmoduckAddr)
+	gizmoduckClient, err := clients.NewGizmoduckClient(downstreamConfig("gizmoduck", *gizmoduckAddr))
 	if err != nil {
 		log.Printf("警告: 创建 Gizmoduck 客户端失败: %v", err)
 		gizmoduckClient = nil
@@ -76,7 +176,7 @@ func main() {
 		defer gizmoduckClient.(*clients.GizmoduckClientImpl).Close()
 	}
 
-	stratoClient, err := clients.NewStratoClient(*stratoAddr)
+	stratoClient, err := clients.NewStratoClient(downstreamConfig("strato", *stratoAddr))
 	if err != nil {
 		log.Printf("警告: 创建 Strato 客户端失败: %v", err)
 		stratoClient = nil
@@ -85,7 +185,7 @@ func main() {
 		defer stratoClient.(*clients.StratoClientImpl).Close()
 	}
 
-	uasFetcher, err := clients.NewUASFetcher(*uasAddr)
+	uasFetcher, err := clients.NewUASFetcher(downstreamConfig("uas", *uasAddr))
 	if err != nil {
 		log.Printf("警告: 创建 UAS 获取器失败: %v", err)
 		uasFetcher = nil
@@ -94,7 +194,7 @@ func main() {
 		defer uasFetcher.(*clients.UASFetcherImpl).Close()
 	}
 
-	vfClient, err := clients.NewVFClient(*vfAddr)
+	vfClient, err := clients.NewVFClient(downstreamConfig("vf", *vfAddr))
 	if err != nil {
 		log.Printf("警告: 创建 VF 客户端失败: %v", err)
 		vfClient = nil
@@ -103,7 +203,7 @@ func main() {
 		defer vfClient.(*clients.VFClientImpl).Close()
 	}
 
-	stratoClientForCache, err := clients.NewStratoClientForCache(*stratoAddr)
+	stratoClientForCache, err := clients.NewStratoClientForCache(downstreamConfig("strato", *stratoAddr))
 	if err != nil {
 		log.Printf("警告: 创建用于缓存的 Strato 客户端失败: %v", err)
 		stratoClientForCache = nil
@@ -112,10 +212,88 @@ func main() {
 		defer stratoClientForCache.(*clients.StratoClientForCacheImpl).Close()
 	}
 
-	// 2) 创建 Pipeline 配置
+	// 2) 创建 Side Effect 执行器（关闭时需要 Drain）
+	if *sideEffectDrain <= 0 || *sideEffectDrain >= *shutdownTimeout {
+		log.Fatalf("side_effect_drain_timeout 必须大于 0 且小于 shutdown_timeout，got %s / %s", *sideEffectDrain, *shutdownTimeout)
+	}
+	sideEffectConfig := pipeline.DefaultSideEffectExecutorConfig()
+	sideEffectConfig.QueueSize = *sideEffectQueueSize
+	sideEffectConfig.Workers = *sideEffectWorkers
+	sideEffectConfig.MaxRetries = *sideEffectRetries
+	sideEffectExecutor := pipeline.NewSideEffectExecutor(sideEffectConfig)
+
+	// 加载管道定义（为空时使用内置默认定义）
+	var definition *pipeline.PipelineDefinition
+	if *pipelineDefinition != "" {
+		definition, err = mixer.LoadPipelineDefinition(*pipelineDefinition)
+		if err != nil {
+			log.Fatalf("加载管道定义失败: %v", err)
+		}
+		log.Printf("使用管道定义: %s (%s)", *pipelineDefinition, definition.Name)
+	}
+
+	// 加载动作权重，文件变化时热加载（所有管道共用，版本随响应记录）
+	weightsObserver, err := telemetry.NewPrometheusWeightsObserver(metricsRegistry)
+	if err != nil {
+		log.Fatalf("注册权重指标失败: %v", err)
+	}
+	weights := scorers.NewWeightsStore(nil)
+	if *actionWeights != "" {
+		reloader, err := mixer.NewWeightsReloader(*actionWeights, weightsObserver)
+		if err != nil {
+			log.Fatalf("加载动作权重失败: %v", err)
+		}
+		weights = reloader.Store()
+		if *actionWeightsReloadInterval > 0 {
+			reloadCtx, stopReload := context.WithCancel(context.Background())
+			defer stopReload()
+			go reloader.Run(reloadCtx, *actionWeightsReloadInterval)
+		}
+	} else {
+		weightsObserver.WeightsActivated(scorers.DefaultWeightsVersion)
+	}
+	log.Printf("动作权重: version=%s", weights.Load().Version)
+
+	// 加载分数校准器（加权之前校准 Phoenix 的动作预测）
+	var calibrators *calibration.Set
+	if *calibrationFile != "" {
+		calibrators, err = calibration.Load(*calibrationFile)
+		if err != nil {
+			log.Fatalf("加载校准文件失败: %v", err)
+		}
+		log.Printf("分数校准: %s version=%s actions=%d", *calibrationFile, calibrators.Version, len(calibrators.Calibrators))
+	}
+
+	// 创建指标和追踪 Observer
+	if err := telemetry.RegisterSideEffectStats(metricsRegistry, sideEffectExecutor); err != nil {
+		log.Fatalf("注册 Side Effect 指标失败: %v", err)
+	}
+	prometheusObserver, err := telemetry.NewPrometheusObserver(metricsRegistry)
+	if err != nil {
+		log.Fatalf("注册管道指标失败: %v", err)
+	}
+	observers := pipeline.MultiObserver{prometheusObserver}
+
+	shutdownTracing := func(context.Context) error { return nil }
+	switch *traceExporter {
+	case "none":
+	case "stdout":
+		tracerProvider, err := telemetry.NewStdoutTracerProvider(os.Stdout, *traceSampleRatio)
+		if err != nil {
+			log.Fatalf("创建 TracerProvider 失败: %v", err)
+		}
+		otel.SetTracerProvider(tracerProvider)
+		shutdownTracing = tracerProvider.Shutdown
+		observers = append(observers, telemetry.NewTracingObserver(nil))
+	default:
+		log.Fatalf("未知的 trace_exporter: %s", *traceExporter)
+	}
+
+	// 创建 Pipeline 配置
 	pipelineConfig := &mixer.PipelineConfig{
 		ThunderClient:          thunderClient,
 		PhoenixRetrievalClient: phoenixRetrievalClient,
+		PhoenixRankingClient:   phoenixRankingClient,
 		TESClient:              tesClient,
 		GizmoduckClient:        gizmoduckClient,
 		VFClient:               vfClient,
@@ -126,10 +304,120 @@ func main() {
 		PhoenixMaxResults:      500,
 		TopK:                   50,
 		MaxAge:                 7 * 24 * time.Hour,
+		Deadlines:              mixer.DefaultDeadlines(),
+		Weights:                weights,
+		Calibration:            calibrators,
+		Hedging: pipeline.Hedging{
+			Components: splitList(*hedgeComponents),
+			Percentile: *hedgePercentile,
+			MinDelay:   *hedgeMinDelay,
+			MaxRatio:   *hedgeMaxRatio,
+		},
+		SideEffectExecutor:     sideEffectExecutor,
+		Definition:             definition,
+		Observer:               observers,
+	}
+
+	// 开启录制时，客户端换成录制客户端，被采样的请求会记下全部外部调用
+	var recorder *replay.Recorder
+	if *recordDir != "" {
+		recorder, err = replay.NewRecorder(*recordDir, *recordSampleRatio)
+		if err != nil {
+			log.Fatalf("创建请求录制失败: %v", err)
+		}
+		mixer.RecordClients(pipelineConfig)
+		log.Printf("录制请求到 %s，采样比例 %v", *recordDir, *recordSampleRatio)
 	}
 
 	// 3) 创建 Pipeline
-	candidatePipeline := mixer.NewPhoenixCandidatePipeline(pipelineConfig)
+	candidatePipeline, err := mixer.NewPhoenixCandidatePipeline(pipelineConfig)
+	if err != nil {
+		log.Fatalf("创建 Pipeline 失败: %v", err)
+	}
+
+	// 影子管道与主管道共用客户端，但不上报管道指标，避免与主管道的指标混在一起
+	var shadowRunner *mixer.ShadowRunner
+	if *shadowPipelineDefinition != "" {
+		shadowDefinition, err := mixer.LoadPipelineDefinition(*shadowPipelineDefinition)
+		if err != nil {
+			log.Fatalf("加载影子管道定义失败: %v", err)
+		}
+		shadowPipelineConfig := *pipelineConfig
+		shadowPipelineConfig.Definition = shadowDefinition
+		shadowPipelineConfig.Observer = nil
+		shadowPipeline, err := mixer.NewPhoenixCandidatePipeline(&shadowPipelineConfig)
+		if err != nil {
+			log.Fatalf("创建影子管道失败: %v", err)
+		}
+		shadowObserver, err := telemetry.NewPrometheusShadowObserver(metricsRegistry)
+		if err != nil {
+			log.Fatalf("注册影子管道指标失败: %v", err)
+		}
+		shadowConfig := mixer.DefaultShadowConfig()
+		shadowConfig.SampleRatio = *shadowSampleRatio
+		shadowConfig.Timeout = *shadowTimeout
+		shadowConfig.MaxInFlight = *shadowMaxInFlight
+		shadowConfig.TopK = pipelineConfig.TopK
+		shadowRunner, err = mixer.NewShadowRunner(shadowPipeline.Pipeline, shadowConfig, shadowObserver)
+		if err != nil {
+			log.Fatalf("创建影子执行失败: %v", err)
+		}
+		log.Printf("影子管道: %s (%s)，采样比例 %v", *shadowPipelineDefinition, shadowDefinition.Name, *shadowSampleRatio)
+	}
+
+	// 分页请求在第一页缓存的候选上重新过滤；剩余候选不足一页时执行完整管道
+	var sessionCache *mixer.SessionCache
+	if *sessionCacheTTL > 0 {
+		sessionObserver, err := telemetry.NewPrometheusSessionObserver(metricsRegistry, func() int { return sessionCache.Len() })
+		if err != nil {
+			log.Fatalf("注册会话缓存指标失败: %v", err)
+		}
+		sessionConfig := mixer.DefaultSessionCacheConfig()
+		sessionConfig.TTL = *sessionCacheTTL
+		sessionConfig.MaxSessions = *sessionCacheMaxSessions
+		sessionConfig.MinCandidates = pipelineConfig.TopK
+		sessionCache, err = mixer.NewSessionCache(sessionConfig, sessionObserver)
+		if err != nil {
+			log.Fatalf("创建会话缓存失败: %v", err)
+		}
+		log.Printf("会话缓存: ttl=%s max_sessions=%d", *sessionCacheTTL, *sessionCacheMaxSessions)
+	}
+
+	// 合并重复的在途请求，按优先级限制并发
+	var admission *mixer.Admission
+	admissionObserver, err := telemetry.NewPrometheusAdmissionObserver(metricsRegistry, func() int { return admission.InFlight() })
+	if err != nil {
+		log.Fatalf("注册准入控制指标失败: %v", err)
+	}
+	admissionConfig := mixer.DefaultAdmissionConfig()
+	admissionConfig.MaxConcurrent = *maxConcurrentRequests
+	admissionConfig.BottomRequestShare = *bottomRequestShare
+	admissionConfig.RetryAfter = *overloadRetryAfter
+	admissionConfig.Coalesce = *coalesceRequests
+	admission, err = mixer.NewAdmission(admissionConfig, admissionObserver)
+	if err != nil {
+		log.Fatalf("创建准入控制失败: %v", err)
+	}
+
+	// 降级排序（没有 Phoenix 预测）的响应和帖子数
+	rankingObserver, err := telemetry.NewPrometheusRankingObserver(metricsRegistry)
+	if err != nil {
+		log.Fatalf("注册排序指标失败: %v", err)
+	}
+
+	// 调试接口只对持有 token 的调用方开放
+	var debugAccess *mixer.DebugAccess
+	if *debugTokens != "" {
+		tokens, err := mixer.ParseDebugTokens(*debugTokens)
+		if err != nil {
+			log.Fatalf("解析 debug_tokens 失败: %v", err)
+		}
+		debugAccess, err = mixer.NewDebugAccess(tokens)
+		if err != nil {
+			log.Fatalf("创建调试接口访问控制失败: %v", err)
+		}
+		log.Printf("调试接口已开启: callers=%d", len(tokens))
+	}
 
 	// 4) 创建 gRPC 服务器
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", *grpcPort))
@@ -144,20 +432,27 @@ func main() {
 
 	// 6) 创建服务实现
 	homeMixerServer := mixer.NewHomeMixerServer(candidatePipeline.Pipeline)
+	homeMixerServer.SetRecorder(recorder)
+	homeMixerServer.SetShadow(shadowRunner)
+	homeMixerServer.SetSessionCache(sessionCache)
+	homeMixerServer.SetAdmission(admission)
+	homeMixerServer.SetDebugAccess(debugAccess)
+	homeMixerServer.SetRankingObserver(rankingObserver)
+	homeMixerServer.SetWeights(weights)
 
 	// 7) 注册服务
 	pb.RegisterScoredPostsServiceServer(grpcServer, homeMixerServer)
 
-	// 8) 启动 HTTP 服务器用于健康检查和指标
+	// 8) 启动 HTTP 服务器用于健康检查、指标和 HTTP/JSON 接口
 	httpMux := http.NewServeMux()
 	httpMux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	})
-	httpMux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("# Metrics endpoint - Prometheus integration pending\n"))
-	})
+	httpMux.Handle("/metrics", promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{}))
+	// GetScoredPosts 的 HTTP/JSON 接口，供无法直接使用 gRPC 的工具调用（约定见 gateway 包）
+	httpMux.Handle("/v1/scored_posts", gateway.Unary(homeMixerServer.GetScoredPosts))
+	httpMux.Handle("/v1/scored_posts_debug", gateway.Unary(homeMixerServer.GetScoredPostsDebug))
 
 	httpServer := &http.Server{
 		Addr:    fmt.Sprintf(":%d", *metricsPort),
@@ -180,39 +475,105 @@ func main() {
 	}()
 
 	// 10) 优雅关闭
-	waitForShutdown(grpcServer, httpServer)
+	waitForShutdown(grpcServer, httpServer, sideEffectExecutor, shutdownTracing)
+}
+
+// newDownstreamConfig 返回下游服务 service 的连接配置
+func newDownstreamConfig(service, addresses string) clients.ConnConfig {
+	config := clients.DefaultConnConfig(service, addresses)
+	config.LoadBalancing = *downstreamLoadBalancing
+	config.CallTimeout = *downstreamCallTimeout
+	config.Retry.MaxAttempts = *downstreamMaxAttempts
+	config.Retry.InitialBackoff = *downstreamInitialBackoff
+	config.Retry.MaxBackoff = *downstreamMaxBackoff
+	if *downstreamBreaker {
+		breaker := clients.DefaultBreakerConfig()
+		breaker.ErrorRate = *breakerErrorRate
+		breaker.SlowCallDuration = *breakerSlowCall
+		breaker.SlowCallRate = *breakerSlowCallRate
+		breaker.OpenDuration = *breakerOpenDuration
+		config.Breaker = &breaker
+	}
+	if *downstreamTLS {
+		config.TLS = &clients.TLSConfig{
+			CAFile:   *downstreamTLSCAFile,
+			CertFile: *downstreamTLSCertFile,
+			KeyFile:  *downstreamTLSKeyFile,
+		}
+	}
+	return config
+}
+
+// splitList 把逗号分隔的列表拆分为切片，忽略空白项
+func splitList(list string) []string {
+	var out []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
 }
 
 // waitForShutdown 等待关闭信号并优雅关闭服务器
-func waitForShutdown(grpcServer *grpc.Server, httpServer *http.Server) {
+// 先同时停止 gRPC 和 HTTP 服务器（处理完两者的在途请求，之后不会再有新的 Side Effect 提交），
+// 再 Drain 排队的 Side Effects，两者共用 shutdown_timeout 的总预算：服务器最多使用总预算减去
+// side_effect_drain_timeout，Drain 使用到总截止时间为止的剩余时间。最后导出缓冲的 span
+func waitForShutdown(grpcServer *grpc.Server, httpServer *http.Server, sideEffectExecutor *pipeline.SideEffectExecutor, shutdownTracing func(context.Context) error) {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	log.Println("正在关闭服务器...")
 
-	// 创建超时上下文
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancelShutdown()
+	stopServers(shutdownCtx, grpcServer, httpServer, *shutdownTimeout-*sideEffectDrain)
 
-	// 优雅关闭 HTTP 服务器
-	if err := httpServer.Shutdown(ctx); err != nil {
-		log.Printf("关闭 HTTP 服务器时出错: %v", err)
+	// gRPC 和 HTTP 的在途请求都已结束（或被强制中止），不会再有新的 Side Effect 提交
+	if err := sideEffectExecutor.Drain(shutdownCtx); err != nil {
+		log.Printf("Drain Side Effects 超时: %v", err)
+	} else {
+		log.Println("Side Effects 已全部执行完毕")
 	}
 
-	// 优雅关闭 gRPC 服务器
-	stopped := make(chan struct{})
+	tracingCtx, cancelTracing := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelTracing()
+	if err := shutdownTracing(tracingCtx); err != nil {
+		log.Printf("关闭追踪时出错: %v", err)
+	}
+}
+
+// stopServers 在 timeout 内（不超过 ctx 的截止时间）同时优雅关闭 gRPC 和 HTTP 服务器，超时后强制停止
+func stopServers(ctx context.Context, grpcServer *grpc.Server, httpServer *http.Server, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
 	go func() {
-		grpcServer.GracefulStop()
-		close(stopped)
+		defer wg.Done()
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+			log.Println("gRPC 服务器已优雅关闭")
+		case <-ctx.Done():
+			log.Println("gRPC 服务器关闭超时，强制停止")
+			grpcServer.Stop()
+		}
 	}()
-
-	// 等待关闭完成或超时
-	select {
-	case <-stopped:
-		log.Println("服务器已优雅关闭")
-	case <-ctx.Done():
-		log.Println("服务器关闭超时，强制停止")
-		grpcServer.Stop()
-	}
+	go func() {
+		defer wg.Done()
+		if err := httpServer.Shutdown(ctx); err != nil {
+			log.Printf("HTTP 服务器关闭超时，强制停止: %v", err)
+			httpServer.Close()
+			return
+		}
+		log.Println("HTTP 服务器已优雅关闭")
+	}()
+	wg.Wait()
 }
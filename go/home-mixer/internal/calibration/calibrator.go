@@ -0,0 +1,161 @@
+// Package calibration 校准 Phoenix 的动作预测分数
+//
+// Phoenix 各动作的预测头校准程度不同：稀有动作（report、share_via_dm 等）的预测概率与实际发生率
+// 的偏差往往比 favorite 大得多，直接加权会让这些动作的影响偏大或偏小。
+// 校准把每个动作的预测概率映射为与实际发生率一致的概率，再交给 WeightedScorer 加权。
+//
+// 支持两种校准器：保序回归表（Isotonic，分段线性插值）和 Platt 缩放（Platt，logit 上的线性变换）。
+// 校准器从校准文件加载（见 Load），由 cmd/calibrate 根据记录的预测和互动标签拟合（见 FitIsotonic / FitPlatt）。
+package calibration
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
+)
+
+// Calibrator 把一个动作的预测概率映射为校准后的概率，实现必须是并发安全的（只读）
+type Calibrator interface {
+	Calibrate(p float64) float64
+}
+
+// probabilityEpsilon 限制 logit 的输入，避免 0 和 1 处的无穷大
+const probabilityEpsilon = 1e-7
+
+// Isotonic 是保序回归得到的校准表
+// X 严格递增、Y 单调不减；X 之间按分段线性插值，超出范围时取端点的值
+type Isotonic struct {
+	X []float64
+	Y []float64
+}
+
+// Calibrate 实现 Calibrator
+func (c *Isotonic) Calibrate(p float64) float64 {
+	n := len(c.X)
+	if p <= c.X[0] {
+		return c.Y[0]
+	}
+	if p >= c.X[n-1] {
+		return c.Y[n-1]
+	}
+	i := sort.SearchFloat64s(c.X, p) // c.X[i-1] < p <= c.X[i]
+	t := (p - c.X[i-1]) / (c.X[i] - c.X[i-1])
+	return c.Y[i-1] + t*(c.Y[i]-c.Y[i-1])
+}
+
+func (c *Isotonic) validate() error {
+	if len(c.X) == 0 || len(c.X) != len(c.Y) {
+		return fmt.Errorf("isotonic: x and y must be non-empty and of equal length, got %d and %d", len(c.X), len(c.Y))
+	}
+	for i := range c.X {
+		if !inUnitInterval(c.X[i]) || !inUnitInterval(c.Y[i]) {
+			return fmt.Errorf("isotonic: x and y must be in [0, 1], got (%v, %v)", c.X[i], c.Y[i])
+		}
+		if i > 0 && c.X[i] <= c.X[i-1] {
+			return fmt.Errorf("isotonic: x must be strictly increasing, got %v after %v", c.X[i], c.X[i-1])
+		}
+		if i > 0 && c.Y[i] < c.Y[i-1] {
+			return fmt.Errorf("isotonic: y must be non-decreasing, got %v after %v", c.Y[i], c.Y[i-1])
+		}
+	}
+	return nil
+}
+
+// Platt 是 Platt 缩放：calibrated = sigmoid(A * logit(p) + B)
+// A = 1、B = 0 时不改变预测；A > 0 保证校准后的顺序不变
+type Platt struct {
+	A float64
+	B float64
+}
+
+// Calibrate 实现 Calibrator
+func (c *Platt) Calibrate(p float64) float64 {
+	return sigmoid(c.A*logit(p) + c.B)
+}
+
+func (c *Platt) validate() error {
+	if math.IsNaN(c.A) || math.IsInf(c.A, 0) || c.A <= 0 {
+		return fmt.Errorf("platt: a must be finite and > 0, got %v", c.A)
+	}
+	if math.IsNaN(c.B) || math.IsInf(c.B, 0) {
+		return fmt.Errorf("platt: b must be finite, got %v", c.B)
+	}
+	return nil
+}
+
+// Set 是一组带版本的按动作校准器，创建后不再修改
+type Set struct {
+	Version     string
+	Calibrators map[string]Calibrator // 动作名（home.PhoenixActions）-> 校准器
+}
+
+// NewSet 校验校准器并创建 Set
+// 动作必须是 home.PhoenixActions 中的概率动作（dwell_time 是连续值，不能校准）
+func NewSet(version string, calibrators map[string]Calibrator) (*Set, error) {
+	if version == "" {
+		return nil, fmt.Errorf("calibration: version must not be empty")
+	}
+	for _, action := range sortedActions(calibrators) {
+		if !Calibratable(action) {
+			return nil, fmt.Errorf("calibration %s: %s is not a calibratable action", version, action)
+		}
+		var err error
+		switch c := calibrators[action].(type) {
+		case *Isotonic:
+			err = c.validate()
+		case *Platt:
+			err = c.validate()
+		default:
+			err = fmt.Errorf("unsupported calibrator %T", c)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("calibration %s: %s: %w", version, action, err)
+		}
+	}
+	return &Set{Version: version, Calibrators: calibrators}, nil
+}
+
+// Calibratable 判断动作的预测是否可以校准（Phoenix 的概率动作）
+func Calibratable(action string) bool {
+	if action == "dwell_time" {
+		return false
+	}
+	var ps home.PhoenixScores
+	return ps.Field(action) != nil
+}
+
+// Apply 返回校准后的分数拷贝，没有校准器的动作和缺失的分数保持不变
+func (s *Set) Apply(ps *home.PhoenixScores) *home.PhoenixScores {
+	out := ps.Clone()
+	for action, c := range s.Calibrators {
+		if v := *out.Field(action); v != nil {
+			*v = c.Calibrate(*v)
+		}
+	}
+	return out
+}
+
+// sortedActions 返回按名称排序的动作，保证错误信息稳定
+func sortedActions(calibrators map[string]Calibrator) []string {
+	actions := make([]string, 0, len(calibrators))
+	for action := range calibrators {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+func inUnitInterval(v float64) bool {
+	return v >= 0 && v <= 1
+}
+
+func logit(p float64) float64 {
+	p = math.Min(math.Max(p, probabilityEpsilon), 1-probabilityEpsilon)
+	return math.Log(p / (1 - p))
+}
+
+func sigmoid(z float64) float64 {
+	return 1 / (1 + math.Exp(-z))
+}
@@ -0,0 +1,171 @@
+package calibration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File 是校准文件的格式，例如：
+//
+//	version: "2026-10-18.1"
+//	calibrators:
+//	  report:
+//	    type: platt
+//	    a: 0.8
+//	    b: -1.2
+//	  share_via_dm:
+//	    type: isotonic
+//	    x: [0.0, 0.01, 0.05, 0.2]
+//	    y: [0.0, 0.004, 0.03, 0.25]
+//
+// 没有出现在 calibrators 中的动作不校准。
+type File struct {
+	Version     string          `json:"version"`
+	Calibrators map[string]Spec `json:"calibrators"`
+}
+
+// Spec 是一个校准器在文件中的表示
+type Spec struct {
+	Type string `json:"type"` // isotonic 或 platt
+
+	// isotonic
+	X []float64 `json:"x,omitempty"`
+	Y []float64 `json:"y,omitempty"`
+
+	// platt
+	A *float64 `json:"a,omitempty"`
+	B *float64 `json:"b,omitempty"`
+}
+
+// 校准器类型
+const (
+	TypeIsotonic = "isotonic"
+	TypePlatt    = "platt"
+)
+
+// calibrator 把 Spec 转换为校准器
+func (s Spec) calibrator() (Calibrator, error) {
+	switch s.Type {
+	case TypeIsotonic:
+		if s.A != nil || s.B != nil {
+			return nil, fmt.Errorf("isotonic calibrator does not accept a / b")
+		}
+		return &Isotonic{X: s.X, Y: s.Y}, nil
+	case TypePlatt:
+		if s.X != nil || s.Y != nil {
+			return nil, fmt.Errorf("platt calibrator does not accept x / y")
+		}
+		if s.A == nil || s.B == nil {
+			return nil, fmt.Errorf("platt calibrator requires a and b")
+		}
+		return &Platt{A: *s.A, B: *s.B}, nil
+	default:
+		return nil, fmt.Errorf("unknown calibrator type %q (want %s or %s)", s.Type, TypeIsotonic, TypePlatt)
+	}
+}
+
+// SpecOf 返回校准器在文件中的表示
+func SpecOf(c Calibrator) (Spec, error) {
+	switch c := c.(type) {
+	case *Isotonic:
+		return Spec{Type: TypeIsotonic, X: c.X, Y: c.Y}, nil
+	case *Platt:
+		a, b := c.A, c.B
+		return Spec{Type: TypePlatt, A: &a, B: &b}, nil
+	default:
+		return Spec{}, fmt.Errorf("unsupported calibrator %T", c)
+	}
+}
+
+// Load 从文件加载校准器，按扩展名识别格式（.yaml / .yml / .json）
+func Load(path string) (*Set, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read calibration: %w", err)
+	}
+	set, err := Parse(data, formatOf(path))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return set, nil
+}
+
+// Parse 解析 YAML 或 JSON 格式的校准文件，未知字段报错
+func Parse(data []byte, format string) (*Set, error) {
+	switch format {
+	case "json":
+	case "yaml", "yml":
+		var doc any
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("parse calibration: %w", err)
+		}
+		jsonData, err := json.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("parse calibration: %w", err)
+		}
+		data = jsonData
+	default:
+		return nil, fmt.Errorf("unsupported calibration format %q (want yaml or json)", format)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var file File
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("parse calibration: %w", err)
+	}
+	calibrators := make(map[string]Calibrator, len(file.Calibrators))
+	for action, spec := range file.Calibrators {
+		c, err := spec.calibrator()
+		if err != nil {
+			return nil, fmt.Errorf("parse calibration: %s: %w", action, err)
+		}
+		calibrators[action] = c
+	}
+	return NewSet(file.Version, calibrators)
+}
+
+// Encode 把校准器编码为 YAML 或 JSON 格式的校准文件
+func Encode(s *Set, format string) ([]byte, error) {
+	file := File{Version: s.Version, Calibrators: make(map[string]Spec, len(s.Calibrators))}
+	for action, c := range s.Calibrators {
+		spec, err := SpecOf(c)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", action, err)
+		}
+		file.Calibrators[action] = spec
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case "json":
+		return append(data, '\n'), nil
+	case "yaml", "yml":
+		var doc any
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unsupported calibration format %q (want yaml or json)", format)
+	}
+}
+
+// formatOf 按扩展名返回文件格式
+func formatOf(path string) string {
+	return strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+}
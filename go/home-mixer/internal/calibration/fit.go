@@ -0,0 +1,240 @@
+package calibration
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Sample 是一条记录的预测概率，以及用户是否实际发生了该动作
+type Sample struct {
+	Prediction float64
+	Label      bool
+}
+
+// group 是按预测排序后相邻样本的汇总
+type group struct {
+	sumPrediction float64
+	positives     float64
+	count         float64
+}
+
+func (g *group) add(o group) {
+	g.sumPrediction += o.sumPrediction
+	g.positives += o.positives
+	g.count += o.count
+}
+
+func (g group) meanPrediction() float64 { return g.sumPrediction / g.count }
+func (g group) rate() float64           { return g.positives / g.count }
+
+// checkSamples 校验拟合的样本：预测在 [0, 1] 内，且正负样本都存在
+func checkSamples(samples []Sample) error {
+	if len(samples) == 0 {
+		return errors.New("no samples")
+	}
+	positives := 0
+	for _, s := range samples {
+		if !inUnitInterval(s.Prediction) {
+			return fmt.Errorf("prediction must be in [0, 1], got %v", s.Prediction)
+		}
+		if s.Label {
+			positives++
+		}
+	}
+	if positives == 0 || positives == len(samples) {
+		return fmt.Errorf("need both positive and negative samples, got %d positives of %d", positives, len(samples))
+	}
+	return nil
+}
+
+// quantileGroups 按预测排序后把样本分为最多 bins 个样本数相近的组，相同的预测不会被分到不同的组
+func quantileGroups(samples []Sample, bins int) []group {
+	sorted := append([]Sample(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Prediction < sorted[j].Prediction })
+	size := (len(sorted) + bins - 1) / bins
+	var groups []group
+	var cur group
+	for i, s := range sorted {
+		if cur.count >= float64(size) && s.Prediction != sorted[i-1].Prediction {
+			groups = append(groups, cur)
+			cur = group{}
+		}
+		cur.sumPrediction += s.Prediction
+		cur.count++
+		if s.Label {
+			cur.positives++
+		}
+	}
+	return append(groups, cur)
+}
+
+// FitIsotonic 用保序回归（PAV）拟合校准表
+// 样本先按预测分为最多 bins 个等样本数的组，再对组的发生率做保序回归，
+// 因此校准表最多 bins 个点，每个点至少有 len(samples)/bins 个样本支撑，稀有动作的发生率不会过拟合到单个样本。
+func FitIsotonic(samples []Sample, bins int) (*Isotonic, error) {
+	if bins < 2 {
+		return nil, fmt.Errorf("isotonic: bins must be >= 2, got %d", bins)
+	}
+	if err := checkSamples(samples); err != nil {
+		return nil, fmt.Errorf("isotonic: %w", err)
+	}
+
+	// PAV：相邻块的发生率递减时合并，直到发生率单调不减
+	var blocks []group
+	for _, g := range quantileGroups(samples, bins) {
+		blocks = append(blocks, g)
+		for n := len(blocks); n > 1 && blocks[n-2].rate() >= blocks[n-1].rate(); n = len(blocks) {
+			blocks[n-2].add(blocks[n-1])
+			blocks = blocks[:n-1]
+		}
+	}
+
+	c := &Isotonic{X: make([]float64, len(blocks)), Y: make([]float64, len(blocks))}
+	for i, b := range blocks {
+		c.X[i] = b.meanPrediction()
+		c.Y[i] = b.rate()
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Platt 拟合的牛顿法参数
+const (
+	plattMaxIterations = 100
+	plattTolerance     = 1e-10
+	plattRidge         = 1e-12 // Hessian 对角线上的正则项，保证可逆
+)
+
+// FitPlatt 在预测的 logit 上用逻辑回归拟合 Platt 缩放
+// 按 Platt 的做法把标签平滑为 (N+ + 1) / (N+ + 2) 和 1 / (N- + 2)，避免样本可分时参数发散。
+// 预测与标签负相关（拟合得到 A <= 0）时返回错误：Platt 缩放不能改变排序。
+func FitPlatt(samples []Sample) (*Platt, error) {
+	if err := checkSamples(samples); err != nil {
+		return nil, fmt.Errorf("platt: %w", err)
+	}
+	var positives, negatives float64
+	for _, s := range samples {
+		if s.Label {
+			positives++
+		} else {
+			negatives++
+		}
+	}
+	high := (positives + 1) / (positives + 2)
+	low := 1 / (negatives + 2)
+
+	f := make([]float64, len(samples))
+	t := make([]float64, len(samples))
+	for i, s := range samples {
+		f[i] = logit(s.Prediction)
+		t[i] = low
+		if s.Label {
+			t[i] = high
+		}
+	}
+	loss := func(a, b float64) float64 {
+		var l float64
+		for i := range f {
+			z := a*f[i] + b
+			// -t*log(sigmoid(z)) - (1-t)*log(1-sigmoid(z))，按 z 的符号选择数值稳定的形式
+			if z >= 0 {
+				l += (1-t[i])*z + math.Log1p(math.Exp(-z))
+			} else {
+				l += -t[i]*z + math.Log1p(math.Exp(z))
+			}
+		}
+		return l
+	}
+
+	a, b := 1.0, 0.0 // 从不校准开始
+	current := loss(a, b)
+	for iter := 0; iter < plattMaxIterations; iter++ {
+		var ga, gb, haa, hab, hbb float64
+		for i := range f {
+			p := sigmoid(a*f[i] + b)
+			d := p - t[i]
+			w := p * (1 - p)
+			ga += d * f[i]
+			gb += d
+			haa += w * f[i] * f[i]
+			hab += w * f[i]
+			hbb += w
+		}
+		haa += plattRidge
+		hbb += plattRidge
+		det := haa*hbb - hab*hab
+		if det <= 0 {
+			break
+		}
+		da := -(hbb*ga - hab*gb) / det
+		db := -(haa*gb - hab*ga) / det
+
+		// 回溯线搜索，保证损失下降
+		step := 1.0
+		for ; step > 1e-8; step /= 2 {
+			if next := loss(a+step*da, b+step*db); next < current {
+				a, b, current = a+step*da, b+step*db, next
+				break
+			}
+		}
+		if step <= 1e-8 || math.Abs(step*da)+math.Abs(step*db) < plattTolerance {
+			break
+		}
+	}
+
+	c := &Platt{A: a, B: b}
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("platt: predictions are not positively correlated with labels: %w", err)
+	}
+	return c, nil
+}
+
+// Metrics 是一组预测的校准指标
+type Metrics struct {
+	Samples        int
+	Positives      int
+	MeanPrediction float64 // 平均预测概率，校准良好时接近 PositiveRate
+	PositiveRate   float64 // 实际发生率
+	LogLoss        float64
+	// ECE（expected calibration error）是按预测分为等样本数的组后，各组平均预测与发生率之差的加权平均
+	ECE float64
+}
+
+// Evaluate 计算样本经过校准器 c（为 nil 时使用原始预测）之后的校准指标，ECE 使用最多 bins 个组
+func Evaluate(samples []Sample, c Calibrator, bins int) Metrics {
+	m := Metrics{Samples: len(samples)}
+	if len(samples) == 0 {
+		return m
+	}
+	calibrated := make([]Sample, len(samples))
+	for i, s := range samples {
+		p := s.Prediction
+		if c != nil {
+			p = c.Calibrate(p)
+		}
+		calibrated[i] = Sample{Prediction: p, Label: s.Label}
+		m.MeanPrediction += p
+		clamped := math.Min(math.Max(p, probabilityEpsilon), 1-probabilityEpsilon)
+		if s.Label {
+			m.Positives++
+			m.LogLoss -= math.Log(clamped)
+		} else {
+			m.LogLoss -= math.Log(1 - clamped)
+		}
+	}
+	n := float64(len(samples))
+	m.MeanPrediction /= n
+	m.PositiveRate = float64(m.Positives) / n
+	m.LogLoss /= n
+	if bins < 1 {
+		bins = 1
+	}
+	for _, g := range quantileGroups(calibrated, bins) {
+		m.ECE += math.Abs(g.meanPrediction()-g.rate()) * g.count / n
+	}
+	return m
+}
@@ -0,0 +1,250 @@
+package calibration
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// samples 返回预测为 p 的 n 个样本，其中 positives 个为正样本
+func samples(p float64, n, positives int) []Sample {
+	out := make([]Sample, n)
+	for i := range out {
+		out[i] = Sample{Prediction: p, Label: i < positives}
+	}
+	return out
+}
+
+// calibratedSamples 返回校准良好的样本：每个预测下的发生率等于预测
+func calibratedSamples() []Sample {
+	var out []Sample
+	for _, p := range []float64{0.1, 0.3, 0.5, 0.7, 0.9} {
+		out = append(out, samples(p, 100, int(math.Round(p*100)))...)
+	}
+	return out
+}
+
+func labeled(predictions []float64, labels string) []Sample {
+	out := make([]Sample, len(predictions))
+	for i, p := range predictions {
+		out[i] = Sample{Prediction: p, Label: labels[i] == '1'}
+	}
+	return out
+}
+
+func approxEqual(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestQuantileGroups(t *testing.T) {
+	tests := []struct {
+		name       string
+		samples    []Sample
+		bins       int
+		wantCounts []float64
+	}{
+		{name: "equal sizes", samples: labeled([]float64{0.4, 0.1, 0.3, 0.2}, "0101"), bins: 2, wantCounts: []float64{2, 2}},
+		{name: "last group smaller", samples: labeled([]float64{0.1, 0.2, 0.3, 0.4, 0.5}, "01010"), bins: 2, wantCounts: []float64{3, 2}},
+		// 相同的预测留在同一组，组数可能少于 bins
+		{name: "ties stay together", samples: labeled([]float64{0.2, 0.2, 0.2, 0.8}, "0101"), bins: 4, wantCounts: []float64{3, 1}},
+		{name: "all tied", samples: labeled([]float64{0.5, 0.5, 0.5}, "010"), bins: 3, wantCounts: []float64{3}},
+		{name: "one bin", samples: labeled([]float64{0.1, 0.9}, "01"), bins: 1, wantCounts: []float64{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := quantileGroups(tt.samples, tt.bins)
+			var counts []float64
+			for i, g := range groups {
+				counts = append(counts, g.count)
+				if i > 0 && g.meanPrediction() <= groups[i-1].meanPrediction() {
+					t.Errorf("group %d mean %v not above group %d mean %v", i, g.meanPrediction(), i-1, groups[i-1].meanPrediction())
+				}
+			}
+			if !approxEqual(counts, tt.wantCounts) {
+				t.Errorf("counts=%v, want %v", counts, tt.wantCounts)
+			}
+		})
+	}
+}
+
+func TestFitIsotonic(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []Sample
+		bins    int
+		wantX   []float64
+		wantY   []float64
+		wantErr string
+	}{
+		{
+			name:    "already monotone",
+			samples: labeled([]float64{0.1, 0.2, 0.3, 0.4}, "0011"),
+			bins:    2,
+			wantX:   []float64{0.15, 0.35},
+			wantY:   []float64{0, 1},
+		},
+		{
+			// 发生率 0, 1, 0, 1：中间两组合并为 0.5
+			name:    "violators are pooled",
+			samples: labeled([]float64{0.1, 0.2, 0.3, 0.4}, "0101"),
+			bins:    4,
+			wantX:   []float64{0.1, 0.25, 0.4},
+			wantY:   []float64{0, 0.5, 1},
+		},
+		{
+			// 发生率递减时全部合并为一个点
+			name:    "decreasing rates",
+			samples: labeled([]float64{0.1, 0.2, 0.3, 0.4}, "1100"),
+			bins:    4,
+			wantX:   []float64{0.25},
+			wantY:   []float64{0.5},
+		},
+		{
+			name:    "calibrated input",
+			samples: calibratedSamples(),
+			bins:    5,
+			wantX:   []float64{0.1, 0.3, 0.5, 0.7, 0.9},
+			wantY:   []float64{0.1, 0.3, 0.5, 0.7, 0.9},
+		},
+		{name: "too few bins", samples: calibratedSamples(), bins: 1, wantErr: "bins must be >= 2"},
+		{name: "no samples", bins: 2, wantErr: "no samples"},
+		{name: "prediction out of range", samples: labeled([]float64{0.1, 1.5}, "01"), bins: 2, wantErr: "must be in [0, 1]"},
+		{name: "only positives", samples: labeled([]float64{0.1, 0.2}, "11"), bins: 2, wantErr: "both positive and negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := FitIsotonic(tt.samples, tt.bins)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err=%v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("fit: %v", err)
+			}
+			if !approxEqual(c.X, tt.wantX) || !approxEqual(c.Y, tt.wantY) {
+				t.Errorf("x=%v y=%v, want x=%v y=%v", c.X, c.Y, tt.wantX, tt.wantY)
+			}
+		})
+	}
+}
+
+func TestFitPlatt(t *testing.T) {
+	// overconfident 把校准良好的预测推向 0 和 1，拟合应把它们拉回（A < 1）
+	var overconfident, inverted []Sample
+	for _, s := range calibratedSamples() {
+		overconfident = append(overconfident, Sample{Prediction: sigmoid(2 * logit(s.Prediction)), Label: s.Label})
+		inverted = append(inverted, Sample{Prediction: 1 - s.Prediction, Label: s.Label})
+	}
+	tests := []struct {
+		name       string
+		samples    []Sample
+		wantA      [2]float64 // A 的取值范围
+		wantMaxECE float64
+		wantErr    string
+	}{
+		{name: "calibrated input is kept", samples: calibratedSamples(), wantA: [2]float64{0.95, 1.05}, wantMaxECE: 0.01},
+		{name: "overconfident input is softened", samples: overconfident, wantA: [2]float64{0.45, 0.55}, wantMaxECE: 0.01},
+		{name: "negative correlation", samples: inverted, wantErr: "not positively correlated"},
+		{name: "only negatives", samples: labeled([]float64{0.1, 0.2}, "00"), wantErr: "both positive and negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := FitPlatt(tt.samples)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err=%v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("fit: %v", err)
+			}
+			if c.A < tt.wantA[0] || c.A > tt.wantA[1] || math.Abs(c.B) > 0.05 {
+				t.Errorf("a=%v b=%v, want a in %v and b near 0", c.A, c.B, tt.wantA)
+			}
+			if m := Evaluate(tt.samples, c, 5); m.ECE > tt.wantMaxECE {
+				t.Errorf("ece=%v after calibration, want <= %v", m.ECE, tt.wantMaxECE)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name        string
+		samples     []Sample
+		calibrator  Calibrator
+		bins        int
+		wantMean    float64
+		wantRate    float64
+		wantLogLoss float64
+		wantECE     float64
+	}{
+		{name: "empty"},
+		{
+			name:        "calibrated input",
+			samples:     samples(0.25, 4, 1),
+			bins:        1,
+			wantMean:    0.25,
+			wantRate:    0.25,
+			wantLogLoss: -(math.Log(0.25) + 3*math.Log(0.75)) / 4,
+		},
+		{
+			name:        "miscalibrated input",
+			samples:     samples(0.5, 4, 1),
+			bins:        1,
+			wantMean:    0.5,
+			wantRate:    0.25,
+			wantLogLoss: math.Log(2),
+			wantECE:     0.25,
+		},
+		{
+			name:        "calibrator is applied",
+			samples:     samples(0.5, 4, 1),
+			calibrator:  &Isotonic{X: []float64{0, 1}, Y: []float64{0, 0.5}},
+			bins:        1,
+			wantMean:    0.25,
+			wantRate:    0.25,
+			wantLogLoss: -(math.Log(0.25) + 3*math.Log(0.75)) / 4,
+		},
+		{
+			// 预测 0.1 和 0.9 的两组各自校准良好，ECE 为 0
+			name:        "ece per group",
+			samples:     append(samples(0.1, 10, 1), samples(0.9, 10, 9)...),
+			bins:        2,
+			wantMean:    0.5,
+			wantRate:    0.5,
+			wantLogLoss: -(math.Log(0.1) + 9*math.Log(0.9)) / 10,
+		},
+		{
+			// 预测 0 而实际发生时对数损失有限（截断到 probabilityEpsilon）
+			name:        "clamped log loss",
+			samples:     labeled([]float64{0, 1}, "10"),
+			bins:        2,
+			wantMean:    0.5,
+			wantRate:    0.5,
+			wantLogLoss: -math.Log(probabilityEpsilon),
+			wantECE:     1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Evaluate(tt.samples, tt.calibrator, tt.bins)
+			got := []float64{m.MeanPrediction, m.PositiveRate, m.LogLoss, m.ECE}
+			want := []float64{tt.wantMean, tt.wantRate, tt.wantLogLoss, tt.wantECE}
+			if m.Samples != len(tt.samples) || !approxEqual(got, want) {
+				t.Errorf("metrics=%+v, want mean=%v rate=%v log_loss=%v ece=%v", m, tt.wantMean, tt.wantRate, tt.wantLogLoss, tt.wantECE)
+			}
+		})
+	}
+}
@@ -0,0 +1,315 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrCircuitOpen 表示下游服务的熔断器处于打开状态，调用没有发出
+// 客户端返回的 *Error 满足 errors.Is(err, ErrCircuitOpen)，状态码为 UNAVAILABLE
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// BreakerState 是熔断器的状态
+type BreakerState int
+
+const (
+	// BreakerClosed 正常放行调用，并统计失败率和慢调用比例
+	BreakerClosed BreakerState = iota
+	// BreakerOpen 直接拒绝调用，OpenDuration 之后进入半开
+	BreakerOpen
+	// BreakerHalfOpen 放行少量探测调用：全部成功后关闭，任一失败重新打开
+	BreakerHalfOpen
+)
+
+// String 返回状态名称（用于日志和指标）
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half_open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", int(s))
+	}
+}
+
+// BreakerConfig 配置一个下游服务的熔断器
+//
+// 熔断器统计最近 Window 内每次尝试（包括重试）的结果，失败比例达到 ErrorRate、
+// 或耗时超过 SlowCallDuration 的比例达到 SlowCallRate 时打开。
+// 打开期间调用直接失败，不占用下游和阶段预算；管道中表现为该组件失败，按组件的失败策略处理。
+//
+// 只有表示下游异常的状态码（UNAVAILABLE、DEADLINE_EXCEEDED、RESOURCE_EXHAUSTED、INTERNAL、UNKNOWN、ABORTED、DATA_LOSS）
+// 计为失败；INVALID_ARGUMENT、NOT_FOUND 等说明下游正常工作。调用方取消的调用不计入统计。
+type BreakerConfig struct {
+	Window      time.Duration // 统计窗口
+	MinRequests int           // 窗口内的尝试数少于它时不打开，避免低流量时误判
+	ErrorRate   float64       // 失败比例阈值 (0, 1]
+
+	// SlowCallDuration 是慢调用的耗时阈值，0 表示不按延迟打开
+	SlowCallDuration time.Duration
+	SlowCallRate     float64 // 慢调用比例阈值 (0, 1]
+
+	OpenDuration   time.Duration // 打开多久之后进入半开
+	HalfOpenProbes int           // 半开状态同时放行的探测调用数，全部成功后关闭
+}
+
+// DefaultBreakerConfig 返回默认的熔断器配置
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		Window:           10 * time.Second,
+		MinRequests:      20,
+		ErrorRate:        0.5,
+		SlowCallDuration: 500 * time.Millisecond,
+		SlowCallRate:     0.5,
+		OpenDuration:     5 * time.Second,
+		HalfOpenProbes:   3,
+	}
+}
+
+func (c BreakerConfig) validate() error {
+	switch {
+	case c.Window <= 0:
+		return fmt.Errorf("circuit breaker: window must be > 0, got %s", c.Window)
+	case c.ErrorRate <= 0 || c.ErrorRate > 1:
+		return fmt.Errorf("circuit breaker: error rate must be in (0, 1], got %v", c.ErrorRate)
+	case c.SlowCallDuration < 0:
+		return fmt.Errorf("circuit breaker: slow call duration must be >= 0, got %s", c.SlowCallDuration)
+	case c.SlowCallDuration > 0 && (c.SlowCallRate <= 0 || c.SlowCallRate > 1):
+		return fmt.Errorf("circuit breaker: slow call rate must be in (0, 1], got %v", c.SlowCallRate)
+	case c.OpenDuration <= 0:
+		return fmt.Errorf("circuit breaker: open duration must be > 0, got %s", c.OpenDuration)
+	case c.HalfOpenProbes <= 0:
+		return fmt.Errorf("circuit breaker: half open probes must be > 0, got %d", c.HalfOpenProbes)
+	}
+	return nil
+}
+
+// BreakerObserver 接收熔断器的状态变化（例如记录为指标），实现必须是并发安全的
+type BreakerObserver interface {
+	BreakerStateChanged(service string, from, to BreakerState)
+}
+
+// Breaker 是一个下游服务的熔断器，在客户端接口上使用（见 WithPhoenixRetrievalBreaker 等）
+//
+// Dial 中的熔断器只统计经过连接的 gRPC 调用；Breaker 包装客户端方法的整个调用，
+// 不论客户端如何访问下游。打开时方法直接返回 ErrCircuitOpen，管道中表现为组件失败。
+type Breaker struct {
+	cb *circuitBreaker
+}
+
+// NewBreaker 按 config.Breaker 创建服务 config.Service 的熔断器，config.Breaker 为 nil 时返回 nil（不熔断）
+func NewBreaker(config ConnConfig) (*Breaker, error) {
+	if config.Breaker == nil {
+		return nil, nil
+	}
+	if err := config.Breaker.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", config.Service, err)
+	}
+	return &Breaker{cb: newCircuitBreaker(config.Service, *config.Breaker, config.BreakerObserver)}, nil
+}
+
+// Do 在熔断器放行时执行 call，并把结果和耗时记入熔断器；b 为 nil 时直接执行 call
+// 熔断器打开时不执行 call，返回状态码为 UNAVAILABLE、满足 errors.Is(err, ErrCircuitOpen) 的 *Error
+func (b *Breaker) Do(ctx context.Context, method string, call func(ctx context.Context) error) (err error) {
+	if b == nil {
+		return call(ctx)
+	}
+	probe, ok := b.cb.allow()
+	if !ok {
+		return circuitOpenError(b.cb.service, method)
+	}
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			// 保证半开状态的探测名额被释放，panic 交给调用方（管道）处理
+			b.cb.record(probe, status.Error(codes.Internal, "panic"), time.Since(start))
+			panic(r)
+		}
+		b.cb.record(probe, err, time.Since(start))
+	}()
+	return call(ctx)
+}
+
+// State 返回熔断器当前的状态（打开超过 OpenDuration 后，下一次调用时才进入半开）
+func (b *Breaker) State() BreakerState {
+	b.cb.mu.Lock()
+	defer b.cb.mu.Unlock()
+	return b.cb.state
+}
+
+// circuitOpenError 返回熔断器打开时调用 method 的错误
+func circuitOpenError(service, method string) *Error {
+	return &Error{
+		Service: service,
+		Method:  method,
+		Code:    codes.Unavailable,
+		Message: ErrCircuitOpen.Error(),
+		err:     ErrCircuitOpen,
+	}
+}
+
+// breakerBuckets 是统计窗口划分的桶数，窗口按桶滑动
+const breakerBuckets = 10
+
+// breakerBucket 是统计窗口中的一个桶
+type breakerBucket struct {
+	start    time.Time
+	total    int
+	failures int
+	slow     int
+}
+
+// circuitBreaker 是一个下游服务的熔断器，并发安全
+type circuitBreaker struct {
+	service  string
+	config   BreakerConfig
+	observer BreakerObserver
+
+	mu       sync.Mutex
+	state    BreakerState
+	buckets  [breakerBuckets]breakerBucket
+	openedAt time.Time
+	probes   int // 半开状态下正在进行的探测调用数
+	passed   int // 半开状态下成功的探测调用数
+}
+
+func newCircuitBreaker(service string, config BreakerConfig, observer BreakerObserver) *circuitBreaker {
+	return &circuitBreaker{service: service, config: config, observer: observer}
+}
+
+// allow 判断是否可以发出一次尝试；probe 表示这次尝试是半开状态的探测，结束时需原样传给 record
+func (b *circuitBreaker) allow() (probe, ok bool) {
+	b.mu.Lock()
+	from := b.state
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.config.OpenDuration {
+			b.mu.Unlock()
+			return false, false
+		}
+		b.state, b.probes, b.passed = BreakerHalfOpen, 0, 0
+		fallthrough
+	case BreakerHalfOpen:
+		if b.probes >= b.config.HalfOpenProbes {
+			b.mu.Unlock()
+			b.notify(from, BreakerHalfOpen)
+			return false, false
+		}
+		b.probes++
+		b.mu.Unlock()
+		b.notify(from, BreakerHalfOpen)
+		return true, true
+	}
+	b.mu.Unlock()
+	return false, true
+}
+
+// record 记录一次尝试的结果
+func (b *circuitBreaker) record(probe bool, err error, elapsed time.Duration) {
+	ignored := false
+	failed := false
+	if err != nil {
+		switch status.Code(err) {
+		case codes.Canceled:
+			ignored = true
+		case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted,
+			codes.Internal, codes.Unknown, codes.Aborted, codes.DataLoss:
+			failed = true
+		}
+		if errors.Is(err, context.Canceled) {
+			ignored = true
+		}
+	}
+	slow := b.config.SlowCallDuration > 0 && elapsed >= b.config.SlowCallDuration
+
+	b.mu.Lock()
+	from := b.state
+	switch {
+	case b.state == BreakerHalfOpen && probe:
+		b.probes--
+		switch {
+		case ignored:
+		case failed || slow:
+			b.trip()
+		default:
+			if b.passed++; b.passed >= b.config.HalfOpenProbes {
+				b.state = BreakerClosed
+				b.buckets = [breakerBuckets]breakerBucket{}
+			}
+		}
+	case b.state == BreakerClosed && !ignored:
+		now := time.Now()
+		bucket := b.bucket(now)
+		bucket.total++
+		if failed {
+			bucket.failures++
+		}
+		if slow {
+			bucket.slow++
+		}
+		if b.shouldTrip(now) {
+			b.trip()
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+	b.notify(from, to)
+}
+
+// bucket 返回 now 所在的桶，过期的桶会被清空
+func (b *circuitBreaker) bucket(now time.Time) *breakerBucket {
+	width := b.config.Window / breakerBuckets
+	start := now.Truncate(width)
+	bucket := &b.buckets[int(start.UnixNano()/int64(width))%breakerBuckets]
+	if !bucket.start.Equal(start) {
+		*bucket = breakerBucket{start: start}
+	}
+	return bucket
+}
+
+// shouldTrip 按窗口内的统计判断是否打开
+func (b *circuitBreaker) shouldTrip(now time.Time) bool {
+	var total, failures, slow int
+	for _, bucket := range b.buckets {
+		if now.Sub(bucket.start) < b.config.Window {
+			total += bucket.total
+			failures += bucket.failures
+			slow += bucket.slow
+		}
+	}
+	if total == 0 || total < b.config.MinRequests {
+		return false
+	}
+	if float64(failures) >= b.config.ErrorRate*float64(total) {
+		return true
+	}
+	return b.config.SlowCallDuration > 0 && float64(slow) >= b.config.SlowCallRate*float64(total)
+}
+
+// trip 打开熔断器，调用方需持有锁
+func (b *circuitBreaker) trip() {
+	b.state = BreakerOpen
+	b.openedAt = time.Now()
+	b.buckets = [breakerBuckets]breakerBucket{}
+}
+
+// notify 记录状态变化，不能在持有锁时调用
+func (b *circuitBreaker) notify(from, to BreakerState) {
+	if from == to {
+		return
+	}
+	log.Printf("service=%s circuit_breaker %s -> %s", b.service, from, to)
+	if b.observer != nil {
+		b.observer.BreakerStateChanged(b.service, from, to)
+	}
+}
@@ -0,0 +1,277 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"x-algorithm-go/home-mixer/internal/scorers"
+)
+
+func TestBreakerConfigValidate(t *testing.T) {
+	valid := DefaultBreakerConfig()
+	tests := []struct {
+		name    string
+		modify  func(c *BreakerConfig)
+		wantErr string
+	}{
+		{name: "default", modify: func(c *BreakerConfig) {}},
+		{name: "no slow calls", modify: func(c *BreakerConfig) { c.SlowCallDuration, c.SlowCallRate = 0, 0 }},
+		{name: "window", modify: func(c *BreakerConfig) { c.Window = 0 }, wantErr: "window"},
+		{name: "error rate zero", modify: func(c *BreakerConfig) { c.ErrorRate = 0 }, wantErr: "error rate"},
+		{name: "error rate above one", modify: func(c *BreakerConfig) { c.ErrorRate = 1.5 }, wantErr: "error rate"},
+		{name: "negative slow call duration", modify: func(c *BreakerConfig) { c.SlowCallDuration = -1 }, wantErr: "slow call duration"},
+		{name: "slow call rate", modify: func(c *BreakerConfig) { c.SlowCallRate = 0 }, wantErr: "slow call rate"},
+		{name: "open duration", modify: func(c *BreakerConfig) { c.OpenDuration = 0 }, wantErr: "open duration"},
+		{name: "half open probes", modify: func(c *BreakerConfig) { c.HalfOpenProbes = 0 }, wantErr: "half open probes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := valid
+			tt.modify(&config)
+			err := config.validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validate: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err=%v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// attempt 是记入熔断器的一次尝试
+type attempt struct {
+	err     error
+	elapsed time.Duration
+}
+
+func attempts(n int, a attempt) []attempt {
+	out := make([]attempt, n)
+	for i := range out {
+		out[i] = a
+	}
+	return out
+}
+
+func concat(lists ...[]attempt) []attempt {
+	var out []attempt
+	for _, l := range lists {
+		out = append(out, l...)
+	}
+	return out
+}
+
+var (
+	ok          = attempt{}
+	unavailable = attempt{err: status.Error(codes.Unavailable, "down")}
+	invalid     = attempt{err: status.Error(codes.InvalidArgument, "bad request")}
+	notFound    = attempt{err: status.Error(codes.NotFound, "missing")}
+	canceled    = attempt{err: context.Canceled}
+	slowCall    = attempt{elapsed: 200 * time.Millisecond}
+)
+
+func testBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		Window:           10 * time.Second,
+		MinRequests:      4,
+		ErrorRate:        0.5,
+		SlowCallDuration: 100 * time.Millisecond,
+		SlowCallRate:     0.75,
+		OpenDuration:     time.Minute,
+		HalfOpenProbes:   2,
+	}
+}
+
+func TestCircuitBreakerTrips(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   func(c *BreakerConfig)
+		attempts []attempt
+		want     BreakerState
+	}{
+		{name: "below min requests", attempts: attempts(3, unavailable), want: BreakerClosed},
+		{name: "error rate reached", attempts: concat(attempts(2, ok), attempts(2, unavailable)), want: BreakerOpen},
+		{name: "error rate not reached", attempts: concat(attempts(3, ok), attempts(2, unavailable)), want: BreakerClosed},
+		// INVALID_ARGUMENT / NOT_FOUND 说明下游正常工作
+		{name: "client errors are not failures", attempts: concat(attempts(2, invalid), attempts(2, notFound)), want: BreakerClosed},
+		// 调用方取消的调用不计入统计：3 次失败 + 3 次取消仍然少于 MinRequests
+		{name: "canceled calls are ignored", attempts: concat(attempts(3, canceled), attempts(3, unavailable)), want: BreakerClosed},
+		{name: "slow call rate reached", attempts: concat(attempts(1, ok), attempts(3, slowCall)), want: BreakerOpen},
+		{name: "slow call rate not reached", attempts: concat(attempts(2, ok), attempts(2, slowCall)), want: BreakerClosed},
+		{
+			name:     "slow calls disabled",
+			config:   func(c *BreakerConfig) { c.SlowCallDuration = 0 },
+			attempts: attempts(4, slowCall),
+			want:     BreakerClosed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testBreakerConfig()
+			if tt.config != nil {
+				tt.config(&config)
+			}
+			b := newCircuitBreaker("test", config, nil)
+			for _, a := range tt.attempts {
+				probe, allowed := b.allow()
+				if !allowed {
+					t.Fatal("attempt rejected before the breaker should have opened")
+				}
+				b.record(probe, a.err, a.elapsed)
+			}
+			if b.state != tt.want {
+				t.Errorf("state=%s, want %s", b.state, tt.want)
+			}
+			if _, allowed := b.allow(); allowed != (tt.want != BreakerOpen) {
+				t.Errorf("allow=%v in state %s", allowed, b.state)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	tests := []struct {
+		name   string
+		probes []attempt // 按顺序结束的探测调用（最多 HalfOpenProbes 个）
+		want   BreakerState
+	}{
+		{name: "all probes succeed", probes: []attempt{ok, ok}, want: BreakerClosed},
+		{name: "one probe succeeds", probes: []attempt{ok}, want: BreakerHalfOpen},
+		{name: "probe fails", probes: []attempt{ok, unavailable}, want: BreakerOpen},
+		{name: "probe is slow", probes: []attempt{slowCall}, want: BreakerOpen},
+		{name: "client error counts as success", probes: []attempt{invalid, ok}, want: BreakerClosed},
+		{name: "canceled probe is not counted", probes: []attempt{canceled, ok}, want: BreakerHalfOpen},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observer := &stateChanges{}
+			b := newCircuitBreaker("test", testBreakerConfig(), observer)
+			b.trip()
+			b.openedAt = time.Now().Add(-2 * time.Minute)
+
+			// 半开状态同时最多放行 HalfOpenProbes 个探测
+			var probes []bool
+			for i := 0; i < b.config.HalfOpenProbes; i++ {
+				probe, allowed := b.allow()
+				if !allowed || !probe {
+					t.Fatalf("probe %d: allowed=%v probe=%v", i, allowed, probe)
+				}
+				probes = append(probes, probe)
+			}
+			if _, allowed := b.allow(); allowed {
+				t.Fatal("allowed more probes than HalfOpenProbes")
+			}
+
+			for i, a := range tt.probes {
+				b.record(probes[i], a.err, a.elapsed)
+			}
+			if b.state != tt.want {
+				t.Errorf("state=%s, want %s", b.state, tt.want)
+			}
+			if got := observer.last(); got != tt.want {
+				t.Errorf("last reported state=%s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBreakerDo(t *testing.T) {
+	config := DefaultConnConfig("test", "127.0.0.1:1")
+	if b, err := NewBreaker(config); b != nil || err != nil {
+		t.Fatalf("NewBreaker without config = %v, %v, want nil", b, err)
+	}
+	var nilBreaker *Breaker
+	if err := nilBreaker.Do(context.Background(), "Call", func(context.Context) error { return nil }); err != nil {
+		t.Fatalf("nil breaker: %v", err)
+	}
+
+	breakerConfig := testBreakerConfig()
+	config.Breaker = &breakerConfig
+	b, err := NewBreaker(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// panic 被记为失败后继续向上传递
+	for i := 0; i < breakerConfig.MinRequests; i++ {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("panic was swallowed")
+				}
+			}()
+			b.Do(context.Background(), "Call", func(context.Context) error { panic("boom") })
+		}()
+	}
+	if b.State() != BreakerOpen {
+		t.Fatalf("state=%s after %d panics, want open", b.State(), breakerConfig.MinRequests)
+	}
+
+	called := false
+	err = b.Do(context.Background(), "Call", func(context.Context) error { called = true; return nil })
+	var clientErr *Error
+	if called || !errors.Is(err, ErrCircuitOpen) || !errors.As(err, &clientErr) ||
+		clientErr.Service != "test" || clientErr.Method != "Call" || status.Code(err) != codes.Unavailable {
+		t.Fatalf("open breaker: called=%v err=%v, want circuit open without calling", called, err)
+	}
+}
+
+func TestWithPhoenixRankingBreaker(t *testing.T) {
+	inner := &failingRanking{}
+	if got := WithPhoenixRankingBreaker(inner, nil); got != scorers.PhoenixRankingClient(inner) {
+		t.Fatalf("nil breaker wrapped the client")
+	}
+
+	config := DefaultConnConfig("phoenix_ranking", "127.0.0.1:1")
+	breakerConfig := testBreakerConfig()
+	config.Breaker = &breakerConfig
+	b, err := NewBreaker(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := WithPhoenixRankingBreaker(inner, b)
+	for i := 0; i < breakerConfig.MinRequests+2; i++ {
+		client.Rank(context.Background(), &scorers.RankingRequest{})
+	}
+	if inner.calls != breakerConfig.MinRequests {
+		t.Errorf("inner calls=%d, want %d (no call while open)", inner.calls, breakerConfig.MinRequests)
+	}
+	if _, err := client.Rank(context.Background(), &scorers.RankingRequest{}); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("err=%v, want circuit open", err)
+	}
+}
+
+// failingRanking 是总是返回 UNAVAILABLE 的排序客户端
+type failingRanking struct {
+	calls int
+}
+
+func (f *failingRanking) Rank(context.Context, *scorers.RankingRequest) (*scorers.RankingResponse, error) {
+	f.calls++
+	return nil, status.Error(codes.Unavailable, "down")
+}
+
+// stateChanges 记录熔断器的状态变化
+type stateChanges struct {
+	states []BreakerState
+}
+
+func (s *stateChanges) BreakerStateChanged(_ string, _, to BreakerState) {
+	s.states = append(s.states, to)
+}
+
+func (s *stateChanges) last() BreakerState {
+	if len(s.states) == 0 {
+		return BreakerClosed
+	}
+	return s.states[len(s.states)-1]
+}
@@ -0,0 +1,124 @@
+package clients
+
+import (
+	"context"
+	"io"
+
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
+	"x-algorithm-go/home-mixer/internal/hydrators"
+	"x-algorithm-go/home-mixer/internal/scorers"
+	"x-algorithm-go/home-mixer/internal/sources"
+)
+
+// 客户端接口的熔断包装：熔断器打开时方法直接返回 ErrCircuitOpen，不调用被包装的客户端。
+// 包装后的客户端实现 io.Closer，Close 转发给被包装的客户端。
+
+// WithPhoenixRetrievalBreaker 用熔断器包装 Phoenix 检索客户端，breaker 为 nil 时原样返回
+func WithPhoenixRetrievalBreaker(client sources.PhoenixRetrievalClient, breaker *Breaker) sources.PhoenixRetrievalClient {
+	if breaker == nil {
+		return client
+	}
+	return &breakingPhoenixRetrievalClient{inner: client, breaker: breaker}
+}
+
+type breakingPhoenixRetrievalClient struct {
+	inner   sources.PhoenixRetrievalClient
+	breaker *Breaker
+}
+
+func (c *breakingPhoenixRetrievalClient) Retrieve(ctx context.Context, userID uint64, sequence *home.UserActionSequence, maxResults int) (*sources.RetrievalResponse, error) {
+	var resp *sources.RetrievalResponse
+	err := c.breaker.Do(ctx, "Retrieve", func(ctx context.Context) error {
+		var err error
+		resp, err = c.inner.Retrieve(ctx, userID, sequence, maxResults)
+		return err
+	})
+	return resp, err
+}
+
+func (c *breakingPhoenixRetrievalClient) Close() error {
+	return closeClient(c.inner)
+}
+
+// WithPhoenixRankingBreaker 用熔断器包装 Phoenix 排序客户端，breaker 为 nil 时原样返回
+func WithPhoenixRankingBreaker(client scorers.PhoenixRankingClient, breaker *Breaker) scorers.PhoenixRankingClient {
+	if breaker == nil {
+		return client
+	}
+	return &breakingPhoenixRankingClient{inner: client, breaker: breaker}
+}
+
+type breakingPhoenixRankingClient struct {
+	inner   scorers.PhoenixRankingClient
+	breaker *Breaker
+}
+
+func (c *breakingPhoenixRankingClient) Rank(ctx context.Context, req *scorers.RankingRequest) (*scorers.RankingResponse, error) {
+	var resp *scorers.RankingResponse
+	err := c.breaker.Do(ctx, "Rank", func(ctx context.Context) error {
+		var err error
+		resp, err = c.inner.Rank(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+func (c *breakingPhoenixRankingClient) Close() error {
+	return closeClient(c.inner)
+}
+
+// WithTESBreaker 用熔断器包装 TES 客户端，breaker 为 nil 时原样返回
+// 同一个 TES 客户端的所有方法共享一个熔断器
+func WithTESBreaker(client hydrators.TweetEntityServiceClient, breaker *Breaker) hydrators.TweetEntityServiceClient {
+	if breaker == nil {
+		return client
+	}
+	return &breakingTESClient{inner: client, breaker: breaker}
+}
+
+type breakingTESClient struct {
+	inner   hydrators.TweetEntityServiceClient
+	breaker *Breaker
+}
+
+func (c *breakingTESClient) GetTweetCoreDatas(ctx context.Context, tweetIDs []int64) (map[int64]*hydrators.CoreData, error) {
+	var resp map[int64]*hydrators.CoreData
+	err := c.breaker.Do(ctx, "GetTweetCoreDatas", func(ctx context.Context) error {
+		var err error
+		resp, err = c.inner.GetTweetCoreDatas(ctx, tweetIDs)
+		return err
+	})
+	return resp, err
+}
+
+func (c *breakingTESClient) GetTweetMediaEntities(ctx context.Context, tweetIDs []int64) (map[int64]*hydrators.MediaEntities, error) {
+	var resp map[int64]*hydrators.MediaEntities
+	err := c.breaker.Do(ctx, "GetTweetMediaEntities", func(ctx context.Context) error {
+		var err error
+		resp, err = c.inner.GetTweetMediaEntities(ctx, tweetIDs)
+		return err
+	})
+	return resp, err
+}
+
+func (c *breakingTESClient) GetSubscriptionAuthorIDs(ctx context.Context, tweetIDs []int64) (map[int64]*uint64, error) {
+	var resp map[int64]*uint64
+	err := c.breaker.Do(ctx, "GetSubscriptionAuthorIDs", func(ctx context.Context) error {
+		var err error
+		resp, err = c.inner.GetSubscriptionAuthorIDs(ctx, tweetIDs)
+		return err
+	})
+	return resp, err
+}
+
+func (c *breakingTESClient) Close() error {
+	return closeClient(c.inner)
+}
+
+// closeClient 关闭实现了 io.Closer 的客户端
+func closeClient(client any) error {
+	if closer, ok := client.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,410 @@
+package clients
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+	"google.golang.org/grpc/status"
+)
+
+// 负载均衡策略（ConnConfig.LoadBalancing 的取值）
+const (
+	LoadBalancingPickFirst  = "pick_first"
+	LoadBalancingRoundRobin = "round_robin"
+)
+
+// ConnConfig 配置到一个下游服务的连接
+//
+// 所有下游客户端都通过 Dial 建立连接，使用同样的超时、重试、负载均衡、TLS 和错误映射：
+//   - 超时：每次调用的超时为 CallTimeout（或 MethodTimeouts 中的覆盖值），且不超过 ctx 的剩余时间，
+//     即管道为该阶段分配的预算
+//   - 重试：只重试 Dial 时声明为幂等的方法，按 RetryPolicy 使用带抖动的指数退避；
+//     剩余预算不足以再等待一次退避时不重试，服务端通过 grpc-retry-pushback-ms 要求等待时至少等待该时间
+//   - 熔断：配置了 Breaker 时，下游失败率或慢调用比例过高后直接拒绝调用（见 BreakerConfig）；
+//     Phoenix 检索 / 排序和 TES 客户端在客户端接口上熔断（见 Breaker），不论调用是否经过 gRPC
+//   - 错误：失败的调用返回 *Error，保留 gRPC 状态码（见 Error）
+type ConnConfig struct {
+	// Service 是下游服务名，用于日志和错误信息
+	Service string
+	// Addresses 是后端地址列表：多个 host:port 时在它们之间负载均衡；
+	// 单个地址可以带 resolver scheme（例如 dns:///thunder.example:50052），由 gRPC 解析为多个后端
+	Addresses []string
+	// LoadBalancing 是后端之间的负载均衡策略，为空时使用 round_robin
+	LoadBalancing string
+	// TLS 为 nil 时使用明文连接
+	TLS *TLSConfig
+
+	// CallTimeout 是单次调用（包括重试）的默认超时，0 表示只受 ctx 的 deadline 限制
+	CallTimeout time.Duration
+	// MethodTimeouts 按完整方法名（/package.Service/Method）覆盖 CallTimeout
+	MethodTimeouts map[string]time.Duration
+	// Retry 是幂等方法的重试策略
+	Retry RetryPolicy
+
+	// Breaker 是该下游服务的熔断器配置，为 nil 时不熔断；同一个连接上的所有方法共享一个熔断器
+	Breaker *BreakerConfig
+	// BreakerObserver 接收熔断器的状态变化，可以为 nil
+	BreakerObserver BreakerObserver
+}
+
+// TLSConfig 配置到下游服务的 TLS；同时给出 CertFile 和 KeyFile 时使用 mTLS
+type TLSConfig struct {
+	CAFile     string // 校验服务端证书的 CA，为空时使用系统 CA
+	CertFile   string // 客户端证书（mTLS）
+	KeyFile    string // 客户端私钥（mTLS）
+	ServerName string // 校验服务端证书时使用的名称，为空时使用地址中的主机名
+}
+
+// RetryPolicy 配置幂等方法的重试
+type RetryPolicy struct {
+	MaxAttempts    int           // 最多尝试次数（包括第一次），<= 1 表示不重试
+	InitialBackoff time.Duration // 第一次重试前的退避
+	MaxBackoff     time.Duration // 退避上限
+	Multiplier     float64       // 每次重试退避的倍数
+	Jitter         float64       // 退避的随机抖动比例 [0, 1]，避免大量客户端同时重试
+	// RetryableCodes 是可以重试的状态码；单次尝试超时（DEADLINE_EXCEEDED 且 ctx 仍有剩余时间）总是可以重试
+	RetryableCodes []codes.Code
+}
+
+// DefaultRetryPolicy 返回默认的重试策略
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     100 * time.Millisecond,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryableCodes: []codes.Code{codes.Unavailable, codes.ResourceExhausted, codes.Aborted},
+	}
+}
+
+// DefaultConnConfig 返回服务 service 在 addresses 上的默认连接配置
+// addresses 为逗号分隔的地址列表（与命令行参数的格式一致）
+func DefaultConnConfig(service, addresses string) ConnConfig {
+	return ConnConfig{
+		Service:       service,
+		Addresses:     SplitAddresses(addresses),
+		LoadBalancing: LoadBalancingRoundRobin,
+		CallTimeout:   time.Second,
+		Retry:         DefaultRetryPolicy(),
+	}
+}
+
+// SplitAddresses 把逗号分隔的地址列表拆分为地址切片，忽略空白项
+func SplitAddresses(addresses string) []string {
+	var out []string
+	for _, a := range strings.Split(addresses, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// Target 返回用于日志的地址描述
+func (c ConnConfig) Target() string {
+	return strings.Join(c.Addresses, ",")
+}
+
+// Dial 按配置创建到下游服务的连接（不等待连接建立）
+// idempotent 是可以安全重试的完整方法名，其他方法失败时不重试
+func Dial(config ConnConfig, idempotent ...string) (*grpc.ClientConn, error) {
+	if len(config.Addresses) == 0 {
+		return nil, fmt.Errorf("%s: no backend address", config.Service)
+	}
+	switch config.LoadBalancing {
+	case "":
+		config.LoadBalancing = LoadBalancingRoundRobin
+	case LoadBalancingPickFirst, LoadBalancingRoundRobin:
+	default:
+		return nil, fmt.Errorf("%s: unknown load balancing policy %q", config.Service, config.LoadBalancing)
+	}
+	if config.Retry.MaxAttempts > 1 && (config.Retry.Multiplier < 1 || config.Retry.Jitter < 0 || config.Retry.Jitter > 1) {
+		return nil, fmt.Errorf("%s: invalid retry policy: multiplier must be >= 1 and jitter in [0, 1]", config.Service)
+	}
+
+	if config.Breaker != nil {
+		if err := config.Breaker.validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", config.Service, err)
+		}
+	}
+
+	creds := insecure.NewCredentials()
+	if config.TLS != nil {
+		tlsConfig, err := config.TLS.build()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", config.Service, err)
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultServiceConfig(fmt.Sprintf(`{"loadBalancingConfig":[{%q:{}}]}`, config.LoadBalancing)),
+		grpc.WithUnaryInterceptor(newCallInterceptor(config, idempotent).intercept),
+	}
+	target := config.Addresses[0]
+	if len(config.Addresses) > 1 {
+		// 多个地址通过 manual resolver 交给负载均衡器
+		r := manual.NewBuilderWithScheme(fmt.Sprintf("static-%d", staticResolverSeq.Add(1)))
+		addrs := make([]resolver.Address, len(config.Addresses))
+		for i, a := range config.Addresses {
+			addrs[i] = resolver.Address{Addr: a}
+		}
+		r.InitialState(resolver.State{Addresses: addrs})
+		opts = append(opts, grpc.WithResolvers(r))
+		target = r.Scheme() + ":///" + config.Service
+	}
+
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: dial %s: %w", config.Service, config.Target(), err)
+	}
+	return conn, nil
+}
+
+// staticResolverSeq 为每个多地址连接生成唯一的 resolver scheme
+var staticResolverSeq atomic.Int64
+
+func (t *TLSConfig) build() (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName: t.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read tls ca: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls ca %s: no certificate found", t.CAFile)
+		}
+		config.RootCAs = pool
+	}
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return nil, fmt.Errorf("tls client certificate requires both cert and key files")
+	}
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load tls client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// Error 是下游调用失败的错误，所有客户端返回的 gRPC 错误都统一为该类型
+//
+// GRPCStatus 保留下游的状态码（status.Code(err) 可以取到），
+// DEADLINE_EXCEEDED / CANCELLED 分别满足 errors.Is(err, context.DeadlineExceeded / context.Canceled)，
+// 与管道对超时和取消的处理一致
+type Error struct {
+	Service  string
+	Method   string
+	Code     codes.Code
+	Message  string
+	Attempts int // 实际尝试的次数，熔断器打开导致调用没有发出时为 0
+	err      error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s %s: %s: %s (attempts=%d)", e.Service, e.Method, e.Code, e.Message, e.Attempts)
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// Is 把超时和取消映射为对应的 context 错误
+func (e *Error) Is(target error) bool {
+	switch target {
+	case context.DeadlineExceeded:
+		return e.Code == codes.DeadlineExceeded
+	case context.Canceled:
+		return e.Code == codes.Canceled
+	}
+	return false
+}
+
+// GRPCStatus 返回保留下游状态码的 gRPC 状态
+func (e *Error) GRPCStatus() *status.Status {
+	return status.New(e.Code, e.Error())
+}
+
+// callInterceptor 实现 ConnConfig 描述的超时、重试和错误映射
+type callInterceptor struct {
+	config     ConnConfig
+	idempotent map[string]bool
+	retryable  map[codes.Code]bool
+	breaker    *circuitBreaker // 为 nil 时不熔断
+}
+
+func newCallInterceptor(config ConnConfig, idempotent []string) *callInterceptor {
+	i := &callInterceptor{
+		config:     config,
+		idempotent: make(map[string]bool, len(idempotent)),
+		retryable:  make(map[codes.Code]bool, len(config.Retry.RetryableCodes)),
+	}
+	for _, m := range idempotent {
+		i.idempotent[m] = true
+	}
+	for _, c := range config.Retry.RetryableCodes {
+		i.retryable[c] = true
+	}
+	if config.Breaker != nil {
+		i.breaker = newCircuitBreaker(config.Service, *config.Breaker, config.BreakerObserver)
+	}
+	return i
+}
+
+func (i *callInterceptor) intercept(
+	ctx context.Context,
+	method string,
+	req, reply any,
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	timeout := i.config.CallTimeout
+	if t, ok := i.config.MethodTimeouts[method]; ok {
+		timeout = t
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	maxAttempts := 1
+	if i.idempotent[method] && i.config.Retry.MaxAttempts > 1 {
+		maxAttempts = i.config.Retry.MaxAttempts
+	}
+	var err error
+	attempt := 1
+	for ; ; attempt++ {
+		probe, allowed := i.allow()
+		if !allowed {
+			// 熔断器打开：第一次尝试直接失败，重试时返回上一次尝试的错误
+			if attempt == 1 {
+				return circuitOpenError(i.config.Service, method)
+			}
+			attempt--
+			break
+		}
+		var trailer metadata.MD
+		start := time.Now()
+		err = invoker(ctx, method, req, reply, cc, append(opts, grpc.Trailer(&trailer))...)
+		i.record(probe, err, time.Since(start))
+		if err == nil {
+			return nil
+		}
+		if attempt >= maxAttempts || !i.shouldRetry(ctx, err) {
+			break
+		}
+		backoff, ok := i.backoff(attempt, trailer)
+		if !ok {
+			break
+		}
+		// 剩余预算不足以等待退避后再尝试一次时，直接返回本次的错误
+		if deadline, has := ctx.Deadline(); has && time.Until(deadline) <= backoff {
+			break
+		}
+		log.Printf("service=%s method=%s attempt=%d code=%s retrying in %s",
+			i.config.Service, method, attempt, status.Code(err), backoff)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return toError(i.config.Service, method, attempt, ctx.Err())
+		}
+	}
+	return toError(i.config.Service, method, attempt, err)
+}
+
+// allow 判断熔断器是否放行一次尝试
+func (i *callInterceptor) allow() (probe, ok bool) {
+	if i.breaker == nil {
+		return false, true
+	}
+	return i.breaker.allow()
+}
+
+// record 把一次尝试的结果记入熔断器
+func (i *callInterceptor) record(probe bool, err error, elapsed time.Duration) {
+	if i.breaker != nil {
+		i.breaker.record(probe, err, elapsed)
+	}
+}
+
+// shouldRetry 判断失败的尝试是否可以重试
+func (i *callInterceptor) shouldRetry(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	code := status.Code(err)
+	return i.retryable[code] || code == codes.DeadlineExceeded
+}
+
+// backoff 返回第 attempt 次失败后的退避时间；服务端要求不再重试（负的 pushback）时返回 false
+func (i *callInterceptor) backoff(attempt int, trailer metadata.MD) (time.Duration, bool) {
+	p := i.config.Retry
+	d := float64(p.InitialBackoff)
+	for n := 1; n < attempt; n++ {
+		d *= p.Multiplier
+	}
+	if limit := float64(p.MaxBackoff); limit > 0 && d > limit {
+		d = limit
+	}
+	d *= 1 + p.Jitter*(2*rand.Float64()-1)
+	backoff := time.Duration(d)
+
+	if values := trailer.Get("grpc-retry-pushback-ms"); len(values) > 0 {
+		ms, err := strconv.ParseInt(values[0], 10, 64)
+		if err != nil || ms < 0 {
+			return 0, false
+		}
+		if pushback := time.Duration(ms) * time.Millisecond; pushback > backoff {
+			backoff = pushback
+		}
+	}
+	return backoff, true
+}
+
+// toError 把调用错误统一为 *Error
+func toError(service, method string, attempts int, err error) error {
+	st := status.Convert(err)
+	code := st.Code()
+	switch err {
+	case context.DeadlineExceeded:
+		code = codes.DeadlineExceeded
+	case context.Canceled:
+		code = codes.Canceled
+	}
+	return &Error{
+		Service:  service,
+		Method:   method,
+		Code:     code,
+		Message:  st.Message(),
+		Attempts: attempts,
+		err:      err,
+	}
+}
@@ -0,0 +1,431 @@
+package clients_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
+	"x-algorithm-go/home-mixer/internal/clients"
+	"x-algorithm-go/home-mixer/internal/selectors"
+	"x-algorithm-go/home-mixer/internal/sources"
+	"x-algorithm-go/proto/thunder"
+)
+
+// 用本地的替身服务检查下游客户端层（clients.Dial）的行为：
+// 多后端负载均衡、幂等调用的重试、超时和错误映射、服务端 pushback、mTLS、熔断器，
+// 以及管道 Source 阶段的对冲请求。
+// 替身服务实现 Thunder 的 InNetworkPostsService，监听在 127.0.0.1 的随机端口上。
+
+// standIn 是 Thunder 的替身服务，按配置返回错误或延迟响应
+type standIn struct {
+	thunder.UnimplementedInNetworkPostsServiceServer
+	calls    atomic.Int64
+	failures atomic.Int64 // 前 failures 次调用返回 code
+	code     codes.Code
+	pushback string // 失败时通过 trailer 返回的 grpc-retry-pushback-ms
+	delay    time.Duration
+	// slowEvery 不为 0 时，每 slowEvery 次调用中有一次额外等待 slowDelay（模拟尾部延迟）
+	slowEvery int64
+	slowDelay time.Duration
+}
+
+func (s *standIn) GetInNetworkPosts(ctx context.Context, req *thunder.GetInNetworkPostsRequest) (*thunder.GetInNetworkPostsResponse, error) {
+	n := s.calls.Add(1)
+	if s.failures.Add(-1) >= 0 {
+		if s.pushback != "" {
+			grpc.SetTrailer(ctx, metadata.Pairs("grpc-retry-pushback-ms", s.pushback))
+		}
+		return nil, status.Error(s.code, "stand-in failure")
+	}
+	delay := s.delay
+	if s.slowEvery > 0 && n%s.slowEvery == 0 {
+		delay += s.slowDelay
+	}
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	return &thunder.GetInNetworkPostsResponse{Posts: []*thunder.LightPost{{PostId: 1, AuthorId: int64(req.GetUserId())}}}, nil
+}
+
+var testRequest = &sources.GetInNetworkPostsRequest{UserID: 42, FollowingUserIDs: []uint64{1, 2}, MaxResults: 10}
+
+func TestDialRoundRobin(t *testing.T) {
+	// 两个后端 round_robin，其中一个的第一次调用返回 UNAVAILABLE：全部调用成功，两个后端都收到请求
+	a, b := &standIn{code: codes.Unavailable}, &standIn{}
+	a.failures.Store(1)
+	client := newClient(t, clients.DefaultConnConfig("thunder", serve(t, a, nil)+","+serve(t, b, nil)))
+	for i := 0; i < 20; i++ {
+		if _, err := client.GetInNetworkPosts(context.Background(), testRequest); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+	if a.calls.Load() < 5 || b.calls.Load() < 5 || a.calls.Load()+b.calls.Load() != 21 {
+		t.Errorf("backend calls a=%d b=%d, want both >= 5 and 21 in total (one retry)", a.calls.Load(), b.calls.Load())
+	}
+}
+
+func TestDialDeadline(t *testing.T) {
+	// 调用不超过 ctx 的剩余预算，错误映射为 DEADLINE_EXCEEDED 且满足 errors.Is(context.DeadlineExceeded)
+	client := newClient(t, clients.DefaultConnConfig("thunder", serve(t, &standIn{delay: 500 * time.Millisecond}, nil)))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.GetInNetworkPosts(ctx, testRequest)
+	elapsed := time.Since(start)
+	if !errors.Is(err, context.DeadlineExceeded) || status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("err=%v, want DEADLINE_EXCEEDED", err)
+	}
+	if elapsed > 300*time.Millisecond {
+		t.Errorf("call returned after %s, want within the 50ms budget", elapsed)
+	}
+}
+
+func TestDialRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     codes.Code
+		pushback string
+		attempts int
+	}{
+		// 不可重试的状态码只尝试一次，状态码保留
+		{name: "not retryable", code: codes.InvalidArgument, attempts: 1},
+		// 持续 UNAVAILABLE 时按 MaxAttempts 重试后放弃
+		{name: "gives up", code: codes.Unavailable, attempts: 3},
+		// 服务端通过负的 pushback 要求不要重试
+		{name: "pushback", code: codes.ResourceExhausted, pushback: "-1", attempts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &standIn{code: tt.code, pushback: tt.pushback}
+			backend.failures.Store(100)
+			client := newClient(t, clients.DefaultConnConfig("thunder", serve(t, backend, nil)))
+			_, err := client.GetInNetworkPosts(context.Background(), testRequest)
+			expectAttempts(t, err, tt.code, tt.attempts)
+			if got := backend.calls.Load(); got != int64(tt.attempts) {
+				t.Errorf("backend calls=%d, want %d", got, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestDialMTLS(t *testing.T) {
+	// 带客户端证书时成功，不带时连接失败
+	serverTLS, tlsConfig := writeCertificates(t, t.TempDir())
+	addr := serve(t, &standIn{}, serverTLS)
+
+	config := clients.DefaultConnConfig("thunder", addr)
+	config.TLS = tlsConfig
+	if _, err := newClient(t, config).GetInNetworkPosts(context.Background(), testRequest); err != nil {
+		t.Fatalf("with client certificate: %v", err)
+	}
+
+	config.TLS = &clients.TLSConfig{CAFile: tlsConfig.CAFile}
+	config.Retry.MaxAttempts = 1
+	if _, err := newClient(t, config).GetInNetworkPosts(context.Background(), testRequest); status.Code(err) != codes.Unavailable {
+		t.Fatalf("without client certificate: err=%v, want UNAVAILABLE", err)
+	}
+}
+
+func TestDialBreaker(t *testing.T) {
+	// 失败率：连续 5 次 UNAVAILABLE 后打开，打开期间的调用不再发出；OpenDuration 之后的探测调用成功，熔断器关闭
+	ctx := context.Background()
+	observer := &transitions{}
+	breaker := clients.BreakerConfig{
+		Window:         10 * time.Second,
+		MinRequests:    5,
+		ErrorRate:      0.5,
+		OpenDuration:   200 * time.Millisecond,
+		HalfOpenProbes: 1,
+	}
+	flaky := &standIn{code: codes.Unavailable}
+	flaky.failures.Store(5)
+	config := clients.DefaultConnConfig("thunder", serve(t, flaky, nil))
+	config.Retry.MaxAttempts = 1
+	config.Breaker = &breaker
+	config.BreakerObserver = observer
+	client := newClient(t, config)
+	for i := 0; i < 5; i++ {
+		client.GetInNetworkPosts(ctx, testRequest)
+	}
+
+	_, err := client.GetInNetworkPosts(ctx, testRequest)
+	var clientErr *clients.Error
+	if !errors.Is(err, clients.ErrCircuitOpen) || !errors.As(err, &clientErr) || clientErr.Attempts != 0 || status.Code(err) != codes.Unavailable {
+		t.Fatalf("err=%v, want circuit open (UNAVAILABLE, 0 attempts)", err)
+	}
+	if got := flaky.calls.Load(); got != 5 {
+		t.Fatalf("backend calls=%d, want 5 (no call while open)", got)
+	}
+
+	// 打开期间，使用该客户端的 Source 在管道中表现为组件失败
+	p := &home.CandidatePipeline{
+		Sources:  []home.Source{sources.NewThunderSource(client, 10)},
+		Selector: selectors.NewTopKScoreSelector(10),
+	}
+	recorder := &pipeline.EventRecorder{}
+	if _, err := p.ExecuteWithOptions(ctx, newQuery(0), pipeline.ExecuteOptions{Observer: recorder}); err != nil {
+		t.Fatalf("pipeline: %v", err)
+	}
+	if events := componentEvents(recorder, pipeline.StageSource); len(events) != 1 || !errors.Is(events[0].Err, clients.ErrCircuitOpen) {
+		t.Fatalf("source events %+v, want a circuit open failure", events)
+	}
+
+	time.Sleep(breaker.OpenDuration + 50*time.Millisecond)
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetInNetworkPosts(ctx, testRequest); err != nil {
+			t.Fatalf("recovery call %d: %v", i, err)
+		}
+	}
+	if got, want := observer.String(), "open,half_open,closed"; got != want {
+		t.Errorf("transitions %s, want %s", got, want)
+	}
+}
+
+func TestDialBreakerSlowCalls(t *testing.T) {
+	// 慢调用：3 次超过 SlowCallDuration 的成功调用后打开
+	config := clients.DefaultConnConfig("thunder", serve(t, &standIn{delay: 60 * time.Millisecond}, nil))
+	config.Breaker = &clients.BreakerConfig{
+		Window:           10 * time.Second,
+		MinRequests:      3,
+		ErrorRate:        0.5,
+		SlowCallDuration: 30 * time.Millisecond,
+		SlowCallRate:     0.5,
+		OpenDuration:     time.Minute,
+		HalfOpenProbes:   1,
+	}
+	client := newClient(t, config)
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetInNetworkPosts(context.Background(), testRequest); err != nil {
+			t.Fatalf("slow call %d: %v", i, err)
+		}
+	}
+	start := time.Now()
+	_, err := client.GetInNetworkPosts(context.Background(), testRequest)
+	if !errors.Is(err, clients.ErrCircuitOpen) {
+		t.Fatalf("err=%v, want circuit open", err)
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("open circuit returned after %s, want immediately", elapsed)
+	}
+}
+
+func TestHedging(t *testing.T) {
+	// Source 偶尔很慢时，超过对冲延迟后再发起一次调用，请求不再等待慢调用
+	backend := &standIn{delay: 2 * time.Millisecond, slowEvery: 25, slowDelay: 300 * time.Millisecond}
+	source := sources.NewThunderSource(newClient(t, clients.DefaultConnConfig("thunder", serve(t, backend, nil))), 10)
+	p := &home.CandidatePipeline{
+		Sources:  []home.Source{source},
+		Selector: selectors.NewTopKScoreSelector(10),
+		Hedging:  pipeline.Hedging{Components: []string{source.Name()}, MinDelay: 20 * time.Millisecond},
+	}
+
+	var hedged int
+	var slowest time.Duration
+	for i := 0; i < 200; i++ {
+		recorder := &pipeline.EventRecorder{}
+		start := time.Now()
+		if _, err := p.ExecuteWithOptions(context.Background(), newQuery(i), pipeline.ExecuteOptions{Observer: recorder}); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		elapsed := time.Since(start)
+		events := componentEvents(recorder, pipeline.StageSource)
+		if len(events) != 1 || events[0].Err != nil {
+			t.Fatalf("request %d: source events %+v", i, events)
+		}
+		if events[0].Hedged {
+			hedged++
+		}
+		// 前 20 个请求还没有足够的延迟样本，不对冲
+		if i >= 20 && elapsed > slowest {
+			slowest = elapsed
+		}
+	}
+	if hedged == 0 || hedged > 20 {
+		t.Errorf("hedged=%d, want some hedges and at most 20", hedged)
+	}
+	if slowest > 150*time.Millisecond {
+		t.Errorf("slowest request %s, want no request waiting for a 300ms slow call", slowest)
+	}
+}
+
+func newQuery(i int) *home.Query {
+	q := &home.Query{UserFeatures: home.UserFeatures{FollowedUserIDs: []int64{1, 2}}}
+	q.UserID = 42
+	q.RequestID = fmt.Sprintf("conn-test-%d", i)
+	return q
+}
+
+func componentEvents(recorder *pipeline.EventRecorder, stage string) []pipeline.ComponentEvent {
+	var out []pipeline.ComponentEvent
+	for _, e := range recorder.Components() {
+		if e.Stage == stage {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// transitions 记录熔断器的状态变化
+type transitions struct {
+	mu     sync.Mutex
+	states []string
+}
+
+func (t *transitions) BreakerStateChanged(_ string, _, to clients.BreakerState) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.states = append(t.states, to.String())
+}
+
+func (t *transitions) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return strings.Join(t.states, ",")
+}
+
+func newClient(t *testing.T, config clients.ConnConfig) sources.ThunderClient {
+	t.Helper()
+	client, err := clients.NewThunderClient(config)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	if closer, ok := client.(io.Closer); ok {
+		t.Cleanup(func() { closer.Close() })
+	}
+	return client
+}
+
+func expectAttempts(t *testing.T, err error, code codes.Code, attempts int) {
+	t.Helper()
+	var clientErr *clients.Error
+	if !errors.As(err, &clientErr) || clientErr.Code != code || clientErr.Attempts != attempts || status.Code(err) != code {
+		t.Fatalf("err=%v, want %s after %d attempts", err, code, attempts)
+	}
+}
+
+// serve 在 127.0.0.1 的随机端口上启动替身服务，返回监听地址；测试结束时停止
+func serve(t *testing.T, s *standIn, tlsConfig *tls.Config) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	var opts []grpc.ServerOption
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	server := grpc.NewServer(opts...)
+	thunder.RegisterInNetworkPostsServiceServer(server, s)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return lis.Addr().String()
+}
+
+// writeCertificates 生成 CA、服务端证书和客户端证书，返回要求客户端证书的服务端 TLS 配置和客户端的 TLS 文件配置
+func writeCertificates(t *testing.T, dir string) (*tls.Config, *clients.TLSConfig) {
+	t.Helper()
+	caKey, caCert, caPEM := newCertificate(t, "conn-test-ca", nil, nil, true)
+	serverKey, _, serverPEM := newCertificate(t, "127.0.0.1", caCert, caKey, false)
+	clientKey, _, clientPEM := newCertificate(t, "conn-test-client", caCert, caKey, false)
+
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		return path
+	}
+	serverCert, err := tls.X509KeyPair(serverPEM, keyPEM(t, serverKey))
+	if err != nil {
+		t.Fatalf("server key pair: %v", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+	serverTLS := &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	return serverTLS, &clients.TLSConfig{
+		CAFile:   write("ca.pem", caPEM),
+		CertFile: write("client.pem", clientPEM),
+		KeyFile:  write("client-key.pem", keyPEM(t, clientKey)),
+	}
+}
+
+var serial atomic.Int64
+
+// newCertificate 生成证书；parent 为 nil 时生成自签名的 CA
+func newCertificate(t *testing.T, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, isCA bool) (*ecdsa.PrivateKey, *x509.Certificate, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial.Add(1)),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if ip := net.ParseIP(name); ip != nil {
+		template.IPAddresses = []net.IP{ip}
+	}
+	if isCA {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	return key, cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func keyPEM(t *testing.T, key *ecdsa.PrivateKey) []byte {
+	t.Helper()
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
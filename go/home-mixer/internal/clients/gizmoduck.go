@@ -6,7 +6,6 @@ import (
 
 	"x-algorithm-go/home-mixer/internal/hydrators"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 // GizmoduckClientImpl 实现 GizmoduckClient 接口
@@ -16,15 +15,15 @@ type GizmoduckClientImpl struct {
 }
 
 // NewGizmoduckClient 创建一个新的 Gizmoduck 客户端
-func NewGizmoduckClient(address string) (hydrators.GizmoduckClient, error) {
-	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+func NewGizmoduckClient(config ConnConfig) (hydrators.GizmoduckClient, error) {
+	conn, err := Dial(config)
 	if err != nil {
 		return nil, fmt.Errorf("连接 Gizmoduck 服务失败: %w", err)
 	}
 
 	return &GizmoduckClientImpl{
 		conn:    conn,
-		address: address,
+		address: config.Target(),
 	}, nil
 }
 
@@ -9,10 +9,7 @@ import (
 
 // NewMockThunderClient creates a mock Thunder client for local testing
 func NewMockThunderClient() sources.ThunderClient {
-	return &ThunderClientImpl{
-		conn:   nil, // No real connection needed for mock
-		client: &thunderClientWrapper{conn: nil},
-	}
+	return &ThunderClientImpl{} // No real connection needed for mock
 }
 
 // NewMockPhoenixRetrievalClient creates a mock Phoenix Retrieval client
@@ -4,10 +4,10 @@ import (
 	"context"
 	"fmt"
 
-	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
+	"x-algorithm-go/home-mixer/internal/scorers"
 	"x-algorithm-go/home-mixer/internal/sources"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 // PhoenixRetrievalClientImpl 实现 PhoenixRetrievalClient 接口
@@ -17,23 +17,29 @@ type PhoenixRetrievalClientImpl struct {
 }
 
 // NewPhoenixRetrievalClient 创建一个新的 Phoenix 检索客户端
-func NewPhoenixRetrievalClient(address string) (sources.PhoenixRetrievalClient, error) {
-	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+func NewPhoenixRetrievalClient(config ConnConfig) (sources.PhoenixRetrievalClient, error) {
+	// 熔断在客户端接口上实现（包装整个调用），连接上不再重复统计
+	breaker, err := NewBreaker(config)
+	if err != nil {
+		return nil, err
+	}
+	config.Breaker = nil
+	conn, err := Dial(config)
 	if err != nil {
 		return nil, fmt.Errorf("连接 Phoenix 检索服务失败: %w", err)
 	}
 
-	return &PhoenixRetrievalClientImpl{
+	return WithPhoenixRetrievalBreaker(&PhoenixRetrievalClientImpl{
 		conn:    conn,
-		address: address,
-	}, nil
+		address: config.Target(),
+	}, breaker), nil
 }
 
 // Retrieve 实现 PhoenixRetrievalClient 接口
 func (c *PhoenixRetrievalClientImpl) Retrieve(
 	ctx context.Context,
 	userID uint64,
-	sequence *pipeline.UserActionSequence,
+	sequence *home.UserActionSequence,
 	maxResults int,
 ) (*sources.RetrievalResponse, error) {
 	// 用于本地学习/测试的模拟实现
@@ -81,34 +87,39 @@ func (c *PhoenixRetrievalClientImpl) Close() error {
 type PhoenixRankingClientImpl struct {
 	conn   *grpc.ClientConn
 	address string
+	mock    scorers.PhoenixRankingClient
 }
 
 // NewPhoenixRankingClient 创建一个新的 Phoenix 排序客户端
-func NewPhoenixRankingClient(address string) (*PhoenixRankingClientImpl, error) {
-	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+func NewPhoenixRankingClient(config ConnConfig) (scorers.PhoenixRankingClient, error) {
+	// 熔断在客户端接口上实现（包装整个调用），连接上不再重复统计
+	breaker, err := NewBreaker(config)
+	if err != nil {
+		return nil, err
+	}
+	config.Breaker = nil
+	conn, err := Dial(config)
 	if err != nil {
 		return nil, fmt.Errorf("连接 Phoenix 排序服务失败: %w", err)
 	}
 
-	return &PhoenixRankingClientImpl{
+	return WithPhoenixRankingBreaker(&PhoenixRankingClientImpl{
 		conn:    conn,
-		address: address,
-	}, nil
+		address: config.Target(),
+		mock:    scorers.NewMockPhoenixRankingClient(),
+	}, breaker), nil
 }
 
 // Rank 实现 PhoenixRankingClient 接口
 func (c *PhoenixRankingClientImpl) Rank(
 	ctx context.Context,
-	req interface{}, // TODO: 定义正确的请求类型
-) (interface{}, error) {
-	// 模拟实现 - 这通常会调用 Phoenix 排序服务
-	// 对于本地学习，我们返回模拟预测
-	_ = ctx
-	_ = req
-	return nil, fmt.Errorf("Phoenix 排序需要正确的请求类型 - 使用带有模拟客户端的 scorers.NewPhoenixScorer")
+	req *scorers.RankingRequest,
+) (*scorers.RankingResponse, error) {
+	// 用于本地学习/测试的模拟实现
+	// Phoenix 排序服务的协议尚未接入，预测与 scorers.MockPhoenixRankingClient 相同
+	return c.mock.Rank(ctx, req)
 }
 
-
 // Close 关闭 gRPC 连接
 func (c *PhoenixRankingClientImpl) Close() error {
 	if c.conn != nil {
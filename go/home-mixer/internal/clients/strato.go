@@ -4,11 +4,10 @@ import (
 	"context"
 	"fmt"
 
-	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 	"x-algorithm-go/home-mixer/internal/query_hydrators"
 	"x-algorithm-go/home-mixer/internal/side_effects"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 // StratoClientImpl 为查询增强器实现 StratoClient 接口
@@ -18,15 +17,15 @@ type StratoClientImpl struct {
 }
 
 // NewStratoClient 为查询增强器创建一个新的 Strato 客户端
-func NewStratoClient(address string) (query_hydrators.StratoClient, error) {
-	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+func NewStratoClient(config ConnConfig) (query_hydrators.StratoClient, error) {
+	conn, err := Dial(config)
 	if err != nil {
 		return nil, fmt.Errorf("连接 Strato 服务失败: %w", err)
 	}
 
 	return &StratoClientImpl{
 		conn:    conn,
-		address: address,
+		address: config.Target(),
 	}, nil
 }
 
@@ -34,7 +33,7 @@ func NewStratoClient(address string) (query_hydrators.StratoClient, error) {
 func (c *StratoClientImpl) GetUserFeatures(
 	ctx context.Context,
 	userID int64,
-) (*pipeline.UserFeatures, error) {
+) (*home.UserFeatures, error) {
 	// 用于本地学习/测试的模拟实现
 	// 返回测试用户特征（关注列表）
 	
@@ -47,7 +46,7 @@ func (c *StratoClientImpl) GetUserFeatures(
 		followedUserIDs[i] = userID + 100 + int64(i*10)
 	}
 	
-	features := &pipeline.UserFeatures{
+	features := &home.UserFeatures{
 		FollowedUserIDs: followedUserIDs,
 	}
 	return features, nil
@@ -68,15 +67,15 @@ type StratoClientForCacheImpl struct {
 }
 
 // NewStratoClientForCache 为副作用创建一个新的 Strato 客户端
-func NewStratoClientForCache(address string) (side_effects.StratoClient, error) {
-	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+func NewStratoClientForCache(config ConnConfig) (side_effects.StratoClient, error) {
+	conn, err := Dial(config)
 	if err != nil {
 		return nil, fmt.Errorf("连接 Strato 服务失败: %w", err)
 	}
 
 	return &StratoClientForCacheImpl{
 		conn:    conn,
-		address: address,
+		address: config.Target(),
 	}, nil
 }
 
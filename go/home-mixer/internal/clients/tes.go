@@ -6,7 +6,6 @@ import (
 
 	"x-algorithm-go/home-mixer/internal/hydrators"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 // TESClientImpl 实现 TweetEntityServiceClient 接口
@@ -16,16 +15,22 @@ type TESClientImpl struct {
 }
 
 // NewTESClient 创建一个新的 TES 客户端
-func NewTESClient(address string) (hydrators.TweetEntityServiceClient, error) {
-	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+func NewTESClient(config ConnConfig) (hydrators.TweetEntityServiceClient, error) {
+	// 熔断在客户端接口上实现（包装整个调用），连接上不再重复统计
+	breaker, err := NewBreaker(config)
+	if err != nil {
+		return nil, err
+	}
+	config.Breaker = nil
+	conn, err := Dial(config)
 	if err != nil {
 		return nil, fmt.Errorf("连接 TES 服务失败: %w", err)
 	}
 
-	return &TESClientImpl{
+	return WithTESBreaker(&TESClientImpl{
 		conn:    conn,
-		address: address,
-	}, nil
+		address: config.Target(),
+	}, breaker), nil
 }
 
 // GetTweetCoreDatas 实现 TweetEntityServiceClient 接口
@@ -4,58 +4,37 @@ import (
 	"context"
 	"fmt"
 
+	"google.golang.org/grpc"
 	"x-algorithm-go/home-mixer/internal/sources"
 	"x-algorithm-go/proto/thunder"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
-// InNetworkPostsServiceClient 是 Thunder 服务的客户端接口
-type InNetworkPostsServiceClient interface {
-	GetInNetworkPosts(ctx context.Context, req *thunder.GetInNetworkPostsRequest, opts ...grpc.CallOption) (*thunder.GetInNetworkPostsResponse, error)
-}
-
-// thunderClientWrapper 包装 gRPC 连接并实现 ThunderClient
-type thunderClientWrapper struct {
-	conn *grpc.ClientConn
-}
-
 // ThunderClientImpl 使用 gRPC 实现 ThunderClient 接口
 type ThunderClientImpl struct {
-	conn   *grpc.ClientConn
-	client InNetworkPostsServiceClient
+	conn   *grpc.ClientConn                    // 由 NewThunderClient 创建时负责关闭
+	client thunder.InNetworkPostsServiceClient // 为 nil 时返回模拟数据（见 NewMockThunderClient）
 }
 
 // NewThunderClient 创建一个新的 Thunder gRPC 客户端
-func NewThunderClient(address string) (sources.ThunderClient, error) {
-	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// GetInNetworkPosts 是只读调用，失败时按 config.Retry 重试
+func NewThunderClient(config ConnConfig) (sources.ThunderClient, error) {
+	conn, err := Dial(config, thunder.InNetworkPostsService_GetInNetworkPosts_FullMethodName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to Thunder service: %w", err)
 	}
 
-	// 创建一个实现接口的客户端包装器
-	client := &thunderClientWrapper{conn: conn}
-
 	return &ThunderClientImpl{
 		conn:   conn,
-		client: client,
+		client: thunder.NewInNetworkPostsServiceClient(conn),
 	}, nil
 }
 
-// GetInNetworkPosts 为包装器实现 ThunderClient 接口
-func (w *thunderClientWrapper) GetInNetworkPosts(ctx context.Context, req *thunder.GetInNetworkPostsRequest, opts ...grpc.CallOption) (*thunder.GetInNetworkPostsResponse, error) {
-	// 这是一个占位符实现
-	// 在生产环境中，应该使用 proto 生成的客户端：
-	// client := thunder.NewInNetworkPostsServiceClient(w.conn)
-	// return client.GetInNetworkPosts(ctx, req, opts...)
-	
-	// 目前返回空响应以允许编译
-	_ = ctx
-	_ = req
-	_ = opts
-	return &thunder.GetInNetworkPostsResponse{
-		Posts: []*thunder.LightPost{},
-	}, nil
+// NewThunderClientFromConn 使用已有的连接创建 Thunder 客户端（例如进程内的 bufconn 连接）
+// 连接由调用方负责关闭
+func NewThunderClientFromConn(conn grpc.ClientConnInterface) *ThunderClientImpl {
+	return &ThunderClientImpl{
+		client: thunder.NewInNetworkPostsServiceClient(conn),
+	}
 }
 
 // GetInNetworkPosts 实现 ThunderClient 接口
@@ -63,35 +42,61 @@ func (c *ThunderClientImpl) GetInNetworkPosts(
 	ctx context.Context,
 	req *sources.GetInNetworkPostsRequest,
 ) (*sources.GetInNetworkPostsResponse, error) {
-	// 用于本地学习/测试的模拟实现
-	// 返回来自关注用户的测试帖子
-	
-	_ = ctx
-	
-	// 生成来自关注用户的模拟帖子
+	if c.client == nil {
+		return mockInNetworkPosts(req), nil
+	}
+
+	excludeTweetIDs := make([]uint64, len(req.ExcludeTweetIDs))
+	for i, id := range req.ExcludeTweetIDs {
+		excludeTweetIDs[i] = uint64(id)
+	}
+	resp, err := c.client.GetInNetworkPosts(ctx, &thunder.GetInNetworkPostsRequest{
+		UserId:           req.UserID,
+		FollowingUserIds: req.FollowingUserIDs,
+		MaxResults:       uint32(req.MaxResults),
+		ExcludeTweetIds:  excludeTweetIDs,
+		Algorithm:        req.Algorithm,
+		Debug:            req.Debug,
+		IsVideoRequest:   req.IsVideoRequest,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	posts := make([]sources.LightPost, 0, len(resp.GetPosts()))
+	for _, post := range resp.GetPosts() {
+		posts = append(posts, sources.LightPost{
+			PostID:          post.GetPostId(),
+			AuthorID:        uint64(post.GetAuthorId()),
+			InReplyToPostID: post.InReplyToPostId,
+			ConversationID:  post.ConversationId,
+		})
+	}
+	return &sources.GetInNetworkPostsResponse{Posts: posts}, nil
+}
+
+// mockInNetworkPosts 返回来自关注用户的测试帖子，用于本地学习/测试
+func mockInNetworkPosts(req *sources.GetInNetworkPostsRequest) *sources.GetInNetworkPostsResponse {
 	posts := make([]sources.LightPost, 0)
 	currentTime := int64(1704067200) // 2024-01-01 00:00:00 UTC
-	
-	// 创建一些来自关注用户的测试帖子
+
 	for i, authorID := range req.FollowingUserIDs {
 		if i >= req.MaxResults {
 			break
 		}
-		
+
 		// 生成一个推文 ID（简单的雪花 ID）
 		tweetID := int64(authorID)*1000000 + currentTime + int64(i)
-		
+
 		posts = append(posts, sources.LightPost{
-			PostID:        tweetID,
-			AuthorID:      authorID,
+			PostID:          tweetID,
+			AuthorID:        authorID,
 			InReplyToPostID: nil,
-			ConversationID: &tweetID,
+			ConversationID:  &tweetID,
 		})
 	}
 
-	return &sources.GetInNetworkPostsResponse{
-		Posts: posts,
-	}, nil
+	return &sources.GetInNetworkPostsResponse{Posts: posts}
 }
 
 // Close 关闭 gRPC 连接
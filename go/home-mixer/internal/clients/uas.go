@@ -6,7 +6,6 @@ import (
 
 	"x-algorithm-go/home-mixer/internal/query_hydrators"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 // UASFetcherImpl 实现 UserActionSequenceFetcher 接口
@@ -16,15 +15,15 @@ type UASFetcherImpl struct {
 }
 
 // NewUASFetcher 创建一个新的 UAS 获取器客户端
-func NewUASFetcher(address string) (query_hydrators.UserActionSequenceFetcher, error) {
-	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+func NewUASFetcher(config ConnConfig) (query_hydrators.UserActionSequenceFetcher, error) {
+	conn, err := Dial(config)
 	if err != nil {
 		return nil, fmt.Errorf("连接 UAS 服务失败: %w", err)
 	}
 
 	return &UASFetcherImpl{
 		conn:    conn,
-		address: address,
+		address: config.Target(),
 	}, nil
 }
 
@@ -53,6 +52,7 @@ func (c *UASFetcherImpl) GetByUserID(
 		actions[i] = query_hydrators.UserActionData{
 			ActionType: actionType,
 			TweetID:    currentTime + int64(i*100),
+			AuthorID:   uint64(userID) + 100 + uint64(10*(i%10)), // 与 Strato 模拟的关注列表一致
 			Timestamp:  currentTime - int64(i*3600), // 动作分布在过去 20 小时内
 		}
 	}
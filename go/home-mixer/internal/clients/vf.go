@@ -6,7 +6,6 @@ import (
 
 	"x-algorithm-go/home-mixer/internal/hydrators"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 // VFClientImpl 实现 VisibilityFilteringClient 接口
@@ -16,15 +15,15 @@ type VFClientImpl struct {
 }
 
 // NewVFClient 创建一个新的可见性过滤客户端
-func NewVFClient(address string) (hydrators.VisibilityFilteringClient, error) {
-	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+func NewVFClient(config ConnConfig) (hydrators.VisibilityFilteringClient, error) {
+	conn, err := Dial(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to VF service: %w", err)
 	}
 
 	return &VFClientImpl{
 		conn:    conn,
-		address: address,
+		address: config.Target(),
 	}, nil
 }
 
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 	"x-algorithm-go/home-mixer/internal/utils"
 )
 
@@ -21,21 +22,25 @@ func NewAgeFilter(maxAge time.Duration) *AgeFilter {
 }
 
 // Filter 实现 Filter 接口
-func (f *AgeFilter) Filter(ctx context.Context, query *pipeline.Query, candidates []*pipeline.Candidate) (*pipeline.FilterResult, error) {
-	var kept []*pipeline.Candidate
-	var removed []*pipeline.Candidate
+func (f *AgeFilter) Filter(ctx context.Context, query *home.Query, candidates []*home.Candidate) (*home.FilterResult, error) {
+	var kept []*home.Candidate
+	var removed []*home.Candidate
+	var reasons []pipeline.RemovalReason
 
+	now := query.RequestTime()
 	for _, candidate := range candidates {
-		if utils.IsWithinAge(candidate.TweetID, f.MaxAge) {
+		if utils.IsWithinAgeAt(candidate.TweetID, f.MaxAge, now) {
 			kept = append(kept, candidate)
 		} else {
 			removed = append(removed, candidate)
+			reasons = append(reasons, ReasonTooOld)
 		}
 	}
 
-	return &pipeline.FilterResult{
+	return &home.FilterResult{
 		Kept:    kept,
 		Removed: removed,
+		Reasons: reasons,
 	}, nil
 }
 
@@ -45,6 +50,6 @@ func (f *AgeFilter) Name() string {
 }
 
 // Enable 决定是否启用（AgeFilter 总是启用）
-func (f *AgeFilter) Enable(query *pipeline.Query) bool {
+func (f *AgeFilter) Enable(query *home.Query) bool {
 	return true
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 )
 
 // AuthorSocialgraphFilter 移除来自屏蔽/静音作者的帖子
@@ -15,17 +16,18 @@ func NewAuthorSocialgraphFilter() *AuthorSocialgraphFilter {
 }
 
 // Filter 实现 Filter 接口
-func (f *AuthorSocialgraphFilter) Filter(ctx context.Context, query *pipeline.Query, candidates []*pipeline.Candidate) (*pipeline.FilterResult, error) {
+func (f *AuthorSocialgraphFilter) Filter(ctx context.Context, query *home.Query, candidates []*home.Candidate) (*home.FilterResult, error) {
 	// 早期返回优化：如果没有屏蔽和静音列表，直接返回所有候选（与Rust版本一致）
 	if len(query.UserFeatures.BlockedUserIDs) == 0 && len(query.UserFeatures.MutedUserIDs) == 0 {
-		return &pipeline.FilterResult{
+		return &home.FilterResult{
 			Kept:    candidates,
-			Removed: []*pipeline.Candidate{},
+			Removed: []*home.Candidate{},
 		}, nil
 	}
 
-	var kept []*pipeline.Candidate
-	var removed []*pipeline.Candidate
+	var kept []*home.Candidate
+	var removed []*home.Candidate
+	var reasons []pipeline.RemovalReason
 
 	// 构建屏蔽和静音作者ID集合（用于快速查找）
 	blockedSet := make(map[int64]bool)
@@ -41,17 +43,22 @@ func (f *AuthorSocialgraphFilter) Filter(ctx context.Context, query *pipeline.Qu
 	for _, candidate := range candidates {
 		authorID := int64(candidate.AuthorID)
 
-		// 检查作者是否被屏蔽或静音
-		if blockedSet[authorID] || mutedSet[authorID] {
+		// 检查作者是否被屏蔽或静音（屏蔽优先）
+		if blockedSet[authorID] {
 			removed = append(removed, candidate)
+			reasons = append(reasons, ReasonBlockedAuthor)
+		} else if mutedSet[authorID] {
+			removed = append(removed, candidate)
+			reasons = append(reasons, ReasonMutedAuthor)
 		} else {
 			kept = append(kept, candidate)
 		}
 	}
 
-	return &pipeline.FilterResult{
+	return &home.FilterResult{
 		Kept:    kept,
 		Removed: removed,
+		Reasons: reasons,
 	}, nil
 }
 
@@ -61,6 +68,12 @@ func (f *AuthorSocialgraphFilter) Name() string {
 }
 
 // Enable 决定是否启用（AuthorSocialgraphFilter 总是启用）
-func (f *AuthorSocialgraphFilter) Enable(query *pipeline.Query) bool {
+func (f *AuthorSocialgraphFilter) Enable(query *home.Query) bool {
 	return true
 }
+
+// FailurePolicy 返回失败策略
+// 屏蔽/静音作者过滤属于安全过滤，无法评估时丢弃全部候选
+func (f *AuthorSocialgraphFilter) FailurePolicy() pipeline.FailurePolicy {
+	return pipeline.FailClosed
+}
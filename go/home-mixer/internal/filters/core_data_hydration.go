@@ -5,10 +5,13 @@ import (
 	"strings"
 
 	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
+	"x-algorithm-go/home-mixer/internal/hydrators"
 )
 
 // CoreDataHydrationFilter 移除核心数据获取失败的候选
-// 检查 author_id 和 tweet_text 是否有效
+// 检查 author_id 和 tweet_text 是否有效；
+// CoreDataCandidateHydrator 超时或失败时使用单独的原因代码，便于区分依赖故障和数据缺失
 type CoreDataHydrationFilter struct{}
 
 // NewCoreDataHydrationFilter 创建新的 CoreDataHydrationFilter 实例
@@ -17,22 +20,32 @@ func NewCoreDataHydrationFilter() *CoreDataHydrationFilter {
 }
 
 // Filter 实现 Filter 接口
-func (f *CoreDataHydrationFilter) Filter(ctx context.Context, query *pipeline.Query, candidates []*pipeline.Candidate) (*pipeline.FilterResult, error) {
-	var kept []*pipeline.Candidate
-	var removed []*pipeline.Candidate
+func (f *CoreDataHydrationFilter) Filter(ctx context.Context, query *home.Query, candidates []*home.Candidate) (*home.FilterResult, error) {
+	var kept []*home.Candidate
+	var removed []*home.Candidate
+	var reasons []pipeline.RemovalReason
 
 	for _, candidate := range candidates {
+		// CoreDataCandidateHydrator 被放弃或失败，核心数据不可用
+		if candidate.HydrationMissing(hydrators.CoreDataHydratorName) {
+			removed = append(removed, candidate)
+			reasons = append(reasons, ReasonCoreDataUnavailable)
+			continue
+		}
+
 		// 检查 author_id 和 tweet_text 是否有效
 		if candidate.AuthorID == 0 || strings.TrimSpace(candidate.TweetText) == "" {
 			removed = append(removed, candidate)
+			reasons = append(reasons, ReasonMissingCoreData)
 		} else {
 			kept = append(kept, candidate)
 		}
 	}
 
-	return &pipeline.FilterResult{
+	return &home.FilterResult{
 		Kept:    kept,
 		Removed: removed,
+		Reasons: reasons,
 	}, nil
 }
 
@@ -42,6 +55,16 @@ func (f *CoreDataHydrationFilter) Name() string {
 }
 
 // Enable 决定是否启用（CoreDataHydrationFilter 总是启用）
-func (f *CoreDataHydrationFilter) Enable(query *pipeline.Query) bool {
+func (f *CoreDataHydrationFilter) Enable(query *home.Query) bool {
 	return true
 }
+
+// ReadFields 返回 Filter 读取的字段（用于构建时校验字段读写顺序）
+func (f *CoreDataHydrationFilter) ReadFields() []string {
+	return []string{"AuthorID", "TweetText", "MissingHydrations"}
+}
+
+// WriteFields 返回 nil：Filter 不修改候选
+func (f *CoreDataHydrationFilter) WriteFields() []string {
+	return nil
+}
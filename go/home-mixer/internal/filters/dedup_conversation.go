@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 )
 
 // DedupConversationFilter 对话去重，每个对话分支只保留分数最高的候选
@@ -15,9 +16,10 @@ func NewDedupConversationFilter() *DedupConversationFilter {
 }
 
 // Filter 实现 Filter 接口
-func (f *DedupConversationFilter) Filter(ctx context.Context, query *pipeline.Query, candidates []*pipeline.Candidate) (*pipeline.FilterResult, error) {
-	var kept []*pipeline.Candidate
-	var removed []*pipeline.Candidate
+func (f *DedupConversationFilter) Filter(ctx context.Context, query *home.Query, candidates []*home.Candidate) (*home.FilterResult, error) {
+	var kept []*home.Candidate
+	var removed []*home.Candidate
+	var reasons []pipeline.RemovalReason
 	
 	// 记录每个对话的最佳候选（conversation_id -> (index_in_kept, score)）
 	bestPerConversation := make(map[uint64]struct {
@@ -37,6 +39,7 @@ func (f *DedupConversationFilter) Filter(ctx context.Context, query *pipeline.Qu
 			if score > best.score {
 				// 当前候选分数更高，替换之前的
 				removed = append(removed, kept[best.index])
+				reasons = append(reasons, ReasonDuplicateConversation)
 				kept[best.index] = candidate
 				bestPerConversation[conversationID] = struct {
 					index int
@@ -45,6 +48,7 @@ func (f *DedupConversationFilter) Filter(ctx context.Context, query *pipeline.Qu
 			} else {
 				// 当前候选分数较低，移除
 				removed = append(removed, candidate)
+				reasons = append(reasons, ReasonDuplicateConversation)
 			}
 		} else {
 			// 第一次遇到这个对话，保留
@@ -57,15 +61,16 @@ func (f *DedupConversationFilter) Filter(ctx context.Context, query *pipeline.Qu
 		}
 	}
 
-	return &pipeline.FilterResult{
+	return &home.FilterResult{
 		Kept:    kept,
 		Removed: removed,
+		Reasons: reasons,
 	}, nil
 }
 
 // getConversationID 获取对话ID
 // 使用 ancestors 中的最小值，如果没有则使用 tweet_id
-func getConversationID(candidate *pipeline.Candidate) uint64 {
+func getConversationID(candidate *home.Candidate) uint64 {
 	if len(candidate.Ancestors) > 0 {
 		minID := candidate.Ancestors[0]
 		for _, id := range candidate.Ancestors[1:] {
@@ -84,6 +89,6 @@ func (f *DedupConversationFilter) Name() string {
 }
 
 // Enable 决定是否启用（DedupConversationFilter 总是启用）
-func (f *DedupConversationFilter) Enable(query *pipeline.Query) bool {
+func (f *DedupConversationFilter) Enable(query *home.Query) bool {
 	return true
 }
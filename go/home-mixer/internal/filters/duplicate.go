@@ -4,9 +4,11 @@ import (
 	"context"
 
 	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 )
 
 // DropDuplicatesFilter 移除重复的帖子（基于 tweet_id）
+// 跨 Source 的重复已由 Merger 合并（保留全部来源），这里兜底处理未配置 Merger 或同一 Source 内的重复
 type DropDuplicatesFilter struct{}
 
 // NewDropDuplicatesFilter 创建新的 DropDuplicatesFilter 实例
@@ -15,15 +17,17 @@ func NewDropDuplicatesFilter() *DropDuplicatesFilter {
 }
 
 // Filter 实现 Filter 接口
-func (f *DropDuplicatesFilter) Filter(ctx context.Context, query *pipeline.Query, candidates []*pipeline.Candidate) (*pipeline.FilterResult, error) {
+func (f *DropDuplicatesFilter) Filter(ctx context.Context, query *home.Query, candidates []*home.Candidate) (*home.FilterResult, error) {
 	seenIDs := make(map[int64]bool)
-	var kept []*pipeline.Candidate
-	var removed []*pipeline.Candidate
+	var kept []*home.Candidate
+	var removed []*home.Candidate
+	var reasons []pipeline.RemovalReason
 
 	for _, candidate := range candidates {
 		if seenIDs[candidate.TweetID] {
 			// 已经见过，移除
 			removed = append(removed, candidate)
+			reasons = append(reasons, ReasonDuplicate)
 		} else {
 			// 第一次见到，保留
 			seenIDs[candidate.TweetID] = true
@@ -31,9 +35,10 @@ func (f *DropDuplicatesFilter) Filter(ctx context.Context, query *pipeline.Query
 		}
 	}
 
-	return &pipeline.FilterResult{
+	return &home.FilterResult{
 		Kept:    kept,
 		Removed: removed,
+		Reasons: reasons,
 	}, nil
 }
 
@@ -43,6 +48,6 @@ func (f *DropDuplicatesFilter) Name() string {
 }
 
 // Enable 决定是否启用（DropDuplicatesFilter 总是启用）
-func (f *DropDuplicatesFilter) Enable(query *pipeline.Query) bool {
+func (f *DropDuplicatesFilter) Enable(query *home.Query) bool {
 	return true
 }
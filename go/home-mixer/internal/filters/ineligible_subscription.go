@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 )
 
 // IneligibleSubscriptionFilter 移除用户未订阅的订阅内容
@@ -16,15 +17,16 @@ func NewIneligibleSubscriptionFilter() *IneligibleSubscriptionFilter {
 }
 
 // Filter 实现 Filter 接口
-func (f *IneligibleSubscriptionFilter) Filter(ctx context.Context, query *pipeline.Query, candidates []*pipeline.Candidate) (*pipeline.FilterResult, error) {
+func (f *IneligibleSubscriptionFilter) Filter(ctx context.Context, query *home.Query, candidates []*home.Candidate) (*home.FilterResult, error) {
 	// 构建订阅用户ID集合（用于快速查找）
 	subscribedSet := make(map[uint64]bool)
 	for _, id := range query.UserFeatures.SubscribedUserIDs {
 		subscribedSet[uint64(id)] = true
 	}
 
-	var kept []*pipeline.Candidate
-	var removed []*pipeline.Candidate
+	var kept []*home.Candidate
+	var removed []*home.Candidate
+	var reasons []pipeline.RemovalReason
 
 	for _, candidate := range candidates {
 		// 如果没有订阅作者ID，保留（不是订阅内容）
@@ -39,12 +41,14 @@ func (f *IneligibleSubscriptionFilter) Filter(ctx context.Context, query *pipeli
 			kept = append(kept, candidate)
 		} else {
 			removed = append(removed, candidate)
+			reasons = append(reasons, ReasonIneligibleSubscription)
 		}
 	}
 
-	return &pipeline.FilterResult{
+	return &home.FilterResult{
 		Kept:    kept,
 		Removed: removed,
+		Reasons: reasons,
 	}, nil
 }
 
@@ -54,6 +58,22 @@ func (f *IneligibleSubscriptionFilter) Name() string {
 }
 
 // Enable 决定是否启用（IneligibleSubscriptionFilter 总是启用）
-func (f *IneligibleSubscriptionFilter) Enable(query *pipeline.Query) bool {
+func (f *IneligibleSubscriptionFilter) Enable(query *home.Query) bool {
 	return true
 }
+
+// ReadFields 返回 Filter 读取的字段（用于构建时校验字段读写顺序）
+func (f *IneligibleSubscriptionFilter) ReadFields() []string {
+	return []string{"SubscriptionAuthorID"}
+}
+
+// WriteFields 返回 nil：Filter 不修改候选
+func (f *IneligibleSubscriptionFilter) WriteFields() []string {
+	return nil
+}
+
+// FailurePolicy 返回失败策略
+// 付费订阅内容不能泄露给未订阅用户，无法评估时丢弃全部候选
+func (f *IneligibleSubscriptionFilter) FailurePolicy() pipeline.FailurePolicy {
+	return pipeline.FailClosed
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 	"x-algorithm-go/home-mixer/internal/utils"
 )
 
@@ -21,14 +22,14 @@ func NewMutedKeywordFilter() *MutedKeywordFilter {
 }
 
 // Filter 实现 Filter 接口
-func (f *MutedKeywordFilter) Filter(ctx context.Context, query *pipeline.Query, candidates []*pipeline.Candidate) (*pipeline.FilterResult, error) {
+func (f *MutedKeywordFilter) Filter(ctx context.Context, query *home.Query, candidates []*home.Candidate) (*home.FilterResult, error) {
 	mutedKeywords := query.UserFeatures.MutedKeywords
 
 	// 如果没有静音关键词，直接返回所有候选
 	if len(mutedKeywords) == 0 {
-		return &pipeline.FilterResult{
+		return &home.FilterResult{
 			Kept:    candidates,
-			Removed: []*pipeline.Candidate{},
+			Removed: []*home.Candidate{},
 		}, nil
 	}
 
@@ -45,8 +46,9 @@ func (f *MutedKeywordFilter) Filter(ctx context.Context, query *pipeline.Query,
 	userMutes := utils.NewUserMutes(tokenSequences)
 	matcher := utils.NewMatchTweetGroup(userMutes)
 
-	var kept []*pipeline.Candidate
-	var removed []*pipeline.Candidate
+	var kept []*home.Candidate
+	var removed []*home.Candidate
+	var reasons []pipeline.RemovalReason
 
 	// 检查每个候选
 	for _, candidate := range candidates {
@@ -58,15 +60,17 @@ func (f *MutedKeywordFilter) Filter(ctx context.Context, query *pipeline.Query,
 		if matcher.Matches(tweetTokenSequence) {
 			// 匹配静音关键词 - 应该被过滤掉
 			removed = append(removed, candidate)
+			reasons = append(reasons, ReasonMutedKeyword)
 		} else {
 			// 不匹配 - 保留
 			kept = append(kept, candidate)
 		}
 	}
 
-	return &pipeline.FilterResult{
+	return &home.FilterResult{
 		Kept:    kept,
 		Removed: removed,
+		Reasons: reasons,
 	}, nil
 }
 
@@ -76,6 +80,22 @@ func (f *MutedKeywordFilter) Name() string {
 }
 
 // Enable 决定是否启用（MutedKeywordFilter 总是启用）
-func (f *MutedKeywordFilter) Enable(query *pipeline.Query) bool {
+func (f *MutedKeywordFilter) Enable(query *home.Query) bool {
 	return true
 }
+
+// ReadFields 返回 Filter 读取的字段（用于构建时校验字段读写顺序）
+func (f *MutedKeywordFilter) ReadFields() []string {
+	return []string{"TweetText"}
+}
+
+// WriteFields 返回 nil：Filter 不修改候选
+func (f *MutedKeywordFilter) WriteFields() []string {
+	return nil
+}
+
+// FailurePolicy 返回失败策略
+// 静音关键词过滤属于安全过滤，无法评估时丢弃全部候选
+func (f *MutedKeywordFilter) FailurePolicy() pipeline.FailurePolicy {
+	return pipeline.FailClosed
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 	"x-algorithm-go/home-mixer/internal/utils"
 )
 
@@ -17,9 +18,10 @@ func NewPreviouslySeenPostsFilter() *PreviouslySeenPostsFilter {
 }
 
 // Filter 实现 Filter 接口
-func (f *PreviouslySeenPostsFilter) Filter(ctx context.Context, query *pipeline.Query, candidates []*pipeline.Candidate) (*pipeline.FilterResult, error) {
-	var kept []*pipeline.Candidate
-	var removed []*pipeline.Candidate
+func (f *PreviouslySeenPostsFilter) Filter(ctx context.Context, query *home.Query, candidates []*home.Candidate) (*home.FilterResult, error) {
+	var kept []*home.Candidate
+	var removed []*home.Candidate
+	var reasons []pipeline.RemovalReason
 
 	// 构建已看过的ID集合（用于快速查找）
 	seenIDsSet := make(map[int64]bool)
@@ -42,41 +44,43 @@ func (f *PreviouslySeenPostsFilter) Filter(ctx context.Context, query *pipeline.
 		relatedIDs := getRelatedPostIDs(candidate)
 
 		// 检查是否有任何相关ID在已看过的列表中或Bloom Filter中
-		shouldRemove := false
+		var reason pipeline.RemovalReason
 		for _, id := range relatedIDs {
 			// 首先检查精确的seen_ids
 			if seenIDsSet[id] {
-				shouldRemove = true
+				reason = ReasonPreviouslySeen
 				break
 			}
 
 			// 然后检查Bloom Filter
 			for _, bf := range bloomFilters {
 				if bf.MayContain(id) {
-					shouldRemove = true
+					reason = ReasonPreviouslySeenBloom
 					break
 				}
 			}
-			if shouldRemove {
+			if reason != "" {
 				break
 			}
 		}
 
-		if shouldRemove {
+		if reason != "" {
 			removed = append(removed, candidate)
+			reasons = append(reasons, reason)
 		} else {
 			kept = append(kept, candidate)
 		}
 	}
 
-	return &pipeline.FilterResult{
+	return &home.FilterResult{
 		Kept:    kept,
 		Removed: removed,
+		Reasons: reasons,
 	}, nil
 }
 
 // getRelatedPostIDs 获取候选相关的所有帖子ID
-func getRelatedPostIDs(candidate *pipeline.Candidate) []int64 {
+func getRelatedPostIDs(candidate *home.Candidate) []int64 {
 	ids := []int64{candidate.TweetID}
 	
 	if candidate.RetweetedTweetID != nil {
@@ -95,6 +99,6 @@ func (f *PreviouslySeenPostsFilter) Name() string {
 }
 
 // Enable 决定是否启用（PreviouslySeenPostsFilter 总是启用）
-func (f *PreviouslySeenPostsFilter) Enable(query *pipeline.Query) bool {
+func (f *PreviouslySeenPostsFilter) Enable(query *home.Query) bool {
 	return true
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 )
 
 // PreviouslyServedPostsFilter 移除本次会话中已经服务过的帖子
@@ -16,9 +17,10 @@ func NewPreviouslyServedPostsFilter() *PreviouslyServedPostsFilter {
 }
 
 // Filter 实现 Filter 接口
-func (f *PreviouslyServedPostsFilter) Filter(ctx context.Context, query *pipeline.Query, candidates []*pipeline.Candidate) (*pipeline.FilterResult, error) {
-	var kept []*pipeline.Candidate
-	var removed []*pipeline.Candidate
+func (f *PreviouslyServedPostsFilter) Filter(ctx context.Context, query *home.Query, candidates []*home.Candidate) (*home.FilterResult, error) {
+	var kept []*home.Candidate
+	var removed []*home.Candidate
+	var reasons []pipeline.RemovalReason
 
 	// 构建已服务的ID集合（用于快速查找）
 	servedIDsSet := make(map[int64]bool)
@@ -41,14 +43,16 @@ func (f *PreviouslyServedPostsFilter) Filter(ctx context.Context, query *pipelin
 
 		if shouldRemove {
 			removed = append(removed, candidate)
+			reasons = append(reasons, ReasonPreviouslyServed)
 		} else {
 			kept = append(kept, candidate)
 		}
 	}
 
-	return &pipeline.FilterResult{
+	return &home.FilterResult{
 		Kept:    kept,
 		Removed: removed,
+		Reasons: reasons,
 	}, nil
 }
 
@@ -58,6 +62,6 @@ func (f *PreviouslyServedPostsFilter) Name() string {
 }
 
 // Enable 决定是否启用（只在 is_bottom_request 时启用）
-func (f *PreviouslyServedPostsFilter) Enable(query *pipeline.Query) bool {
+func (f *PreviouslyServedPostsFilter) Enable(query *home.Query) bool {
 	return query.IsBottomRequest
 }
@@ -0,0 +1,26 @@
+package filters
+
+import "x-algorithm-go/candidate-pipeline/pipeline"
+
+// 各 Filter 的移除原因代码
+// 这些代码会出现在 PipelineResult.Removals 和 explain 输出中，供排障和监控使用
+// 修改已有代码的取值会影响下游的看板和告警，请只新增不修改
+const (
+	ReasonDuplicate              pipeline.RemovalReason = "duplicate"
+	ReasonMissingCoreData        pipeline.RemovalReason = "missing_core_data"
+	ReasonCoreDataUnavailable    pipeline.RemovalReason = "core_data_unavailable"
+	ReasonTooOld                 pipeline.RemovalReason = "too_old"
+	ReasonSelfTweet              pipeline.RemovalReason = "self_tweet"
+	ReasonDuplicateRetweet       pipeline.RemovalReason = "duplicate_retweet"
+	ReasonIneligibleSubscription pipeline.RemovalReason = "ineligible_subscription"
+	ReasonPreviouslySeen         pipeline.RemovalReason = "previously_seen"
+	ReasonPreviouslySeenBloom    pipeline.RemovalReason = "previously_seen_bloom"
+	ReasonPreviouslyServed       pipeline.RemovalReason = "previously_served"
+	ReasonMutedKeyword           pipeline.RemovalReason = "muted_keyword"
+	ReasonBlockedAuthor          pipeline.RemovalReason = "blocked_author"
+	ReasonMutedAuthor            pipeline.RemovalReason = "muted_author"
+	ReasonDuplicateConversation  pipeline.RemovalReason = "duplicate_conversation"
+)
+
+// vfReasonPrefix 是 VFFilter 原因代码的前缀，后接命中的可见性关键词（例如 "vf_spam"）
+const vfReasonPrefix = "vf_"
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 )
 
 // RetweetDeduplicationFilter 去重转发，只保留第一次出现的帖子
@@ -16,10 +17,11 @@ func NewRetweetDeduplicationFilter() *RetweetDeduplicationFilter {
 }
 
 // Filter 实现 Filter 接口
-func (f *RetweetDeduplicationFilter) Filter(ctx context.Context, query *pipeline.Query, candidates []*pipeline.Candidate) (*pipeline.FilterResult, error) {
+func (f *RetweetDeduplicationFilter) Filter(ctx context.Context, query *home.Query, candidates []*home.Candidate) (*home.FilterResult, error) {
 	seenTweetIDs := make(map[uint64]bool)
-	var kept []*pipeline.Candidate
-	var removed []*pipeline.Candidate
+	var kept []*home.Candidate
+	var removed []*home.Candidate
+	var reasons []pipeline.RemovalReason
 
 	for _, candidate := range candidates {
 		if candidate.RetweetedTweetID != nil {
@@ -28,6 +30,7 @@ func (f *RetweetDeduplicationFilter) Filter(ctx context.Context, query *pipeline
 			// 如果已经见过这个帖子（作为原帖或转发），则移除
 			if seenTweetIDs[retweetedID] {
 				removed = append(removed, candidate)
+				reasons = append(reasons, ReasonDuplicateRetweet)
 			} else {
 				seenTweetIDs[retweetedID] = true
 				kept = append(kept, candidate)
@@ -39,9 +42,10 @@ func (f *RetweetDeduplicationFilter) Filter(ctx context.Context, query *pipeline
 		}
 	}
 
-	return &pipeline.FilterResult{
+	return &home.FilterResult{
 		Kept:    kept,
 		Removed: removed,
+		Reasons: reasons,
 	}, nil
 }
 
@@ -51,6 +55,6 @@ func (f *RetweetDeduplicationFilter) Name() string {
 }
 
 // Enable 决定是否启用（RetweetDeduplicationFilter 总是启用）
-func (f *RetweetDeduplicationFilter) Enable(query *pipeline.Query) bool {
+func (f *RetweetDeduplicationFilter) Enable(query *home.Query) bool {
 	return true
 }
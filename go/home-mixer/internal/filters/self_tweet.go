@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 )
 
 // SelfTweetFilter 移除用户自己发的帖子
@@ -15,24 +16,27 @@ func NewSelfTweetFilter() *SelfTweetFilter {
 }
 
 // Filter 实现 Filter 接口
-func (f *SelfTweetFilter) Filter(ctx context.Context, query *pipeline.Query, candidates []*pipeline.Candidate) (*pipeline.FilterResult, error) {
+func (f *SelfTweetFilter) Filter(ctx context.Context, query *home.Query, candidates []*home.Candidate) (*home.FilterResult, error) {
 	viewerID := uint64(query.UserID)
-	var kept []*pipeline.Candidate
-	var removed []*pipeline.Candidate
+	var kept []*home.Candidate
+	var removed []*home.Candidate
+	var reasons []pipeline.RemovalReason
 
 	for _, candidate := range candidates {
 		if candidate.AuthorID == viewerID {
 			// 作者是查看者自己，移除
 			removed = append(removed, candidate)
+			reasons = append(reasons, ReasonSelfTweet)
 		} else {
 			// 保留
 			kept = append(kept, candidate)
 		}
 	}
 
-	return &pipeline.FilterResult{
+	return &home.FilterResult{
 		Kept:    kept,
 		Removed: removed,
+		Reasons: reasons,
 	}, nil
 }
 
@@ -42,6 +46,6 @@ func (f *SelfTweetFilter) Name() string {
 }
 
 // Enable 决定是否启用（SelfTweetFilter 总是启用）
-func (f *SelfTweetFilter) Enable(query *pipeline.Query) bool {
+func (f *SelfTweetFilter) Enable(query *home.Query) bool {
 	return true
 }
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 )
 
 // VFFilter 移除可见性过滤（Visibility Filtering）标记为不可见的帖子
@@ -17,30 +18,33 @@ func NewVFFilter() *VFFilter {
 }
 
 // Filter 实现 Filter 接口
-func (f *VFFilter) Filter(ctx context.Context, query *pipeline.Query, candidates []*pipeline.Candidate) (*pipeline.FilterResult, error) {
-	var kept []*pipeline.Candidate
-	var removed []*pipeline.Candidate
+func (f *VFFilter) Filter(ctx context.Context, query *home.Query, candidates []*home.Candidate) (*home.FilterResult, error) {
+	var kept []*home.Candidate
+	var removed []*home.Candidate
+	var reasons []pipeline.RemovalReason
 
 	for _, candidate := range candidates {
 		// 检查 visibility_reason，如果有且表示应该移除，则移除
-		if shouldDrop(candidate.VisibilityReason) {
+		if keyword, drop := shouldDrop(candidate.VisibilityReason); drop {
 			removed = append(removed, candidate)
+			reasons = append(reasons, pipeline.RemovalReason(vfReasonPrefix+keyword))
 		} else {
 			kept = append(kept, candidate)
 		}
 	}
 
-	return &pipeline.FilterResult{
+	return &home.FilterResult{
 		Kept:    kept,
 		Removed: removed,
+		Reasons: reasons,
 	}, nil
 }
 
-// shouldDrop 判断是否应该移除
+// shouldDrop 判断是否应该移除，并返回命中的关键词
 // 简化实现：如果 visibility_reason 不为空且包含特定关键词，则移除
-func shouldDrop(reason *string) bool {
+func shouldDrop(reason *string) (string, bool) {
 	if reason == nil || *reason == "" {
-		return false
+		return "", false
 	}
 	
 	reasonLower := strings.ToLower(*reason)
@@ -58,11 +62,11 @@ func shouldDrop(reason *string) bool {
 	
 	for _, keyword := range dropKeywords {
 		if strings.Contains(reasonLower, keyword) {
-			return true
+			return keyword, true
 		}
 	}
 	
-	return false
+	return "", false
 }
 
 // Name 返回 Filter 名称
@@ -71,6 +75,22 @@ func (f *VFFilter) Name() string {
 }
 
 // Enable 决定是否启用（VFFilter 总是启用）
-func (f *VFFilter) Enable(query *pipeline.Query) bool {
+func (f *VFFilter) Enable(query *home.Query) bool {
 	return true
 }
+
+// ReadFields 返回 Filter 读取的字段（用于构建时校验字段读写顺序）
+func (f *VFFilter) ReadFields() []string {
+	return []string{"VisibilityReason"}
+}
+
+// WriteFields 返回 nil：Filter 不修改候选
+func (f *VFFilter) WriteFields() []string {
+	return nil
+}
+
+// FailurePolicy 返回失败策略
+// 可见性过滤属于安全过滤，无法评估时丢弃全部候选
+func (f *VFFilter) FailurePolicy() pipeline.FailurePolicy {
+	return pipeline.FailClosed
+}
@@ -3,9 +3,13 @@ package hydrators
 import (
 	"context"
 
-	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 )
 
+// CoreDataHydratorName 是 CoreDataCandidateHydrator 的组件名
+// 下游组件通过 Candidate.HydrationMissing(CoreDataHydratorName) 判断核心数据是否缺失
+const CoreDataHydratorName = "CoreDataCandidateHydrator"
+
 // CoreDataCandidateHydrator 增强候选的核心数据（帖子内容、作者信息等）
 type CoreDataCandidateHydrator struct {
 	tesClient TweetEntityServiceClient
@@ -38,7 +42,7 @@ func NewCoreDataCandidateHydrator(client TweetEntityServiceClient) *CoreDataCand
 }
 
 // Hydrate 实现 Hydrator 接口
-func (h *CoreDataCandidateHydrator) Hydrate(ctx context.Context, query *pipeline.Query, candidates []*pipeline.Candidate) ([]*pipeline.Candidate, error) {
+func (h *CoreDataCandidateHydrator) Hydrate(ctx context.Context, query *home.Query, candidates []*home.Candidate) ([]*home.Candidate, error) {
 	// 提取所有 tweet_id
 	tweetIDs := make([]int64, len(candidates))
 	for i, c := range candidates {
@@ -53,14 +57,14 @@ func (h *CoreDataCandidateHydrator) Hydrate(ctx context.Context, query *pipeline
 
 	// 构建增强后的候选列表（保持顺序和数量一致）
 	// 与Rust版本一致：总是创建新的Candidate，只包含需要更新的字段
-	hydrated := make([]*pipeline.Candidate, len(candidates))
+	hydrated := make([]*home.Candidate, len(candidates))
 	for i, candidate := range candidates {
 		// 获取核心数据
 		coreData := coreDatas[candidate.TweetID]
 		
 		// 创建新的Candidate（与Rust版本的Default::default()语义一致）
 		// 只设置需要更新的字段，其他字段保持默认值
-		hydrated[i] = &pipeline.Candidate{}
+		hydrated[i] = &home.Candidate{}
 		
 		if coreData != nil {
 			// 更新字段（与Rust版本一致）
@@ -81,7 +85,7 @@ func (h *CoreDataCandidateHydrator) Hydrate(ctx context.Context, query *pipeline
 
 // Update 更新单个候选的增强字段
 // 注意：与Rust版本一致，不更新AuthorID（AuthorID在创建候选时已设置）
-func (h *CoreDataCandidateHydrator) Update(candidate *pipeline.Candidate, hydrated *pipeline.Candidate) {
+func (h *CoreDataCandidateHydrator) Update(candidate *home.Candidate, hydrated *home.Candidate) {
 	candidate.TweetText = hydrated.TweetText
 	// 注意：Rust版本不更新author_id，只更新以下字段
 	candidate.RetweetedTweetID = hydrated.RetweetedTweetID
@@ -90,7 +94,7 @@ func (h *CoreDataCandidateHydrator) Update(candidate *pipeline.Candidate, hydrat
 }
 
 // UpdateAll 批量更新候选的增强字段
-func (h *CoreDataCandidateHydrator) UpdateAll(candidates []*pipeline.Candidate, hydrated []*pipeline.Candidate) {
+func (h *CoreDataCandidateHydrator) UpdateAll(candidates []*home.Candidate, hydrated []*home.Candidate) {
 	if len(candidates) != len(hydrated) {
 		return
 	}
@@ -101,10 +105,20 @@ func (h *CoreDataCandidateHydrator) UpdateAll(candidates []*pipeline.Candidate,
 
 // Name 返回 Hydrator 名称
 func (h *CoreDataCandidateHydrator) Name() string {
-	return "CoreDataCandidateHydrator"
+	return CoreDataHydratorName
 }
 
 // Enable 决定是否启用（CoreDataCandidateHydrator 总是启用）
-func (h *CoreDataCandidateHydrator) Enable(query *pipeline.Query) bool {
+func (h *CoreDataCandidateHydrator) Enable(query *home.Query) bool {
 	return true
 }
+
+// ReadFields 返回 Hydrate 读取的字段（用于构建依赖图）
+func (h *CoreDataCandidateHydrator) ReadFields() []string {
+	return []string{"TweetID"}
+}
+
+// WriteFields 返回 Update 写入的字段（用于构建依赖图）
+func (h *CoreDataCandidateHydrator) WriteFields() []string {
+	return []string{"TweetText", "RetweetedTweetID", "RetweetedUserID", "InReplyToTweetID"}
+}
@@ -3,7 +3,7 @@ package hydrators
 import (
 	"context"
 
-	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 )
 
 // GizmoduckCandidateHydrator 增强候选的作者信息（用户名、粉丝数等）
@@ -47,7 +47,7 @@ func NewGizmoduckCandidateHydrator(client GizmoduckClient) *GizmoduckCandidateHy
 }
 
 // Hydrate 实现 Hydrator 接口
-func (h *GizmoduckCandidateHydrator) Hydrate(ctx context.Context, query *pipeline.Query, candidates []*pipeline.Candidate) ([]*pipeline.Candidate, error) {
+func (h *GizmoduckCandidateHydrator) Hydrate(ctx context.Context, query *home.Query, candidates []*home.Candidate) ([]*home.Candidate, error) {
 	// 收集所有需要查询的用户ID（作者和转发作者）
 	userIDsSet := make(map[int64]bool)
 	for _, candidate := range candidates {
@@ -70,11 +70,9 @@ func (h *GizmoduckCandidateHydrator) Hydrate(ctx context.Context, query *pipelin
 	}
 
 	// 构建增强后的候选列表（保持顺序和数量一致）
-	hydrated := make([]*pipeline.Candidate, len(candidates))
+	// 只填写本 hydrator 负责的字段，由管道通过 Update 合并
+	hydrated := home.NewCandidatePatches(len(candidates))
 	for i, candidate := range candidates {
-		// 克隆候选
-		hydrated[i] = candidate.Clone()
-
 		// 获取作者信息
 		authorID := int64(candidate.AuthorID)
 		if userResult, ok := users[authorID]; ok && userResult != nil && userResult.User != nil {
@@ -106,7 +104,7 @@ func (h *GizmoduckCandidateHydrator) Hydrate(ctx context.Context, query *pipelin
 }
 
 // Update 更新单个候选的增强字段
-func (h *GizmoduckCandidateHydrator) Update(candidate *pipeline.Candidate, hydrated *pipeline.Candidate) {
+func (h *GizmoduckCandidateHydrator) Update(candidate *home.Candidate, hydrated *home.Candidate) {
 	if hydrated.AuthorScreenName != nil {
 		candidate.AuthorScreenName = hydrated.AuthorScreenName
 	}
@@ -119,7 +117,7 @@ func (h *GizmoduckCandidateHydrator) Update(candidate *pipeline.Candidate, hydra
 }
 
 // UpdateAll 批量更新候选的增强字段
-func (h *GizmoduckCandidateHydrator) UpdateAll(candidates []*pipeline.Candidate, hydrated []*pipeline.Candidate) {
+func (h *GizmoduckCandidateHydrator) UpdateAll(candidates []*home.Candidate, hydrated []*home.Candidate) {
 	if len(candidates) != len(hydrated) {
 		return
 	}
@@ -134,6 +132,16 @@ func (h *GizmoduckCandidateHydrator) Name() string {
 }
 
 // Enable 决定是否启用（GizmoduckCandidateHydrator 总是启用）
-func (h *GizmoduckCandidateHydrator) Enable(query *pipeline.Query) bool {
+func (h *GizmoduckCandidateHydrator) Enable(query *home.Query) bool {
 	return true
 }
+
+// ReadFields 返回 Hydrate 读取的字段（用于构建依赖图）
+func (h *GizmoduckCandidateHydrator) ReadFields() []string {
+	return []string{"AuthorID", "RetweetedUserID"}
+}
+
+// WriteFields 返回 Update 写入的字段（用于构建依赖图）
+func (h *GizmoduckCandidateHydrator) WriteFields() []string {
+	return []string{"AuthorScreenName", "AuthorFollowersCount", "RetweetedScreenName"}
+}
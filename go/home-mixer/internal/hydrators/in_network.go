@@ -3,7 +3,7 @@ package hydrators
 import (
 	"context"
 
-	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 )
 
 // InNetworkCandidateHydrator 标记候选是否为站内内容（来自关注账号）
@@ -15,7 +15,7 @@ func NewInNetworkCandidateHydrator() *InNetworkCandidateHydrator {
 }
 
 // Hydrate 实现 Hydrator 接口
-func (h *InNetworkCandidateHydrator) Hydrate(ctx context.Context, query *pipeline.Query, candidates []*pipeline.Candidate) ([]*pipeline.Candidate, error) {
+func (h *InNetworkCandidateHydrator) Hydrate(ctx context.Context, query *home.Query, candidates []*home.Candidate) ([]*home.Candidate, error) {
 	// 构建关注列表集合（用于快速查找）
 	followedSet := make(map[int64]bool)
 	for _, id := range query.UserFeatures.FollowedUserIDs {
@@ -24,11 +24,9 @@ func (h *InNetworkCandidateHydrator) Hydrate(ctx context.Context, query *pipelin
 
 	// 构建增强后的候选列表（保持顺序和数量一致）
 	viewerID := int64(query.UserID)
-	hydrated := make([]*pipeline.Candidate, len(candidates))
+	// 只填写本 hydrator 负责的字段，由管道通过 Update 合并
+	hydrated := home.NewCandidatePatches(len(candidates))
 	for i, candidate := range candidates {
-		// 克隆候选
-		hydrated[i] = candidate.Clone()
-
 		// 判断是否为站内内容（作者在关注列表中，或者是自己的帖子）
 		authorID := int64(candidate.AuthorID)
 		isSelf := authorID == viewerID
@@ -40,14 +38,14 @@ func (h *InNetworkCandidateHydrator) Hydrate(ctx context.Context, query *pipelin
 }
 
 // Update 更新单个候选的增强字段
-func (h *InNetworkCandidateHydrator) Update(candidate *pipeline.Candidate, hydrated *pipeline.Candidate) {
+func (h *InNetworkCandidateHydrator) Update(candidate *home.Candidate, hydrated *home.Candidate) {
 	if hydrated.InNetwork != nil {
 		candidate.InNetwork = hydrated.InNetwork
 	}
 }
 
 // UpdateAll 批量更新候选的增强字段
-func (h *InNetworkCandidateHydrator) UpdateAll(candidates []*pipeline.Candidate, hydrated []*pipeline.Candidate) {
+func (h *InNetworkCandidateHydrator) UpdateAll(candidates []*home.Candidate, hydrated []*home.Candidate) {
 	if len(candidates) != len(hydrated) {
 		return
 	}
@@ -62,6 +60,16 @@ func (h *InNetworkCandidateHydrator) Name() string {
 }
 
 // Enable 决定是否启用（InNetworkCandidateHydrator 总是启用）
-func (h *InNetworkCandidateHydrator) Enable(query *pipeline.Query) bool {
+func (h *InNetworkCandidateHydrator) Enable(query *home.Query) bool {
 	return true
 }
+
+// ReadFields 返回 Hydrate 读取的字段（用于构建依赖图）
+func (h *InNetworkCandidateHydrator) ReadFields() []string {
+	return []string{"AuthorID"}
+}
+
+// WriteFields 返回 Update 写入的字段（用于构建依赖图）
+func (h *InNetworkCandidateHydrator) WriteFields() []string {
+	return []string{"InNetwork"}
+}
@@ -3,7 +3,7 @@ package hydrators
 import (
 	"context"
 
-	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 )
 
 // SubscriptionHydrator 增强候选的订阅状态信息
@@ -19,7 +19,7 @@ func NewSubscriptionHydrator(client TweetEntityServiceClient) *SubscriptionHydra
 }
 
 // Hydrate 实现 Hydrator 接口
-func (h *SubscriptionHydrator) Hydrate(ctx context.Context, query *pipeline.Query, candidates []*pipeline.Candidate) ([]*pipeline.Candidate, error) {
+func (h *SubscriptionHydrator) Hydrate(ctx context.Context, query *home.Query, candidates []*home.Candidate) ([]*home.Candidate, error) {
 	// 提取所有 tweet_id
 	tweetIDs := make([]int64, len(candidates))
 	for i, c := range candidates {
@@ -33,11 +33,9 @@ func (h *SubscriptionHydrator) Hydrate(ctx context.Context, query *pipeline.Quer
 	}
 
 	// 构建增强后的候选列表（保持顺序和数量一致）
-	hydrated := make([]*pipeline.Candidate, len(candidates))
+	// 只填写本 hydrator 负责的字段，由管道通过 Update 合并
+	hydrated := home.NewCandidatePatches(len(candidates))
 	for i, candidate := range candidates {
-		// 克隆候选
-		hydrated[i] = candidate.Clone()
-
 		// 获取订阅作者ID
 		if authorID, ok := subscriptionAuthorIDs[candidate.TweetID]; ok && authorID != nil {
 			hydrated[i].SubscriptionAuthorID = authorID
@@ -48,14 +46,14 @@ func (h *SubscriptionHydrator) Hydrate(ctx context.Context, query *pipeline.Quer
 }
 
 // Update 更新单个候选的增强字段
-func (h *SubscriptionHydrator) Update(candidate *pipeline.Candidate, hydrated *pipeline.Candidate) {
+func (h *SubscriptionHydrator) Update(candidate *home.Candidate, hydrated *home.Candidate) {
 	if hydrated.SubscriptionAuthorID != nil {
 		candidate.SubscriptionAuthorID = hydrated.SubscriptionAuthorID
 	}
 }
 
 // UpdateAll 批量更新候选的增强字段
-func (h *SubscriptionHydrator) UpdateAll(candidates []*pipeline.Candidate, hydrated []*pipeline.Candidate) {
+func (h *SubscriptionHydrator) UpdateAll(candidates []*home.Candidate, hydrated []*home.Candidate) {
 	if len(candidates) != len(hydrated) {
 		return
 	}
@@ -70,6 +68,16 @@ func (h *SubscriptionHydrator) Name() string {
 }
 
 // Enable 决定是否启用（SubscriptionHydrator 总是启用）
-func (h *SubscriptionHydrator) Enable(query *pipeline.Query) bool {
+func (h *SubscriptionHydrator) Enable(query *home.Query) bool {
 	return true
 }
+
+// ReadFields 返回 Hydrate 读取的字段（用于构建依赖图）
+func (h *SubscriptionHydrator) ReadFields() []string {
+	return []string{"TweetID"}
+}
+
+// WriteFields 返回 Update 写入的字段（用于构建依赖图）
+func (h *SubscriptionHydrator) WriteFields() []string {
+	return []string{"SubscriptionAuthorID"}
+}
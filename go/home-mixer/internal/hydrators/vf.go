@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 )
 
 // VFCandidateHydrator 增强候选的可见性信息（Visibility Filtering）
@@ -26,7 +27,7 @@ func NewVFCandidateHydrator(client VisibilityFilteringClient) *VFCandidateHydrat
 }
 
 // Hydrate 实现 Hydrator 接口
-func (h *VFCandidateHydrator) Hydrate(ctx context.Context, query *pipeline.Query, candidates []*pipeline.Candidate) ([]*pipeline.Candidate, error) {
+func (h *VFCandidateHydrator) Hydrate(ctx context.Context, query *home.Query, candidates []*home.Candidate) ([]*home.Candidate, error) {
 	if len(candidates) == 0 {
 		return candidates, nil
 	}
@@ -81,11 +82,9 @@ func (h *VFCandidateHydrator) Hydrate(ctx context.Context, query *pipeline.Query
 	}
 
 	// 构建增强后的候选列表（保持顺序和数量一致）
-	hydrated := make([]*pipeline.Candidate, len(candidates))
+	// 只填写本 hydrator 负责的字段，由管道通过 Update 合并
+	hydrated := home.NewCandidatePatches(len(candidates))
 	for i, candidate := range candidates {
-		// 克隆候选
-		hydrated[i] = candidate.Clone()
-
 		// 获取可见性原因
 		if reason, ok := visibilityResults[candidate.TweetID]; ok {
 			hydrated[i].VisibilityReason = reason
@@ -96,14 +95,14 @@ func (h *VFCandidateHydrator) Hydrate(ctx context.Context, query *pipeline.Query
 }
 
 // Update 更新单个候选的增强字段
-func (h *VFCandidateHydrator) Update(candidate *pipeline.Candidate, hydrated *pipeline.Candidate) {
+func (h *VFCandidateHydrator) Update(candidate *home.Candidate, hydrated *home.Candidate) {
 	if hydrated.VisibilityReason != nil {
 		candidate.VisibilityReason = hydrated.VisibilityReason
 	}
 }
 
 // UpdateAll 批量更新候选的增强字段
-func (h *VFCandidateHydrator) UpdateAll(candidates []*pipeline.Candidate, hydrated []*pipeline.Candidate) {
+func (h *VFCandidateHydrator) UpdateAll(candidates []*home.Candidate, hydrated []*home.Candidate) {
 	if len(candidates) != len(hydrated) {
 		return
 	}
@@ -118,6 +117,22 @@ func (h *VFCandidateHydrator) Name() string {
 }
 
 // Enable 决定是否启用（VFCandidateHydrator 总是启用）
-func (h *VFCandidateHydrator) Enable(query *pipeline.Query) bool {
+func (h *VFCandidateHydrator) Enable(query *home.Query) bool {
 	return true
 }
+
+// ReadFields 返回 Hydrate 读取的字段（用于构建依赖图）
+func (h *VFCandidateHydrator) ReadFields() []string {
+	return []string{"TweetID", "InNetwork"}
+}
+
+// WriteFields 返回 Update 写入的字段（用于构建依赖图）
+func (h *VFCandidateHydrator) WriteFields() []string {
+	return []string{"VisibilityReason"}
+}
+
+// FailurePolicy 返回失败策略
+// 没有可见性结果时 VFFilter 会放过所有候选，因此失败时丢弃全部候选
+func (h *VFCandidateHydrator) FailurePolicy() pipeline.FailurePolicy {
+	return pipeline.FailClosed
+}
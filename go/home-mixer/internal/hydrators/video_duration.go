@@ -3,7 +3,7 @@ package hydrators
 import (
 	"context"
 
-	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 )
 
 // VideoDurationCandidateHydrator 增强候选的视频时长信息
@@ -37,7 +37,7 @@ type VideoInfo struct {
 }
 
 // Hydrate 实现 Hydrator 接口
-func (h *VideoDurationCandidateHydrator) Hydrate(ctx context.Context, query *pipeline.Query, candidates []*pipeline.Candidate) ([]*pipeline.Candidate, error) {
+func (h *VideoDurationCandidateHydrator) Hydrate(ctx context.Context, query *home.Query, candidates []*home.Candidate) ([]*home.Candidate, error) {
 	// 提取所有 tweet_id
 	tweetIDs := make([]int64, len(candidates))
 	for i, c := range candidates {
@@ -51,11 +51,9 @@ func (h *VideoDurationCandidateHydrator) Hydrate(ctx context.Context, query *pip
 	}
 
 	// 构建增强后的候选列表（保持顺序和数量一致）
-	hydrated := make([]*pipeline.Candidate, len(candidates))
+	// 只填写本 hydrator 负责的字段，由管道通过 Update 合并
+	hydrated := home.NewCandidatePatches(len(candidates))
 	for i, candidate := range candidates {
-		// 克隆候选
-		hydrated[i] = candidate.Clone()
-
 		// 获取媒体实体
 		mediaEntities := mediaEntitiesMap[candidate.TweetID]
 		if mediaEntities != nil {
@@ -74,14 +72,14 @@ func (h *VideoDurationCandidateHydrator) Hydrate(ctx context.Context, query *pip
 }
 
 // Update 更新单个候选的增强字段
-func (h *VideoDurationCandidateHydrator) Update(candidate *pipeline.Candidate, hydrated *pipeline.Candidate) {
+func (h *VideoDurationCandidateHydrator) Update(candidate *home.Candidate, hydrated *home.Candidate) {
 	if hydrated.VideoDurationMs != nil {
 		candidate.VideoDurationMs = hydrated.VideoDurationMs
 	}
 }
 
 // UpdateAll 批量更新候选的增强字段
-func (h *VideoDurationCandidateHydrator) UpdateAll(candidates []*pipeline.Candidate, hydrated []*pipeline.Candidate) {
+func (h *VideoDurationCandidateHydrator) UpdateAll(candidates []*home.Candidate, hydrated []*home.Candidate) {
 	if len(candidates) != len(hydrated) {
 		return
 	}
@@ -96,6 +94,16 @@ func (h *VideoDurationCandidateHydrator) Name() string {
 }
 
 // Enable 决定是否启用（VideoDurationCandidateHydrator 总是启用）
-func (h *VideoDurationCandidateHydrator) Enable(query *pipeline.Query) bool {
+func (h *VideoDurationCandidateHydrator) Enable(query *home.Query) bool {
 	return true
 }
+
+// ReadFields 返回 Hydrate 读取的字段（用于构建依赖图）
+func (h *VideoDurationCandidateHydrator) ReadFields() []string {
+	return []string{"TweetID"}
+}
+
+// WriteFields 返回 Update 写入的字段（用于构建依赖图）
+func (h *VideoDurationCandidateHydrator) WriteFields() []string {
+	return []string{"VideoDurationMs"}
+}
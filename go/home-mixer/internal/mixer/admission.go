@@ -0,0 +1,305 @@
+package mixer
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
+)
+
+// AdmissionConfig 配置请求合并和并发控制
+type AdmissionConfig struct {
+	// MaxConcurrent 是同时执行的管道上限，<= 0 表示不限制
+	MaxConcurrent int
+	// BottomRequestShare 是分页请求（IsBottomRequest）最多占用的并发比例（0-1]，
+	// 剩余的并发留给首页请求，过载时先拒绝分页请求
+	BottomRequestShare float64
+	// RetryAfter 是被拒绝的请求建议客户端等待的时间（首页请求），分页请求加倍
+	RetryAfter time.Duration
+	// Coalesce 为 true 时合并同一用户完全相同的在途请求，只执行一次管道
+	Coalesce bool
+	// CoalescedTimeout 是合并执行的超时上限：合并执行不随单个调用方取消，避免一个客户端断开导致其他调用方失败，
+	// 所有调用方都放弃等待（deadline 到期或取消）时提前取消
+	CoalescedTimeout time.Duration
+}
+
+// DefaultAdmissionConfig 返回默认的请求合并和并发控制配置
+func DefaultAdmissionConfig() AdmissionConfig {
+	return AdmissionConfig{
+		MaxConcurrent:      256,
+		BottomRequestShare: 0.8,
+		RetryAfter:         200 * time.Millisecond,
+		Coalesce:           true,
+		CoalescedTimeout:   2 * time.Second,
+	}
+}
+
+// 请求优先级，用于指标和日志
+const (
+	PriorityTop    = "top"
+	PriorityBottom = "bottom"
+)
+
+// AdmissionOutcome 是请求经过准入控制的结果
+type AdmissionOutcome string
+
+const (
+	AdmissionAdmitted  AdmissionOutcome = "admitted"  // 获得并发额度并执行管道
+	AdmissionCoalesced AdmissionOutcome = "coalesced" // 与在途的相同请求合并，共享其结果
+	AdmissionShed      AdmissionOutcome = "shed"      // 并发已满，被拒绝
+)
+
+// AdmissionObserver 接收准入控制的结果（例如导出为指标）
+type AdmissionObserver interface {
+	Admission(priority string, outcome AdmissionOutcome)
+}
+
+// OverloadedError 表示服务过载，请求被拒绝；服务返回 RESOURCE_EXHAUSTED 并附带 RetryAfter
+type OverloadedError struct {
+	Priority   string
+	InFlight   int
+	Limit      int
+	RetryAfter time.Duration
+}
+
+func (e *OverloadedError) Error() string {
+	return fmt.Sprintf("server overloaded: priority=%s in_flight=%d limit=%d retry_after=%s",
+		e.Priority, e.InFlight, e.Limit, e.RetryAfter)
+}
+
+// Admission 在执行管道之前做准入控制
+//
+//   - 合并：同一用户完全相同的在途请求（客户端重试、重复触发）只执行一次管道，其余调用方共享结果
+//   - 并发上限：同时执行的管道数超过 MaxConcurrent 时直接拒绝，首页请求可以使用全部并发，
+//     分页请求只能使用 BottomRequestShare 比例的并发
+//
+// 被合并的请求不占用并发额度。nil 的 *Admission 不做任何控制。
+type Admission struct {
+	config   AdmissionConfig
+	observer AdmissionObserver
+
+	mu       sync.Mutex
+	inFlight int
+
+	flightsMu sync.Mutex
+	flights   map[string]*flight // 合并键 -> 在途的合并执行
+}
+
+// flight 是一次合并执行
+type flight struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{} // 执行结束后关闭，之后 result 和 err 只读
+	result  *home.PipelineResult
+	err     error
+	waiters int // 仍在等待的调用方数，由 Admission.flightsMu 保护
+}
+
+// NewAdmission 创建 Admission，observer 可以为 nil
+func NewAdmission(config AdmissionConfig, observer AdmissionObserver) (*Admission, error) {
+	if config.MaxConcurrent > 0 && (config.BottomRequestShare <= 0 || config.BottomRequestShare > 1) {
+		return nil, fmt.Errorf("bottom request share must be in (0, 1], got %v", config.BottomRequestShare)
+	}
+	if config.RetryAfter < 0 {
+		return nil, fmt.Errorf("retry after must be >= 0, got %s", config.RetryAfter)
+	}
+	if config.Coalesce && config.CoalescedTimeout <= 0 {
+		return nil, fmt.Errorf("coalesced timeout must be > 0, got %s", config.CoalescedTimeout)
+	}
+	return &Admission{config: config, observer: observer, flights: make(map[string]*flight)}, nil
+}
+
+// Do 在准入控制下执行 run
+// 相同的在途请求共享同一次执行的结果（共享的结果只读）；并发已满时返回 *OverloadedError
+func (a *Admission) Do(
+	ctx context.Context,
+	query *home.Query,
+	run func(ctx context.Context) (*home.PipelineResult, error),
+) (*home.PipelineResult, error) {
+	if a == nil {
+		return run(ctx)
+	}
+	priority := requestPriority(query)
+	if !a.config.Coalesce {
+		return a.admit(ctx, priority, run)
+	}
+
+	// 合并执行不随单个调用方取消（保留发起者 ctx 中的值，例如追踪的 span），超时为 CoalescedTimeout；
+	// 每个调用方仍然按自己的 ctx 放弃等待，最后一个调用方放弃时取消执行，
+	// 因此执行占用并发额度的时间不超过等待的调用方中最晚的 deadline
+	key := coalesceKey(query)
+	f, started := a.join(ctx, key)
+	if started {
+		go a.execute(key, f, priority, run)
+	}
+	select {
+	case <-f.done:
+		a.leave(key, f)
+		if !started {
+			a.report(priority, AdmissionCoalesced)
+		}
+		return f.result, f.err
+	case <-ctx.Done():
+		a.leave(key, f)
+		return nil, ctx.Err()
+	}
+}
+
+// join 把调用方加入 key 的合并执行，没有在途的执行时创建一个（started 为 true，由调用方启动）
+func (a *Admission) join(ctx context.Context, key string) (f *flight, started bool) {
+	a.flightsMu.Lock()
+	defer a.flightsMu.Unlock()
+	f, ok := a.flights[key]
+	if !ok {
+		fctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), a.config.CoalescedTimeout)
+		f = &flight{ctx: fctx, cancel: cancel, done: make(chan struct{})}
+		a.flights[key] = f
+	}
+	f.waiters++
+	return f, !ok
+}
+
+// leave 把调用方从合并执行中去掉，执行尚未结束且没有其他调用方在等待时取消执行
+func (a *Admission) leave(key string, f *flight) {
+	a.flightsMu.Lock()
+	defer a.flightsMu.Unlock()
+	f.waiters--
+	if f.waiters > 0 {
+		return
+	}
+	// 之后的相同请求重新发起执行，而不是加入已被取消的执行
+	if a.flights[key] == f {
+		delete(a.flights, key)
+	}
+	f.cancel()
+}
+
+// execute 在 f.ctx 下执行管道，结束后唤醒所有调用方
+func (a *Admission) execute(key string, f *flight, priority string, run func(ctx context.Context) (*home.PipelineResult, error)) {
+	f.result, f.err = a.admit(f.ctx, priority, run)
+	a.flightsMu.Lock()
+	if a.flights[key] == f {
+		delete(a.flights, key)
+	}
+	a.flightsMu.Unlock()
+	f.cancel()
+	close(f.done)
+}
+
+// admit 获取并发额度后执行 run，额度不足时返回 *OverloadedError
+func (a *Admission) admit(
+	ctx context.Context,
+	priority string,
+	run func(ctx context.Context) (*home.PipelineResult, error),
+) (*home.PipelineResult, error) {
+	if err := a.acquire(priority); err != nil {
+		a.report(priority, AdmissionShed)
+		return nil, err
+	}
+	defer a.release()
+	a.report(priority, AdmissionAdmitted)
+	return run(ctx)
+}
+
+func (a *Admission) acquire(priority string) error {
+	if a.config.MaxConcurrent <= 0 {
+		return nil
+	}
+	limit := a.config.MaxConcurrent
+	retryAfter := a.config.RetryAfter
+	if priority == PriorityBottom {
+		limit = int(float64(limit) * a.config.BottomRequestShare)
+		if limit < 1 {
+			limit = 1
+		}
+		retryAfter *= 2
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.inFlight >= limit {
+		return &OverloadedError{Priority: priority, InFlight: a.inFlight, Limit: limit, RetryAfter: retryAfter}
+	}
+	a.inFlight++
+	return nil
+}
+
+func (a *Admission) release() {
+	if a.config.MaxConcurrent <= 0 {
+		return
+	}
+	a.mu.Lock()
+	a.inFlight--
+	a.mu.Unlock()
+}
+
+// InFlight 返回正在执行的管道数
+func (a *Admission) InFlight() int {
+	if a == nil {
+		return 0
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.inFlight
+}
+
+func (a *Admission) report(priority string, outcome AdmissionOutcome) {
+	if a.observer != nil {
+		a.observer.Admission(priority, outcome)
+	}
+}
+
+// requestPriority 返回请求的优先级：首页请求优先于分页请求
+func requestPriority(query *home.Query) string {
+	if query.IsBottomRequest {
+		return PriorityBottom
+	}
+	return PriorityTop
+}
+
+// coalesceKey 返回请求的合并键：用户和所有影响结果的请求字段相同的请求视为同一请求
+// RequestID 和请求时间不参与比较
+func coalesceKey(query *home.Query) string {
+	h := fnv.New64a()
+	var buf [8]byte
+	writeInt := func(v int64) {
+		binary.LittleEndian.PutUint64(buf[:], uint64(v))
+		h.Write(buf[:])
+	}
+	writeString := func(s string) {
+		writeInt(int64(len(s)))
+		h.Write([]byte(s))
+	}
+	writeIDs := func(ids []int64) {
+		writeInt(int64(len(ids)))
+		for _, id := range ids {
+			writeInt(id)
+		}
+	}
+	writeBool := func(b bool) {
+		if b {
+			writeInt(1)
+		} else {
+			writeInt(0)
+		}
+	}
+
+	writeInt(int64(query.ClientAppID))
+	writeString(query.CountryCode)
+	writeString(query.LanguageCode)
+	writeIDs(query.SeenIDs)
+	writeIDs(query.ServedIDs)
+	writeBool(query.InNetworkOnly)
+	writeBool(query.IsBottomRequest)
+	writeString(query.SessionID)
+	writeInt(int64(query.PreRankSize))
+	writeInt(int64(len(query.BloomFilterEntries)))
+	for _, e := range query.BloomFilterEntries {
+		writeInt(int64(len(e.Data)))
+		h.Write(e.Data)
+	}
+	return fmt.Sprintf("%d/%016x", query.UserID, h.Sum64())
+}
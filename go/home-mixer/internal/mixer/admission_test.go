@@ -0,0 +1,246 @@
+package mixer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
+)
+
+func TestCoalesceKey(t *testing.T) {
+	base := func() *home.Query {
+		return &home.Query{
+			RequestMeta:        pipeline.RequestMeta{UserID: 42, RequestID: "a", RequestTimeMs: 1000},
+			ClientAppID:        1,
+			CountryCode:        "US",
+			LanguageCode:       "en",
+			SeenIDs:            []int64{1, 2},
+			ServedIDs:          []int64{3},
+			SessionID:          "s",
+			BloomFilterEntries: []home.BloomFilterEntry{{Data: []byte{1, 2}}},
+		}
+	}
+	tests := []struct {
+		name   string
+		modify func(q *home.Query)
+		same   bool
+	}{
+		{name: "identical", modify: func(q *home.Query) {}, same: true},
+		{name: "request id", modify: func(q *home.Query) { q.RequestID = "b" }, same: true},
+		{name: "request time", modify: func(q *home.Query) { q.RequestTimeMs = 2000 }, same: true},
+		{name: "user", modify: func(q *home.Query) { q.UserID = 43 }},
+		{name: "client app", modify: func(q *home.Query) { q.ClientAppID = 2 }},
+		{name: "country", modify: func(q *home.Query) { q.CountryCode = "GB" }},
+		{name: "language", modify: func(q *home.Query) { q.LanguageCode = "fr" }},
+		{name: "seen ids", modify: func(q *home.Query) { q.SeenIDs = append(q.SeenIDs, 4) }},
+		// 把 ID 从 SeenIDs 移到 ServedIDs 也是不同的请求（长度前缀区分列表边界）
+		{name: "ids moved between lists", modify: func(q *home.Query) { q.SeenIDs, q.ServedIDs = []int64{1}, []int64{2, 3} }},
+		{name: "in network only", modify: func(q *home.Query) { q.InNetworkOnly = true }},
+		{name: "bottom request", modify: func(q *home.Query) { q.IsBottomRequest = true }},
+		{name: "session", modify: func(q *home.Query) { q.SessionID = "t" }},
+		{name: "pre rank size", modify: func(q *home.Query) { q.PreRankSize = 100 }},
+		{name: "bloom filter", modify: func(q *home.Query) { q.BloomFilterEntries[0].Data = []byte{1, 3} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := base()
+			tt.modify(q)
+			if got := coalesceKey(q) == coalesceKey(base()); got != tt.same {
+				t.Errorf("same key=%v, want %v", got, tt.same)
+			}
+		})
+	}
+}
+
+func TestAdmissionAcquire(t *testing.T) {
+	config := AdmissionConfig{MaxConcurrent: 10, BottomRequestShare: 0.5, RetryAfter: 100 * time.Millisecond}
+	tests := []struct {
+		name           string
+		config         AdmissionConfig
+		inFlight       int
+		priority       string
+		wantLimit      int // 0 表示应获得额度
+		wantRetryAfter time.Duration
+	}{
+		{name: "top below limit", config: config, inFlight: 9, priority: PriorityTop},
+		{name: "top at limit", config: config, inFlight: 10, priority: PriorityTop, wantLimit: 10, wantRetryAfter: 100 * time.Millisecond},
+		{name: "bottom below share", config: config, inFlight: 4, priority: PriorityBottom},
+		{name: "bottom at share", config: config, inFlight: 5, priority: PriorityBottom, wantLimit: 5, wantRetryAfter: 200 * time.Millisecond},
+		{
+			name:     "bottom share rounds up to one",
+			config:   AdmissionConfig{MaxConcurrent: 1, BottomRequestShare: 0.1},
+			inFlight: 0, priority: PriorityBottom,
+		},
+		{name: "unlimited", config: AdmissionConfig{}, inFlight: 1000, priority: PriorityBottom},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Admission{config: tt.config, inFlight: tt.inFlight}
+			err := a.acquire(tt.priority)
+			if tt.wantLimit == 0 {
+				if err != nil {
+					t.Fatalf("acquire: %v", err)
+				}
+				if tt.config.MaxConcurrent > 0 && a.InFlight() != tt.inFlight+1 {
+					t.Errorf("in flight=%d, want %d", a.InFlight(), tt.inFlight+1)
+				}
+				return
+			}
+			var overloaded *OverloadedError
+			if !errors.As(err, &overloaded) {
+				t.Fatalf("err=%v, want *OverloadedError", err)
+			}
+			if overloaded.Limit != tt.wantLimit || overloaded.RetryAfter != tt.wantRetryAfter || overloaded.Priority != tt.priority {
+				t.Errorf("overloaded=%+v, want limit=%d retry_after=%s", overloaded, tt.wantLimit, tt.wantRetryAfter)
+			}
+			if a.InFlight() != tt.inFlight {
+				t.Errorf("in flight=%d after shedding, want %d", a.InFlight(), tt.inFlight)
+			}
+		})
+	}
+}
+
+func TestAdmissionCoalesces(t *testing.T) {
+	// 相同的在途请求只执行一次，其余调用方共享结果且不占用并发额度
+	observer := &admissionCounts{}
+	a, err := NewAdmission(AdmissionConfig{MaxConcurrent: 1, BottomRequestShare: 1, Coalesce: true, CoalescedTimeout: time.Second}, observer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := &home.Query{RequestMeta: pipeline.RequestMeta{UserID: 42}}
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var runs atomic.Int64
+	run := func(ctx context.Context) (*home.PipelineResult, error) {
+		if runs.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		return &home.PipelineResult{Query: query}, nil
+	}
+
+	const callers = 5
+	var wg sync.WaitGroup
+	results := make([]*home.PipelineResult, callers)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		results[0], _ = a.Do(context.Background(), query, run)
+	}()
+	<-started
+	for i := 1; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = a.Do(context.Background(), query.Clone(), run)
+		}(i)
+	}
+	// 等其余调用方进入合并后再让执行返回；并发上限为 1，没有合并的调用方会被拒绝而不是再执行一次
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := runs.Load(); got != 1 {
+		t.Fatalf("runs=%d, want 1", got)
+	}
+	for i, r := range results {
+		if r == nil || r != results[0] {
+			t.Errorf("caller %d got %p, want the shared result %p", i, r, results[0])
+		}
+	}
+	if got := observer.count(AdmissionCoalesced); got != callers-1 {
+		t.Errorf("coalesced=%d, want %d", got, callers-1)
+	}
+	if a.InFlight() != 0 {
+		t.Errorf("in flight=%d after all callers returned, want 0", a.InFlight())
+	}
+}
+
+func TestAdmissionCoalescedDeadline(t *testing.T) {
+	// 合并执行在最后一个调用方放弃等待时取消，不会占用并发额度到 CoalescedTimeout
+	tests := []struct {
+		name      string
+		deadlines []time.Duration // 各调用方的 deadline，依次加入同一次执行
+		wantEnd   time.Duration   // 执行被取消的时间（以最晚的 deadline 为准）
+	}{
+		{name: "single caller", deadlines: []time.Duration{50 * time.Millisecond}, wantEnd: 50 * time.Millisecond},
+		{name: "later caller extends", deadlines: []time.Duration{50 * time.Millisecond, 150 * time.Millisecond}, wantEnd: 150 * time.Millisecond},
+		{name: "earlier caller does not shorten", deadlines: []time.Duration{150 * time.Millisecond, 50 * time.Millisecond}, wantEnd: 150 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := NewAdmission(AdmissionConfig{MaxConcurrent: 1, BottomRequestShare: 1, Coalesce: true, CoalescedTimeout: 5 * time.Second}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			query := &home.Query{RequestMeta: pipeline.RequestMeta{UserID: 42}}
+			started := make(chan struct{})
+			ended := make(chan time.Duration, 1)
+			start := time.Now()
+			run := func(ctx context.Context) (*home.PipelineResult, error) {
+				close(started)
+				<-ctx.Done()
+				ended <- time.Since(start)
+				return nil, ctx.Err()
+			}
+
+			var wg sync.WaitGroup
+			for i, d := range tt.deadlines {
+				ctx, cancel := context.WithDeadline(context.Background(), start.Add(d))
+				defer cancel()
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if _, err := a.Do(ctx, query.Clone(), run); !errors.Is(err, context.DeadlineExceeded) {
+						t.Errorf("caller err=%v, want its own deadline", err)
+					}
+				}()
+				if i == 0 {
+					<-started
+				}
+			}
+			wg.Wait()
+
+			select {
+			case got := <-ended:
+				if got < tt.wantEnd || got > tt.wantEnd+time.Second {
+					t.Errorf("execution cancelled after %s, want about %s", got, tt.wantEnd)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("execution not cancelled after every caller gave up")
+			}
+			for deadline := time.Now().Add(time.Second); a.InFlight() != 0 && time.Now().Before(deadline); {
+				time.Sleep(time.Millisecond)
+			}
+			if a.InFlight() != 0 {
+				t.Errorf("in flight=%d, want the slot released", a.InFlight())
+			}
+		})
+	}
+}
+
+// admissionCounts 按结果统计准入控制的调用
+type admissionCounts struct {
+	mu     sync.Mutex
+	counts map[AdmissionOutcome]int
+}
+
+func (c *admissionCounts) Admission(_ string, outcome AdmissionOutcome) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[AdmissionOutcome]int)
+	}
+	c.counts[outcome]++
+}
+
+func (c *admissionCounts) count(outcome AdmissionOutcome) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[outcome]
+}
@@ -0,0 +1,271 @@
+package mixer
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
+	pb "x-algorithm-go/proto"
+)
+
+// DebugAccess 控制 GetScoredPostsDebug 的访问
+// 调用方在 gRPC metadata（HTTP/JSON 接口为 Authorization 头）中带上 authorization: Bearer <token>
+type DebugAccess struct {
+	tokens map[string]string // 调用方名称 -> token，名称只用于日志
+}
+
+// NewDebugAccess 创建 DebugAccess，tokens 为调用方名称到 token 的映射
+func NewDebugAccess(tokens map[string]string) (*DebugAccess, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("debug access requires at least one token")
+	}
+	for name, token := range tokens {
+		if name == "" || token == "" {
+			return nil, fmt.Errorf("debug access: empty caller name or token")
+		}
+	}
+	return &DebugAccess{tokens: tokens}, nil
+}
+
+// ParseDebugTokens 解析 "name=token,name=token" 形式的 token 列表
+func ParseDebugTokens(spec string) (map[string]string, error) {
+	tokens := make(map[string]string)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, token, ok := strings.Cut(entry, "=")
+		if !ok || name == "" || token == "" {
+			return nil, fmt.Errorf("invalid debug token %q, want name=token", entry)
+		}
+		if _, dup := tokens[name]; dup {
+			return nil, fmt.Errorf("duplicate debug token name %q", name)
+		}
+		tokens[name] = token
+	}
+	return tokens, nil
+}
+
+// Authorize 校验请求的凭证，返回调用方名称
+// 未配置 DebugAccess 时调试接口关闭，返回 PERMISSION_DENIED；凭证缺失或错误时返回 UNAUTHENTICATED
+func (a *DebugAccess) Authorize(ctx context.Context) (string, error) {
+	if a == nil {
+		return "", status.Error(codes.PermissionDenied, "debug rpc is disabled on this server")
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		token, ok := strings.CutPrefix(value, "Bearer ")
+		if !ok {
+			continue
+		}
+		// 逐个比较所有 token，耗时与匹配的位置无关
+		caller := ""
+		for name, want := range a.tokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1 {
+				caller = name
+			}
+		}
+		if caller != "" {
+			return caller, nil
+		}
+	}
+	return "", status.Error(codes.Unauthenticated, "debug rpc requires a valid bearer token")
+}
+
+// SetDebugAccess 开启 GetScoredPostsDebug，只有持有 token 的调用方可以访问
+func (s *HomeMixerServer) SetDebugAccess(access *DebugAccess) {
+	s.debugAccess = access
+}
+
+// GetScoredPostsDebug 以 explain 模式为任意用户执行一次请求，返回完整的管道结果
+// 调试请求不经过准入控制（不与线上请求合并），不读写会话缓存、不录制，也不提交 Side Effects
+func (s *HomeMixerServer) GetScoredPostsDebug(
+	ctx context.Context,
+	req *pb.ScoredPostsDebugRequest,
+) (*pb.ScoredPostsDebugResponse, error) {
+	caller, err := s.debugAccess.Authorize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetQuery() == nil {
+		return nil, status.Error(codes.InvalidArgument, "query must be specified")
+	}
+	query, err := queryFromRequest(req.GetQuery())
+	if err != nil {
+		return nil, err
+	}
+	query.WeightsVersion = s.weights.Load().Version
+
+	start := time.Now()
+	log.Printf("request_id=%s debug request caller=%s viewer_id=%d", query.RequestID, caller, query.UserID)
+	events := &pipeline.EventRecorder{}
+	result, err := s.pipeline.ExecuteWithOptions(ctx, query, pipeline.ExecuteOptions{
+		Explain:         true,
+		SkipSideEffects: true,
+		Observer:        events,
+	})
+	if err != nil {
+		return nil, pipelineErrorStatus(err)
+	}
+
+	resp := &pb.ScoredPostsDebugResponse{
+		RequestId:      result.Query.RequestID,
+		Query:          debugQuery(result.Query),
+		Candidates:     make([]*pb.DebugCandidate, 0, len(result.Explanations)),
+		ScoredPosts:    scoredPostsFromResult(result),
+		WeightsVersion: result.Query.WeightsVersion,
+	}
+	for _, ex := range result.Explanations {
+		resp.Candidates = append(resp.Candidates, debugCandidate(ex))
+	}
+	for _, e := range events.Stages() {
+		resp.Stages = append(resp.Stages, &pb.StageTiming{
+			Stage:         e.Stage,
+			CandidatesIn:  int32(e.CandidatesIn),
+			CandidatesOut: int32(e.CandidatesOut),
+			DurationMs:    durationMs(e.Duration),
+			Error:         errorString(e.Err),
+		})
+	}
+	for _, e := range events.Components() {
+		resp.Components = append(resp.Components, &pb.ComponentTiming{
+			Stage:         e.Stage,
+			Component:     e.Component,
+			CandidatesIn:  int32(e.CandidatesIn),
+			CandidatesOut: int32(e.CandidatesOut),
+			Removed:       int32(e.Removed),
+			DurationMs:    durationMs(e.Duration),
+			Error:         errorString(e.Err),
+			TimedOut:      e.TimedOut,
+		})
+	}
+
+	log.Printf("request_id=%s debug response caller=%s candidates=%d posts=%d (%d ms)",
+		query.RequestID, caller, len(resp.Candidates), len(resp.ScoredPosts), time.Since(start).Milliseconds())
+	return resp, nil
+}
+
+// debugQuery 把增强后的 Query 转换为调试响应格式
+func debugQuery(q *home.Query) *pb.DebugQuery {
+	out := &pb.DebugQuery{
+		UserId:            q.UserID,
+		ClientAppId:       q.ClientAppID,
+		CountryCode:       q.CountryCode,
+		LanguageCode:      q.LanguageCode,
+		RequestTimeMs:     q.RequestTimeMs,
+		PreRankSize:       int32(q.PreRankSize),
+		InNetworkOnly:     q.InNetworkOnly,
+		IsBottomRequest:   q.IsBottomRequest,
+		SessionId:         q.SessionID,
+		SeenIds:           q.SeenIDs,
+		ServedIds:         q.ServedIDs,
+		MissingHydrations: q.MissingHydrations,
+		FollowedUserIds:   q.UserFeatures.FollowedUserIDs,
+		BlockedUserIds:    q.UserFeatures.BlockedUserIDs,
+		MutedUserIds:      q.UserFeatures.MutedUserIDs,
+		SubscribedUserIds: q.UserFeatures.SubscribedUserIDs,
+		MutedKeywords:     q.UserFeatures.MutedKeywords,
+	}
+	if q.UserActionSequence != nil {
+		out.UserActionCount = int32(len(q.UserActionSequence.Actions))
+	}
+	for _, a := range q.Experiments {
+		out.Experiments = append(out.Experiments, &pb.ExperimentAssignment{
+			Experiment: a.Experiment,
+			Treatment:  a.Treatment,
+			Bucket:     int32(a.Bucket),
+		})
+	}
+	return out
+}
+
+// debugCandidate 把候选的最终状态和轨迹转换为调试响应格式
+func debugCandidate(ex *home.CandidateExplanation) *pb.DebugCandidate {
+	c := ex.Candidate
+	out := &pb.DebugCandidate{
+		TweetId:              c.TweetID,
+		AuthorId:             c.AuthorID,
+		TweetText:            c.TweetText,
+		InReplyToTweetId:     c.InReplyToTweetID,
+		RetweetedTweetId:     c.RetweetedTweetID,
+		RetweetedUserId:      c.RetweetedUserID,
+		Ancestors:            c.Ancestors,
+		InNetwork:            c.InNetwork,
+		VideoDurationMs:      c.VideoDurationMs,
+		AuthorFollowersCount: c.AuthorFollowersCount,
+		AuthorScreenName:     c.AuthorScreenName,
+		RetweetedScreenName:  c.RetweetedScreenName,
+		VisibilityReason:     c.VisibilityReason,
+		SubscriptionAuthorId: c.SubscriptionAuthorID,
+		PredictionRequestId:  c.PredictionRequestID,
+		LastScoredAtMs:       c.LastScoredAtMs,
+		Source:               ex.Source,
+		MissingHydrations:    c.MissingHydrations,
+		PhoenixScores:        phoenixScoreMap(c.PhoenixScores),
+		PreRankScore:         c.PreRankScore,
+		WeightedScore:        c.WeightedScore,
+		WeightsVersion:       c.WeightsVersion,
+		Score:                c.Score,
+		Selected:             ex.Selected,
+	}
+	if c.ServedType != nil {
+		servedType := int32(*c.ServedType)
+		out.ServedType = &servedType
+	}
+	for _, p := range c.Provenance {
+		out.Provenance = append(out.Provenance, &pb.SourceProvenance{Source: p.Source, Rank: int32(p.Rank), Score: p.Score})
+	}
+	if ex.Removal != nil {
+		out.Removal = &pb.CandidateRemoval{
+			Stage:     ex.Removal.Stage,
+			Component: ex.Removal.Component,
+			Reason:    string(ex.Removal.Reason),
+		}
+	}
+	for _, h := range ex.Hydrations {
+		out.Hydrations = append(out.Hydrations, &pb.HydrationStep{Stage: h.Stage, Component: h.Component, Fields: h.Fields})
+	}
+	for _, sc := range ex.Scores {
+		step := &pb.ScoreStep{Component: sc.Component, PreRankScore: sc.PreRankScore, Score: sc.Score}
+		if v, ok := sc.Extra["WeightedScore"]; ok {
+			step.WeightedScore = &v
+		}
+		out.Scores = append(out.Scores, step)
+	}
+	return out
+}
+
+// phoenixScoreMap 返回 Phoenix 各动作的预测分数，缺失的动作不出现在结果中
+func phoenixScoreMap(ps *home.PhoenixScores) map[string]float64 {
+	if ps == nil {
+		return nil
+	}
+	scores := make(map[string]float64)
+	for _, action := range home.PhoenixActions {
+		if v := *ps.Field(action); v != nil {
+			scores[action] = *v
+		}
+	}
+	return scores
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
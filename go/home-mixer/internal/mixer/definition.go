@@ -0,0 +1,67 @@
+package mixer
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"x-algorithm-go/candidate-pipeline/pipeline"
+)
+
+//go:embed pipelines/default.yaml
+var defaultDefinitionYAML []byte
+
+// DefaultPipelineDefinition 返回内置的默认管道定义（pipelines/default.yaml）
+func DefaultPipelineDefinition() *pipeline.PipelineDefinition {
+	def, err := ParsePipelineDefinition(defaultDefinitionYAML, "yaml")
+	if err != nil {
+		// 内置定义随二进制发布，解析失败属于编程错误
+		panic(fmt.Sprintf("mixer: invalid built-in pipeline definition: %v", err))
+	}
+	return def
+}
+
+// LoadPipelineDefinition 从文件加载管道定义，按扩展名识别格式（.yaml / .yml / .json）
+func LoadPipelineDefinition(path string) (*pipeline.PipelineDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read pipeline definition: %w", err)
+	}
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	def, err := ParsePipelineDefinition(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return def, nil
+}
+
+// ParsePipelineDefinition 解析 YAML 或 JSON 格式的管道定义
+// YAML 先转换为 JSON，再与 JSON 使用同一套严格解析（未知字段报错）
+func ParsePipelineDefinition(data []byte, format string) (*pipeline.PipelineDefinition, error) {
+	jsonData, err := configToJSON(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("parse pipeline definition: %w", err)
+	}
+	return pipeline.ParseDefinition(jsonData)
+}
+
+// configToJSON 把 YAML 或 JSON 格式的配置统一转换为 JSON
+func configToJSON(data []byte, format string) ([]byte, error) {
+	switch format {
+	case "json":
+		return data, nil
+	case "yaml", "yml":
+		var doc any
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		return json.Marshal(doc)
+	default:
+		return nil, fmt.Errorf("unsupported format %q (want yaml or json)", format)
+	}
+}
@@ -0,0 +1,445 @@
+package mixer_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"x-algorithm-go/home-mixer/internal/calibration"
+	"x-algorithm-go/home-mixer/internal/clients"
+	"x-algorithm-go/home-mixer/internal/mixer"
+	"x-algorithm-go/home-mixer/internal/scorers"
+	pb "x-algorithm-go/proto"
+	"x-algorithm-go/proto/gateway"
+	"x-algorithm-go/proto/thunder"
+	"x-algorithm-go/thunder/inprocess"
+)
+
+const (
+	viewerID      int64 = 42 // 请求的用户 ID
+	postsPerUser        = 3  // 每个被关注用户写入 Thunder 的帖子数
+	bufconnBuffer       = 1 << 20
+	debugToken          = "e2e-debug-token"
+)
+
+var twitterEpoch = time.UnixMilli(1142974214000)
+
+// TestEndToEnd 在进程内启动 Thunder 和 home-mixer，通过 bufconn 连接真实的 gRPC 服务端和客户端，
+// 验证 GetScoredPosts 能返回来自 Thunder 的站内帖子，并检查调试接口、两个服务的 HTTP/JSON 接口（见 gateway 包），
+// Phoenix 排序服务不可用时的降级排序、动作权重的热加载，以及加权之前的分数校准。
+//
+// Thunder 使用 x-algorithm-go/thunder/inprocess（与线上相同的 PostStore 和 ThunderService），
+// home-mixer 使用默认管道定义，Thunder 以外的下游依赖使用模拟客户端。
+func TestEndToEnd(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// 1) Thunder：写入被关注用户的帖子（模拟 Strato 客户端返回的关注列表为 viewer+100+10*i）
+	followed := make(map[uint64]bool)
+	thunderServer := inprocess.NewServer(inprocess.DefaultConfig())
+	now := time.Now()
+	var posts []*thunder.LightPost
+	for i := 0; i < 10; i++ {
+		authorID := viewerID + 100 + int64(i*10)
+		followed[uint64(authorID)] = true
+		for j := 0; j < postsPerUser; j++ {
+			createdAt := now.Add(-time.Duration(i*postsPerUser+j+1) * time.Minute)
+			postID := snowflakeID(createdAt, i*postsPerUser+j)
+			posts = append(posts, &thunder.LightPost{
+				PostId:         postID,
+				AuthorId:       authorID,
+				CreatedAt:      createdAt.Unix(),
+				ConversationId: &postID,
+			})
+		}
+	}
+	thunderServer.InsertPosts(posts)
+	thunderConn := serve(t, thunderServer.Register)
+
+	// 2) home-mixer：默认管道，Thunder 客户端连接进程内的 Thunder
+	candidatePipeline, err := mixer.NewPhoenixCandidatePipeline(&mixer.PipelineConfig{
+		ThunderClient:     clients.NewThunderClientFromConn(thunderConn),
+		ThunderMaxResults: 500,
+		PhoenixMaxResults: 500,
+		TopK:              50,
+		MaxAge:            7 * 24 * time.Hour,
+		Deadlines:         mixer.DefaultDeadlines(),
+	})
+	if err != nil {
+		t.Fatalf("build pipeline: %v", err)
+	}
+	homeMixerServer := mixer.NewHomeMixerServer(candidatePipeline.Pipeline)
+	debugAccess, err := mixer.NewDebugAccess(map[string]string{"e2e": debugToken})
+	if err != nil {
+		t.Fatalf("debug access: %v", err)
+	}
+	homeMixerServer.SetDebugAccess(debugAccess)
+	homeMixerConn := serve(t, func(registrar grpc.ServiceRegistrar) {
+		pb.RegisterScoredPostsServiceServer(registrar, homeMixerServer)
+	})
+
+	// 3) 直接调用 Thunder，确认服务已注册并返回写入的帖子
+	thunderResp, err := thunder.NewInNetworkPostsServiceClient(thunderConn).GetInNetworkPosts(ctx, &thunder.GetInNetworkPostsRequest{
+		UserId:           uint64(viewerID),
+		FollowingUserIds: keys(followed),
+	})
+	if err != nil {
+		t.Fatalf("thunder GetInNetworkPosts: %v", err)
+	}
+	if len(thunderResp.GetPosts()) != len(posts) {
+		t.Fatalf("thunder returned %d posts, want %d", len(thunderResp.GetPosts()), len(posts))
+	}
+
+	// 4) 调用 home-mixer（只要站内帖子），结果必须全部来自被关注用户
+	resp, err := pb.NewScoredPostsServiceClient(homeMixerConn).GetScoredPosts(ctx, &pb.ScoredPostsQuery{
+		ViewerId:      viewerID,
+		InNetworkOnly: true,
+	})
+	if err != nil {
+		t.Fatalf("home-mixer GetScoredPosts: %v", err)
+	}
+	if len(resp.GetScoredPosts()) == 0 {
+		t.Fatalf("home-mixer returned no posts")
+	}
+	inserted := make(map[uint64]bool, len(posts))
+	for _, post := range posts {
+		inserted[uint64(post.PostId)] = true
+	}
+	for _, post := range resp.GetScoredPosts() {
+		if !inserted[post.GetTweetId()] || !followed[post.GetAuthorId()] || !post.GetInNetwork() {
+			t.Fatalf("unexpected post: tweet_id=%d author_id=%d in_network=%v", post.GetTweetId(), post.GetAuthorId(), post.GetInNetwork())
+		}
+		if post.GetFallbackRanked() {
+			t.Fatalf("post %d fallback ranked although Phoenix ranking is available", post.GetTweetId())
+		}
+	}
+	if resp.GetRankingDegraded() {
+		t.Fatalf("ranking degraded although Phoenix ranking is available")
+	}
+	if resp.GetWeightsVersion() != scorers.DefaultWeightsVersion {
+		t.Fatalf("weights_version=%q, want %q", resp.GetWeightsVersion(), scorers.DefaultWeightsVersion)
+	}
+
+	// 5) 调试接口：没有 token 时拒绝；有 token 时返回全部检索到的候选、被选中的候选与 GetScoredPosts 一致，以及各阶段耗时
+	homeMixerClient := pb.NewScoredPostsServiceClient(homeMixerConn)
+	debugReq := &pb.ScoredPostsDebugRequest{Query: &pb.ScoredPostsQuery{ViewerId: viewerID, InNetworkOnly: true}}
+	if _, err := homeMixerClient.GetScoredPostsDebug(ctx, debugReq); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("debug without token: err=%v, want UNAUTHENTICATED", err)
+	}
+	debugCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+debugToken)
+	debugResp, err := homeMixerClient.GetScoredPostsDebug(debugCtx, debugReq)
+	if err != nil {
+		t.Fatalf("home-mixer GetScoredPostsDebug: %v", err)
+	}
+	selected := 0
+	for _, c := range debugResp.GetCandidates() {
+		if c.GetSelected() {
+			selected++
+			// 轨迹记录每个 Scorer 执行后的加权分数：WeightedScorer 之前为空，之后有值
+			weighted := false
+			for _, step := range c.GetScores() {
+				weighted = weighted || step.GetComponent() == "WeightedScorer"
+				if (step.WeightedScore != nil) != weighted {
+					t.Fatalf("debug candidate %d: score step %s weighted_score=%v", c.GetTweetId(), step.GetComponent(), step.WeightedScore)
+				}
+			}
+			if !weighted {
+				t.Fatalf("debug candidate %d has no WeightedScorer step", c.GetTweetId())
+			}
+		} else if c.GetRemoval() == nil {
+			t.Fatalf("debug candidate %d neither selected nor removed", c.GetTweetId())
+		}
+	}
+	if len(debugResp.GetCandidates()) < len(posts) || selected != len(debugResp.GetScoredPosts()) || len(debugResp.GetStages()) == 0 {
+		t.Fatalf("debug returned candidates=%d selected=%d scored_posts=%d stages=%d",
+			len(debugResp.GetCandidates()), selected, len(debugResp.GetScoredPosts()), len(debugResp.GetStages()))
+	}
+
+	// 6) HTTP/JSON 接口：与 gRPC 返回相同数量的帖子，int64 编码为字符串，错误映射为对应的 HTTP 状态码
+	thunderHTTP := httptest.NewServer(gateway.Unary(thunderServer.Service().GetInNetworkPosts))
+	defer thunderHTTP.Close()
+	homeMixerHTTP := httptest.NewServer(gateway.Unary(homeMixerServer.GetScoredPosts))
+	defer homeMixerHTTP.Close()
+
+	var thunderJSON struct {
+		Posts []struct {
+			PostID string `json:"post_id"`
+		} `json:"posts"`
+	}
+	followingJSON := make([]string, 0, len(followed))
+	for _, id := range keys(followed) {
+		followingJSON = append(followingJSON, strconv.FormatUint(id, 10))
+	}
+	postJSON(t, thunderHTTP.URL, map[string]any{"user_id": strconv.FormatInt(viewerID, 10), "following_user_ids": followingJSON}, http.StatusOK, &thunderJSON)
+	if len(thunderJSON.Posts) != len(posts) || thunderJSON.Posts[0].PostID == "" {
+		t.Fatalf("thunder http returned %d posts, want %d with string post_id", len(thunderJSON.Posts), len(posts))
+	}
+
+	var scoredJSON struct {
+		ScoredPosts []struct {
+			TweetID string `json:"tweet_id"`
+		} `json:"scored_posts"`
+	}
+	postJSON(t, homeMixerHTTP.URL, map[string]any{"viewer_id": strconv.FormatInt(viewerID, 10), "in_network_only": true}, http.StatusOK, &scoredJSON)
+	if len(scoredJSON.ScoredPosts) != len(resp.GetScoredPosts()) {
+		t.Fatalf("home-mixer http returned %d posts, want %d", len(scoredJSON.ScoredPosts), len(resp.GetScoredPosts()))
+	}
+	if _, err := strconv.ParseUint(scoredJSON.ScoredPosts[0].TweetID, 10, 64); err != nil {
+		t.Fatalf("home-mixer http tweet_id %q is not an integer string", scoredJSON.ScoredPosts[0].TweetID)
+	}
+
+	var errJSON struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	postJSON(t, homeMixerHTTP.URL, map[string]any{}, http.StatusBadRequest, &errJSON)
+	if errJSON.Code != 3 || errJSON.Message == "" { // INVALID_ARGUMENT
+		t.Fatalf("home-mixer http error = %+v, want code 3", errJSON)
+	}
+
+	debugHTTP := httptest.NewServer(withAuthorization(gateway.Unary(homeMixerServer.GetScoredPostsDebug), "Bearer "+debugToken))
+	defer debugHTTP.Close()
+	var debugJSON struct {
+		Candidates []struct {
+			TweetID string `json:"tweet_id"`
+		} `json:"candidates"`
+	}
+	postJSON(t, debugHTTP.URL, map[string]any{"query": map[string]any{"viewer_id": strconv.FormatInt(viewerID, 10), "in_network_only": true}}, http.StatusOK, &debugJSON)
+	if len(debugJSON.Candidates) != len(debugResp.GetCandidates()) {
+		t.Fatalf("home-mixer debug http returned %d candidates, want %d", len(debugJSON.Candidates), len(debugResp.GetCandidates()))
+	}
+
+	// 7) Phoenix 排序服务不可用：PhoenixScorer 失败，FallbackScorer 给出启发式分数，响应标记为降级
+	degradedPipeline, err := mixer.NewPhoenixCandidatePipeline(&mixer.PipelineConfig{
+		ThunderClient:        clients.NewThunderClientFromConn(thunderConn),
+		PhoenixRankingClient: unavailableRanking{},
+		ThunderMaxResults:    500,
+		PhoenixMaxResults:    500,
+		TopK:                 50,
+		MaxAge:               7 * 24 * time.Hour,
+		Deadlines:            mixer.DefaultDeadlines(),
+	})
+	if err != nil {
+		t.Fatalf("build degraded pipeline: %v", err)
+	}
+	degradedResp, err := mixer.NewHomeMixerServer(degradedPipeline.Pipeline).GetScoredPosts(ctx, &pb.ScoredPostsQuery{
+		ViewerId:      viewerID,
+		InNetworkOnly: true,
+	})
+	if err != nil {
+		t.Fatalf("degraded GetScoredPosts: %v", err)
+	}
+	if !degradedResp.GetRankingDegraded() || len(degradedResp.GetScoredPosts()) != len(resp.GetScoredPosts()) {
+		t.Fatalf("degraded response: ranking_degraded=%v posts=%d, want true and %d posts",
+			degradedResp.GetRankingDegraded(), len(degradedResp.GetScoredPosts()), len(resp.GetScoredPosts()))
+	}
+	distinct := make(map[float32]bool)
+	for _, post := range degradedResp.GetScoredPosts() {
+		if !post.GetFallbackRanked() || post.GetScore() <= 0 {
+			t.Fatalf("degraded post %d: fallback_ranked=%v score=%v, want a positive fallback score", post.GetTweetId(), post.GetFallbackRanked(), post.GetScore())
+		}
+		distinct[post.GetScore()] = true
+	}
+	if len(distinct) < len(degradedResp.GetScoredPosts())/2 {
+		t.Fatalf("degraded ranking produced only %d distinct scores for %d posts", len(distinct), len(degradedResp.GetScoredPosts()))
+	}
+	if degradedResp.GetWeightsVersion() != scorers.DefaultWeightsVersion {
+		t.Fatalf("degraded response weights_version=%q, want %q (the version active when the request started)", degradedResp.GetWeightsVersion(), scorers.DefaultWeightsVersion)
+	}
+
+	// 8) 动作权重热加载：新版本的权重文件生效后响应带上新版本，不合法的文件被拒绝且不影响当前权重
+	weightsPath := t.TempDir() + "/action_weights.json"
+	writeWeights(t, weightsPath, "v1", 1)
+	reloader, err := mixer.NewWeightsReloader(weightsPath, nil)
+	if err != nil {
+		t.Fatalf("load action weights: %v", err)
+	}
+	weightsPipeline, err := mixer.NewPhoenixCandidatePipeline(&mixer.PipelineConfig{
+		ThunderClient:     clients.NewThunderClientFromConn(thunderConn),
+		ThunderMaxResults: 500,
+		PhoenixMaxResults: 500,
+		TopK:              50,
+		MaxAge:            7 * 24 * time.Hour,
+		Deadlines:         mixer.DefaultDeadlines(),
+		Weights:           reloader.Store(),
+	})
+	if err != nil {
+		t.Fatalf("build weights pipeline: %v", err)
+	}
+	weightsServer := mixer.NewHomeMixerServer(weightsPipeline.Pipeline)
+	weightsServer.SetWeights(reloader.Store())
+	scoredWith := func(want string) *pb.ScoredPostsResponse {
+		r, err := weightsServer.GetScoredPosts(ctx, &pb.ScoredPostsQuery{ViewerId: viewerID, InNetworkOnly: true})
+		if err != nil {
+			t.Fatalf("GetScoredPosts with weights %s: %v", want, err)
+		}
+		if r.GetWeightsVersion() != want {
+			t.Fatalf("weights_version=%q, want %q", r.GetWeightsVersion(), want)
+		}
+		return r
+	}
+	v1 := scoredWith("v1")
+	writeWeights(t, weightsPath, "v2", 2)
+	if err := reloader.Reload(); err != nil {
+		t.Fatalf("reload action weights: %v", err)
+	}
+	if v2 := scoredWith("v2"); v2.GetScoredPosts()[0].GetScore() == v1.GetScoredPosts()[0].GetScore() {
+		t.Fatalf("scores unchanged after doubling the action weights: %v", v2.GetScoredPosts()[0].GetScore())
+	}
+	writeWeights(t, weightsPath, "v2", 3)
+	if err := reloader.Reload(); err == nil {
+		t.Fatalf("reload accepted changed weights with an unchanged version")
+	}
+	scoredWith("v2")
+
+	// 9) 分数校准：把 favorite 的预测概率压低后，加权分数整体下降，排序仍然来自 Phoenix 预测
+	calibrated, err := calibration.NewSet("e2e", map[string]calibration.Calibrator{
+		"favorite": &calibration.Platt{A: 1, B: -3},
+	})
+	if err != nil {
+		t.Fatalf("build calibration: %v", err)
+	}
+	calibratedPipeline, err := mixer.NewPhoenixCandidatePipeline(&mixer.PipelineConfig{
+		ThunderClient:     clients.NewThunderClientFromConn(thunderConn),
+		ThunderMaxResults: 500,
+		PhoenixMaxResults: 500,
+		TopK:              50,
+		MaxAge:            7 * 24 * time.Hour,
+		Deadlines:         mixer.DefaultDeadlines(),
+		Calibration:       calibrated,
+	})
+	if err != nil {
+		t.Fatalf("build calibrated pipeline: %v", err)
+	}
+	calibratedResp, err := mixer.NewHomeMixerServer(calibratedPipeline.Pipeline).GetScoredPosts(ctx, &pb.ScoredPostsQuery{
+		ViewerId:      viewerID,
+		InNetworkOnly: true,
+	})
+	if err != nil {
+		t.Fatalf("calibrated GetScoredPosts: %v", err)
+	}
+	if calibratedResp.GetRankingDegraded() || len(calibratedResp.GetScoredPosts()) != len(resp.GetScoredPosts()) {
+		t.Fatalf("calibrated response: ranking_degraded=%v posts=%d, want false and %d posts",
+			calibratedResp.GetRankingDegraded(), len(calibratedResp.GetScoredPosts()), len(resp.GetScoredPosts()))
+	}
+	if top, base := calibratedResp.GetScoredPosts()[0].GetScore(), resp.GetScoredPosts()[0].GetScore(); top >= base {
+		t.Fatalf("calibrated top score %v, want below the uncalibrated %v", top, base)
+	}
+}
+
+// writeWeights 写入版本为 version、动作权重为默认权重 scale 倍的权重文件
+func writeWeights(t *testing.T, path, version string, scale float64) {
+	t.Helper()
+	defaults, err := json.Marshal(scorers.DefaultActionWeights())
+	if err != nil {
+		t.Fatalf("encode action weights: %v", err)
+	}
+	var weights map[string]float64
+	if err := json.Unmarshal(defaults, &weights); err != nil {
+		t.Fatalf("decode action weights: %v", err)
+	}
+	for key := range weights {
+		if strings.HasSuffix(key, "_weight") {
+			weights[key] *= scale
+		}
+	}
+	data, err := json.Marshal(map[string]any{"version": version, "weights": weights})
+	if err != nil {
+		t.Fatalf("encode action weights: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("write action weights: %v", err)
+	}
+}
+
+// unavailableRanking 是总是失败的 Phoenix 排序客户端，模拟排序服务不可用
+type unavailableRanking struct{}
+
+func (unavailableRanking) Rank(context.Context, *scorers.RankingRequest) (*scorers.RankingResponse, error) {
+	return nil, status.Error(codes.Unavailable, "phoenix ranking unavailable")
+}
+
+// withAuthorization 为每个请求加上 Authorization 头
+func withAuthorization(h http.Handler, auth string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Set("Authorization", auth)
+		h.ServeHTTP(w, r)
+	})
+}
+
+// postJSON 向 HTTP/JSON 接口发送请求，检查状态码并解码响应体
+func postJSON(t *testing.T, url string, body any, wantStatus int, out any) {
+	t.Helper()
+	in, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("encode request: %v", err)
+	}
+	httpResp, err := http.Post(url, "application/json", bytes.NewReader(in))
+	if err != nil {
+		t.Fatalf("POST %s: %v", url, err)
+	}
+	defer httpResp.Body.Close()
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		t.Fatalf("read response: %v", err)
+	}
+	if httpResp.StatusCode != wantStatus {
+		t.Fatalf("POST %s: status %d, want %d: %s", url, httpResp.StatusCode, wantStatus, data)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		t.Fatalf("decode response %s: %v", data, err)
+	}
+}
+
+// serve 在 bufconn 上启动 gRPC 服务器，返回连接到它的客户端连接；测试结束时关闭连接并停止服务器
+func serve(t *testing.T, register func(grpc.ServiceRegistrar)) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(bufconnBuffer)
+	server := grpc.NewServer()
+	register(server)
+	go server.Serve(lis)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial bufconn: %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		server.Stop()
+	})
+	return conn
+}
+
+// snowflakeID 生成创建时间为 t 的雪花 ID（管道按雪花 ID 计算帖子年龄）
+func snowflakeID(t time.Time, seq int) int64 {
+	return t.Sub(twitterEpoch).Milliseconds()<<22 | int64(seq&0xfff)
+}
+
+func keys(m map[uint64]bool) []uint64 {
+	ids := make([]uint64, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	return ids
+}
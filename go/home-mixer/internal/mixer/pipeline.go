@@ -2,15 +2,15 @@ package mixer
 
 import (
 	"context"
+	"fmt"
 	"time"
 
-	"x-algorithm-go/home-mixer/internal/clients"
-	"x-algorithm-go/home-mixer/internal/filters"
+	"x-algorithm-go/home-mixer/internal/calibration"
 	"x-algorithm-go/home-mixer/internal/hydrators"
 	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 	"x-algorithm-go/home-mixer/internal/query_hydrators"
 	"x-algorithm-go/home-mixer/internal/scorers"
-	"x-algorithm-go/home-mixer/internal/selectors"
 	"x-algorithm-go/home-mixer/internal/side_effects"
 	"x-algorithm-go/home-mixer/internal/sources"
 )
@@ -18,7 +18,7 @@ import (
 // PhoenixCandidatePipeline 配置完整的推荐管道
 // 组装所有组件：Query Hydrators, Sources, Hydrators, Filters, Scorers, Selector 等
 type PhoenixCandidatePipeline struct {
-	Pipeline *pipeline.CandidatePipeline
+	Pipeline *home.CandidatePipeline
 }
 
 // PipelineConfig 配置管道的所有组件
@@ -39,152 +39,79 @@ type PipelineConfig struct {
 	PhoenixMaxResults       int
 	TopK                    int
 	MaxAge                  time.Duration
+	Deadlines               pipeline.Deadlines // 各阶段预算和组件超时
+	Hedging                 pipeline.Hedging   // Source / Hydrator 的对冲请求，零值表示不对冲
+	Weights                 *scorers.WeightsStore // WeightedScorer 的动作权重（见 WeightsReloader），为 nil 时使用默认权重
+	Calibration             *calibration.Set      // CalibrationScorer 的按动作校准器，为 nil 时不校准
+	SideEffectExecutor      *pipeline.SideEffectExecutor // Side Effect 执行器，为 nil 时使用默认执行器
+
+	// Definition 声明管道的组件及顺序，为 nil 时使用内置的默认定义
+	// 定义中的 result_size / deadlines / hedging 优先于上面的 TopK / Deadlines / Hedging
+	Definition              *pipeline.PipelineDefinition
+
+	// Observer 接收管道的阶段和组件事件（指标、追踪），为 nil 时不上报
+	Observer                pipeline.Observer
+}
+
+// DefaultDeadlines 返回默认的阶段预算
+// 各阶段预算之和小于常见的客户端 deadline，保证超时的依赖不会拖垮整个请求
+func DefaultDeadlines() pipeline.Deadlines {
+	return pipeline.Deadlines{
+		QueryHydration:         100 * time.Millisecond,
+		Sourcing:               200 * time.Millisecond,
+		Hydration:              150 * time.Millisecond,
+		PreRanking:             30 * time.Millisecond,
+		Scoring:                300 * time.Millisecond,
+		PostSelectionHydration: 100 * time.Millisecond,
+	}
 }
 
 // NewPhoenixCandidatePipeline 创建新的 PhoenixCandidatePipeline 实例
-func NewPhoenixCandidatePipeline(config *PipelineConfig) *PhoenixCandidatePipeline {
+// 组件列表来自 config.Definition（为 nil 时使用内置的 pipelines/default.yaml），
+// 由 NewComponentRegistry 编译为 CandidatePipeline。
+// 定义不合法、组件的字段依赖存在环或冲突时返回错误
+func NewPhoenixCandidatePipeline(config *PipelineConfig) (*PhoenixCandidatePipeline, error) {
 	if config == nil {
 		config = &PipelineConfig{
 			ThunderMaxResults: 500,
 			PhoenixMaxResults: 500,
 			TopK:              50,
 			MaxAge:            7 * 24 * time.Hour, // 7天
+			Deadlines:         DefaultDeadlines(),
 		}
 	}
 
-	// 1) Query Hydrators（并行执行）
-	// Use mock clients if real clients are not provided
-	uasFetcher := config.UASFetcher
-	if uasFetcher == nil {
-		uasFetcher = clients.NewMockUASFetcher()
-	}
-	
-	stratoClient := config.StratoClient
-	if stratoClient == nil {
-		stratoClient = clients.NewMockStratoClient()
-	}
-	
-	queryHydrators := []pipeline.QueryHydrator{
-		query_hydrators.NewUserActionSeqQueryHydrator(uasFetcher),
-		query_hydrators.NewUserFeaturesQueryHydrator(stratoClient),
-	}
-
-	// 2) Sources（并行执行）
-	// Use mock clients if real clients are not provided
-	phoenixRetrievalClient := config.PhoenixRetrievalClient
-	if phoenixRetrievalClient == nil {
-		phoenixRetrievalClient = clients.NewMockPhoenixRetrievalClient()
-	}
-	
-	thunderClient := config.ThunderClient
-	if thunderClient == nil {
-		thunderClient = clients.NewMockThunderClient()
-	}
-	
-	sourceList := []pipeline.Source{
-		sources.NewPhoenixSource(phoenixRetrievalClient, config.PhoenixMaxResults),
-		sources.NewThunderSource(thunderClient, config.ThunderMaxResults),
-	}
-
-	// 3) Hydrators（并行执行）
-	// Use mock clients if real clients are not provided
-	tesClient := config.TESClient
-	if tesClient == nil {
-		tesClient = clients.NewMockTESClient()
-	}
-	
-	gizmoduckClient := config.GizmoduckClient
-	if gizmoduckClient == nil {
-		gizmoduckClient = clients.NewMockGizmoduckClient()
-	}
-	
-	vfClient := config.VFClient
-	if vfClient == nil {
-		vfClient = clients.NewMockVFClient()
-	}
-	
-	hydratorList := []pipeline.Hydrator{
-		hydrators.NewInNetworkCandidateHydrator(), // 站内标记（需要先执行，因为依赖 UserFeatures）
-		hydrators.NewCoreDataCandidateHydrator(tesClient),
-		hydrators.NewVideoDurationCandidateHydrator(tesClient),
-		hydrators.NewSubscriptionHydrator(tesClient),
-		hydrators.NewGizmoduckCandidateHydrator(gizmoduckClient),
-	}
-
-	// 4) Pre-Scoring Filters（顺序执行）
-	// 注意：顺序必须与Rust版本一致，因为Filter的执行顺序会影响结果
-	filterList := []pipeline.Filter{
-		filters.NewDropDuplicatesFilter(),        // 1. 去重
-		filters.NewCoreDataHydrationFilter(),     // 2. 移除数据获取失败的候选
-		filters.NewAgeFilter(config.MaxAge),      // 3. 年龄过滤
-		filters.NewSelfTweetFilter(),             // 4. 移除自己的帖子
-		filters.NewRetweetDeduplicationFilter(), // 5. 转发去重
-		filters.NewIneligibleSubscriptionFilter(), // 6. 订阅过滤
-		filters.NewPreviouslySeenPostsFilter(),   // 7. 移除已看过的帖子
-		filters.NewPreviouslyServedPostsFilter(), // 8. 移除已服务的帖子（分页时）
-		filters.NewMutedKeywordFilter(),         // 9. 移除包含静音关键词的帖子
-		filters.NewAuthorSocialgraphFilter(),    // 10. 移除屏蔽/静音作者的帖子
-	}
-
-	// 5) Scorers（顺序执行）
-	// Create mock PhoenixRankingClient for local learning if not provided
-	var phoenixRankingClient scorers.PhoenixRankingClient
-	if config.PhoenixRankingClient == nil {
-		// Use mock client for local testing
-		phoenixRankingClient = scorers.NewMockPhoenixRankingClient()
-	} else {
-		phoenixRankingClient = config.PhoenixRankingClient
-	}
-	
-	scorerList := []pipeline.Scorer{
-		scorers.NewPhoenixScorer(phoenixRankingClient),
-		scorers.NewWeightedScorer(nil),          // 使用默认权重
-		scorers.DefaultAuthorDiversityScorer(),  // 作者多样性调整
-		scorers.DefaultOONScorer(),              // 站外内容调整
+	definition := config.Definition
+	if definition == nil {
+		definition = DefaultPipelineDefinition()
 	}
 
-	// 6) Selector
-	selector := selectors.NewTopKScoreSelector(config.TopK)
-
-	// 7) Post-Selection Hydrators（并行执行）
-	postSelectionHydrators := []pipeline.Hydrator{
-		hydrators.NewVFCandidateHydrator(vfClient),
+	candidatePipeline, err := NewComponentRegistry(config).Compile(definition)
+	if err != nil {
+		return nil, err
 	}
 
-	// 8) Post-Selection Filters（顺序执行）
-	postSelectionFilters := []pipeline.Filter{
-		filters.NewVFFilter(),                    // 可见性过滤
-		filters.NewDedupConversationFilter(),     // 对话去重
+	// 定义中未给出的配置使用 PipelineConfig 的值
+	if candidatePipeline.ResultSize == 0 {
+		candidatePipeline.ResultSize = config.TopK
 	}
-
-	// 9) Side Effects（异步执行）
-	// Use mock client if real client is not provided
-	stratoClientForCache := config.StratoClientForCache
-	if stratoClientForCache == nil {
-		stratoClientForCache = clients.NewMockStratoClientForCache()
+	if definition.Deadlines == nil {
+		candidatePipeline.Deadlines = config.Deadlines
 	}
-	
-	sideEffects := []pipeline.SideEffect{
-		side_effects.NewCacheRequestInfoSideEffect(stratoClientForCache),
+	if definition.Hedging == nil {
+		candidatePipeline.Hedging = config.Hedging
 	}
+	candidatePipeline.SideEffectExecutor = config.SideEffectExecutor
+	candidatePipeline.Observer = config.Observer
 
-	// 创建 Pipeline
-	candidatePipeline := &pipeline.CandidatePipeline{
-		QueryHydrators:         queryHydrators,
-		Sources:                sourceList,
-		Hydrators:              hydratorList,
-		Filters:                filterList,
-		Scorers:                scorerList,
-		Selector:               selector,
-		PostSelectionHydrators: postSelectionHydrators,
-		PostSelectionFilters:   postSelectionFilters,
-		SideEffects:            sideEffects,
-		ResultSize:             config.TopK,
+	// 校验失败时返回全部问题，服务拒绝启动
+	if err := candidatePipeline.Build(); err != nil {
+		return nil, fmt.Errorf("pipeline %q: invalid configuration:\n%w", definition.Name, err)
 	}
 
 	return &PhoenixCandidatePipeline{
 		Pipeline: candidatePipeline,
-	}
+	}, nil
 }
 
 // Prod creates a production-ready pipeline configuration with real clients
@@ -208,24 +135,36 @@ func Prod(
 		PhoenixMaxResults: 500,
 		TopK:              50,
 		MaxAge:            7 * 24 * time.Hour,
+		Deadlines:         DefaultDeadlines(),
 		// Clients are nil, which means mock implementations will be used
 	}
-	return NewPhoenixCandidatePipeline(config), nil
+	return NewPhoenixCandidatePipeline(config)
 }
 
 // NewMockPipeline creates a pipeline with all mock clients for local learning
-func NewMockPipeline() *PhoenixCandidatePipeline {
+func NewMockPipeline() (*PhoenixCandidatePipeline, error) {
 	config := &PipelineConfig{
 		ThunderMaxResults: 500,
 		PhoenixMaxResults: 500,
 		TopK:              50,
 		MaxAge:            7 * 24 * time.Hour,
+		Deadlines:         DefaultDeadlines(),
 		// All clients are nil - mock implementations will be used via sources/hydrators/etc.
 	}
 	return NewPhoenixCandidatePipeline(config)
 }
 
 // Execute 执行管道（委托给内部的 CandidatePipeline）
-func (p *PhoenixCandidatePipeline) Execute(ctx context.Context, query *pipeline.Query) (*pipeline.PipelineResult, error) {
+func (p *PhoenixCandidatePipeline) Execute(ctx context.Context, query *home.Query) (*home.PipelineResult, error) {
 	return p.Pipeline.Execute(ctx, query)
 }
+
+// ExecuteWithOptions 按给定选项执行管道（例如开启 explain 模式排查候选去向）
+func (p *PhoenixCandidatePipeline) ExecuteWithOptions(ctx context.Context, query *home.Query, opts pipeline.ExecuteOptions) (*home.PipelineResult, error) {
+	return p.Pipeline.ExecuteWithOptions(ctx, query, opts)
+}
+
+// ExecuteScored 在缓存的已打分候选上执行过滤、选择和之后的阶段（用于分页，见 SessionCache）
+func (p *PhoenixCandidatePipeline) ExecuteScored(ctx context.Context, query *home.Query, candidates []*home.Candidate, opts pipeline.ExecuteOptions) (*home.PipelineResult, error) {
+	return p.Pipeline.ExecuteScored(ctx, query, candidates, opts)
+}
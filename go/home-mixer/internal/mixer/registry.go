@@ -0,0 +1,341 @@
+package mixer
+
+import (
+	"fmt"
+	"time"
+
+	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
+	"x-algorithm-go/home-mixer/internal/clients"
+	"x-algorithm-go/home-mixer/internal/filters"
+	"x-algorithm-go/home-mixer/internal/hydrators"
+	"x-algorithm-go/home-mixer/internal/query_hydrators"
+	"x-algorithm-go/home-mixer/internal/scorers"
+	"x-algorithm-go/home-mixer/internal/selectors"
+	"x-algorithm-go/home-mixer/internal/side_effects"
+	"x-algorithm-go/home-mixer/internal/sources"
+)
+
+// 组件参数
+// 未在定义文件中给出的字段使用注册时的默认值（大多来自 PipelineConfig）
+
+// MaxResultsParams 是 Source 的参数
+type MaxResultsParams struct {
+	MaxResults int `json:"max_results"`
+}
+
+// Validate 实现 pipeline.ParamsValidator
+func (p *MaxResultsParams) Validate() error {
+	if p.MaxResults <= 0 {
+		return fmt.Errorf("max_results must be > 0, got %d", p.MaxResults)
+	}
+	return nil
+}
+
+// AgeFilterParams 是 AgeFilter 的参数
+type AgeFilterParams struct {
+	MaxAge pipeline.Duration `json:"max_age"`
+}
+
+// Validate 实现 pipeline.ParamsValidator
+func (p *AgeFilterParams) Validate() error {
+	if p.MaxAge <= 0 {
+		return fmt.Errorf("max_age must be > 0, got %s", time.Duration(p.MaxAge))
+	}
+	return nil
+}
+
+// AuthorDiversityParams 是 AuthorDiversityScorer 的参数
+type AuthorDiversityParams struct {
+	DecayFactor float64 `json:"decay_factor"`
+	Floor       float64 `json:"floor"`
+}
+
+// Validate 实现 pipeline.ParamsValidator
+func (p *AuthorDiversityParams) Validate() error {
+	if p.DecayFactor < 0 || p.DecayFactor > 1 {
+		return fmt.Errorf("decay_factor must be in [0, 1], got %v", p.DecayFactor)
+	}
+	if p.Floor < 0 || p.Floor > 1 {
+		return fmt.Errorf("floor must be in [0, 1], got %v", p.Floor)
+	}
+	return nil
+}
+
+// OONParams 是 OONScorer 的参数
+type OONParams struct {
+	WeightFactor float64 `json:"weight_factor"`
+}
+
+// Validate 实现 pipeline.ParamsValidator
+func (p *OONParams) Validate() error {
+	if p.WeightFactor < 0 {
+		return fmt.Errorf("weight_factor must be >= 0, got %v", p.WeightFactor)
+	}
+	return nil
+}
+
+// HeuristicPreRankerParams 是 HeuristicPreRanker 的参数
+type HeuristicPreRankerParams struct {
+	RecencyWeight    float64           `json:"recency_weight"`
+	AffinityWeight   float64           `json:"affinity_weight"`
+	EngagementWeight float64           `json:"engagement_weight"`
+	RecencyHalfLife  pipeline.Duration `json:"recency_half_life"`
+	MultiSourceBonus float64           `json:"multi_source_bonus"`
+}
+
+// Validate 实现 pipeline.ParamsValidator
+func (p *HeuristicPreRankerParams) Validate() error {
+	if p.RecencyWeight < 0 || p.AffinityWeight < 0 || p.EngagementWeight < 0 || p.MultiSourceBonus < 0 {
+		return fmt.Errorf("weights must be >= 0, got recency=%v affinity=%v engagement=%v multi_source_bonus=%v",
+			p.RecencyWeight, p.AffinityWeight, p.EngagementWeight, p.MultiSourceBonus)
+	}
+	if p.RecencyHalfLife <= 0 {
+		return fmt.Errorf("recency_half_life must be > 0, got %s", time.Duration(p.RecencyHalfLife))
+	}
+	return nil
+}
+
+// FallbackParams 是 FallbackScorer 的参数
+type FallbackParams struct {
+	RecencyWeight    float64           `json:"recency_weight"`
+	AffinityWeight   float64           `json:"affinity_weight"`
+	InNetworkWeight  float64           `json:"in_network_weight"`
+	PopularityWeight float64           `json:"popularity_weight"`
+	RecencyHalfLife  pipeline.Duration `json:"recency_half_life"`
+	Scale            float64           `json:"scale"`
+}
+
+// Validate 实现 pipeline.ParamsValidator
+func (p *FallbackParams) Validate() error {
+	if p.RecencyWeight < 0 || p.AffinityWeight < 0 || p.InNetworkWeight < 0 || p.PopularityWeight < 0 {
+		return fmt.Errorf("weights must be >= 0, got recency=%v affinity=%v in_network=%v popularity=%v",
+			p.RecencyWeight, p.AffinityWeight, p.InNetworkWeight, p.PopularityWeight)
+	}
+	if p.RecencyWeight+p.AffinityWeight+p.InNetworkWeight+p.PopularityWeight == 0 {
+		return fmt.Errorf("at least one weight must be > 0")
+	}
+	if p.RecencyHalfLife <= 0 {
+		return fmt.Errorf("recency_half_life must be > 0, got %s", time.Duration(p.RecencyHalfLife))
+	}
+	if p.Scale <= 0 {
+		return fmt.Errorf("scale must be > 0, got %v", p.Scale)
+	}
+	return nil
+}
+
+// TopKParams 是 TopKScoreSelector 的参数
+type TopKParams struct {
+	K int `json:"k"`
+}
+
+// Validate 实现 pipeline.ParamsValidator
+func (p *TopKParams) Validate() error {
+	if p.K <= 0 {
+		return fmt.Errorf("k must be > 0, got %d", p.K)
+	}
+	return nil
+}
+
+// NewComponentRegistry 创建注册了 home-mixer 全部组件的注册表
+// 组件以 Name() 的返回值注册，与日志和 Deadlines.Component 中的名称一致。
+// 未注入的客户端使用 mock 实现。
+func NewComponentRegistry(config *PipelineConfig) *home.Registry {
+	c := resolveClients(config)
+	r := home.NewRegistry()
+	noParams := func() pipeline.NoParams { return pipeline.NoParams{} }
+
+	// Query Hydrators
+	pipeline.RegisterQueryHydrator(r, "UserActionSeqQueryHydrator", noParams, func(pipeline.NoParams) (home.QueryHydrator, error) {
+		return query_hydrators.NewUserActionSeqQueryHydrator(c.uasFetcher), nil
+	})
+	pipeline.RegisterQueryHydrator(r, "UserFeaturesQueryHydrator", noParams, func(pipeline.NoParams) (home.QueryHydrator, error) {
+		return query_hydrators.NewUserFeaturesQueryHydrator(c.stratoClient), nil
+	})
+
+	// Sources
+	pipeline.RegisterSource(r, "PhoenixSource", func() MaxResultsParams {
+		return MaxResultsParams{MaxResults: config.PhoenixMaxResults}
+	}, func(p MaxResultsParams) (home.Source, error) {
+		return sources.NewPhoenixSource(c.phoenixRetrievalClient, p.MaxResults), nil
+	})
+	pipeline.RegisterSource(r, "ThunderSource", func() MaxResultsParams {
+		return MaxResultsParams{MaxResults: config.ThunderMaxResults}
+	}, func(p MaxResultsParams) (home.Source, error) {
+		return sources.NewThunderSource(c.thunderClient, p.MaxResults), nil
+	})
+
+	// Merger
+	pipeline.RegisterMerger(r, "SourceMerger", home.DefaultMergePolicy, func(p home.MergePolicy) (home.CandidateMerger, error) {
+		return home.NewSourceMerger(p), nil
+	})
+
+	// Hydrators
+	pipeline.RegisterHydrator(r, "InNetworkCandidateHydrator", noParams, func(pipeline.NoParams) (home.Hydrator, error) {
+		return hydrators.NewInNetworkCandidateHydrator(), nil
+	})
+	pipeline.RegisterHydrator(r, hydrators.CoreDataHydratorName, noParams, func(pipeline.NoParams) (home.Hydrator, error) {
+		return hydrators.NewCoreDataCandidateHydrator(c.tesClient), nil
+	})
+	pipeline.RegisterHydrator(r, "VideoDurationCandidateHydrator", noParams, func(pipeline.NoParams) (home.Hydrator, error) {
+		return hydrators.NewVideoDurationCandidateHydrator(c.tesClient), nil
+	})
+	pipeline.RegisterHydrator(r, "SubscriptionHydrator", noParams, func(pipeline.NoParams) (home.Hydrator, error) {
+		return hydrators.NewSubscriptionHydrator(c.tesClient), nil
+	})
+	pipeline.RegisterHydrator(r, "GizmoduckCandidateHydrator", noParams, func(pipeline.NoParams) (home.Hydrator, error) {
+		return hydrators.NewGizmoduckCandidateHydrator(c.gizmoduckClient), nil
+	})
+	pipeline.RegisterHydrator(r, "VFCandidateHydrator", noParams, func(pipeline.NoParams) (home.Hydrator, error) {
+		return hydrators.NewVFCandidateHydrator(c.vfClient), nil
+	})
+
+	// Filters
+	simpleFilters := map[string]func() home.Filter{
+		"DropDuplicatesFilter":         func() home.Filter { return filters.NewDropDuplicatesFilter() },
+		"CoreDataHydrationFilter":      func() home.Filter { return filters.NewCoreDataHydrationFilter() },
+		"SelfTweetFilter":              func() home.Filter { return filters.NewSelfTweetFilter() },
+		"RetweetDeduplicationFilter":   func() home.Filter { return filters.NewRetweetDeduplicationFilter() },
+		"IneligibleSubscriptionFilter": func() home.Filter { return filters.NewIneligibleSubscriptionFilter() },
+		"PreviouslySeenPostsFilter":    func() home.Filter { return filters.NewPreviouslySeenPostsFilter() },
+		"PreviouslyServedPostsFilter":  func() home.Filter { return filters.NewPreviouslyServedPostsFilter() },
+		"MutedKeywordFilter":           func() home.Filter { return filters.NewMutedKeywordFilter() },
+		"AuthorSocialgraphFilter":      func() home.Filter { return filters.NewAuthorSocialgraphFilter() },
+		"VFFilter":                     func() home.Filter { return filters.NewVFFilter() },
+		"DedupConversationFilter":      func() home.Filter { return filters.NewDedupConversationFilter() },
+	}
+	for name, newFilter := range simpleFilters {
+		newFilter := newFilter
+		pipeline.RegisterFilter(r, name, noParams, func(pipeline.NoParams) (home.Filter, error) {
+			return newFilter(), nil
+		})
+	}
+	pipeline.RegisterFilter(r, "AgeFilter", func() AgeFilterParams {
+		return AgeFilterParams{MaxAge: pipeline.Duration(config.MaxAge)}
+	}, func(p AgeFilterParams) (home.Filter, error) {
+		return filters.NewAgeFilter(time.Duration(p.MaxAge)), nil
+	})
+
+	// Scorers
+	pipeline.RegisterScorer(r, "PhoenixScorer", noParams, func(pipeline.NoParams) (home.Scorer, error) {
+		return scorers.NewPhoenixScorer(c.phoenixRankingClient), nil
+	})
+	pipeline.RegisterScorer(r, "CalibrationScorer", noParams, func(pipeline.NoParams) (home.Scorer, error) {
+		return scorers.NewCalibrationScorer(config.Calibration), nil
+	})
+	// 权重不在管道定义中配置：所有管道（包括影子管道）共用 PipelineConfig.Weights，随权重文件热加载
+	weights := config.Weights
+	if weights == nil {
+		weights = scorers.NewWeightsStore(nil)
+	}
+	pipeline.RegisterScorer(r, "WeightedScorer", noParams, func(pipeline.NoParams) (home.Scorer, error) {
+		return scorers.NewWeightedScorer(weights), nil
+	})
+	pipeline.RegisterScorer(r, "FallbackScorer", func() FallbackParams {
+		d := scorers.DefaultFallbackScorer()
+		return FallbackParams{
+			RecencyWeight:    d.RecencyWeight,
+			AffinityWeight:   d.AffinityWeight,
+			InNetworkWeight:  d.InNetworkWeight,
+			PopularityWeight: d.PopularityWeight,
+			RecencyHalfLife:  pipeline.Duration(d.RecencyHalfLife),
+			Scale:            d.Scale,
+		}
+	}, func(p FallbackParams) (home.Scorer, error) {
+		return scorers.NewFallbackScorer(p.RecencyWeight, p.AffinityWeight, p.InNetworkWeight, p.PopularityWeight, time.Duration(p.RecencyHalfLife), p.Scale), nil
+	})
+	pipeline.RegisterScorer(r, "AuthorDiversityScorer", func() AuthorDiversityParams {
+		d := scorers.DefaultAuthorDiversityScorer()
+		return AuthorDiversityParams{DecayFactor: d.DecayFactor, Floor: d.Floor}
+	}, func(p AuthorDiversityParams) (home.Scorer, error) {
+		return scorers.NewAuthorDiversityScorer(p.DecayFactor, p.Floor), nil
+	})
+	pipeline.RegisterScorer(r, "OONScorer", func() OONParams {
+		return OONParams{WeightFactor: scorers.DefaultOONScorer().OONWeightFactor}
+	}, func(p OONParams) (home.Scorer, error) {
+		return scorers.NewOONScorer(p.WeightFactor), nil
+	})
+
+	pipeline.RegisterScorer(r, "HeuristicPreRanker", func() HeuristicPreRankerParams {
+		d := scorers.DefaultHeuristicPreRanker()
+		return HeuristicPreRankerParams{
+			RecencyWeight:    d.RecencyWeight,
+			AffinityWeight:   d.AffinityWeight,
+			EngagementWeight: d.EngagementWeight,
+			RecencyHalfLife:  pipeline.Duration(d.RecencyHalfLife),
+			MultiSourceBonus: d.MultiSourceBonus,
+		}
+	}, func(p HeuristicPreRankerParams) (home.Scorer, error) {
+		return scorers.NewHeuristicPreRanker(p.RecencyWeight, p.AffinityWeight, p.EngagementWeight, time.Duration(p.RecencyHalfLife), p.MultiSourceBonus), nil
+	})
+
+	// Selector
+	pipeline.RegisterSelector(r, "TopKScoreSelector", func() TopKParams {
+		return TopKParams{K: config.TopK}
+	}, func(p TopKParams) (home.Selector, error) {
+		return selectors.NewTopKScoreSelector(p.K), nil
+	})
+
+	// Side Effects
+	pipeline.RegisterSideEffect(r, "CacheRequestInfoSideEffect", noParams, func(pipeline.NoParams) (home.SideEffect, error) {
+		return side_effects.NewCacheRequestInfoSideEffect(c.stratoClientForCache), nil
+	})
+
+	return r
+}
+
+// resolvedClients 是补齐 mock 实现之后的客户端集合
+type resolvedClients struct {
+	thunderClient          sources.ThunderClient
+	phoenixRetrievalClient sources.PhoenixRetrievalClient
+	phoenixRankingClient   scorers.PhoenixRankingClient
+	tesClient              hydrators.TweetEntityServiceClient
+	gizmoduckClient        hydrators.GizmoduckClient
+	vfClient               hydrators.VisibilityFilteringClient
+	uasFetcher             query_hydrators.UserActionSequenceFetcher
+	stratoClient           query_hydrators.StratoClient
+	stratoClientForCache   side_effects.StratoClient
+}
+
+// resolveClients 返回 config 中注入的客户端，未注入的使用 mock 实现（用于本地学习/测试）
+func resolveClients(config *PipelineConfig) resolvedClients {
+	c := resolvedClients{
+		thunderClient:          config.ThunderClient,
+		phoenixRetrievalClient: config.PhoenixRetrievalClient,
+		phoenixRankingClient:   config.PhoenixRankingClient,
+		tesClient:              config.TESClient,
+		gizmoduckClient:        config.GizmoduckClient,
+		vfClient:               config.VFClient,
+		uasFetcher:             config.UASFetcher,
+		stratoClient:           config.StratoClient,
+		stratoClientForCache:   config.StratoClientForCache,
+	}
+	if c.thunderClient == nil {
+		c.thunderClient = clients.NewMockThunderClient()
+	}
+	if c.phoenixRetrievalClient == nil {
+		c.phoenixRetrievalClient = clients.NewMockPhoenixRetrievalClient()
+	}
+	if c.phoenixRankingClient == nil {
+		c.phoenixRankingClient = scorers.NewMockPhoenixRankingClient()
+	}
+	if c.tesClient == nil {
+		c.tesClient = clients.NewMockTESClient()
+	}
+	if c.gizmoduckClient == nil {
+		c.gizmoduckClient = clients.NewMockGizmoduckClient()
+	}
+	if c.vfClient == nil {
+		c.vfClient = clients.NewMockVFClient()
+	}
+	if c.uasFetcher == nil {
+		c.uasFetcher = clients.NewMockUASFetcher()
+	}
+	if c.stratoClient == nil {
+		c.stratoClient = clients.NewMockStratoClient()
+	}
+	if c.stratoClientForCache == nil {
+		c.stratoClientForCache = clients.NewMockStratoClientForCache()
+	}
+	return c
+}
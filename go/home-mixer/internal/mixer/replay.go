@@ -0,0 +1,38 @@
+package mixer
+
+import "x-algorithm-go/home-mixer/internal/replay"
+
+// RecordClients 把 config 中的客户端替换为录制客户端（未注入的客户端先补齐 mock 实现）
+// 带有 replay.Session 的请求会录制全部外部调用，其他请求不受影响
+func RecordClients(config *PipelineConfig) {
+	c := resolveClients(config)
+	setClients(config, replay.RecordingClients(replay.Clients{
+		Thunder:          c.thunderClient,
+		PhoenixRetrieval: c.phoenixRetrievalClient,
+		PhoenixRanking:   c.phoenixRankingClient,
+		TES:              c.tesClient,
+		Gizmoduck:        c.gizmoduckClient,
+		VF:               c.vfClient,
+		UAS:              c.uasFetcher,
+		Strato:           c.stratoClient,
+		StratoForCache:   c.stratoClientForCache,
+	}))
+}
+
+// ReplayClients 把 config 中的客户端替换为回放客户端
+// 管道执行时从 ctx 中的 replay.Recording 读取响应（见 replay.WithRecording）
+func ReplayClients(config *PipelineConfig) {
+	setClients(config, replay.ReplayClients())
+}
+
+func setClients(config *PipelineConfig, c replay.Clients) {
+	config.ThunderClient = c.Thunder
+	config.PhoenixRetrievalClient = c.PhoenixRetrieval
+	config.PhoenixRankingClient = c.PhoenixRanking
+	config.TESClient = c.TES
+	config.GizmoduckClient = c.Gizmoduck
+	config.VFClient = c.VF
+	config.UASFetcher = c.UAS
+	config.StratoClient = c.Strato
+	config.StratoClientForCache = c.StratoForCache
+}
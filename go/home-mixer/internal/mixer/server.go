@@ -2,33 +2,85 @@ package mixer
 
 import (
 	"context"
+	"errors"
 	"log"
 	"strconv"
 	"time"
 
 	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
+	"x-algorithm-go/home-mixer/internal/replay"
+	"x-algorithm-go/home-mixer/internal/scorers"
 	"x-algorithm-go/home-mixer/internal/utils"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 
-	// 这里假设 proto 生成的代码在 pkg/proto 包中
-	// 实际使用时需要先运行 protoc 生成代码
 	pb "x-algorithm-go/proto"
 )
 
 // HomeMixerServer 实现 gRPC 服务
 type HomeMixerServer struct {
 	pb.UnimplementedScoredPostsServiceServer
-	pipeline *pipeline.CandidatePipeline
+	pipeline    *home.CandidatePipeline
+	recorder    *replay.Recorder // 为 nil 时不录制
+	shadow      *ShadowRunner    // 为 nil 时不执行影子管道
+	sessions    *SessionCache    // 为 nil 时分页请求总是执行完整管道
+	admission   *Admission       // 为 nil 时不合并请求、不限制并发
+	debugAccess *DebugAccess     // 为 nil 时关闭调试接口
+	ranking     RankingObserver  // 为 nil 时不上报排序质量
+	weights     *scorers.WeightsStore // 管道 WeightedScorer 使用的权重，执行开始时记录其版本
+}
+
+// RankingObserver 接收每个响应的排序情况（例如导出为指标，观察降级排序的比例）
+type RankingObserver interface {
+	// Ranked 在返回响应前调用：posts 是响应中的帖子数，fallbackRanked 是其中没有 Phoenix 预测、按降级分数排序的帖子数
+	Ranked(posts, fallbackRanked int)
 }
 
 // NewHomeMixerServer 创建新的 HomeMixerServer 实例
-func NewHomeMixerServer(p *pipeline.CandidatePipeline) *HomeMixerServer {
+func NewHomeMixerServer(p *home.CandidatePipeline) *HomeMixerServer {
 	return &HomeMixerServer{
 		pipeline: p,
+		weights:  scorers.NewWeightsStore(nil),
 	}
 }
 
+// SetWeights 设置管道 WeightedScorer 使用的权重（PipelineConfig.Weights）
+// 每个请求在执行开始时记录其当前版本，并在响应中返回；未设置时为默认权重（与 PipelineConfig.Weights 为 nil 时一致）
+func (s *HomeMixerServer) SetWeights(weights *scorers.WeightsStore) {
+	s.weights = weights
+}
+
+// SetRecorder 按采样率把请求录制为回放文件
+// 管道需要使用录制客户端（见 RecordClients），否则回放文件中只有 Query 和结果
+func (s *HomeMixerServer) SetRecorder(recorder *replay.Recorder) {
+	s.recorder = recorder
+}
+
+// SetShadow 按采样率在后台执行影子管道并与主结果比较
+func (s *HomeMixerServer) SetShadow(shadow *ShadowRunner) {
+	s.shadow = shadow
+}
+
+// SetSessionCache 缓存每个会话第一页的排序结果，分页请求优先从缓存产出
+func (s *HomeMixerServer) SetSessionCache(sessions *SessionCache) {
+	s.sessions = sessions
+}
+
+// SetRankingObserver 上报每个响应中降级排序（没有 Phoenix 预测）的帖子数
+func (s *HomeMixerServer) SetRankingObserver(observer RankingObserver) {
+	s.ranking = observer
+}
+
+// SetAdmission 合并同一用户的相同在途请求，并限制同时执行的管道数
+func (s *HomeMixerServer) SetAdmission(admission *Admission) {
+	s.admission = admission
+}
+
 // GetScoredPosts 处理获取排序后帖子的请求
 func (s *HomeMixerServer) GetScoredPosts(
 	ctx context.Context,
@@ -36,12 +88,122 @@ func (s *HomeMixerServer) GetScoredPosts(
 ) (*pb.ScoredPostsResponse, error) {
 	start := time.Now()
 
-	// 1) 参数校验
+	// 1) 参数校验，构建内部 Query
+	query, err := queryFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	// 记录执行开始时生效的权重版本：本次请求按该版本打分，响应也返回该版本
+	query.WeightsVersion = s.weights.Load().Version
+
+	log.Printf("Scored Posts request - request_id %s", query.RequestID)
+
+	// 2) 执行候选管道（准入控制：合并相同的在途请求，过载时拒绝）
+	pipelineResult, err := s.admission.Do(ctx, query, func(ctx context.Context) (*home.PipelineResult, error) {
+		return s.execute(ctx, query)
+	})
+	if err != nil {
+		var overloaded *OverloadedError
+		if errors.As(err, &overloaded) {
+			log.Printf("request_id=%s shed: %v", query.RequestID, overloaded)
+			return nil, overloadedStatus(ctx, overloaded)
+		}
+		// 根据错误类型决定返回的 gRPC 状态码
+		return nil, pipelineErrorStatus(err)
+	}
+	if pipelineResult.Query.RequestID != query.RequestID {
+		log.Printf("request_id=%s coalesced into request_id=%s", query.RequestID, pipelineResult.Query.RequestID)
+	}
+
+	// 3) 转换为响应格式
+	scoredPosts := scoredPostsFromResult(pipelineResult)
+	fallbackRanked := countFallbackRanked(scoredPosts)
+	version := pipelineResult.Query.WeightsVersion
+	if s.ranking != nil {
+		s.ranking.Ranked(len(scoredPosts), fallbackRanked)
+	}
+
+	// 实验分组和权重版本随响应一起记录，用于离线分析
+	log.Printf(
+		"Scored Posts response - request_id %s - %d posts (%d ms) experiments=%s fallback_ranked=%d weights_version=%s",
+		pipelineResult.Query.RequestID,
+		len(scoredPosts),
+		time.Since(start).Milliseconds(),
+		pipelineResult.Query.Experiments,
+		fallbackRanked,
+		version,
+	)
+
+	return &pb.ScoredPostsResponse{
+		ScoredPosts:     scoredPosts,
+		RankingDegraded: fallbackRanked > 0,
+		WeightsVersion:  version,
+	}, nil
+}
+
+// execute 执行候选管道
+// 同一会话的分页请求优先在缓存的候选上执行，缓存不可用时执行完整管道（被采样的请求录制全部外部调用）
+func (s *HomeMixerServer) execute(ctx context.Context, query *home.Query) (*home.PipelineResult, error) {
+	if page := s.sessions.Lookup(query); page != nil {
+		result, err := s.pipeline.ExecuteScored(ctx, page.Query, page.Candidates, pipeline.ExecuteOptions{})
+		if s.sessions.Advance(page, result, err) {
+			return result, nil
+		}
+		if err != nil {
+			log.Printf("request_id=%s session=%s cached page failed, running full pipeline: %v", query.RequestID, query.SessionID, err)
+		}
+	}
+
+	shadowQuery := s.shadow.Fork(query)
+	ctx, session := s.recorder.Start(ctx, query)
+	pipelineResult, err := s.pipeline.Execute(ctx, query)
+	s.recorder.Finish(session, pipelineResult, err)
+	if err != nil {
+		return nil, err
+	}
+	// 影子管道在后台执行，不影响本次响应
+	s.shadow.Run(ctx, shadowQuery, pipelineResult)
+	s.sessions.Store(query, pipelineResult)
+	return pipelineResult, nil
+}
+
+// pipelineErrorStatus 把管道错误映射为 gRPC 状态
+// critical 组件失败说明下游依赖不可用，客户端可以重试；超时和取消保留原语义
+func pipelineErrorStatus(err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Errorf(codes.DeadlineExceeded, "pipeline execute failed: %v", err)
+	case errors.Is(err, context.Canceled):
+		return status.Errorf(codes.Canceled, "pipeline execute failed: %v", err)
+	}
+	var componentErr *pipeline.ComponentError
+	if errors.As(err, &componentErr) {
+		return status.Errorf(codes.Unavailable, "pipeline execute failed: %v", err)
+	}
+	return status.Errorf(codes.Internal, "pipeline execute failed: %v", err)
+}
+
+// overloadedStatus 返回过载时的 RESOURCE_EXHAUSTED 状态
+// 重试建议同时放在状态详情（RetryInfo）和 grpc-retry-pushback-ms trailer 中，后者由 gRPC 客户端的重试策略识别
+func overloadedStatus(ctx context.Context, err *OverloadedError) error {
+	// 不是通过 gRPC 服务器调用时（例如测试）SetTrailer 返回错误，忽略即可
+	_ = grpc.SetTrailer(ctx, metadata.Pairs("grpc-retry-pushback-ms", strconv.FormatInt(err.RetryAfter.Milliseconds(), 10)))
+	st := status.New(codes.ResourceExhausted, err.Error())
+	if withDetails, detailErr := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(err.RetryAfter)}); detailErr == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// queryFromRequest 校验请求并构建内部 Query
+func queryFromRequest(req *pb.ScoredPostsQuery) (*home.Query, error) {
 	if req.ViewerId == 0 {
 		return nil, status.Error(codes.InvalidArgument, "viewer_id must be specified")
 	}
+	if req.PreRankSize < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "pre_rank_size must be >= 0, got %d", req.PreRankSize)
+	}
 
-	// 2) 构建内部 Query
 	query := NewScoredPostsQuery(
 		req.ViewerId,
 		req.ClientAppId,
@@ -53,27 +215,18 @@ func (s *HomeMixerServer) GetScoredPosts(
 		req.IsBottomRequest,
 		convertBloomFilterEntries(req.BloomFilterEntries),
 	)
-
-	log.Printf("Scored Posts request - request_id %s", query.RequestID)
-
-	// 3) 执行候选管道
-	pipelineResult, err := s.pipeline.Execute(ctx, query)
-	if err != nil {
-		// 根据错误类型决定返回的 gRPC 状态码
-		return nil, status.Errorf(codes.Internal, "pipeline execute failed: %v", err)
+	// 请求可以覆盖进入重排的候选上限（级联排序）
+	if req.PreRankSize > 0 {
+		query.PreRankSize = int(req.PreRankSize)
 	}
+	query.SessionID = req.SessionId
+	return query, nil
+}
 
-	// 4) 转换为响应格式
+// scoredPostsFromResult 把管道选中的候选转换为响应格式
+func scoredPostsFromResult(pipelineResult *home.PipelineResult) []*pb.ScoredPost {
 	scoredPosts := make([]*pb.ScoredPost, 0, len(pipelineResult.SelectedCandidates))
 	for _, c := range pipelineResult.SelectedCandidates {
-		screenNames := c.GetScreenNames()
-		
-		// 转换为 map[string]string（proto 的 map 键必须是 string）
-		screenNamesMap := make(map[string]string)
-		for k, v := range screenNames {
-			screenNamesMap[uint64ToString(k)] = v
-		}
-
 		var retweetedTweetID uint64
 		if c.RetweetedTweetID != nil {
 			retweetedTweetID = *c.RetweetedTweetID
@@ -96,7 +249,7 @@ func (s *HomeMixerServer) GetScoredPosts(
 		}
 		var servedType int32
 		if c.ServedType != nil {
-			servedType = *c.ServedType
+			servedType = int32(*c.ServedType)
 		}
 		var lastScoredTimestampMs uint64
 		if c.LastScoredAtMs != nil {
@@ -123,19 +276,23 @@ func (s *HomeMixerServer) GetScoredPosts(
 			LastScoredTimestampMs: lastScoredTimestampMs,
 			PredictionRequestId:   predictionRequestID,
 			Ancestors:             c.Ancestors,
-			ScreenNames:           screenNamesMap,
+			ScreenNames:           c.GetScreenNames(),
 			VisibilityReason:      visibilityReason,
+			FallbackRanked:        scorers.PhoenixPredictionsMissing(c),
 		})
 	}
+	return scoredPosts
+}
 
-	log.Printf(
-		"Scored Posts response - request_id %s - %d posts (%d ms)",
-		pipelineResult.Query.RequestID,
-		len(scoredPosts),
-		time.Since(start).Milliseconds(),
-	)
-
-	return &pb.ScoredPostsResponse{ScoredPosts: scoredPosts}, nil
+// countFallbackRanked 返回没有 Phoenix 预测、按降级的启发式分数排序的帖子数
+func countFallbackRanked(scoredPosts []*pb.ScoredPost) int {
+	n := 0
+	for _, p := range scoredPosts {
+		if p.FallbackRanked {
+			n++
+		}
+	}
+	return n
 }
 
 // NewScoredPostsQuery 从 gRPC 请求构建内部 Query 对象
@@ -148,13 +305,17 @@ func NewScoredPostsQuery(
 	servedIDs []int64,
 	inNetworkOnly bool,
 	isBottomRequest bool,
-	bloomFilterEntries []pipeline.BloomFilterEntry,
-) *pipeline.Query {
+	bloomFilterEntries []home.BloomFilterEntry,
+) *home.Query {
 	// 生成 request_id（简化实现，实际应该使用更复杂的生成逻辑）
 	requestID := generateRequestID(viewerID)
 
-	return &pipeline.Query{
-		UserID:            viewerID,
+	return &home.Query{
+		RequestMeta: pipeline.RequestMeta{
+			UserID:        viewerID,
+			RequestID:     requestID,
+			RequestTimeMs: time.Now().UnixMilli(),
+		},
 		ClientAppID:       clientAppID,
 		CountryCode:       countryCode,
 		LanguageCode:      languageCode,
@@ -163,20 +324,19 @@ func NewScoredPostsQuery(
 		InNetworkOnly:     inNetworkOnly,
 		IsBottomRequest:   isBottomRequest,
 		BloomFilterEntries: bloomFilterEntries,
-		RequestID:         requestID,
-		UserFeatures:      pipeline.UserFeatures{}, // 初始为空，由 Query Hydrators 填充
+		UserFeatures:      home.UserFeatures{}, // 初始为空，由 Query Hydrators 填充
 	}
 }
 
 // convertBloomFilterEntries 转换 proto 的 BloomFilterEntry 到内部类型
-func convertBloomFilterEntries(entries []*pb.BloomFilterEntry) []pipeline.BloomFilterEntry {
+func convertBloomFilterEntries(entries []*pb.BloomFilterEntry) []home.BloomFilterEntry {
 	if entries == nil {
 		return nil
 	}
-	result := make([]pipeline.BloomFilterEntry, len(entries))
+	result := make([]home.BloomFilterEntry, len(entries))
 	for i, entry := range entries {
 		// 将proto的BloomFilterEntry转换为内部类型
-		result[i] = pipeline.BloomFilterEntry{
+		result[i] = home.BloomFilterEntry{
 			Data: entry.Data, // proto的BloomFilterEntry包含bytes data字段
 		}
 	}
@@ -187,8 +347,3 @@ func convertBloomFilterEntries(entries []*pb.BloomFilterEntry) []pipeline.BloomF
 func generateRequestID(userID int64) string {
 	return utils.GenerateRequestID(userID)
 }
-
-// 辅助函数
-func uint64ToString(v uint64) string {
-	return strconv.FormatUint(v, 10)
-}
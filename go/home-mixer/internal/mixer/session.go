@@ -0,0 +1,255 @@
+package mixer
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
+)
+
+// SessionCacheConfig 配置分页会话缓存
+type SessionCacheConfig struct {
+	TTL           time.Duration // 第一页排序结果的有效期，过期后重新执行完整管道
+	MaxSessions   int           // 缓存的会话数上限，超过时淘汰最早写入的会话
+	MinCandidates int           // 缓存中剩余的候选少于该值时视为耗尽，重新执行完整管道
+}
+
+// DefaultSessionCacheConfig 返回默认的会话缓存配置
+func DefaultSessionCacheConfig() SessionCacheConfig {
+	return SessionCacheConfig{
+		TTL:           5 * time.Minute,
+		MaxSessions:   100000,
+		MinCandidates: 50,
+	}
+}
+
+// SessionOutcome 是分页请求查询会话缓存的结果
+type SessionOutcome string
+
+const (
+	SessionHit       SessionOutcome = "hit"       // 从缓存产出了分页结果
+	SessionMiss      SessionOutcome = "miss"      // 没有该会话的缓存
+	SessionStale     SessionOutcome = "stale"     // 缓存已过期
+	SessionExhausted SessionOutcome = "exhausted" // 缓存中剩余的候选不足，或重新过滤后没有结果
+	SessionFailed    SessionOutcome = "failed"    // 在缓存的候选上执行管道失败
+)
+
+// SessionObserver 接收会话缓存的查询结果（例如导出为指标）
+type SessionObserver interface {
+	SessionLookup(outcome SessionOutcome)
+}
+
+// sessionKey 按用户和会话区分缓存
+type sessionKey struct {
+	userID    int64
+	sessionID string
+}
+
+// sessionEntry 是一个会话缓存的排序结果
+type sessionEntry struct {
+	key        sessionKey
+	query      *home.Query       // 第一页增强后的查询
+	candidates []*home.Candidate // 打分完成但尚未服务的候选
+	createdAt  time.Time         // 第一页执行完整管道的时间，之后的分页不会延长有效期
+	elem       *list.Element
+}
+
+// SessionPage 是从会话缓存取出的一页的输入，交给 CandidatePipeline.ExecuteScored 执行
+type SessionPage struct {
+	Query      *home.Query       // 本次请求的查询，增强字段来自缓存
+	Candidates []*home.Candidate // 缓存的候选的拷贝
+
+	key       sessionKey
+	createdAt time.Time
+}
+
+// SessionCache 缓存每个会话第一页的增强后查询和打分后未服务的候选
+//
+// 分页请求（IsBottomRequest）原本会重新执行完整管道：UAS、Strato 特征、Thunder / Phoenix 检索
+// 和 Phoenix 打分，最后只是由 PreviouslyServedPostsFilter 去掉已经服务过的帖子。
+// 有缓存时，分页请求在缓存的候选上重新执行 Filters 和 Selection（见 CandidatePipeline.ExecuteScored），
+// 缓存过期或耗尽时才回退到完整管道。
+type SessionCache struct {
+	config   SessionCacheConfig
+	observer SessionObserver
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[sessionKey]*sessionEntry
+	order   *list.List // 按写入顺序排列的 sessionKey，用于淘汰
+}
+
+// NewSessionCache 创建 SessionCache，observer 可以为 nil
+func NewSessionCache(config SessionCacheConfig, observer SessionObserver) (*SessionCache, error) {
+	if config.TTL <= 0 {
+		return nil, fmt.Errorf("session cache ttl must be > 0, got %s", config.TTL)
+	}
+	if config.MaxSessions <= 0 {
+		return nil, fmt.Errorf("session cache max sessions must be > 0, got %d", config.MaxSessions)
+	}
+	if config.MinCandidates < 0 {
+		return nil, fmt.Errorf("session cache min candidates must be >= 0, got %d", config.MinCandidates)
+	}
+	return &SessionCache{
+		config:   config,
+		observer: observer,
+		now:      time.Now,
+		entries:  make(map[sessionKey]*sessionEntry),
+		order:    list.New(),
+	}, nil
+}
+
+// Lookup 为分页请求取出缓存的一页输入
+// 不是分页请求、没有会话 ID、缓存不存在 / 过期 / 耗尽时返回 nil，调用方应执行完整管道
+func (c *SessionCache) Lookup(query *home.Query) *SessionPage {
+	if c == nil || !query.IsBottomRequest || query.SessionID == "" {
+		return nil
+	}
+	key := sessionKey{userID: query.UserID, sessionID: query.SessionID}
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	var outcome SessionOutcome
+	switch {
+	case !ok:
+		outcome = SessionMiss
+	case c.now().Sub(entry.createdAt) > c.config.TTL:
+		outcome = SessionStale
+		c.removeLocked(entry)
+	case len(entry.candidates) == 0 || len(entry.candidates) < c.config.MinCandidates:
+		outcome = SessionExhausted
+		c.removeLocked(entry)
+	}
+	if outcome != "" {
+		c.mu.Unlock()
+		c.report(outcome)
+		return nil
+	}
+	cached, candidates, createdAt := entry.query, entry.candidates, entry.createdAt
+	c.mu.Unlock()
+
+	// 管道会修改候选（Post-Selection Hydrator），同一会话的并发请求各自使用拷贝
+	clones := make([]*home.Candidate, len(candidates))
+	for i, cand := range candidates {
+		clones[i] = cand.Clone()
+	}
+	return &SessionPage{
+		Query:      pageQuery(query, cached),
+		Candidates: clones,
+		key:        key,
+		createdAt:  createdAt,
+	}
+}
+
+// pageQuery 返回本次请求的查询，Query Hydrator 填充的字段、实验分组和权重版本取自缓存的第一页
+// （缓存的候选按第一页的权重打分）；RequestID、请求时间、SeenIDs / ServedIDs 和布隆过滤器等分页字段保留本次请求的值
+func pageQuery(query, cached *home.Query) *home.Query {
+	q := query.Clone()
+	hydrated := cached.Clone()
+	q.WeightsVersion = hydrated.WeightsVersion
+	q.Experiments = hydrated.Experiments
+	q.MissingHydrations = hydrated.MissingHydrations
+	q.UserActionSequence = hydrated.UserActionSequence
+	q.UserFeatures = hydrated.UserFeatures
+	return q
+}
+
+// Advance 记录在缓存的候选上执行的结果，返回该结果是否可以作为响应
+// 成功且有结果时从缓存中去掉本页服务和移除的候选；失败或重新过滤后没有结果时删除缓存，调用方应执行完整管道
+func (c *SessionCache) Advance(page *SessionPage, result *home.PipelineResult, err error) bool {
+	if c == nil || page == nil {
+		return false
+	}
+	switch {
+	case err != nil:
+		c.drop(page.key)
+		c.report(SessionFailed)
+		return false
+	case len(result.SelectedCandidates) == 0:
+		c.drop(page.key)
+		c.report(SessionExhausted)
+		return false
+	}
+	c.put(page.key, result.Query, unserved(result), page.createdAt)
+	c.report(SessionHit)
+	return true
+}
+
+// Store 缓存完整管道执行的结果，供同一会话之后的分页请求使用
+// 没有会话 ID 的请求不缓存
+func (c *SessionCache) Store(query *home.Query, result *home.PipelineResult) {
+	if c == nil || query.SessionID == "" || result == nil {
+		return
+	}
+	key := sessionKey{userID: query.UserID, sessionID: query.SessionID}
+	c.put(key, result.Query, unserved(result), c.now())
+}
+
+// unserved 返回打分完成、既未被选中也未在之后的阶段被移除的候选
+func unserved(result *home.PipelineResult) []*home.Candidate {
+	served := make(map[*home.Candidate]bool, len(result.SelectedCandidates)+len(result.FilteredCandidates))
+	for _, c := range result.SelectedCandidates {
+		served[c] = true
+	}
+	for _, c := range result.FilteredCandidates {
+		served[c] = true
+	}
+	remaining := make([]*home.Candidate, 0, len(result.ScoredCandidates))
+	for _, c := range result.ScoredCandidates {
+		if !served[c] {
+			remaining = append(remaining, c)
+		}
+	}
+	return remaining
+}
+
+func (c *SessionCache) put(key sessionKey, query *home.Query, candidates []*home.Candidate, createdAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if old, ok := c.entries[key]; ok {
+		c.removeLocked(old)
+	}
+	entry := &sessionEntry{key: key, query: query, candidates: candidates, createdAt: createdAt}
+	entry.elem = c.order.PushBack(key)
+	c.entries[key] = entry
+	// 淘汰超出上限的会话和排在最前面的过期会话（不再翻页的会话不会被 Lookup 清理）
+	now := c.now()
+	for c.order.Len() > 0 {
+		oldest := c.entries[c.order.Front().Value.(sessionKey)]
+		if c.order.Len() <= c.config.MaxSessions && now.Sub(oldest.createdAt) <= c.config.TTL {
+			break
+		}
+		c.removeLocked(oldest)
+	}
+}
+
+func (c *SessionCache) drop(key sessionKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.entries[key]; ok {
+		c.removeLocked(entry)
+	}
+}
+
+func (c *SessionCache) removeLocked(entry *sessionEntry) {
+	c.order.Remove(entry.elem)
+	delete(c.entries, entry.key)
+}
+
+func (c *SessionCache) report(outcome SessionOutcome) {
+	if c.observer != nil {
+		c.observer.SessionLookup(outcome)
+	}
+}
+
+// Len 返回缓存的会话数
+func (c *SessionCache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
@@ -0,0 +1,229 @@
+package mixer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
+)
+
+// sessionOutcomes 记录会话缓存的查询结果
+type sessionOutcomes []SessionOutcome
+
+func (o *sessionOutcomes) SessionLookup(outcome SessionOutcome) { *o = append(*o, outcome) }
+
+// newTestSessionCache 创建时钟可控的 SessionCache
+func newTestSessionCache(t *testing.T, config SessionCacheConfig) (*SessionCache, *sessionOutcomes, *time.Time) {
+	t.Helper()
+	outcomes := &sessionOutcomes{}
+	c, err := NewSessionCache(config, outcomes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	c.now = func() time.Time { return now }
+	return c, outcomes, &now
+}
+
+func sessionQuery(userID int64, sessionID string, bottom bool) *home.Query {
+	return &home.Query{
+		RequestMeta:     pipeline.RequestMeta{UserID: userID, RequestID: "page"},
+		SessionID:       sessionID,
+		IsBottomRequest: bottom,
+	}
+}
+
+// firstPage 返回第一页的结果：ids 都打完分，前 selected 个被选中
+func firstPage(query *home.Query, selected int, ids ...int64) *home.PipelineResult {
+	result := &home.PipelineResult{Query: query}
+	for i, id := range ids {
+		c := &home.Candidate{TweetID: id}
+		result.ScoredCandidates = append(result.ScoredCandidates, c)
+		if i < selected {
+			result.SelectedCandidates = append(result.SelectedCandidates, c)
+		}
+	}
+	return result
+}
+
+func TestSessionCacheLookup(t *testing.T) {
+	config := SessionCacheConfig{TTL: time.Minute, MaxSessions: 10, MinCandidates: 2}
+	tests := []struct {
+		name        string
+		stored      []int64 // 第一页打分的候选（第一个被选中），nil 表示没有缓存
+		age         time.Duration
+		query       *home.Query
+		wantPage    int // 取出的候选数，0 表示回退到完整管道
+		wantOutcome SessionOutcome
+	}{
+		{name: "hit", stored: []int64{1, 2, 3}, query: sessionQuery(42, "s", true), wantPage: 2},
+		{name: "miss", query: sessionQuery(42, "s", true), wantOutcome: SessionMiss},
+		{name: "other session", stored: []int64{1, 2, 3}, query: sessionQuery(42, "t", true), wantOutcome: SessionMiss},
+		{name: "other user", stored: []int64{1, 2, 3}, query: sessionQuery(43, "s", true), wantOutcome: SessionMiss},
+		{name: "stale", stored: []int64{1, 2, 3}, age: 2 * time.Minute, query: sessionQuery(42, "s", true), wantOutcome: SessionStale},
+		{name: "exhausted", stored: []int64{1, 2}, query: sessionQuery(42, "s", true), wantOutcome: SessionExhausted},
+		// 首页请求和没有会话 ID 的请求不查询缓存，也不上报
+		{name: "top request", stored: []int64{1, 2, 3}, query: sessionQuery(42, "s", false)},
+		{name: "no session", stored: []int64{1, 2, 3}, query: sessionQuery(42, "", true)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, outcomes, now := newTestSessionCache(t, config)
+			if tt.stored != nil {
+				first := sessionQuery(42, "s", false)
+				c.Store(first, firstPage(first, 1, tt.stored...))
+			}
+			*now = now.Add(tt.age)
+
+			page := c.Lookup(tt.query)
+			if tt.wantPage == 0 {
+				if page != nil {
+					t.Fatalf("page with %d candidates, want nil", len(page.Candidates))
+				}
+			} else if page == nil || len(page.Candidates) != tt.wantPage {
+				t.Fatalf("page=%+v, want %d candidates", page, tt.wantPage)
+			}
+			var want sessionOutcomes
+			if tt.wantOutcome != "" {
+				want = sessionOutcomes{tt.wantOutcome}
+			}
+			if len(*outcomes) != len(want) || (len(want) > 0 && (*outcomes)[0] != want[0]) {
+				t.Errorf("outcomes=%v, want %v", *outcomes, want)
+			}
+			if (tt.wantOutcome == SessionStale || tt.wantOutcome == SessionExhausted) && c.Len() != 0 {
+				t.Errorf("len=%d, want the %s session dropped", c.Len(), tt.wantOutcome)
+			}
+		})
+	}
+}
+
+func TestSessionCachePageQuery(t *testing.T) {
+	// 分页请求保留自己的分页字段，增强字段、实验分组和权重版本来自第一页
+	c, _, _ := newTestSessionCache(t, SessionCacheConfig{TTL: time.Minute, MaxSessions: 10})
+	first := sessionQuery(42, "s", false)
+	first.WeightsVersion = "v1"
+	first.Experiments = pipeline.ExperimentAssignments{{Experiment: "e", Treatment: "t"}}
+	first.MissingHydrations = []string{"UserActionSeqQueryHydrator"}
+	first.UserFeatures.FollowedUserIDs = []int64{7}
+	c.Store(first, firstPage(first, 1, 1, 2))
+
+	query := sessionQuery(42, "s", true)
+	query.RequestID = "second"
+	query.SeenIDs = []int64{1}
+	query.WeightsVersion = "v2"
+	page := c.Lookup(query)
+	if page == nil {
+		t.Fatal("lookup missed")
+	}
+	q := page.Query
+	if q.RequestID != "second" || len(q.SeenIDs) != 1 || !q.IsBottomRequest {
+		t.Errorf("page query lost the request's paging fields: %+v", q)
+	}
+	if q.WeightsVersion != "v1" || q.Experiments.String() != first.Experiments.String() ||
+		!q.HydrationMissing("UserActionSeqQueryHydrator") || len(q.UserFeatures.FollowedUserIDs) != 1 {
+		t.Errorf("page query did not take the first page's hydrated fields: %+v", q)
+	}
+
+	// 取出的候选是拷贝，管道修改它们不影响缓存
+	page.Candidates[0].TweetID = 100
+	if again := c.Lookup(query); again == nil || again.Candidates[0].TweetID != 2 {
+		t.Errorf("cached candidates modified through a page")
+	}
+}
+
+func TestSessionCacheAdvance(t *testing.T) {
+	tests := []struct {
+		name        string
+		result      func(page *SessionPage) *home.PipelineResult
+		err         error
+		wantOK      bool
+		wantOutcome SessionOutcome
+		wantLeft    int // 之后缓存中剩余的候选数，-1 表示会话被删除
+	}{
+		{
+			name: "serves and removes the page",
+			result: func(page *SessionPage) *home.PipelineResult {
+				return &home.PipelineResult{
+					Query:              page.Query,
+					ScoredCandidates:   page.Candidates,
+					SelectedCandidates: page.Candidates[:1],
+					FilteredCandidates: page.Candidates[1:2],
+				}
+			},
+			wantOK:      true,
+			wantOutcome: SessionHit,
+			wantLeft:    2,
+		},
+		{
+			name:        "failure drops the session",
+			err:         errors.New("boom"),
+			wantOutcome: SessionFailed,
+			wantLeft:    -1,
+		},
+		{
+			name: "empty page drops the session",
+			result: func(page *SessionPage) *home.PipelineResult {
+				return &home.PipelineResult{Query: page.Query, ScoredCandidates: page.Candidates}
+			},
+			wantOutcome: SessionExhausted,
+			wantLeft:    -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, outcomes, _ := newTestSessionCache(t, SessionCacheConfig{TTL: time.Minute, MaxSessions: 10})
+			first := sessionQuery(42, "s", false)
+			c.Store(first, firstPage(first, 1, 1, 2, 3, 4, 5))
+
+			query := sessionQuery(42, "s", true)
+			page := c.Lookup(query)
+			if page == nil || len(page.Candidates) != 4 {
+				t.Fatalf("page=%+v, want the 4 unserved candidates", page)
+			}
+			var result *home.PipelineResult
+			if tt.result != nil {
+				result = tt.result(page)
+			}
+			if ok := c.Advance(page, result, tt.err); ok != tt.wantOK {
+				t.Errorf("advance=%v, want %v", ok, tt.wantOK)
+			}
+			if last := (*outcomes)[len(*outcomes)-1]; last != tt.wantOutcome {
+				t.Errorf("outcome=%s, want %s", last, tt.wantOutcome)
+			}
+			if tt.wantLeft < 0 {
+				if c.Len() != 0 {
+					t.Errorf("len=%d, want the session dropped", c.Len())
+				}
+				return
+			}
+			if next := c.Lookup(query); next == nil || len(next.Candidates) != tt.wantLeft {
+				t.Errorf("next page=%+v, want %d candidates left", next, tt.wantLeft)
+			}
+		})
+	}
+}
+
+func TestSessionCacheEviction(t *testing.T) {
+	c, _, now := newTestSessionCache(t, SessionCacheConfig{TTL: time.Minute, MaxSessions: 2})
+	store := func(sessionID string) {
+		q := sessionQuery(42, sessionID, false)
+		c.Store(q, firstPage(q, 1, 1, 2))
+	}
+
+	// 超过上限时淘汰最早写入的会话
+	store("a")
+	store("b")
+	store("c")
+	if c.Len() != 2 || c.Lookup(sessionQuery(42, "a", true)) != nil || c.Lookup(sessionQuery(42, "c", true)) == nil {
+		t.Errorf("len=%d, want a evicted and c kept", c.Len())
+	}
+
+	// 写入时清理排在最前面的过期会话
+	*now = now.Add(2 * time.Minute)
+	store("d")
+	if c.Len() != 1 {
+		t.Errorf("len=%d after expiry, want only the new session", c.Len())
+	}
+}
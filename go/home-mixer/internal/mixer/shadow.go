@@ -0,0 +1,141 @@
+package mixer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
+)
+
+// ShadowConfig 配置影子执行
+type ShadowConfig struct {
+	SampleRatio    float64       // 执行影子管道的请求比例（0-1）
+	TopK           int           // 比较前 K 个选中的候选，<= 0 时比较全部
+	Timeout        time.Duration // 单次影子执行的超时
+	MaxInFlight    int           // 同时执行的影子请求上限，超过时丢弃，避免放大下游压力
+	LogSampleRatio float64       // 打印比较结果的比例（0-1）
+}
+
+// DefaultShadowConfig 返回默认的影子执行配置
+func DefaultShadowConfig() ShadowConfig {
+	return ShadowConfig{
+		SampleRatio:    0.01,
+		TopK:           50,
+		Timeout:        time.Second,
+		MaxInFlight:    16,
+		LogSampleRatio: 0.1,
+	}
+}
+
+// ShadowObserver 接收影子执行的结果（例如导出为指标）
+type ShadowObserver interface {
+	// ShadowCompared 在影子管道执行成功并与主结果比较后调用
+	ShadowCompared(c pipeline.ResultComparison)
+	// ShadowFailed 在影子管道执行失败（包括超时）时调用
+	ShadowFailed(err error)
+	// ShadowDropped 在被采样的请求因在途影子请求达到上限而被丢弃时调用
+	ShadowDropped()
+}
+
+// ShadowRunner 在后台用候选管道执行一部分线上请求，并与主管道的结果比较
+//
+// 影子执行不影响用户拿到的结果：它在主管道返回之后开始，使用独立的超时，不提交 Side Effects。
+// 比较结果交给 ShadowObserver，并按 LogSampleRatio 打印日志，用于在切换之前
+// 在真实流量上验证新的打分权重或过滤器。
+type ShadowRunner struct {
+	pipeline *home.CandidatePipeline
+	config   ShadowConfig
+	observer ShadowObserver
+	inFlight chan struct{}
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// NewShadowRunner 创建 ShadowRunner，observer 可以为 nil
+func NewShadowRunner(p *home.CandidatePipeline, config ShadowConfig, observer ShadowObserver) (*ShadowRunner, error) {
+	if p == nil {
+		return nil, fmt.Errorf("shadow pipeline is required")
+	}
+	if config.SampleRatio < 0 || config.SampleRatio > 1 {
+		return nil, fmt.Errorf("shadow sample ratio must be in [0, 1], got %v", config.SampleRatio)
+	}
+	if config.LogSampleRatio < 0 || config.LogSampleRatio > 1 {
+		return nil, fmt.Errorf("shadow log sample ratio must be in [0, 1], got %v", config.LogSampleRatio)
+	}
+	if config.Timeout <= 0 {
+		return nil, fmt.Errorf("shadow timeout must be > 0, got %s", config.Timeout)
+	}
+	if config.MaxInFlight <= 0 {
+		return nil, fmt.Errorf("shadow max in flight must be > 0, got %d", config.MaxInFlight)
+	}
+	return &ShadowRunner{
+		pipeline: p,
+		config:   config,
+		observer: observer,
+		inFlight: make(chan struct{}, config.MaxInFlight),
+		rnd:      rand.New(rand.NewSource(rand.Int63())),
+	}, nil
+}
+
+func (r *ShadowRunner) sample(ratio float64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rnd.Float64() < ratio
+}
+
+// Fork 按采样率决定是否对该请求执行影子管道
+// 需要执行时返回请求在进入主管道之前的拷贝，否则返回 nil
+func (r *ShadowRunner) Fork(query *home.Query) *home.Query {
+	if r == nil || !r.sample(r.config.SampleRatio) {
+		return nil
+	}
+	return query.Clone()
+}
+
+// Run 在后台用 Fork 得到的请求执行影子管道，并与主管道的结果比较
+// query 为 nil 时什么也不做；在途影子请求达到上限时丢弃本次执行
+func (r *ShadowRunner) Run(ctx context.Context, query *home.Query, primary *home.PipelineResult) {
+	if r == nil || query == nil || primary == nil {
+		return
+	}
+	select {
+	case r.inFlight <- struct{}{}:
+	default:
+		if r.observer != nil {
+			r.observer.ShadowDropped()
+		}
+		return
+	}
+
+	// 影子执行不随主请求取消，但保留 ctx 中的值（例如追踪的 span）
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.config.Timeout)
+	go func() {
+		defer func() { <-r.inFlight }()
+		defer cancel()
+		r.run(ctx, query, primary)
+	}()
+}
+
+func (r *ShadowRunner) run(ctx context.Context, query *home.Query, primary *home.PipelineResult) {
+	shadow, err := r.pipeline.ExecuteWithOptions(ctx, query, pipeline.ExecuteOptions{SkipSideEffects: true})
+	if err != nil {
+		log.Printf("request_id=%s shadow pipeline failed: %v", query.RequestID, err)
+		if r.observer != nil {
+			r.observer.ShadowFailed(err)
+		}
+		return
+	}
+	c := pipeline.CompareResults(primary, shadow, r.config.TopK)
+	if r.observer != nil {
+		r.observer.ShadowCompared(c)
+	}
+	if r.sample(r.config.LogSampleRatio) {
+		log.Printf("request_id=%s shadow %s", query.RequestID, c)
+	}
+}
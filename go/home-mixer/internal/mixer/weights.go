@@ -0,0 +1,215 @@
+package mixer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"x-algorithm-go/home-mixer/internal/scorers"
+)
+
+// actionWeightsFile 是权重文件的格式，例如：
+//
+//	version: "2026-10-18.1"
+//	weights:
+//	  favorite_weight: 1.0
+//	  reply_weight: 1.0
+//	  ...
+//	  report_weight: -3.0
+//	  min_video_duration_ms: 3000
+//	  negative_scores_offset: 0.0
+//
+// weights 必须给出 scorers.ActionWeights 的全部字段（缺少的权重不会默认为 0），
+// 归一化常数 WeightsSum / NegativeWeightsSum 由权重计算，不能出现在文件中。
+type actionWeightsFile struct {
+	Version string          `json:"version"`
+	Weights json.RawMessage `json:"weights"`
+}
+
+// LoadActionWeights 从文件加载动作权重，按扩展名识别格式（.yaml / .yml / .json）
+func LoadActionWeights(path string) (*scorers.VersionedWeights, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read action weights: %w", err)
+	}
+	return parseActionWeightsFile(path, data)
+}
+
+// ParseActionWeights 解析 YAML 或 JSON 格式的权重文件，未知字段和缺少的字段都会报错
+func ParseActionWeights(data []byte, format string) (*scorers.VersionedWeights, error) {
+	jsonData, err := configToJSON(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("parse action weights: %w", err)
+	}
+	var file actionWeightsFile
+	if err := decodeStrict(jsonData, &file); err != nil {
+		return nil, fmt.Errorf("parse action weights: %w", err)
+	}
+	if len(file.Weights) == 0 {
+		return nil, fmt.Errorf("parse action weights: missing weights")
+	}
+	var weights scorers.ActionWeights
+	if err := decodeStrict(file.Weights, &weights); err != nil {
+		return nil, fmt.Errorf("parse action weights: weights: %w", err)
+	}
+	var given map[string]json.RawMessage
+	if err := json.Unmarshal(file.Weights, &given); err != nil {
+		return nil, fmt.Errorf("parse action weights: weights: %w", err)
+	}
+	var missing []string
+	for _, key := range actionWeightKeys() {
+		if _, ok := given[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("parse action weights: weights: missing %s", strings.Join(missing, ", "))
+	}
+	return scorers.NewVersionedWeights(file.Version, weights)
+}
+
+// decodeStrict 解析 JSON，未知字段报错
+func decodeStrict(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// actionWeightKeys 返回权重文件 weights 中必须给出的键（ActionWeights 的 json 字段名）
+func actionWeightKeys() []string {
+	data, _ := json.Marshal(scorers.ActionWeights{})
+	var fields map[string]json.RawMessage
+	_ = json.Unmarshal(data, &fields)
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WeightsObserver 接收权重文件的加载结果（例如记录为指标），实现必须是并发安全的
+type WeightsObserver interface {
+	// WeightsActivated 在一组权重开始生效时调用（包括启动时的第一次加载）
+	WeightsActivated(version string)
+	// WeightsReloadFailed 在权重文件变化但不能生效时调用，此时继续使用原来的权重
+	WeightsReloadFailed(err error)
+}
+
+// WeightsReloader 从文件加载动作权重，并在文件变化时热加载
+//
+// 新的权重通过校验后原子替换 Store 中的权重：已经开始执行的请求继续使用原来的版本（见 WeightsStore.LoadVersion），
+// 之后的请求使用新版本。文件不合法时保留原来的权重，直到文件再次变化。
+// 版本号标识一组权重（随响应记录，用于离线分析），因此权重变化而版本号不变的文件会被拒绝。
+type WeightsReloader struct {
+	path     string
+	store    *scorers.WeightsStore
+	observer WeightsObserver // 为 nil 时不上报
+
+	mu   sync.Mutex
+	last []byte // 最近一次读取的文件内容，内容不变时跳过解析
+}
+
+// NewWeightsReloader 加载 path 中的权重，加载失败时返回错误（服务拒绝启动）
+func NewWeightsReloader(path string, observer WeightsObserver) (*WeightsReloader, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read action weights: %w", err)
+	}
+	weights, err := parseActionWeightsFile(path, data)
+	if err != nil {
+		return nil, err
+	}
+	r := &WeightsReloader{
+		path:     path,
+		store:    scorers.NewWeightsStore(weights),
+		observer: observer,
+		last:     data,
+	}
+	if observer != nil {
+		observer.WeightsActivated(weights.Version)
+	}
+	return r, nil
+}
+
+// parseActionWeightsFile 按 path 的扩展名解析文件内容
+func parseActionWeightsFile(path string, data []byte) (*scorers.VersionedWeights, error) {
+	weights, err := ParseActionWeights(data, strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), "."))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return weights, nil
+}
+
+// Store 返回当前生效的权重（传给 PipelineConfig.Weights）
+func (r *WeightsReloader) Store() *scorers.WeightsStore {
+	return r.store
+}
+
+// Reload 重新读取权重文件，文件变化且合法时替换当前权重
+// 文件不可读或不合法时返回错误，继续使用原来的权重
+func (r *WeightsReloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		r.last = nil
+		return r.failed(fmt.Errorf("read action weights: %w", err))
+	}
+	if bytes.Equal(data, r.last) {
+		return nil
+	}
+	r.last = data
+
+	next, err := parseActionWeightsFile(r.path, data)
+	if err != nil {
+		return r.failed(err)
+	}
+	current := r.store.Load()
+	if next.Version == current.Version {
+		if next.Weights == current.Weights {
+			// 只修改了注释或格式
+			return nil
+		}
+		return r.failed(fmt.Errorf("%s: action weights changed but version %s did not", r.path, next.Version))
+	}
+	r.store.Swap(next)
+	log.Printf("action_weights reloaded: path=%s version=%s previous=%s", r.path, next.Version, current.Version)
+	if r.observer != nil {
+		r.observer.WeightsActivated(next.Version)
+	}
+	return nil
+}
+
+// failed 记录一次失败的热加载
+func (r *WeightsReloader) failed(err error) error {
+	log.Printf("action_weights reload failed, keeping version=%s: %v", r.store.Load().Version, err)
+	if r.observer != nil {
+		r.observer.WeightsReloadFailed(err)
+	}
+	return err
+}
+
+// Run 每隔 interval 检查一次权重文件，直到 ctx 结束
+// 轮询文件内容（而不是监听文件事件），对编辑器的原子替换和 Kubernetes ConfigMap 的符号链接切换同样有效
+func (r *WeightsReloader) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = r.Reload()
+		}
+	}
+}
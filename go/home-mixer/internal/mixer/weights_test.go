@@ -0,0 +1,165 @@
+package mixer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"x-algorithm-go/home-mixer/internal/scorers"
+)
+
+// weightsEvents 记录权重的生效和热加载失败
+type weightsEvents struct {
+	activated []string
+	failures  int
+}
+
+func (e *weightsEvents) WeightsActivated(version string) { e.activated = append(e.activated, version) }
+func (e *weightsEvents) WeightsReloadFailed(error)       { e.failures++ }
+
+// weightsJSON 返回版本为 version、动作权重为默认权重 scale 倍的权重文件；indent 只改变格式
+func weightsJSON(t *testing.T, version string, scale float64, indent bool) []byte {
+	t.Helper()
+	defaults, err := json.Marshal(scorers.DefaultActionWeights())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var weights map[string]float64
+	if err := json.Unmarshal(defaults, &weights); err != nil {
+		t.Fatal(err)
+	}
+	for key := range weights {
+		if strings.HasSuffix(key, "_weight") {
+			weights[key] *= scale
+		}
+	}
+	file := map[string]any{"version": version, "weights": weights}
+	var data []byte
+	if indent {
+		data, err = json.MarshalIndent(file, "", "  ")
+	} else {
+		data, err = json.Marshal(file)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestWeightsReloaderReload(t *testing.T) {
+	tests := []struct {
+		name        string
+		next        func(t *testing.T) []byte // nil 表示删除文件
+		wantErr     string
+		wantVersion string
+		wantEvents  weightsEvents // 初始加载之后的事件
+	}{
+		{
+			name:        "unchanged file",
+			next:        func(t *testing.T) []byte { return weightsJSON(t, "v1", 1, false) },
+			wantVersion: "v1",
+		},
+		{
+			name:        "new version activates",
+			next:        func(t *testing.T) []byte { return weightsJSON(t, "v2", 2, false) },
+			wantVersion: "v2",
+			wantEvents:  weightsEvents{activated: []string{"v2"}},
+		},
+		{
+			name:        "formatting only",
+			next:        func(t *testing.T) []byte { return weightsJSON(t, "v1", 1, true) },
+			wantVersion: "v1",
+		},
+		{
+			name:        "changed weights with the same version",
+			next:        func(t *testing.T) []byte { return weightsJSON(t, "v1", 2, false) },
+			wantErr:     "action weights changed but version v1 did not",
+			wantVersion: "v1",
+			wantEvents:  weightsEvents{failures: 1},
+		},
+		{
+			name:        "invalid file",
+			next:        func(t *testing.T) []byte { return []byte(`{"version": "v2", "weights": {"favorite_weight": 1}}`) },
+			wantErr:     "missing",
+			wantVersion: "v1",
+			wantEvents:  weightsEvents{failures: 1},
+		},
+		{
+			name:        "unknown field",
+			next:        func(t *testing.T) []byte { return []byte(`{"version": "v2", "weights": {}, "extra": 1}`) },
+			wantErr:     "unknown field",
+			wantVersion: "v1",
+			wantEvents:  weightsEvents{failures: 1},
+		},
+		{
+			name:        "missing file",
+			wantErr:     "read action weights",
+			wantVersion: "v1",
+			wantEvents:  weightsEvents{failures: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "action_weights.json")
+			if err := os.WriteFile(path, weightsJSON(t, "v1", 1, false), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			events := &weightsEvents{}
+			r, err := NewWeightsReloader(path, events)
+			if err != nil {
+				t.Fatal(err)
+			}
+			events.activated = nil
+
+			if tt.next == nil {
+				os.Remove(path)
+			} else if err := os.WriteFile(path, tt.next(t), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			err = r.Reload()
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("reload: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("reload err=%v, want %q", err, tt.wantErr)
+			}
+			if got := r.Store().Load().Version; got != tt.wantVersion {
+				t.Errorf("version=%s, want %s", got, tt.wantVersion)
+			}
+			if strings.Join(events.activated, ",") != strings.Join(tt.wantEvents.activated, ",") || events.failures != tt.wantEvents.failures {
+				t.Errorf("events=%+v, want %+v", *events, tt.wantEvents)
+			}
+		})
+	}
+}
+
+func TestWeightsReloaderRetriesAfterFailure(t *testing.T) {
+	// 读取失败后继续使用原来的权重，文件恢复后的下一次热加载生效
+	path := filepath.Join(t.TempDir(), "action_weights.json")
+	if err := os.WriteFile(path, weightsJSON(t, "v1", 1, false), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewWeightsReloader(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(path)
+	if err := r.Reload(); err == nil {
+		t.Fatal("reload of a missing file succeeded")
+	}
+	if err := os.WriteFile(path, weightsJSON(t, "v2", 2, false), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Reload(); err != nil {
+		t.Fatalf("reload after the file came back: %v", err)
+	}
+
+	// 替换后，执行开始时记录了 v1 的请求仍然按 v1 打分
+	store := r.Store()
+	if store.Load().Version != "v2" || store.LoadVersion("v1").Version != "v1" || store.LoadVersion("unknown").Version != "v2" {
+		t.Errorf("load=%s load(v1)=%s load(unknown)=%s, want v2 v1 v2",
+			store.Load().Version, store.LoadVersion("v1").Version, store.LoadVersion("unknown").Version)
+	}
+}
@@ -3,7 +3,7 @@ package query_hydrators
 import (
 	"context"
 
-	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 )
 
 // MockUserActionSequenceFetcher 是 UserActionSequenceFetcher 的 Mock 实现（用于测试）
@@ -30,19 +30,19 @@ func (m *MockUserActionSequenceFetcher) GetByUserID(ctx context.Context, userID
 
 // MockStratoClient 是 StratoClient 的 Mock 实现（用于测试）
 type MockStratoClient struct {
-	Features map[int64]*pipeline.UserFeatures
+	Features map[int64]*home.UserFeatures
 }
 
 // GetUserFeatures 实现 StratoClient 接口
-func (m *MockStratoClient) GetUserFeatures(ctx context.Context, userID int64) (*pipeline.UserFeatures, error) {
+func (m *MockStratoClient) GetUserFeatures(ctx context.Context, userID int64) (*home.UserFeatures, error) {
 	if m.Features == nil {
-		m.Features = make(map[int64]*pipeline.UserFeatures)
+		m.Features = make(map[int64]*home.UserFeatures)
 	}
 	
 	// 返回预设的特征，如果不存在则返回空特征
 	features, ok := m.Features[userID]
 	if !ok {
-		return &pipeline.UserFeatures{}, nil
+		return &home.UserFeatures{}, nil
 	}
 	return features, nil
 }
@@ -3,7 +3,7 @@ package query_hydrators
 import (
 	"context"
 
-	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 )
 
 // UserActionSeqQueryHydrator 增强查询，添加用户交互历史序列
@@ -29,6 +29,7 @@ type UserActionSequenceData struct {
 type UserActionData struct {
 	ActionType string
 	TweetID    int64
+	AuthorID   uint64 // 帖子作者，未知时为 0
 	Timestamp  int64
 }
 
@@ -49,7 +50,7 @@ func NewUserActionSeqQueryHydrator(fetcher UserActionSequenceFetcher) *UserActio
 }
 
 // Hydrate 实现 QueryHydrator 接口
-func (h *UserActionSeqQueryHydrator) Hydrate(ctx context.Context, query *pipeline.Query) (*pipeline.Query, error) {
+func (h *UserActionSeqQueryHydrator) Hydrate(ctx context.Context, query *home.Query) (*home.Query, error) {
 	// 获取用户动作序列
 	uasData, err := h.uasFetcher.GetByUserID(ctx, query.UserID)
 	if err != nil {
@@ -60,24 +61,24 @@ func (h *UserActionSeqQueryHydrator) Hydrate(ctx context.Context, query *pipelin
 	userActionSequence := h.convertToUserActionSequence(uasData)
 
 	// 返回增强后的查询
-	return &pipeline.Query{
+	return &home.Query{
 		UserActionSequence: userActionSequence,
 	}, nil
 }
 
 // convertToUserActionSequence 转换数据格式
-func (h *UserActionSeqQueryHydrator) convertToUserActionSequence(data *UserActionSequenceData) *pipeline.UserActionSequence {
+func (h *UserActionSeqQueryHydrator) convertToUserActionSequence(data *UserActionSequenceData) *home.UserActionSequence {
 	if data == nil {
 		return nil
 	}
 
-	sequence := &pipeline.UserActionSequence{
+	sequence := &home.UserActionSequence{
 		UserID: uint64(data.UserID),
 	}
 
 	// 转换元数据
 	if data.Metadata != nil {
-		sequence.Metadata = &pipeline.UserActionSequenceMeta{
+		sequence.Metadata = &home.UserActionSequenceMeta{
 			Length:                    data.Metadata.Length,
 			FirstSequenceTime:         data.Metadata.FirstSequenceTime,
 			LastSequenceTime:          data.Metadata.LastSequenceTime,
@@ -88,11 +89,12 @@ func (h *UserActionSeqQueryHydrator) convertToUserActionSequence(data *UserActio
 
 	// 转换动作列表
 	if data.Actions != nil {
-		sequence.Actions = make([]pipeline.UserAction, len(data.Actions))
+		sequence.Actions = make([]home.UserAction, len(data.Actions))
 		for i, action := range data.Actions {
-			sequence.Actions[i] = pipeline.UserAction{
+			sequence.Actions[i] = home.UserAction{
 				ActionType: action.ActionType,
 				TweetID:    action.TweetID,
+				AuthorID:   action.AuthorID,
 				Timestamp:  action.Timestamp,
 			}
 		}
@@ -102,7 +104,7 @@ func (h *UserActionSeqQueryHydrator) convertToUserActionSequence(data *UserActio
 }
 
 // Update 更新查询对象的增强字段
-func (h *UserActionSeqQueryHydrator) Update(query *pipeline.Query, hydrated *pipeline.Query) {
+func (h *UserActionSeqQueryHydrator) Update(query *home.Query, hydrated *home.Query) {
 	if hydrated.UserActionSequence != nil {
 		query.UserActionSequence = hydrated.UserActionSequence
 	}
@@ -114,6 +116,16 @@ func (h *UserActionSeqQueryHydrator) Name() string {
 }
 
 // Enable 决定是否启用（UserActionSeqQueryHydrator 总是启用）
-func (h *UserActionSeqQueryHydrator) Enable(query *pipeline.Query) bool {
+func (h *UserActionSeqQueryHydrator) Enable(query *home.Query) bool {
 	return true
 }
+
+// ReadFields 返回 Hydrate 读取的字段（用于构建依赖图）
+func (h *UserActionSeqQueryHydrator) ReadFields() []string {
+	return []string{"UserID"}
+}
+
+// WriteFields 返回 Update 写入的字段（用于构建依赖图）
+func (h *UserActionSeqQueryHydrator) WriteFields() []string {
+	return []string{"UserActionSequence"}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 )
 
 // UserFeaturesQueryHydrator 增强查询，添加用户特征（关注列表、屏蔽列表等）
@@ -14,7 +15,7 @@ type UserFeaturesQueryHydrator struct {
 // StratoClient 定义 Strato 客户端接口
 type StratoClient interface {
 	// GetUserFeatures 获取用户特征
-	GetUserFeatures(ctx context.Context, userID int64) (*pipeline.UserFeatures, error)
+	GetUserFeatures(ctx context.Context, userID int64) (*home.UserFeatures, error)
 }
 
 // NewUserFeaturesQueryHydrator 创建新的 UserFeaturesQueryHydrator 实例
@@ -25,7 +26,7 @@ func NewUserFeaturesQueryHydrator(client StratoClient) *UserFeaturesQueryHydrato
 }
 
 // Hydrate 实现 QueryHydrator 接口
-func (h *UserFeaturesQueryHydrator) Hydrate(ctx context.Context, query *pipeline.Query) (*pipeline.Query, error) {
+func (h *UserFeaturesQueryHydrator) Hydrate(ctx context.Context, query *home.Query) (*home.Query, error) {
 	// 获取用户特征
 	userFeatures, err := h.stratoClient.GetUserFeatures(ctx, query.UserID)
 	if err != nil {
@@ -33,13 +34,13 @@ func (h *UserFeaturesQueryHydrator) Hydrate(ctx context.Context, query *pipeline
 	}
 
 	// 返回增强后的查询
-	return &pipeline.Query{
+	return &home.Query{
 		UserFeatures: *userFeatures,
 	}, nil
 }
 
 // Update 更新查询对象的增强字段
-func (h *UserFeaturesQueryHydrator) Update(query *pipeline.Query, hydrated *pipeline.Query) {
+func (h *UserFeaturesQueryHydrator) Update(query *home.Query, hydrated *home.Query) {
 	query.UserFeatures = hydrated.UserFeatures
 }
 
@@ -49,6 +50,22 @@ func (h *UserFeaturesQueryHydrator) Name() string {
 }
 
 // Enable 决定是否启用（UserFeaturesQueryHydrator 总是启用）
-func (h *UserFeaturesQueryHydrator) Enable(query *pipeline.Query) bool {
+func (h *UserFeaturesQueryHydrator) Enable(query *home.Query) bool {
 	return true
 }
+
+// ReadFields 返回 Hydrate 读取的字段（用于构建依赖图）
+func (h *UserFeaturesQueryHydrator) ReadFields() []string {
+	return []string{"UserID"}
+}
+
+// WriteFields 返回 Update 写入的字段（用于构建依赖图）
+func (h *UserFeaturesQueryHydrator) WriteFields() []string {
+	return []string{"UserFeatures"}
+}
+
+// FailurePolicy 返回失败策略
+// 没有用户特征就无法执行屏蔽/静音过滤，必须终止请求
+func (h *UserFeaturesQueryHydrator) FailurePolicy() pipeline.FailurePolicy {
+	return pipeline.Critical
+}
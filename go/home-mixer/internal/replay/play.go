@@ -0,0 +1,152 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
+	"x-algorithm-go/home-mixer/internal/hydrators"
+	"x-algorithm-go/home-mixer/internal/query_hydrators"
+	"x-algorithm-go/home-mixer/internal/scorers"
+	"x-algorithm-go/home-mixer/internal/sources"
+)
+
+// ReplayClients 返回从 ctx 中的 Recording（见 WithRecording）读取响应的回放客户端
+// 录制中没有的调用返回错误，与线上依赖不可用时一样按组件的失败策略处理；
+// StratoForCache 不做任何事，回放不会产生写入
+func ReplayClients() Clients {
+	return Clients{
+		Thunder:          replayThunder{},
+		PhoenixRetrieval: replayPhoenixRetrieval{},
+		PhoenixRanking:   replayPhoenixRanking{},
+		TES:              replayTES{},
+		Gizmoduck:        replayGizmoduck{},
+		VF:               replayVF{},
+		UAS:              replayUAS{},
+		Strato:           replayStrato{},
+		StratoForCache:   replayStratoForCache{},
+	}
+}
+
+// replayCall 解码一次按用户查询的调用
+func replayCall[T any](ctx context.Context, name string, slot func(*Recording) *Call) (*T, error) {
+	rec, err := recordingFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	call := slot(rec)
+	if call == nil {
+		return nil, fmt.Errorf("replay: %s was not recorded", name)
+	}
+	if call.Error != "" {
+		return nil, errors.New(call.Error)
+	}
+	var resp *T
+	if err := json.Unmarshal(call.Response, &resp); err != nil {
+		return nil, fmt.Errorf("replay: decode %s: %w", name, err)
+	}
+	return resp, nil
+}
+
+// replayBatch 按请求的 ID 解码批量调用的结果，录制中没有的 ID 不出现在结果中
+func replayBatch[K comparable, V any](ctx context.Context, name string, slot func(*Recording) *Batch[K], ids []K) (map[K]V, error) {
+	rec, err := recordingFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	batch := slot(rec)
+	if batch == nil {
+		return nil, fmt.Errorf("replay: %s was not recorded", name)
+	}
+	if batch.Error != "" {
+		return nil, errors.New(batch.Error)
+	}
+	values := make(map[K]V, len(ids))
+	for _, id := range ids {
+		raw, ok := batch.Values[id]
+		if !ok {
+			continue
+		}
+		var v V
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, fmt.Errorf("replay: decode %s %v: %w", name, id, err)
+		}
+		values[id] = v
+	}
+	return values, nil
+}
+
+type replayThunder struct{}
+
+func (replayThunder) GetInNetworkPosts(ctx context.Context, req *sources.GetInNetworkPostsRequest) (*sources.GetInNetworkPostsResponse, error) {
+	return replayCall[sources.GetInNetworkPostsResponse](ctx, "thunder", func(r *Recording) *Call { return r.Thunder })
+}
+
+type replayPhoenixRetrieval struct{}
+
+func (replayPhoenixRetrieval) Retrieve(ctx context.Context, userID uint64, sequence *home.UserActionSequence, maxResults int) (*sources.RetrievalResponse, error) {
+	return replayCall[sources.RetrievalResponse](ctx, "phoenix_retrieval", func(r *Recording) *Call { return r.PhoenixRetrieval })
+}
+
+type replayPhoenixRanking struct{}
+
+func (replayPhoenixRanking) Rank(ctx context.Context, req *scorers.RankingRequest) (*scorers.RankingResponse, error) {
+	ids := make([]uint64, len(req.TweetInfos))
+	for i, info := range req.TweetInfos {
+		ids[i] = uint64(info.TweetID)
+	}
+	predictions, err := replayBatch[uint64, *scorers.PhoenixPrediction](ctx, "phoenix_ranking", func(r *Recording) *Batch[uint64] { return r.PhoenixRanking }, ids)
+	if err != nil {
+		return nil, err
+	}
+	return &scorers.RankingResponse{PredictionsMap: predictions}, nil
+}
+
+type replayTES struct{}
+
+func (replayTES) GetTweetCoreDatas(ctx context.Context, tweetIDs []int64) (map[int64]*hydrators.CoreData, error) {
+	return replayBatch[int64, *hydrators.CoreData](ctx, "tweet_core_data", func(r *Recording) *Batch[int64] { return r.TweetCoreData }, tweetIDs)
+}
+
+func (replayTES) GetTweetMediaEntities(ctx context.Context, tweetIDs []int64) (map[int64]*hydrators.MediaEntities, error) {
+	return replayBatch[int64, *hydrators.MediaEntities](ctx, "tweet_media", func(r *Recording) *Batch[int64] { return r.TweetMedia }, tweetIDs)
+}
+
+func (replayTES) GetSubscriptionAuthorIDs(ctx context.Context, tweetIDs []int64) (map[int64]*uint64, error) {
+	return replayBatch[int64, *uint64](ctx, "subscription_authors", func(r *Recording) *Batch[int64] { return r.SubscriptionAuthors }, tweetIDs)
+}
+
+type replayGizmoduck struct{}
+
+func (replayGizmoduck) GetUsers(ctx context.Context, userIDs []int64) (map[int64]*hydrators.GizmoduckUserResult, error) {
+	return replayBatch[int64, *hydrators.GizmoduckUserResult](ctx, "users", func(r *Recording) *Batch[int64] { return r.Users }, userIDs)
+}
+
+type replayVF struct{}
+
+func (replayVF) GetVisibilityResults(ctx context.Context, tweetIDs []int64, isInNetwork bool, userID int64) (map[int64]*string, error) {
+	if isInNetwork {
+		return replayBatch[int64, *string](ctx, "visibility_in_network", func(r *Recording) *Batch[int64] { return r.VisibilityInNetwork }, tweetIDs)
+	}
+	return replayBatch[int64, *string](ctx, "visibility_out_of_network", func(r *Recording) *Batch[int64] { return r.VisibilityOutOfNetwork }, tweetIDs)
+}
+
+type replayUAS struct{}
+
+func (replayUAS) GetByUserID(ctx context.Context, userID int64) (*query_hydrators.UserActionSequenceData, error) {
+	return replayCall[query_hydrators.UserActionSequenceData](ctx, "user_action_sequence", func(r *Recording) *Call { return r.UserActionSequence })
+}
+
+type replayStrato struct{}
+
+func (replayStrato) GetUserFeatures(ctx context.Context, userID int64) (*home.UserFeatures, error) {
+	return replayCall[home.UserFeatures](ctx, "user_features", func(r *Recording) *Call { return r.UserFeatures })
+}
+
+type replayStratoForCache struct{}
+
+func (replayStratoForCache) StoreRequestInfo(ctx context.Context, userID int64, postIDs []int64) error {
+	return nil
+}
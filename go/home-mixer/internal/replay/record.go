@@ -0,0 +1,288 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
+	"x-algorithm-go/home-mixer/internal/hydrators"
+	"x-algorithm-go/home-mixer/internal/query_hydrators"
+	"x-algorithm-go/home-mixer/internal/scorers"
+	"x-algorithm-go/home-mixer/internal/sources"
+)
+
+// Recorder 按采样率录制请求，每个被录制的请求写入 Dir 下的 <request_id>.json
+type Recorder struct {
+	dir         string
+	sampleRatio float64
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// NewRecorder 创建 Recorder，sampleRatio 为 [0, 1] 之间的采样率
+func NewRecorder(dir string, sampleRatio float64) (*Recorder, error) {
+	if sampleRatio < 0 || sampleRatio > 1 {
+		return nil, fmt.Errorf("record sample ratio must be in [0, 1], got %v", sampleRatio)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Recorder{dir: dir, sampleRatio: sampleRatio, rnd: rand.New(rand.NewSource(rand.Int63()))}, nil
+}
+
+func (r *Recorder) sampled() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rnd.Float64() < r.sampleRatio
+}
+
+// Start 按采样率决定是否录制该请求
+// 录制时返回带有 Session 的 ctx，之后通过录制客户端发起的调用都会记入 Session；
+// 不录制时原样返回 ctx 和 nil
+func (r *Recorder) Start(ctx context.Context, query *home.Query) (context.Context, *Session) {
+	if r == nil || !r.sampled() {
+		return ctx, nil
+	}
+	s := NewSession(query)
+	return WithSession(ctx, s), s
+}
+
+// Finish 记录管道的执行结果并写入回放文件；session 为 nil 时什么也不做
+func (r *Recorder) Finish(s *Session, result *home.PipelineResult, err error) {
+	if r == nil || s == nil {
+		return
+	}
+	rec := s.finish(NewResult(result, err))
+	path := filepath.Join(r.dir, rec.Query.RequestID+".json")
+	if err := rec.Save(path); err != nil {
+		log.Printf("request_id=%s replay record failed: %v", rec.Query.RequestID, err)
+	}
+}
+
+// Session 收集一次请求的全部外部调用，可以被多个组件并发写入
+type Session struct {
+	mu  sync.Mutex
+	rec *Recording
+}
+
+// NewSession 为请求创建 Session，query 在进入管道之前拷贝
+func NewSession(query *home.Query) *Session {
+	return &Session{rec: &Recording{Version: FormatVersion, Query: query.Clone()}}
+}
+
+// finish 记录结果并返回 Recording；之后到达的调用（例如已超时组件的迟到响应）不再记录
+func (s *Session) finish(result *Result) *Recording {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec := s.rec
+	rec.Result = result
+	s.rec = nil
+	return rec
+}
+
+type sessionKey struct{}
+
+// WithSession 返回带有 Session 的 ctx
+func WithSession(ctx context.Context, s *Session) context.Context {
+	return context.WithValue(ctx, sessionKey{}, s)
+}
+
+func sessionFrom(ctx context.Context) *Session {
+	s, _ := ctx.Value(sessionKey{}).(*Session)
+	return s
+}
+
+// callError 返回调用在组件看来的结果：调用返回时 ctx 已经结束的，组件已按超时处理，
+// 录制为错误才能在回放时得到相同的结果
+func callError(ctx context.Context, err error) error {
+	if err == nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// recordCall 记录一次按用户查询的调用
+func recordCall(ctx context.Context, slot func(*Recording) **Call, resp any, err error) {
+	s := sessionFrom(ctx)
+	if s == nil {
+		return
+	}
+	call := &Call{}
+	if err = callError(ctx, err); err != nil {
+		call.Error = err.Error()
+	} else if call.Response, err = json.Marshal(resp); err != nil {
+		call.Error = fmt.Sprintf("replay: marshal response: %v", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rec != nil {
+		*slot(s.rec) = call
+	}
+}
+
+// recordBatch 把一次批量调用的结果按 ID 合并到录制中；出错时记录第一个错误
+func recordBatch[K comparable, V any](ctx context.Context, slot func(*Recording) **Batch[K], values map[K]V, err error) {
+	s := sessionFrom(ctx)
+	if s == nil {
+		return
+	}
+	err = callError(ctx, err)
+	encoded := make(map[K]json.RawMessage, len(values))
+	if err == nil {
+		for id, v := range values {
+			raw, marshalErr := json.Marshal(v)
+			if marshalErr != nil {
+				err = fmt.Errorf("replay: marshal response: %w", marshalErr)
+				break
+			}
+			encoded[id] = raw
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rec == nil {
+		return
+	}
+	batch := *slot(s.rec)
+	if batch == nil {
+		batch = &Batch[K]{Values: make(map[K]json.RawMessage)}
+		*slot(s.rec) = batch
+	}
+	if err != nil {
+		if batch.Error == "" {
+			batch.Error = err.Error()
+		}
+		return
+	}
+	for id, raw := range encoded {
+		batch.Values[id] = raw
+	}
+}
+
+// RecordingClients 用录制客户端包装 c 中的客户端
+// 只有 ctx 中带有 Session 的调用会被录制；StratoForCache 只写不读，不需要录制
+func RecordingClients(c Clients) Clients {
+	return Clients{
+		Thunder:          &recordingThunder{c.Thunder},
+		PhoenixRetrieval: &recordingPhoenixRetrieval{c.PhoenixRetrieval},
+		PhoenixRanking:   &recordingPhoenixRanking{c.PhoenixRanking},
+		TES:              &recordingTES{c.TES},
+		Gizmoduck:        &recordingGizmoduck{c.Gizmoduck},
+		VF:               &recordingVF{c.VF},
+		UAS:              &recordingUAS{c.UAS},
+		Strato:           &recordingStrato{c.Strato},
+		StratoForCache:   c.StratoForCache,
+	}
+}
+
+type recordingThunder struct{ inner sources.ThunderClient }
+
+func (c *recordingThunder) GetInNetworkPosts(ctx context.Context, req *sources.GetInNetworkPostsRequest) (*sources.GetInNetworkPostsResponse, error) {
+	resp, err := c.inner.GetInNetworkPosts(ctx, req)
+	recordCall(ctx, func(r *Recording) **Call { return &r.Thunder }, resp, err)
+	return resp, err
+}
+
+type recordingPhoenixRetrieval struct {
+	inner sources.PhoenixRetrievalClient
+}
+
+func (c *recordingPhoenixRetrieval) Retrieve(ctx context.Context, userID uint64, sequence *home.UserActionSequence, maxResults int) (*sources.RetrievalResponse, error) {
+	resp, err := c.inner.Retrieve(ctx, userID, sequence, maxResults)
+	recordCall(ctx, func(r *Recording) **Call { return &r.PhoenixRetrieval }, resp, err)
+	return resp, err
+}
+
+type recordingPhoenixRanking struct{ inner scorers.PhoenixRankingClient }
+
+func (c *recordingPhoenixRanking) Rank(ctx context.Context, req *scorers.RankingRequest) (*scorers.RankingResponse, error) {
+	resp, err := c.inner.Rank(ctx, req)
+	var predictions map[uint64]*scorers.PhoenixPrediction
+	if err == nil && resp != nil {
+		predictions = resp.PredictionsMap
+		if predictions == nil {
+			// 只按索引返回的预测转换为按 tweet_id（转发时为原帖 ID）保存
+			predictions = make(map[uint64]*scorers.PhoenixPrediction, len(resp.Predictions))
+			for i := range resp.Predictions {
+				if i < len(req.TweetInfos) {
+					predictions[uint64(req.TweetInfos[i].TweetID)] = &resp.Predictions[i]
+				}
+			}
+		}
+	}
+	recordBatch(ctx, func(r *Recording) **Batch[uint64] { return &r.PhoenixRanking }, predictions, err)
+	return resp, err
+}
+
+type recordingTES struct {
+	inner hydrators.TweetEntityServiceClient
+}
+
+func (c *recordingTES) GetTweetCoreDatas(ctx context.Context, tweetIDs []int64) (map[int64]*hydrators.CoreData, error) {
+	resp, err := c.inner.GetTweetCoreDatas(ctx, tweetIDs)
+	recordBatch(ctx, func(r *Recording) **Batch[int64] { return &r.TweetCoreData }, resp, err)
+	return resp, err
+}
+
+func (c *recordingTES) GetTweetMediaEntities(ctx context.Context, tweetIDs []int64) (map[int64]*hydrators.MediaEntities, error) {
+	resp, err := c.inner.GetTweetMediaEntities(ctx, tweetIDs)
+	recordBatch(ctx, func(r *Recording) **Batch[int64] { return &r.TweetMedia }, resp, err)
+	return resp, err
+}
+
+func (c *recordingTES) GetSubscriptionAuthorIDs(ctx context.Context, tweetIDs []int64) (map[int64]*uint64, error) {
+	resp, err := c.inner.GetSubscriptionAuthorIDs(ctx, tweetIDs)
+	recordBatch(ctx, func(r *Recording) **Batch[int64] { return &r.SubscriptionAuthors }, resp, err)
+	return resp, err
+}
+
+type recordingGizmoduck struct{ inner hydrators.GizmoduckClient }
+
+func (c *recordingGizmoduck) GetUsers(ctx context.Context, userIDs []int64) (map[int64]*hydrators.GizmoduckUserResult, error) {
+	resp, err := c.inner.GetUsers(ctx, userIDs)
+	recordBatch(ctx, func(r *Recording) **Batch[int64] { return &r.Users }, resp, err)
+	return resp, err
+}
+
+type recordingVF struct {
+	inner hydrators.VisibilityFilteringClient
+}
+
+func (c *recordingVF) GetVisibilityResults(ctx context.Context, tweetIDs []int64, isInNetwork bool, userID int64) (map[int64]*string, error) {
+	resp, err := c.inner.GetVisibilityResults(ctx, tweetIDs, isInNetwork, userID)
+	recordBatch(ctx, visibilitySlot(isInNetwork), resp, err)
+	return resp, err
+}
+
+// visibilitySlot 站内和站外候选的可见性规则不同，分开保存
+func visibilitySlot(isInNetwork bool) func(*Recording) **Batch[int64] {
+	if isInNetwork {
+		return func(r *Recording) **Batch[int64] { return &r.VisibilityInNetwork }
+	}
+	return func(r *Recording) **Batch[int64] { return &r.VisibilityOutOfNetwork }
+}
+
+type recordingUAS struct {
+	inner query_hydrators.UserActionSequenceFetcher
+}
+
+func (c *recordingUAS) GetByUserID(ctx context.Context, userID int64) (*query_hydrators.UserActionSequenceData, error) {
+	resp, err := c.inner.GetByUserID(ctx, userID)
+	recordCall(ctx, func(r *Recording) **Call { return &r.UserActionSequence }, resp, err)
+	return resp, err
+}
+
+type recordingStrato struct{ inner query_hydrators.StratoClient }
+
+func (c *recordingStrato) GetUserFeatures(ctx context.Context, userID int64) (*home.UserFeatures, error) {
+	resp, err := c.inner.GetUserFeatures(ctx, userID)
+	recordCall(ctx, func(r *Recording) **Call { return &r.UserFeatures }, resp, err)
+	return resp, err
+}
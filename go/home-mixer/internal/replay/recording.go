@@ -0,0 +1,123 @@
+// Package replay 录制线上请求及其触发的全部外部调用，并在离线环境中回放
+//
+// 录制时，Recorder 用录制客户端包装真实客户端：被采样的请求在 ctx 中带有 Session，
+// 客户端把每次调用的响应（或错误）写入 Session，请求结束后连同 Query 和管道结果保存为一个
+// 回放文件。回放时，回放客户端从 ctx 中的 Recording 返回录制的响应，管道不访问任何外部服务，
+// 在相同的管道定义下可以逐位复现线上结果，也可以用两个管道定义对比同一批流量的输出。
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
+	"x-algorithm-go/home-mixer/internal/hydrators"
+	"x-algorithm-go/home-mixer/internal/query_hydrators"
+	"x-algorithm-go/home-mixer/internal/scorers"
+	"x-algorithm-go/home-mixer/internal/side_effects"
+	"x-algorithm-go/home-mixer/internal/sources"
+)
+
+// FormatVersion 是回放文件的格式版本
+const FormatVersion = 1
+
+// Clients 是管道依赖的全部外部客户端
+type Clients struct {
+	Thunder          sources.ThunderClient
+	PhoenixRetrieval sources.PhoenixRetrievalClient
+	PhoenixRanking   scorers.PhoenixRankingClient
+	TES              hydrators.TweetEntityServiceClient
+	Gizmoduck        hydrators.GizmoduckClient
+	VF               hydrators.VisibilityFilteringClient
+	UAS              query_hydrators.UserActionSequenceFetcher
+	Strato           query_hydrators.StratoClient
+	StratoForCache   side_effects.StratoClient
+}
+
+// Recording 是一次请求的回放文件
+//
+// 按用户查询的调用（Thunder、Phoenix 检索、UAS、Strato）每次请求只发生一次，直接保存响应；
+// 按 ID 批量查询的调用（TES、Gizmoduck、VF、Phoenix 排序）按 ID 合并保存，
+// 回放时按请求的 ID 返回，因此候选集合不同的管道版本也可以使用同一份录制。
+// 所有响应以 JSON 保存，回放时每次调用都解码出新的对象，与真实 RPC 一样互不共享。
+type Recording struct {
+	Version int `json:"version"`
+
+	// Query 是进入管道之前的请求（包括 RequestID 和 RequestTimeMs）
+	Query *home.Query `json:"query"`
+
+	Thunder            *Call `json:"thunder,omitempty"`
+	PhoenixRetrieval   *Call `json:"phoenix_retrieval,omitempty"`
+	UserActionSequence *Call `json:"user_action_sequence,omitempty"`
+	UserFeatures       *Call `json:"user_features,omitempty"`
+
+	PhoenixRanking         *Batch[uint64] `json:"phoenix_ranking,omitempty"`
+	TweetCoreData          *Batch[int64]  `json:"tweet_core_data,omitempty"`
+	TweetMedia             *Batch[int64]  `json:"tweet_media,omitempty"`
+	SubscriptionAuthors    *Batch[int64]  `json:"subscription_authors,omitempty"`
+	Users                  *Batch[int64]  `json:"users,omitempty"`
+	VisibilityInNetwork    *Batch[int64]  `json:"visibility_in_network,omitempty"`
+	VisibilityOutOfNetwork *Batch[int64]  `json:"visibility_out_of_network,omitempty"`
+
+	// Result 是线上执行的结果，用于核对回放是否复现
+	Result *Result `json:"result,omitempty"`
+}
+
+// Call 是一次按用户查询的调用
+type Call struct {
+	Response json.RawMessage `json:"response,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// Batch 是按 ID 合并的批量调用结果
+// Values 中值为 null 的 ID 表示服务返回了该 ID 但值为空，不存在的 ID 表示服务没有返回。
+// Error 记录第一次失败的调用，回放时该方法的所有调用都返回这个错误
+type Batch[K comparable] struct {
+	Values map[K]json.RawMessage `json:"values"`
+	Error  string                `json:"error,omitempty"`
+}
+
+// Load 读取回放文件
+func Load(path string) (*Recording, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rec Recording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("replay file %s: %w", path, err)
+	}
+	if rec.Version != FormatVersion {
+		return nil, fmt.Errorf("replay file %s: unsupported version %d (want %d)", path, rec.Version, FormatVersion)
+	}
+	if rec.Query == nil {
+		return nil, fmt.Errorf("replay file %s: query is missing", path)
+	}
+	return &rec, nil
+}
+
+// Save 写入回放文件
+func (r *Recording) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+type recordingKey struct{}
+
+// WithRecording 返回带有回放数据的 ctx，回放客户端从中读取响应
+func WithRecording(ctx context.Context, rec *Recording) context.Context {
+	return context.WithValue(ctx, recordingKey{}, rec)
+}
+
+func recordingFrom(ctx context.Context) (*Recording, error) {
+	rec, ok := ctx.Value(recordingKey{}).(*Recording)
+	if !ok || rec == nil {
+		return nil, fmt.Errorf("replay: no recording in context")
+	}
+	return rec, nil
+}
@@ -0,0 +1,103 @@
+package replay
+
+import (
+	"fmt"
+	"math"
+
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
+)
+
+// Result 是管道一次执行的输出摘要，用于核对回放和对比两个管道版本
+type Result struct {
+	Error       string `json:"error,omitempty"`
+	Experiments string `json:"experiments,omitempty"`
+	// WeightsVersion 是 WeightedScorer 使用的动作权重版本（没有候选带版本时为空）
+	WeightsVersion string `json:"weights_version,omitempty"`
+	Posts          []Post `json:"posts"`
+}
+
+// Post 是一条被选中的帖子
+type Post struct {
+	TweetID    int64            `json:"tweet_id"`
+	AuthorID   uint64           `json:"author_id"`
+	Score      *float64         `json:"score,omitempty"`
+	ServedType *home.ServedType `json:"served_type,omitempty"`
+}
+
+// NewResult 从管道的执行结果构建 Result
+func NewResult(result *home.PipelineResult, err error) *Result {
+	if err != nil {
+		return &Result{Error: err.Error(), Posts: []Post{}}
+	}
+	r := &Result{
+		Experiments: result.Query.Experiments.String(),
+		Posts:       make([]Post, len(result.SelectedCandidates)),
+	}
+	for i, c := range result.SelectedCandidates {
+		r.Posts[i] = Post{TweetID: c.TweetID, AuthorID: c.AuthorID, Score: c.Score, ServedType: c.ServedType}
+		if r.WeightsVersion == "" && c.WeightsVersion != nil {
+			r.WeightsVersion = *c.WeightsVersion
+		}
+	}
+	return r
+}
+
+// Diff 逐位比较两个结果，返回差异描述；结果完全一致时返回空
+// 分数按二进制位比较，任何浮点误差都视为差异
+func Diff(want, got *Result) []string {
+	var diffs []string
+	if want.Error != got.Error {
+		diffs = append(diffs, fmt.Sprintf("error: %q != %q", want.Error, got.Error))
+	}
+	if want.Experiments != got.Experiments {
+		diffs = append(diffs, fmt.Sprintf("experiments: %s != %s", want.Experiments, got.Experiments))
+	}
+	// 早期的录制没有权重版本，只在录制中有版本时比较
+	if want.WeightsVersion != "" && want.WeightsVersion != got.WeightsVersion {
+		diffs = append(diffs, fmt.Sprintf("weights_version: %s != %s", want.WeightsVersion, got.WeightsVersion))
+	}
+	if len(want.Posts) != len(got.Posts) {
+		diffs = append(diffs, fmt.Sprintf("posts: %d != %d", len(want.Posts), len(got.Posts)))
+	}
+	for i := 0; i < len(want.Posts) || i < len(got.Posts); i++ {
+		var w, g *Post
+		if i < len(want.Posts) {
+			w = &want.Posts[i]
+		}
+		if i < len(got.Posts) {
+			g = &got.Posts[i]
+		}
+		if !samePost(w, g) {
+			diffs = append(diffs, fmt.Sprintf("position %d: %s != %s", i, w, g))
+		}
+	}
+	return diffs
+}
+
+func samePost(a, b *Post) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.TweetID != b.TweetID || a.AuthorID != b.AuthorID {
+		return false
+	}
+	if (a.ServedType == nil) != (b.ServedType == nil) || (a.ServedType != nil && *a.ServedType != *b.ServedType) {
+		return false
+	}
+	if a.Score == nil || b.Score == nil {
+		return a.Score == b.Score
+	}
+	return math.Float64bits(*a.Score) == math.Float64bits(*b.Score)
+}
+
+// String 返回用于差异描述的格式
+func (p *Post) String() string {
+	if p == nil {
+		return "<none>"
+	}
+	score := "nil"
+	if p.Score != nil {
+		score = fmt.Sprintf("%v", *p.Score)
+	}
+	return fmt.Sprintf("tweet_id=%d author_id=%d score=%s", p.TweetID, p.AuthorID, score)
+}
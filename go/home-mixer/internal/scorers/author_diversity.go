@@ -5,7 +5,7 @@ import (
 	"math"
 	"sort"
 
-	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 )
 
 // AuthorDiversityScorer 调整分数以确保 Feed 中作者多样性
@@ -37,14 +37,14 @@ func (s *AuthorDiversityScorer) multiplier(position int) float64 {
 }
 
 // Score 实现 Scorer 接口
-func (s *AuthorDiversityScorer) Score(ctx context.Context, query *pipeline.Query, candidates []*pipeline.Candidate) ([]*pipeline.Candidate, error) {
-	scored := make([]*pipeline.Candidate, len(candidates))
+func (s *AuthorDiversityScorer) Score(ctx context.Context, query *home.Query, candidates []*home.Candidate) ([]*home.Candidate, error) {
+	scored := home.NewCandidatePatches(len(candidates))
 	authorCounts := make(map[uint64]int)
 
 	// 创建索引和候选的配对，并按加权分数排序
 	type indexedCandidate struct {
 		index     int
-		candidate *pipeline.Candidate
+		candidate *home.Candidate
 	}
 	indexed := make([]indexedCandidate, len(candidates))
 	for i, c := range candidates {
@@ -83,8 +83,7 @@ func (s *AuthorDiversityScorer) Score(ctx context.Context, query *pipeline.Query
 			adjustedScore = &adjusted
 		}
 
-		// 创建更新后的候选
-		scored[originalIdx] = candidate.Clone()
+		// 只填写 Score，由管道通过 Update 合并
 		scored[originalIdx].Score = adjustedScore
 	}
 
@@ -92,14 +91,14 @@ func (s *AuthorDiversityScorer) Score(ctx context.Context, query *pipeline.Query
 }
 
 // Update 更新单个候选的打分字段
-func (s *AuthorDiversityScorer) Update(candidate *pipeline.Candidate, scored *pipeline.Candidate) {
+func (s *AuthorDiversityScorer) Update(candidate *home.Candidate, scored *home.Candidate) {
 	if scored.Score != nil {
 		candidate.Score = scored.Score
 	}
 }
 
 // UpdateAll 批量更新候选的打分字段
-func (s *AuthorDiversityScorer) UpdateAll(candidates []*pipeline.Candidate, scored []*pipeline.Candidate) {
+func (s *AuthorDiversityScorer) UpdateAll(candidates []*home.Candidate, scored []*home.Candidate) {
 	if len(candidates) != len(scored) {
 		return
 	}
@@ -114,6 +113,16 @@ func (s *AuthorDiversityScorer) Name() string {
 }
 
 // Enable 决定是否启用（AuthorDiversityScorer 总是启用）
-func (s *AuthorDiversityScorer) Enable(query *pipeline.Query) bool {
+func (s *AuthorDiversityScorer) Enable(query *home.Query) bool {
 	return true
 }
+
+// ReadFields 返回 Score 读取的字段（用于构建时校验字段读写顺序）
+func (s *AuthorDiversityScorer) ReadFields() []string {
+	return []string{"AuthorID", "WeightedScore"}
+}
+
+// WriteFields 返回 Update 写入的字段（用于构建时校验字段读写顺序）
+func (s *AuthorDiversityScorer) WriteFields() []string {
+	return []string{"Score"}
+}
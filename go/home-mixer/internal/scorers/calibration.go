@@ -0,0 +1,68 @@
+package scorers
+
+import (
+	"context"
+
+	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
+	"x-algorithm-go/home-mixer/internal/calibration"
+)
+
+// CalibrationScorer 在加权之前校准 Phoenix 的动作预测分数
+//
+// 排在 PhoenixScorer 和 WeightedScorer 之间：把每个有校准器的动作的预测概率替换为校准后的概率，
+// WeightedScorer 再按动作权重组合。没有校准器的动作和缺失的预测保持不变。
+// 校准器由 cmd/calibrate 根据记录的预测和互动标签拟合（见 calibration 包）。
+type CalibrationScorer struct {
+	Calibration *calibration.Set // 为 nil 时不校准
+}
+
+// NewCalibrationScorer 创建新的 CalibrationScorer 实例
+func NewCalibrationScorer(set *calibration.Set) *CalibrationScorer {
+	return &CalibrationScorer{
+		Calibration: set,
+	}
+}
+
+// Score 实现 Scorer 接口
+func (s *CalibrationScorer) Score(ctx context.Context, query *home.Query, candidates []*home.Candidate) ([]*home.Candidate, error) {
+	scored := home.NewCandidatePatches(len(candidates))
+	for i, candidate := range candidates {
+		if candidate.PhoenixScores != nil {
+			scored[i].PhoenixScores = s.Calibration.Apply(candidate.PhoenixScores)
+		}
+	}
+	return scored, nil
+}
+
+// Update 更新单个候选的打分字段
+func (s *CalibrationScorer) Update(candidate *home.Candidate, scored *home.Candidate) {
+	if scored.PhoenixScores != nil {
+		candidate.PhoenixScores = scored.PhoenixScores
+	}
+}
+
+// UpdateAll 批量更新候选的打分字段
+func (s *CalibrationScorer) UpdateAll(candidates []*home.Candidate, scored []*home.Candidate) {
+	pipeline.DefaultScorerUpdateAll(s, candidates, scored)
+}
+
+// Name 返回 Scorer 名称
+func (s *CalibrationScorer) Name() string {
+	return "CalibrationScorer"
+}
+
+// Enable 决定是否启用（没有配置校准器时跳过）
+func (s *CalibrationScorer) Enable(query *home.Query) bool {
+	return s.Calibration != nil && len(s.Calibration.Calibrators) > 0
+}
+
+// ReadFields 返回 Score 读取的字段（用于构建时校验字段读写顺序）
+func (s *CalibrationScorer) ReadFields() []string {
+	return []string{"PhoenixScores"}
+}
+
+// WriteFields 返回 Update 写入的字段（用于构建时校验字段读写顺序）
+func (s *CalibrationScorer) WriteFields() []string {
+	return []string{"PhoenixScores"}
+}
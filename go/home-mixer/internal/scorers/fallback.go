@@ -0,0 +1,172 @@
+package scorers
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
+	"x-algorithm-go/home-mixer/internal/utils"
+)
+
+// FallbackScorer 为没有 Phoenix 预测的候选给出启发式的 WeightedScore
+//
+// PhoenixScorer 失败、超时或被熔断，用户没有动作序列（冷启动），或 Phoenix 的响应中缺少某条帖子时，
+// 候选没有 PhoenixScores，WeightedScorer 会给出 0 分，排序退化为随机顺序。FallbackScorer 排在
+// WeightedScorer 之后，只为这些候选（PhoenixPredictionsMissing）改写 WeightedScore，其他候选保持不变：
+//
+//	启发式分数 = (RecencyWeight * 新鲜度 + AffinityWeight * 作者亲密度 + InNetworkWeight * 站内 + PopularityWeight * 作者热度) / 权重之和
+//	WeightedScore = NormalizeScore(Scale * 启发式分数)
+//
+// 各项都在 0-1 之间：
+//   - 新鲜度：按帖子年龄（由 snowflake ID 得到）指数衰减，年龄等于 RecencyHalfLife 时为 0.5
+//   - 作者亲密度：用户动作序列中对该作者（转发时为原帖作者）的互动，按动作类型加权后饱和到 0-1
+//   - 站内：站内候选为 1
+//   - 作者热度：作者粉丝数的对数，1000 万粉丝时为 1
+//
+// Scale 把启发式分数放到 Phoenix 加权组合分数的量级上（默认权重下常见的组合分数约为 1-4），
+// 再经过与 WeightedScorer 相同的归一化，因此降级的候选与有预测的候选可以放在一起排序。
+type FallbackScorer struct {
+	RecencyWeight    float64
+	AffinityWeight   float64
+	InNetworkWeight  float64
+	PopularityWeight float64
+	RecencyHalfLife  time.Duration
+	Scale            float64
+}
+
+// 作者亲密度的动作权重，未列出的动作类型使用 defaultActionAffinity
+var actionAffinity = map[string]float64{
+	"reply":    2.0,
+	"quote":    1.5,
+	"retweet":  1.5,
+	"favorite": 1.0,
+}
+
+const (
+	defaultActionAffinity = 0.5
+	// affinitySaturation 是亲密度达到 1-1/e 时的加权动作数
+	affinitySaturation = 3.0
+)
+
+// DefaultFallbackScorer 创建默认的 FallbackScorer
+func DefaultFallbackScorer() *FallbackScorer {
+	return &FallbackScorer{
+		RecencyWeight:    1.0,
+		AffinityWeight:   1.5,
+		InNetworkWeight:  1.0,
+		PopularityWeight: 0.5,
+		RecencyHalfLife:  6 * time.Hour,
+		Scale:            3.0,
+	}
+}
+
+// NewFallbackScorer 创建新的 FallbackScorer 实例
+func NewFallbackScorer(recencyWeight, affinityWeight, inNetworkWeight, popularityWeight float64, recencyHalfLife time.Duration, scale float64) *FallbackScorer {
+	return &FallbackScorer{
+		RecencyWeight:    recencyWeight,
+		AffinityWeight:   affinityWeight,
+		InNetworkWeight:  inNetworkWeight,
+		PopularityWeight: popularityWeight,
+		RecencyHalfLife:  recencyHalfLife,
+		Scale:            scale,
+	}
+}
+
+// Score 实现 Scorer 接口
+func (s *FallbackScorer) Score(ctx context.Context, query *home.Query, candidates []*home.Candidate) ([]*home.Candidate, error) {
+	scored := home.NewCandidatePatches(len(candidates))
+
+	var affinities map[uint64]float64
+	now := query.RequestTime()
+	for i, candidate := range candidates {
+		if !PhoenixPredictionsMissing(candidate) {
+			continue
+		}
+		if affinities == nil {
+			affinities = authorAffinities(query.UserActionSequence)
+		}
+		score := utils.NormalizeScore(candidate, s.Scale*s.heuristic(candidate, affinities, now))
+		scored[i].WeightedScore = &score
+	}
+
+	return scored, nil
+}
+
+// heuristic 返回候选的启发式分数（0-1）
+func (s *FallbackScorer) heuristic(candidate *home.Candidate, affinities map[uint64]float64, now time.Time) float64 {
+	total := s.RecencyWeight + s.AffinityWeight + s.InNetworkWeight + s.PopularityWeight
+	if total <= 0 {
+		return 0
+	}
+	author := candidate.AuthorID
+	if candidate.RetweetedUserID != nil {
+		author = *candidate.RetweetedUserID
+	}
+	inNetwork := 0.0
+	if candidate.InNetwork != nil && *candidate.InNetwork {
+		inNetwork = 1
+	}
+	score := s.RecencyWeight*recencyScore(candidate.TweetID, now, s.RecencyHalfLife) +
+		s.AffinityWeight*affinities[author] +
+		s.InNetworkWeight*inNetwork +
+		s.PopularityWeight*followersScore(candidate)
+	return score / total
+}
+
+// authorAffinities 按用户动作序列计算用户与各作者的亲密度（0-1）
+func authorAffinities(sequence *home.UserActionSequence) map[uint64]float64 {
+	affinities := make(map[uint64]float64)
+	if sequence == nil {
+		return affinities
+	}
+	for _, action := range sequence.Actions {
+		if action.AuthorID == 0 {
+			continue
+		}
+		weight, ok := actionAffinity[action.ActionType]
+		if !ok {
+			weight = defaultActionAffinity
+		}
+		affinities[action.AuthorID] += weight
+	}
+	for author, weighted := range affinities {
+		affinities[author] = 1 - math.Exp(-weighted/affinitySaturation)
+	}
+	return affinities
+}
+
+// Update 更新单个候选的打分字段，只改写没有 Phoenix 预测的候选
+func (s *FallbackScorer) Update(candidate *home.Candidate, scored *home.Candidate) {
+	if scored.WeightedScore != nil {
+		candidate.WeightedScore = scored.WeightedScore
+	}
+}
+
+// UpdateAll 批量更新候选的打分字段
+func (s *FallbackScorer) UpdateAll(candidates []*home.Candidate, scored []*home.Candidate) {
+	pipeline.DefaultScorerUpdateAll(s, candidates, scored)
+}
+
+// Name 返回 Scorer 名称
+func (s *FallbackScorer) Name() string {
+	return "FallbackScorer"
+}
+
+// Enable 决定是否启用（FallbackScorer 总是启用，所有候选都有 Phoenix 预测时不改写任何分数）
+func (s *FallbackScorer) Enable(query *home.Query) bool {
+	return true
+}
+
+// ReadFields 返回 Score 读取的字段（用于构建时校验字段读写顺序）
+// 读取 PhoenixScores 保证排在 PhoenixScorer 之后，
+// 读取 WeightedScore 保证排在 WeightedScorer 之后，否则降级分数会被 0 分覆盖
+func (s *FallbackScorer) ReadFields() []string {
+	return []string{"TweetID", "AuthorID", "RetweetedUserID", "InNetwork", "AuthorFollowersCount", "PhoenixScores", "WeightedScore"}
+}
+
+// WriteFields 返回 Update 写入的字段（用于构建时校验字段读写顺序）
+func (s *FallbackScorer) WriteFields() []string {
+	return []string{"WeightedScore"}
+}
@@ -0,0 +1,66 @@
+package scorers_test
+
+import (
+	"context"
+	"testing"
+
+	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
+	"x-algorithm-go/home-mixer/internal/scorers"
+)
+
+// predictionsFor 是只为 tweetIDs 返回预测的排序客户端
+type predictionsFor []uint64
+
+func (p predictionsFor) Rank(context.Context, *scorers.RankingRequest) (*scorers.RankingResponse, error) {
+	resp := &scorers.RankingResponse{PredictionsMap: make(map[uint64]*scorers.PhoenixPrediction)}
+	for _, id := range p {
+		resp.PredictionsMap[id] = &scorers.PhoenixPrediction{FavoriteScore: 0.5, ReplyScore: 0.1}
+	}
+	return resp, nil
+}
+
+func TestFallbackScorerScoresCandidatesWithoutPredictions(t *testing.T) {
+	sequence := &home.UserActionSequence{}
+	tests := []struct {
+		name         string
+		sequence     *home.UserActionSequence
+		predicted    predictionsFor
+		wantFallback []bool // 按候选顺序，是否由 FallbackScorer 打分
+	}{
+		{name: "all predicted", sequence: sequence, predicted: predictionsFor{1, 2}, wantFallback: []bool{false, false}},
+		// 没有用户动作序列时 PhoenixScorer 不调用 Phoenix，所有候选都没有预测
+		{name: "cold start user", predicted: predictionsFor{1, 2}, wantFallback: []bool{true, true}},
+		{name: "tweet missing from predictions", sequence: sequence, predicted: predictionsFor{1}, wantFallback: []bool{false, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inNetwork := true
+			query := &home.Query{RequestMeta: pipeline.RequestMeta{UserID: 42}, UserActionSequence: tt.sequence}
+			candidates := []*home.Candidate{
+				{TweetID: 1, AuthorID: 7, InNetwork: &inNetwork},
+				{TweetID: 2, AuthorID: 8, InNetwork: &inNetwork},
+			}
+			for _, s := range []pipeline.ScorerOf[*home.Query, *home.Candidate]{
+				scorers.NewPhoenixScorer(tt.predicted),
+				scorers.NewWeightedScorer(scorers.NewWeightsStore(nil)),
+				scorers.DefaultFallbackScorer(),
+			} {
+				scored, err := s.Score(context.Background(), query, candidates)
+				if err != nil {
+					t.Fatalf("%s: %v", s.Name(), err)
+				}
+				s.UpdateAll(candidates, scored)
+			}
+
+			for i, c := range candidates {
+				if got := scorers.PhoenixPredictionsMissing(c); got != tt.wantFallback[i] {
+					t.Errorf("candidate %d: predictions missing=%v, want %v", c.TweetID, got, tt.wantFallback[i])
+				}
+				if tt.wantFallback[i] && (c.WeightedScore == nil || *c.WeightedScore <= 0) {
+					t.Errorf("candidate %d: weighted score %v, want a positive fallback score", c.TweetID, c.WeightedScore)
+				}
+			}
+		})
+	}
+}
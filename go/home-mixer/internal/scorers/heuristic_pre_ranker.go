@@ -0,0 +1,148 @@
+package scorers
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
+	"x-algorithm-go/home-mixer/internal/utils"
+)
+
+// HeuristicPreRanker 是级联排序第一阶段的轻量打分器
+// 只使用已经增强到候选上的字段，不发起 RPC，为所有候选写入 PreRankScore；
+// 管道再按 PreRankScore 截断，只有靠前的候选会进入 PhoenixScorer。
+//
+// PreRankScore = RecencyWeight * 新鲜度 + AffinityWeight * 作者亲密度 + EngagementWeight * 互动潜力
+//   - 新鲜度：按帖子年龄指数衰减，年龄等于 RecencyHalfLife 时为 0.5
+//   - 作者亲密度：站内作者（或用户关注的作者）为 1，订阅作者额外加 0.5
+//   - 互动潜力：作者粉丝数的对数，1000 万粉丝时为 1；被多个 Source 同时召回时再加 MultiSourceBonus
+type HeuristicPreRanker struct {
+	RecencyWeight    float64
+	AffinityWeight   float64
+	EngagementWeight float64
+	RecencyHalfLife  time.Duration
+	MultiSourceBonus float64
+}
+
+// engagementFollowersScale 是互动潜力归一化的粉丝数（此时分数为 1）
+const engagementFollowersScale = 10_000_000
+
+// DefaultHeuristicPreRanker 创建默认的 HeuristicPreRanker
+func DefaultHeuristicPreRanker() *HeuristicPreRanker {
+	return &HeuristicPreRanker{
+		RecencyWeight:    1.0,
+		AffinityWeight:   1.0,
+		EngagementWeight: 0.5,
+		RecencyHalfLife:  6 * time.Hour,
+		MultiSourceBonus: 0.25,
+	}
+}
+
+// NewHeuristicPreRanker 创建新的 HeuristicPreRanker 实例
+func NewHeuristicPreRanker(recencyWeight, affinityWeight, engagementWeight float64, recencyHalfLife time.Duration, multiSourceBonus float64) *HeuristicPreRanker {
+	return &HeuristicPreRanker{
+		RecencyWeight:    recencyWeight,
+		AffinityWeight:   affinityWeight,
+		EngagementWeight: engagementWeight,
+		RecencyHalfLife:  recencyHalfLife,
+		MultiSourceBonus: multiSourceBonus,
+	}
+}
+
+// Score 实现 Scorer 接口
+func (s *HeuristicPreRanker) Score(ctx context.Context, query *home.Query, candidates []*home.Candidate) ([]*home.Candidate, error) {
+	scored := home.NewCandidatePatches(len(candidates))
+
+	followed := make(map[uint64]bool, len(query.UserFeatures.FollowedUserIDs))
+	for _, id := range query.UserFeatures.FollowedUserIDs {
+		followed[uint64(id)] = true
+	}
+
+	now := query.RequestTime()
+	for i, candidate := range candidates {
+		score := s.RecencyWeight*s.recency(candidate, now) +
+			s.AffinityWeight*affinity(candidate, followed) +
+			s.EngagementWeight*s.engagement(candidate)
+		scored[i].PreRankScore = &score
+	}
+
+	return scored, nil
+}
+
+// recency 返回帖子在请求时间 now 的新鲜度（0-1），无法解析创建时间时为 0
+func (s *HeuristicPreRanker) recency(candidate *home.Candidate, now time.Time) float64 {
+	return recencyScore(candidate.TweetID, now, s.RecencyHalfLife)
+}
+
+// recencyScore 按帖子年龄指数衰减，年龄等于 halfLife 时为 0.5；无法解析创建时间时为 0
+func recencyScore(tweetID int64, now time.Time, halfLife time.Duration) float64 {
+	age := utils.DurationSinceCreationAt(tweetID, now)
+	if age == nil || halfLife <= 0 {
+		return 0
+	}
+	if *age <= 0 {
+		return 1
+	}
+	return math.Exp2(-age.Hours() / halfLife.Hours())
+}
+
+// affinity 返回用户与作者的亲密度
+func affinity(candidate *home.Candidate, followed map[uint64]bool) float64 {
+	score := 0.0
+	if (candidate.InNetwork != nil && *candidate.InNetwork) || followed[candidate.AuthorID] {
+		score = 1.0
+	}
+	if candidate.SubscriptionAuthorID != nil {
+		score += 0.5
+	}
+	return score
+}
+
+// engagement 返回互动潜力：作者粉丝数（未增强时为 0）加上多来源召回的奖励
+func (s *HeuristicPreRanker) engagement(candidate *home.Candidate) float64 {
+	score := followersScore(candidate)
+	if candidate.MultiSource() {
+		score += s.MultiSourceBonus
+	}
+	return score
+}
+
+// followersScore 返回作者粉丝数的对数，1000 万粉丝时为 1，未增强时为 0
+func followersScore(candidate *home.Candidate) float64 {
+	if candidate.AuthorFollowersCount == nil || *candidate.AuthorFollowersCount <= 0 {
+		return 0
+	}
+	return math.Log1p(float64(*candidate.AuthorFollowersCount)) / math.Log1p(engagementFollowersScale)
+}
+
+// Update 更新单个候选的打分字段
+func (s *HeuristicPreRanker) Update(candidate *home.Candidate, scored *home.Candidate) {
+	candidate.PreRankScore = scored.PreRankScore
+}
+
+// UpdateAll 批量更新候选的打分字段
+func (s *HeuristicPreRanker) UpdateAll(candidates []*home.Candidate, scored []*home.Candidate) {
+	pipeline.DefaultScorerUpdateAll(s, candidates, scored)
+}
+
+// Name 返回 Scorer 名称
+func (s *HeuristicPreRanker) Name() string {
+	return "HeuristicPreRanker"
+}
+
+// Enable 决定是否启用（HeuristicPreRanker 总是启用）
+func (s *HeuristicPreRanker) Enable(query *home.Query) bool {
+	return true
+}
+
+// ReadFields 返回 Score 读取的字段（用于构建时校验字段读写顺序）
+func (s *HeuristicPreRanker) ReadFields() []string {
+	return []string{"TweetID", "AuthorID", "InNetwork", "SubscriptionAuthorID", "AuthorFollowersCount", "Provenance"}
+}
+
+// WriteFields 返回 Update 写入的字段（用于构建时校验字段读写顺序）
+func (s *HeuristicPreRanker) WriteFields() []string {
+	return []string{"PreRankScore"}
+}
@@ -3,7 +3,7 @@ package scorers
 import (
 	"context"
 
-	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 )
 
 // OONScorer 调整站外内容（Out-of-Network）的分数
@@ -27,13 +27,10 @@ func NewOONScorer(weightFactor float64) *OONScorer {
 }
 
 // Score 实现 Scorer 接口
-func (s *OONScorer) Score(ctx context.Context, query *pipeline.Query, candidates []*pipeline.Candidate) ([]*pipeline.Candidate, error) {
-	scored := make([]*pipeline.Candidate, len(candidates))
+func (s *OONScorer) Score(ctx context.Context, query *home.Query, candidates []*home.Candidate) ([]*home.Candidate, error) {
+	scored := home.NewCandidatePatches(len(candidates))
 
 	for i, candidate := range candidates {
-		// 克隆候选
-		scored[i] = candidate.Clone()
-
 		// 如果是站外内容，调整分数
 		if candidate.Score != nil {
 			if candidate.InNetwork != nil && !*candidate.InNetwork {
@@ -49,14 +46,14 @@ func (s *OONScorer) Score(ctx context.Context, query *pipeline.Query, candidates
 }
 
 // Update 更新单个候选的打分字段
-func (s *OONScorer) Update(candidate *pipeline.Candidate, scored *pipeline.Candidate) {
+func (s *OONScorer) Update(candidate *home.Candidate, scored *home.Candidate) {
 	if scored.Score != nil {
 		candidate.Score = scored.Score
 	}
 }
 
 // UpdateAll 批量更新候选的打分字段
-func (s *OONScorer) UpdateAll(candidates []*pipeline.Candidate, scored []*pipeline.Candidate) {
+func (s *OONScorer) UpdateAll(candidates []*home.Candidate, scored []*home.Candidate) {
 	if len(candidates) != len(scored) {
 		return
 	}
@@ -71,6 +68,16 @@ func (s *OONScorer) Name() string {
 }
 
 // Enable 决定是否启用（OONScorer 总是启用）
-func (s *OONScorer) Enable(query *pipeline.Query) bool {
+func (s *OONScorer) Enable(query *home.Query) bool {
 	return true
 }
+
+// ReadFields 返回 Score 读取的字段（用于构建时校验字段读写顺序）
+func (s *OONScorer) ReadFields() []string {
+	return []string{"InNetwork", "Score"}
+}
+
+// WriteFields 返回 Update 写入的字段（用于构建时校验字段读写顺序）
+func (s *OONScorer) WriteFields() []string {
+	return []string{"Score"}
+}
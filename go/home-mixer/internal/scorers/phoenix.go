@@ -3,9 +3,19 @@ package scorers
 import (
 	"context"
 
-	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 )
 
+// PhoenixScorerName 是 PhoenixScorer 的组件名
+// PhoenixScorer 失败、超时或被熔断时，管道把它记入候选的 MissingHydrations
+const PhoenixScorerName = "PhoenixScorer"
+
+// PhoenixPredictionsMissing 判断候选是否没有 Phoenix 预测，此时排序对该候选降级（见 FallbackScorer）
+// 包括 PhoenixScorer 失败、超时或被熔断，用户没有动作序列（冷启动），以及 Phoenix 的响应中没有该帖子的预测
+func PhoenixPredictionsMissing(candidate *home.Candidate) bool {
+	return candidate.PhoenixScores == nil
+}
+
 // PhoenixScorer 使用 Phoenix 模型为候选打分
 type PhoenixScorer struct {
 	phoenixRankingClient PhoenixRankingClient
@@ -20,8 +30,8 @@ type PhoenixRankingClient interface {
 // RankingRequest 表示排序请求
 type RankingRequest struct {
 	UserID            uint64
-	UserActionSequence *pipeline.UserActionSequence
-	Candidates        []*pipeline.Candidate
+	UserActionSequence *home.UserActionSequence
+	Candidates        []*home.Candidate
 	TweetInfos        []*TweetInfo // 用于预测的TweetInfo（转发时使用原帖ID）
 }
 
@@ -77,19 +87,15 @@ func NewPhoenixScorer(client PhoenixRankingClient) *PhoenixScorer {
 }
 
 // Score 实现 Scorer 接口
-func (s *PhoenixScorer) Score(ctx context.Context, query *pipeline.Query, candidates []*pipeline.Candidate) ([]*pipeline.Candidate, error) {
+func (s *PhoenixScorer) Score(ctx context.Context, query *home.Query, candidates []*home.Candidate) ([]*home.Candidate, error) {
 	if len(candidates) == 0 {
 		return candidates, nil
 	}
 
 	// 检查是否有 user_action_sequence
-	// 如果没有用户历史，返回未改变的候选（与Rust版本一致）
+	// 如果没有用户历史，返回空补丁，候选保持不变（与Rust版本一致）
 	if query.UserActionSequence == nil {
-		scored := make([]*pipeline.Candidate, len(candidates))
-		for i, c := range candidates {
-			scored[i] = c.Clone()
-		}
-		return scored, nil
+		return home.NewCandidatePatches(len(candidates)), nil
 	}
 
 	// 构建请求 - 对于转发，使用原帖ID和作者ID
@@ -124,11 +130,8 @@ func (s *PhoenixScorer) Score(ctx context.Context, query *pipeline.Query, candid
 	}
 
 	// 构建增强后的候选列表（保持顺序和数量一致）
-	scored := make([]*pipeline.Candidate, len(candidates))
+	scored := home.NewCandidatePatches(len(candidates))
 	for i, candidate := range candidates {
-		// 克隆候选
-		scored[i] = candidate.Clone()
-		
 		// 对于转发，使用原帖ID查找预测（与Rust版本一致）
 		lookupTweetID := uint64(candidate.TweetID)
 		if candidate.RetweetedTweetID != nil {
@@ -148,7 +151,7 @@ func (s *PhoenixScorer) Score(ctx context.Context, query *pipeline.Query, candid
 		if pred != nil {
 			
 			// 填充 PhoenixScores
-			scored[i].PhoenixScores = &pipeline.PhoenixScores{
+			scored[i].PhoenixScores = &home.PhoenixScores{
 				FavoriteScore:      &pred.FavoriteScore,
 				ReplyScore:         &pred.ReplyScore,
 				RetweetScore:       &pred.RetweetScore,
@@ -184,7 +187,7 @@ func (s *PhoenixScorer) Score(ctx context.Context, query *pipeline.Query, candid
 }
 
 // Update 更新单个候选的打分字段
-func (s *PhoenixScorer) Update(candidate *pipeline.Candidate, scored *pipeline.Candidate) {
+func (s *PhoenixScorer) Update(candidate *home.Candidate, scored *home.Candidate) {
 	if scored.PhoenixScores != nil {
 		candidate.PhoenixScores = scored.PhoenixScores.Clone()
 	}
@@ -197,7 +200,7 @@ func (s *PhoenixScorer) Update(candidate *pipeline.Candidate, scored *pipeline.C
 }
 
 // UpdateAll 批量更新候选的打分字段
-func (s *PhoenixScorer) UpdateAll(candidates []*pipeline.Candidate, scored []*pipeline.Candidate) {
+func (s *PhoenixScorer) UpdateAll(candidates []*home.Candidate, scored []*home.Candidate) {
 	if len(candidates) != len(scored) {
 		return
 	}
@@ -208,14 +211,24 @@ func (s *PhoenixScorer) UpdateAll(candidates []*pipeline.Candidate, scored []*pi
 
 // Name 返回 Scorer 名称
 func (s *PhoenixScorer) Name() string {
-	return "PhoenixScorer"
+	return PhoenixScorerName
 }
 
 // Enable 决定是否启用（PhoenixScorer 总是启用）
-func (s *PhoenixScorer) Enable(query *pipeline.Query) bool {
+func (s *PhoenixScorer) Enable(query *home.Query) bool {
 	return true
 }
 
+// ReadFields 返回 Score 读取的字段（用于构建时校验字段读写顺序）
+func (s *PhoenixScorer) ReadFields() []string {
+	return []string{"TweetID", "AuthorID", "RetweetedTweetID", "RetweetedUserID"}
+}
+
+// WriteFields 返回 Update 写入的字段（用于构建时校验字段读写顺序）
+func (s *PhoenixScorer) WriteFields() []string {
+	return []string{"PhoenixScores", "PredictionRequestID", "LastScoredAtMs"}
+}
+
 // MockPhoenixRankingClient is a mock implementation for local learning/testing
 type MockPhoenixRankingClient struct{}
 
@@ -2,51 +2,56 @@ package scorers
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"math"
 
-	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 	"x-algorithm-go/home-mixer/internal/utils"
 )
 
 // WeightedScorer 加权组合多个预测分数
 type WeightedScorer struct {
-	// 权重配置（可以从配置文件读取）
-	Weights *ActionWeights
+	// 当前生效的权重（从配置文件加载，热加载时原子替换）
+	Weights *WeightsStore
 }
 
 // ActionWeights 定义各种动作的权重
+// json 字段名即权重文件（见 mixer.LoadActionWeights）中的键
 type ActionWeights struct {
-	FavoriteWeight         float64
-	ReplyWeight            float64
-	RetweetWeight          float64
-	PhotoExpandWeight      float64
-	ClickWeight            float64
-	ProfileClickWeight     float64
-	VqvWeight              float64
-	ShareWeight            float64
-	ShareViaDmWeight       float64
-	ShareViaCopyLinkWeight float64
-	DwellWeight            float64
-	QuoteWeight            float64
-	QuotedClickWeight      float64
-	ContDwellTimeWeight    float64
-	FollowAuthorWeight     float64
-	NotInterestedWeight    float64
-	BlockAuthorWeight      float64
-	MuteAuthorWeight       float64
-	ReportWeight           float64
+	FavoriteWeight         float64 `json:"favorite_weight"`
+	ReplyWeight            float64 `json:"reply_weight"`
+	RetweetWeight          float64 `json:"retweet_weight"`
+	PhotoExpandWeight      float64 `json:"photo_expand_weight"`
+	ClickWeight            float64 `json:"click_weight"`
+	ProfileClickWeight     float64 `json:"profile_click_weight"`
+	VqvWeight              float64 `json:"vqv_weight"`
+	ShareWeight            float64 `json:"share_weight"`
+	ShareViaDmWeight       float64 `json:"share_via_dm_weight"`
+	ShareViaCopyLinkWeight float64 `json:"share_via_copy_link_weight"`
+	DwellWeight            float64 `json:"dwell_weight"`
+	QuoteWeight            float64 `json:"quote_weight"`
+	QuotedClickWeight      float64 `json:"quoted_click_weight"`
+	ContDwellTimeWeight    float64 `json:"cont_dwell_time_weight"`
+	FollowAuthorWeight     float64 `json:"follow_author_weight"`
+	NotInterestedWeight    float64 `json:"not_interested_weight"`
+	BlockAuthorWeight      float64 `json:"block_author_weight"`
+	MuteAuthorWeight       float64 `json:"mute_author_weight"`
+	ReportWeight           float64 `json:"report_weight"`
 	
 	// 配置参数
-	MinVideoDurationMs     int32
-	NegativeScoresOffset    float64
-	WeightsSum             float64
-	NegativeWeightsSum     float64
+	MinVideoDurationMs     int32   `json:"min_video_duration_ms"`
+	NegativeScoresOffset    float64 `json:"negative_scores_offset"`
+
+	// 归一化常数，由 DeriveSums 根据权重计算，不能在配置中给出
+	WeightsSum             float64 `json:"-"` // 所有权重绝对值之和
+	NegativeWeightsSum     float64 `json:"-"` // 负权重绝对值之和
 }
 
 // DefaultActionWeights 返回默认权重配置
 func DefaultActionWeights() *ActionWeights {
-	// 这些是示例权重，实际应该从配置文件读取
-	return &ActionWeights{
+	// 这些是示例权重，线上使用 --action_weights 指定的权重文件
+	w := &ActionWeights{
 		FavoriteWeight:         1.0,
 		ReplyWeight:            1.0,
 		RetweetWeight:          1.0,
@@ -68,31 +73,103 @@ func DefaultActionWeights() *ActionWeights {
 		ReportWeight:           -3.0,
 		MinVideoDurationMs:     3000, // 3秒
 		NegativeScoresOffset:    0.0,
-		WeightsSum:             10.0, // 正权重之和（示例）
-		NegativeWeightsSum:     -7.5, // 负权重之和（示例）
+	}
+	w.DeriveSums()
+	return w
+}
+
+// namedWeight 是一个动作权重及其配置键
+type namedWeight struct {
+	name  string
+	value float64
+}
+
+// named 返回全部动作权重，顺序与 computeWeightedScore 一致
+func (w *ActionWeights) named() []namedWeight {
+	return []namedWeight{
+		{"favorite_weight", w.FavoriteWeight},
+		{"reply_weight", w.ReplyWeight},
+		{"retweet_weight", w.RetweetWeight},
+		{"photo_expand_weight", w.PhotoExpandWeight},
+		{"click_weight", w.ClickWeight},
+		{"profile_click_weight", w.ProfileClickWeight},
+		{"vqv_weight", w.VqvWeight},
+		{"share_weight", w.ShareWeight},
+		{"share_via_dm_weight", w.ShareViaDmWeight},
+		{"share_via_copy_link_weight", w.ShareViaCopyLinkWeight},
+		{"dwell_weight", w.DwellWeight},
+		{"quote_weight", w.QuoteWeight},
+		{"quoted_click_weight", w.QuotedClickWeight},
+		{"cont_dwell_time_weight", w.ContDwellTimeWeight},
+		{"follow_author_weight", w.FollowAuthorWeight},
+		{"not_interested_weight", w.NotInterestedWeight},
+		{"block_author_weight", w.BlockAuthorWeight},
+		{"mute_author_weight", w.MuteAuthorWeight},
+		{"report_weight", w.ReportWeight},
 	}
 }
 
+// DeriveSums 根据权重计算 offsetScore 使用的归一化常数
+//
+// 预测分数是 0-1 的概率，组合分数不低于 -NegativeWeightsSum，因此负的组合分数经过
+// (combined + NegativeWeightsSum) / WeightsSum * NegativeScoresOffset 映射到 [0, NegativeScoresOffset)，
+// 排在所有非负组合分数（映射为 combined + NegativeScoresOffset）之后，且保持原有顺序
+func (w *ActionWeights) DeriveSums() {
+	w.WeightsSum, w.NegativeWeightsSum = 0, 0
+	for _, nw := range w.named() {
+		w.WeightsSum += math.Abs(nw.value)
+		if nw.value < 0 {
+			w.NegativeWeightsSum -= nw.value
+		}
+	}
+}
+
+// Validate 校验权重配置，返回全部问题
+func (w *ActionWeights) Validate() error {
+	var errs []error
+	positive := false
+	for _, nw := range w.named() {
+		if math.IsNaN(nw.value) || math.IsInf(nw.value, 0) {
+			errs = append(errs, fmt.Errorf("%s must be finite, got %v", nw.name, nw.value))
+		}
+		if nw.value > 0 {
+			positive = true
+		}
+	}
+	if !positive {
+		errs = append(errs, errors.New("at least one action weight must be > 0"))
+	}
+	if w.MinVideoDurationMs < 0 {
+		errs = append(errs, fmt.Errorf("min_video_duration_ms must be >= 0, got %d", w.MinVideoDurationMs))
+	}
+	if math.IsNaN(w.NegativeScoresOffset) || math.IsInf(w.NegativeScoresOffset, 0) || w.NegativeScoresOffset < 0 {
+		errs = append(errs, fmt.Errorf("negative_scores_offset must be finite and >= 0, got %v", w.NegativeScoresOffset))
+	}
+	return errors.Join(errs...)
+}
+
 // NewWeightedScorer 创建新的 WeightedScorer 实例
-func NewWeightedScorer(weights *ActionWeights) *WeightedScorer {
-	if weights == nil {
-		weights = DefaultActionWeights()
+// store 为 nil 时使用默认权重（DefaultVersionedWeights）
+func NewWeightedScorer(store *WeightsStore) *WeightedScorer {
+	if store == nil {
+		store = NewWeightsStore(nil)
 	}
 	return &WeightedScorer{
-		Weights: weights,
+		Weights: store,
 	}
 }
 
 // Score 实现 Scorer 接口
-func (s *WeightedScorer) Score(ctx context.Context, query *pipeline.Query, candidates []*pipeline.Candidate) ([]*pipeline.Candidate, error) {
-	scored := make([]*pipeline.Candidate, len(candidates))
+func (s *WeightedScorer) Score(ctx context.Context, query *home.Query, candidates []*home.Candidate) ([]*home.Candidate, error) {
+	scored := home.NewCandidatePatches(len(candidates))
+
+	// 整个请求使用执行开始时记录的权重版本，执行期间的热加载不影响本次请求
+	weights := s.Weights.LoadVersion(query.WeightsVersion)
+	version := weights.Version
 	
 	for i, candidate := range candidates {
-		// 克隆候选
-		scored[i] = candidate.Clone()
-		
 		// 计算加权分数
-		weightedScore := s.computeWeightedScore(candidate)
+		weightedScore := computeWeightedScore(&weights.Weights, candidate)
 		
 		// 归一化分数（使用与Rust版本一致的逻辑）
 		normalizedScore := utils.NormalizeScore(candidate, weightedScore)
@@ -100,50 +177,52 @@ func (s *WeightedScorer) Score(ctx context.Context, query *pipeline.Query, candi
 		// 只更新 WeightedScore 字段（与Rust版本一致）
 		// Score 字段由后续的 AuthorDiversityScorer 设置
 		scored[i].WeightedScore = &normalizedScore
+		if candidate.PhoenixScores != nil {
+			scored[i].WeightsVersion = &version
+		}
 	}
 	
 	return scored, nil
 }
 
 // computeWeightedScore 计算加权分数
-func (s *WeightedScorer) computeWeightedScore(candidate *pipeline.Candidate) float64 {
+func computeWeightedScore(w *ActionWeights, candidate *home.Candidate) float64 {
 	if candidate.PhoenixScores == nil {
 		return 0.0
 	}
 	
 	ps := candidate.PhoenixScores
-	w := s.Weights
 	
 	// 计算 VQV 权重（需要视频时长）
-	vqvWeight := s.vqvWeightEligibility(candidate)
+	vqvWeight := vqvWeightEligibility(w, candidate)
 	
 	// 组合所有分数
-	combinedScore := s.apply(ps.FavoriteScore, w.FavoriteWeight) +
-		s.apply(ps.ReplyScore, w.ReplyWeight) +
-		s.apply(ps.RetweetScore, w.RetweetWeight) +
-		s.apply(ps.PhotoExpandScore, w.PhotoExpandWeight) +
-		s.apply(ps.ClickScore, w.ClickWeight) +
-		s.apply(ps.ProfileClickScore, w.ProfileClickWeight) +
-		s.apply(ps.VqvScore, vqvWeight) +
-		s.apply(ps.ShareScore, w.ShareWeight) +
-		s.apply(ps.ShareViaDmScore, w.ShareViaDmWeight) +
-		s.apply(ps.ShareViaCopyLinkScore, w.ShareViaCopyLinkWeight) +
-		s.apply(ps.DwellScore, w.DwellWeight) +
-		s.apply(ps.QuoteScore, w.QuoteWeight) +
-		s.apply(ps.QuotedClickScore, w.QuotedClickWeight) +
-		s.apply(ps.DwellTime, w.ContDwellTimeWeight) +
-		s.apply(ps.FollowAuthorScore, w.FollowAuthorWeight) +
-		s.apply(ps.NotInterestedScore, w.NotInterestedWeight) +
-		s.apply(ps.BlockAuthorScore, w.BlockAuthorWeight) +
-		s.apply(ps.MuteAuthorScore, w.MuteAuthorWeight) +
-		s.apply(ps.ReportScore, w.ReportWeight)
+	combinedScore := apply(ps.FavoriteScore, w.FavoriteWeight) +
+		apply(ps.ReplyScore, w.ReplyWeight) +
+		apply(ps.RetweetScore, w.RetweetWeight) +
+		apply(ps.PhotoExpandScore, w.PhotoExpandWeight) +
+		apply(ps.ClickScore, w.ClickWeight) +
+		apply(ps.ProfileClickScore, w.ProfileClickWeight) +
+		apply(ps.VqvScore, vqvWeight) +
+		apply(ps.ShareScore, w.ShareWeight) +
+		apply(ps.ShareViaDmScore, w.ShareViaDmWeight) +
+		apply(ps.ShareViaCopyLinkScore, w.ShareViaCopyLinkWeight) +
+		apply(ps.DwellScore, w.DwellWeight) +
+		apply(ps.QuoteScore, w.QuoteWeight) +
+		apply(ps.QuotedClickScore, w.QuotedClickWeight) +
+		apply(ps.DwellTime, w.ContDwellTimeWeight) +
+		apply(ps.FollowAuthorScore, w.FollowAuthorWeight) +
+		apply(ps.NotInterestedScore, w.NotInterestedWeight) +
+		apply(ps.BlockAuthorScore, w.BlockAuthorWeight) +
+		apply(ps.MuteAuthorScore, w.MuteAuthorWeight) +
+		apply(ps.ReportScore, w.ReportWeight)
 	
 	// 应用偏移
-	return s.offsetScore(combinedScore)
+	return offsetScore(w, combinedScore)
 }
 
 // apply 应用权重
-func (s *WeightedScorer) apply(score *float64, weight float64) float64 {
+func apply(score *float64, weight float64) float64 {
 	if score == nil {
 		return 0.0
 	}
@@ -151,20 +230,18 @@ func (s *WeightedScorer) apply(score *float64, weight float64) float64 {
 }
 
 // vqvWeightEligibility 计算 VQV 权重（需要视频时长）
-func (s *WeightedScorer) vqvWeightEligibility(candidate *pipeline.Candidate) float64 {
+func vqvWeightEligibility(w *ActionWeights, candidate *home.Candidate) float64 {
 	if candidate.VideoDurationMs == nil {
 		return 0.0
 	}
-	if *candidate.VideoDurationMs > s.Weights.MinVideoDurationMs {
-		return s.Weights.VqvWeight
+	if *candidate.VideoDurationMs > w.MinVideoDurationMs {
+		return w.VqvWeight
 	}
 	return 0.0
 }
 
 // offsetScore 应用分数偏移
-func (s *WeightedScorer) offsetScore(combinedScore float64) float64 {
-	w := s.Weights
-	
+func offsetScore(w *ActionWeights, combinedScore float64) float64 {
 	if w.WeightsSum == 0.0 {
 		return math.Max(combinedScore, 0.0)
 	}
@@ -177,16 +254,19 @@ func (s *WeightedScorer) offsetScore(combinedScore float64) float64 {
 }
 
 // Update 更新单个候选的打分字段
-// 只更新 WeightedScore 字段（与Rust版本一致）
-func (s *WeightedScorer) Update(candidate *pipeline.Candidate, scored *pipeline.Candidate) {
+// 只更新 WeightedScore 字段（与Rust版本一致）和使用的权重版本
+func (s *WeightedScorer) Update(candidate *home.Candidate, scored *home.Candidate) {
 	if scored.WeightedScore != nil {
 		candidate.WeightedScore = scored.WeightedScore
 	}
+	if scored.WeightsVersion != nil {
+		candidate.WeightsVersion = scored.WeightsVersion
+	}
 	// 注意：不更新 Score 字段，Score 字段由后续的 AuthorDiversityScorer 设置
 }
 
 // UpdateAll 批量更新候选的打分字段
-func (s *WeightedScorer) UpdateAll(candidates []*pipeline.Candidate, scored []*pipeline.Candidate) {
+func (s *WeightedScorer) UpdateAll(candidates []*home.Candidate, scored []*home.Candidate) {
 	if len(candidates) != len(scored) {
 		return
 	}
@@ -201,6 +281,16 @@ func (s *WeightedScorer) Name() string {
 }
 
 // Enable 决定是否启用（WeightedScorer 总是启用）
-func (s *WeightedScorer) Enable(query *pipeline.Query) bool {
+func (s *WeightedScorer) Enable(query *home.Query) bool {
 	return true
 }
+
+// ReadFields 返回 Score 读取的字段（用于构建时校验字段读写顺序）
+func (s *WeightedScorer) ReadFields() []string {
+	return []string{"PhoenixScores", "VideoDurationMs"}
+}
+
+// WriteFields 返回 Update 写入的字段（用于构建时校验字段读写顺序）
+func (s *WeightedScorer) WriteFields() []string {
+	return []string{"WeightedScore", "WeightsVersion"}
+}
@@ -0,0 +1,80 @@
+package scorers
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// DefaultWeightsVersion 是内置默认权重（DefaultActionWeights）的版本
+const DefaultWeightsVersion = "default"
+
+// VersionedWeights 是一组带版本的动作权重
+// 创建后不再修改，热加载时整体替换，因此可以在请求之间共享
+type VersionedWeights struct {
+	Version string
+	Weights ActionWeights
+}
+
+// NewVersionedWeights 校验权重并计算归一化常数（配置中给出的 WeightsSum / NegativeWeightsSum 会被覆盖）
+func NewVersionedWeights(version string, weights ActionWeights) (*VersionedWeights, error) {
+	if version == "" {
+		return nil, errors.New("action weights: version must not be empty")
+	}
+	if err := weights.Validate(); err != nil {
+		return nil, fmt.Errorf("action weights %s: %w", version, err)
+	}
+	weights.DeriveSums()
+	return &VersionedWeights{Version: version, Weights: weights}, nil
+}
+
+// DefaultVersionedWeights 返回内置的默认权重
+func DefaultVersionedWeights() *VersionedWeights {
+	return &VersionedWeights{Version: DefaultWeightsVersion, Weights: *DefaultActionWeights()}
+}
+
+// WeightsStore 持有当前生效的动作权重，并发安全
+// 读取和替换都是原子的：每次读取得到完整的一组权重，不会读到新旧混合的权重
+//
+// 请求在执行开始时记录生效的版本（home.Query.WeightsVersion），打分时按该版本取权重（LoadVersion），
+// 因此执行期间的热加载不改变本次请求的排序。Store 同时保留上一个版本：热加载间隔远大于请求耗时，
+// 一次执行最多跨越一次替换。
+type WeightsStore struct {
+	current  atomic.Pointer[VersionedWeights]
+	previous atomic.Pointer[VersionedWeights]
+}
+
+// NewWeightsStore 创建 WeightsStore，initial 为 nil 时使用默认权重
+func NewWeightsStore(initial *VersionedWeights) *WeightsStore {
+	if initial == nil {
+		initial = DefaultVersionedWeights()
+	}
+	s := &WeightsStore{}
+	s.current.Store(initial)
+	return s
+}
+
+// Load 返回当前生效的权重
+func (s *WeightsStore) Load() *VersionedWeights {
+	return s.current.Load()
+}
+
+// LoadVersion 返回指定版本的权重
+// version 为空或既不是当前版本也不是上一个版本时返回当前生效的权重
+func (s *WeightsStore) LoadVersion(version string) *VersionedWeights {
+	current := s.current.Load()
+	if version == "" || version == current.Version {
+		return current
+	}
+	if previous := s.previous.Load(); previous != nil && previous.Version == version {
+		return previous
+	}
+	return current
+}
+
+// Swap 替换当前生效的权重，返回被替换的权重（被替换的权重仍可通过 LoadVersion 取到）
+func (s *WeightsStore) Swap(next *VersionedWeights) *VersionedWeights {
+	previous := s.current.Swap(next)
+	s.previous.Store(previous)
+	return previous
+}
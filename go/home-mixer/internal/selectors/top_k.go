@@ -5,7 +5,7 @@ import (
 	"math"
 	"sort"
 
-	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 )
 
 // TopKScoreSelector 按分数排序并选择 Top-K 候选
@@ -21,7 +21,7 @@ func NewTopKScoreSelector(k int) *TopKScoreSelector {
 }
 
 // Select 实现 Selector 接口
-func (s *TopKScoreSelector) Select(ctx context.Context, query *pipeline.Query, candidates []*pipeline.Candidate) []*pipeline.Candidate {
+func (s *TopKScoreSelector) Select(ctx context.Context, query *home.Query, candidates []*home.Candidate) []*home.Candidate {
 	// 排序
 	sorted := s.Sort(candidates)
 	
@@ -39,12 +39,12 @@ func (s *TopKScoreSelector) Name() string {
 }
 
 // Enable 决定是否启用（TopKScoreSelector 总是启用）
-func (s *TopKScoreSelector) Enable(query *pipeline.Query) bool {
+func (s *TopKScoreSelector) Enable(query *home.Query) bool {
 	return true
 }
 
 // Score 从候选对象中提取分数用于排序
-func (s *TopKScoreSelector) Score(candidate *pipeline.Candidate) float64 {
+func (s *TopKScoreSelector) Score(candidate *home.Candidate) float64 {
 	if candidate.Score != nil {
 		return *candidate.Score
 	}
@@ -53,9 +53,9 @@ func (s *TopKScoreSelector) Score(candidate *pipeline.Candidate) float64 {
 }
 
 // Sort 按分数降序排序候选列表
-func (s *TopKScoreSelector) Sort(candidates []*pipeline.Candidate) []*pipeline.Candidate {
+func (s *TopKScoreSelector) Sort(candidates []*home.Candidate) []*home.Candidate {
 	// 创建副本以避免修改原切片
-	sorted := make([]*pipeline.Candidate, len(candidates))
+	sorted := make([]*home.Candidate, len(candidates))
 	copy(sorted, candidates)
 	
 	// 按分数降序排序
@@ -4,7 +4,7 @@ import (
 	"context"
 	"os"
 
-	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 )
 
 // CacheRequestInfoSideEffect 缓存请求信息供后续使用
@@ -27,7 +27,7 @@ func NewCacheRequestInfoSideEffect(client StratoClient) *CacheRequestInfoSideEff
 }
 
 // Run 实现 SideEffect 接口
-func (s *CacheRequestInfoSideEffect) Run(ctx context.Context, query *pipeline.Query, candidates []*pipeline.Candidate) error {
+func (s *CacheRequestInfoSideEffect) Run(ctx context.Context, query *home.Query, candidates []*home.Candidate) error {
 	// 提取帖子ID列表
 	postIDs := make([]int64, len(candidates))
 	for i, candidate := range candidates {
@@ -45,7 +45,7 @@ func (s *CacheRequestInfoSideEffect) Name() string {
 
 // Enable 决定是否启用
 // 只在生产环境且非 in_network_only 时启用
-func (s *CacheRequestInfoSideEffect) Enable(query *pipeline.Query) bool {
+func (s *CacheRequestInfoSideEffect) Enable(query *home.Query) bool {
 	appEnv := os.Getenv("APP_ENV")
 	return appEnv == "prod" && !query.InNetworkOnly
 }
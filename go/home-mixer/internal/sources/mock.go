@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 
-	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 )
 
 // MockThunderClient 是 ThunderClient 的 Mock 实现（用于测试）
@@ -31,7 +31,7 @@ type MockPhoenixRetrievalClient struct {
 }
 
 // Retrieve 实现 PhoenixRetrievalClient 接口
-func (m *MockPhoenixRetrievalClient) Retrieve(ctx context.Context, userID uint64, sequence *pipeline.UserActionSequence, maxResults int) (*RetrievalResponse, error) {
+func (m *MockPhoenixRetrievalClient) Retrieve(ctx context.Context, userID uint64, sequence *home.UserActionSequence, maxResults int) (*RetrievalResponse, error) {
 	if sequence == nil {
 		return nil, fmt.Errorf("sequence is required")
 	}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 
-	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 )
 
 // PhoenixSource 从 Phoenix Retrieval 服务获取站外内容（ML 检索）
@@ -17,7 +17,7 @@ type PhoenixSource struct {
 // PhoenixRetrievalClient 定义 Phoenix Retrieval 客户端接口
 type PhoenixRetrievalClient interface {
 	// Retrieve 执行检索，返回相关候选
-	Retrieve(ctx context.Context, userID uint64, sequence *pipeline.UserActionSequence, maxResults int) (*RetrievalResponse, error)
+	Retrieve(ctx context.Context, userID uint64, sequence *home.UserActionSequence, maxResults int) (*RetrievalResponse, error)
 }
 
 // RetrievalResponse 表示检索响应
@@ -54,7 +54,7 @@ func NewPhoenixSource(client PhoenixRetrievalClient, maxResults int) *PhoenixSou
 }
 
 // GetCandidates 实现 Source 接口
-func (s *PhoenixSource) GetCandidates(ctx context.Context, query *pipeline.Query) ([]*pipeline.Candidate, error) {
+func (s *PhoenixSource) GetCandidates(ctx context.Context, query *home.Query) ([]*home.Candidate, error) {
 	// 检查是否有 user_action_sequence
 	if query.UserActionSequence == nil {
 		return nil, fmt.Errorf("PhoenixSource: missing user_action_sequence")
@@ -69,7 +69,7 @@ func (s *PhoenixSource) GetCandidates(ctx context.Context, query *pipeline.Query
 	}
 
 	// 转换为 Candidate
-	candidates := make([]*pipeline.Candidate, 0)
+	candidates := make([]*home.Candidate, 0)
 	for _, group := range response.TopKCandidates {
 		for _, scoredCandidate := range group.Candidates {
 			if scoredCandidate.Candidate == nil {
@@ -89,8 +89,8 @@ func (s *PhoenixSource) GetCandidates(ctx context.Context, query *pipeline.Query
 				inReplyToTweetID = &zero
 			}
 			
-			servedType := int32(1) // ForYouPhoenixRetrieval，实际应该使用常量
-			candidate := &pipeline.Candidate{
+			servedType := home.ServedTypeForYouPhoenixRetrieval
+			candidate := &home.Candidate{
 				TweetID:          tweetInfo.TweetID,
 				AuthorID:         tweetInfo.AuthorID,
 				InReplyToTweetID: inReplyToTweetID,
@@ -109,6 +109,6 @@ func (s *PhoenixSource) Name() string {
 }
 
 // Enable 决定是否启用（只在非 in_network_only 时启用）
-func (s *PhoenixSource) Enable(query *pipeline.Query) bool {
+func (s *PhoenixSource) Enable(query *home.Query) bool {
 	return !query.InNetworkOnly
 }
@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 
-	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 )
 
 // ThunderSource 从 Thunder 服务获取站内内容（关注账号的帖子）
@@ -57,12 +57,12 @@ func NewThunderSource(client ThunderClient, maxResults int) *ThunderSource {
 }
 
 // GetCandidates 实现 Source 接口
-func (s *ThunderSource) GetCandidates(ctx context.Context, query *pipeline.Query) ([]*pipeline.Candidate, error) {
+func (s *ThunderSource) GetCandidates(ctx context.Context, query *home.Query) ([]*home.Candidate, error) {
 	// 获取关注列表
 	followingList := query.UserFeatures.FollowedUserIDs
 	if len(followingList) == 0 {
 		// 如果没有关注列表，返回空结果
-		return []*pipeline.Candidate{}, nil
+		return []*home.Candidate{}, nil
 	}
 
 	// 转换为 uint64
@@ -89,7 +89,7 @@ func (s *ThunderSource) GetCandidates(ctx context.Context, query *pipeline.Query
 	}
 
 	// 转换为 Candidate
-	candidates := make([]*pipeline.Candidate, 0, len(response.Posts))
+	candidates := make([]*home.Candidate, 0, len(response.Posts))
 	for _, post := range response.Posts {
 		var inReplyToTweetID *uint64
 		if post.InReplyToPostID != nil {
@@ -109,8 +109,8 @@ func (s *ThunderSource) GetCandidates(ctx context.Context, query *pipeline.Query
 			}
 		}
 
-		servedType := int32(0) // ForYouInNetwork，实际应该使用常量
-		candidate := &pipeline.Candidate{
+		servedType := home.ServedTypeForYouInNetwork
+		candidate := &home.Candidate{
 			TweetID:          post.PostID,
 			AuthorID:         post.AuthorID,
 			InReplyToTweetID: inReplyToTweetID,
@@ -129,6 +129,6 @@ func (s *ThunderSource) Name() string {
 }
 
 // Enable 决定是否启用（Thunder Source 总是启用）
-func (s *ThunderSource) Enable(query *pipeline.Query) bool {
+func (s *ThunderSource) Enable(query *home.Query) bool {
 	return true
 }
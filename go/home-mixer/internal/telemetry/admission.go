@@ -0,0 +1,48 @@
+package telemetry
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"x-algorithm-go/home-mixer/internal/mixer"
+)
+
+// PrometheusAdmissionObserver 把准入控制的结果记录为 Prometheus 指标
+//
+//   - home_mixer_admission_requests_total{priority="top|bottom",outcome="admitted|coalesced|shed"}
+//   - home_mixer_admission_in_flight（正在执行的管道数）
+type PrometheusAdmissionObserver struct {
+	requests *prometheus.CounterVec
+}
+
+// NewPrometheusAdmissionObserver 创建 PrometheusAdmissionObserver 并把指标注册到 reg
+// inFlight 返回正在执行的管道数（通常为 Admission.InFlight），可以为 nil
+func NewPrometheusAdmissionObserver(reg prometheus.Registerer, inFlight func() int) (*PrometheusAdmissionObserver, error) {
+	o := &PrometheusAdmissionObserver{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "home_mixer",
+			Subsystem: "admission",
+			Name:      "requests_total",
+			Help:      "经过准入控制的请求数，按优先级和结果区分",
+		}, []string{"priority", "outcome"}),
+	}
+	collectors := []prometheus.Collector{o.requests}
+	if inFlight != nil {
+		collectors = append(collectors, prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "home_mixer",
+			Subsystem: "admission",
+			Name:      "in_flight",
+			Help:      "正在执行的管道数",
+		}, func() float64 { return float64(inFlight()) }))
+	}
+	for _, c := range collectors {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// Admission 实现 mixer.AdmissionObserver
+func (o *PrometheusAdmissionObserver) Admission(priority string, outcome mixer.AdmissionOutcome) {
+	o.requests.WithLabelValues(priority, string(outcome)).Inc()
+}
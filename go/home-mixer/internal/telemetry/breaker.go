@@ -0,0 +1,50 @@
+package telemetry
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"x-algorithm-go/home-mixer/internal/clients"
+)
+
+// PrometheusBreakerObserver 把下游熔断器的状态记录为 Prometheus 指标
+//
+//   - home_mixer_downstream_breaker_transitions_total{service,to="closed|open|half_open"}
+//   - home_mixer_downstream_breaker_open{service}（熔断器打开或半开时为 1）
+type PrometheusBreakerObserver struct {
+	transitions *prometheus.CounterVec
+	open        *prometheus.GaugeVec
+}
+
+// NewPrometheusBreakerObserver 创建 PrometheusBreakerObserver 并把指标注册到 reg
+func NewPrometheusBreakerObserver(reg prometheus.Registerer) (*PrometheusBreakerObserver, error) {
+	o := &PrometheusBreakerObserver{
+		transitions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "home_mixer",
+			Subsystem: "downstream",
+			Name:      "breaker_transitions_total",
+			Help:      "下游熔断器的状态变化次数，按变化后的状态区分",
+		}, []string{"service", "to"}),
+		open: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "home_mixer",
+			Subsystem: "downstream",
+			Name:      "breaker_open",
+			Help:      "下游熔断器是否打开（包括半开）",
+		}, []string{"service"}),
+	}
+	for _, c := range []prometheus.Collector{o.transitions, o.open} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// BreakerStateChanged 实现 clients.BreakerObserver
+func (o *PrometheusBreakerObserver) BreakerStateChanged(service string, _, to clients.BreakerState) {
+	o.transitions.WithLabelValues(service, to.String()).Inc()
+	open := 0.0
+	if to != clients.BreakerClosed {
+		open = 1
+	}
+	o.open.WithLabelValues(service).Set(open)
+}
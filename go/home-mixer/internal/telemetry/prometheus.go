@@ -0,0 +1,179 @@
+package telemetry
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"x-algorithm-go/candidate-pipeline/pipeline"
+)
+
+// 延迟直方图的桶（秒），覆盖 1ms ~ 2.5s，与各阶段预算的量级一致
+var latencyBuckets = []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5}
+
+// PrometheusObserver 把管道事件记录为 Prometheus 指标
+//
+//   - home_mixer_pipeline_stage_duration_seconds{stage}
+//   - home_mixer_pipeline_stage_candidates_total{stage,direction="in|out"}
+//   - home_mixer_pipeline_component_duration_seconds{stage,component}
+//   - home_mixer_pipeline_candidates_kept_total{stage,component}
+//   - home_mixer_pipeline_candidates_removed_total{stage,component}
+//   - home_mixer_pipeline_component_failures_total{stage,component,kind="error|timeout",policy}
+//   - home_mixer_pipeline_length_mismatch_total{stage,component}
+//   - home_mixer_pipeline_hedged_total{stage,component}
+//   - home_mixer_pipeline_aborted_total{stage}
+type PrometheusObserver struct {
+	pipeline.NopObserver
+
+	stageDuration     *prometheus.HistogramVec
+	stageCandidates   *prometheus.CounterVec
+	componentDuration *prometheus.HistogramVec
+	kept              *prometheus.CounterVec
+	removed           *prometheus.CounterVec
+	failures          *prometheus.CounterVec
+	lengthMismatches  *prometheus.CounterVec
+	hedged            *prometheus.CounterVec
+	aborted           *prometheus.CounterVec
+}
+
+// NewPrometheusObserver 创建 PrometheusObserver 并把指标注册到 reg
+func NewPrometheusObserver(reg prometheus.Registerer) (*PrometheusObserver, error) {
+	o := &PrometheusObserver{
+		stageDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "home_mixer",
+			Subsystem: "pipeline",
+			Name:      "stage_duration_seconds",
+			Help:      "管道各阶段的耗时",
+			Buckets:   latencyBuckets,
+		}, []string{"stage"}),
+		stageCandidates: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "home_mixer",
+			Subsystem: "pipeline",
+			Name:      "stage_candidates_total",
+			Help:      "进入和离开各阶段的候选数",
+		}, []string{"stage", "direction"}),
+		componentDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "home_mixer",
+			Subsystem: "pipeline",
+			Name:      "component_duration_seconds",
+			Help:      "单个组件调用的耗时（超时的组件记录到被放弃为止）",
+			Buckets:   latencyBuckets,
+		}, []string{"stage", "component"}),
+		kept: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "home_mixer",
+			Subsystem: "pipeline",
+			Name:      "candidates_kept_total",
+			Help:      "组件处理后保留的候选数（Source 为产出数）",
+		}, []string{"stage", "component"}),
+		removed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "home_mixer",
+			Subsystem: "pipeline",
+			Name:      "candidates_removed_total",
+			Help:      "组件移除的候选数（包括 fail-closed 丢弃的候选）",
+		}, []string{"stage", "component"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "home_mixer",
+			Subsystem: "pipeline",
+			Name:      "component_failures_total",
+			Help:      "组件失败次数，按失败类型和失败策略区分",
+		}, []string{"stage", "component", "kind", "policy"}),
+		lengthMismatches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "home_mixer",
+			Subsystem: "pipeline",
+			Name:      "length_mismatch_total",
+			Help:      "Hydrator / Scorer 返回的候选数与输入不一致的次数",
+		}, []string{"stage", "component"}),
+		hedged: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "home_mixer",
+			Subsystem: "pipeline",
+			Name:      "hedged_total",
+			Help:      "超过对冲延迟、发起了对冲请求的组件调用次数",
+		}, []string{"stage", "component"}),
+		aborted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "home_mixer",
+			Subsystem: "pipeline",
+			Name:      "aborted_total",
+			Help:      "因 critical 组件失败而终止的请求数",
+		}, []string{"stage"}),
+	}
+
+	for _, c := range []prometheus.Collector{
+		o.stageDuration, o.stageCandidates, o.componentDuration,
+		o.kept, o.removed, o.failures, o.lengthMismatches, o.hedged, o.aborted,
+	} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// StageEnd 实现 pipeline.Observer
+func (o *PrometheusObserver) StageEnd(_ context.Context, e pipeline.StageEvent) {
+	o.stageDuration.WithLabelValues(e.Stage).Observe(e.Duration.Seconds())
+	o.stageCandidates.WithLabelValues(e.Stage, "in").Add(float64(e.CandidatesIn))
+	o.stageCandidates.WithLabelValues(e.Stage, "out").Add(float64(e.CandidatesOut))
+	if e.Err != nil {
+		o.aborted.WithLabelValues(e.Stage).Inc()
+	}
+}
+
+// ComponentEnd 实现 pipeline.Observer
+func (o *PrometheusObserver) ComponentEnd(_ context.Context, e pipeline.ComponentEvent) {
+	o.componentDuration.WithLabelValues(e.Stage, e.Component).Observe(e.Duration.Seconds())
+	o.kept.WithLabelValues(e.Stage, e.Component).Add(float64(e.CandidatesOut))
+	o.removed.WithLabelValues(e.Stage, e.Component).Add(float64(e.Removed))
+	if e.Hedged {
+		o.hedged.WithLabelValues(e.Stage, e.Component).Inc()
+	}
+	if e.Err != nil {
+		kind := "error"
+		if e.TimedOut {
+			kind = "timeout"
+		}
+		o.failures.WithLabelValues(e.Stage, e.Component, kind, e.Policy.String()).Inc()
+	}
+}
+
+// LengthMismatch 实现 pipeline.Observer
+func (o *PrometheusObserver) LengthMismatch(_ context.Context, e pipeline.ComponentEvent, _, _ int) {
+	o.lengthMismatches.WithLabelValues(e.Stage, e.Component).Inc()
+}
+
+// RegisterSideEffectStats 把 Side Effect 执行器的计数注册为指标
+func RegisterSideEffectStats(reg prometheus.Registerer, executor *pipeline.SideEffectExecutor) error {
+	counter := func(name, help string, value func(pipeline.SideEffectStats) uint64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: "home_mixer",
+			Subsystem: "side_effects",
+			Name:      name,
+			Help:      help,
+		}, func() float64 { return float64(value(executor.Stats())) })
+	}
+	collectors := []prometheus.Collector{
+		counter("submitted_total", "进入队列的 Side Effect 任务数",
+			func(s pipeline.SideEffectStats) uint64 { return s.Submitted }),
+		counter("dropped_total", "因队列已满或执行器已关闭而丢弃的任务数",
+			func(s pipeline.SideEffectStats) uint64 { return s.Dropped }),
+		counter("succeeded_total", "最终成功的任务数",
+			func(s pipeline.SideEffectStats) uint64 { return s.Succeeded }),
+		counter("failed_total", "重试耗尽后仍失败的任务数",
+			func(s pipeline.SideEffectStats) uint64 { return s.Failed }),
+		counter("retried_total", "重试次数",
+			func(s pipeline.SideEffectStats) uint64 { return s.Retried }),
+		counter("panicked_total", "发生 panic 的执行次数",
+			func(s pipeline.SideEffectStats) uint64 { return s.Panicked }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "home_mixer",
+			Subsystem: "side_effects",
+			Name:      "pending",
+			Help:      "当前排队和执行中的任务数",
+		}, func() float64 { return float64(executor.Stats().Pending) }),
+	}
+	for _, c := range collectors {
+		if err := reg.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
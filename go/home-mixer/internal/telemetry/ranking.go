@@ -0,0 +1,49 @@
+package telemetry
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PrometheusRankingObserver 把响应的排序情况记录为 Prometheus 指标
+//
+//   - home_mixer_ranking_responses_total{ranking="full|degraded"}（degraded：至少一条帖子没有 Phoenix 预测、按降级分数排序）
+//   - home_mixer_ranking_posts_total{ranking="phoenix|fallback"}
+type PrometheusRankingObserver struct {
+	responses *prometheus.CounterVec
+	posts     *prometheus.CounterVec
+}
+
+// NewPrometheusRankingObserver 创建 PrometheusRankingObserver 并把指标注册到 reg
+func NewPrometheusRankingObserver(reg prometheus.Registerer) (*PrometheusRankingObserver, error) {
+	o := &PrometheusRankingObserver{
+		responses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "home_mixer",
+			Subsystem: "ranking",
+			Name:      "responses_total",
+			Help:      "响应数，按是否包含降级排序的帖子区分",
+		}, []string{"ranking"}),
+		posts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "home_mixer",
+			Subsystem: "ranking",
+			Name:      "posts_total",
+			Help:      "返回的帖子数，按分数来自 Phoenix 预测还是降级的启发式打分区分",
+		}, []string{"ranking"}),
+	}
+	for _, c := range []prometheus.Collector{o.responses, o.posts} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// Ranked 实现 mixer.RankingObserver
+func (o *PrometheusRankingObserver) Ranked(posts, fallbackRanked int) {
+	ranking := "full"
+	if fallbackRanked > 0 {
+		ranking = "degraded"
+	}
+	o.responses.WithLabelValues(ranking).Inc()
+	o.posts.WithLabelValues("phoenix").Add(float64(posts - fallbackRanked))
+	o.posts.WithLabelValues("fallback").Add(float64(fallbackRanked))
+}
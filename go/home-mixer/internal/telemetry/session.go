@@ -0,0 +1,48 @@
+package telemetry
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"x-algorithm-go/home-mixer/internal/mixer"
+)
+
+// PrometheusSessionObserver 把分页会话缓存的查询结果记录为 Prometheus 指标
+//
+//   - home_mixer_session_cache_requests_total{outcome="hit|miss|stale|exhausted|failed"}
+//   - home_mixer_session_cache_sessions（缓存的会话数）
+type PrometheusSessionObserver struct {
+	requests *prometheus.CounterVec
+}
+
+// NewPrometheusSessionObserver 创建 PrometheusSessionObserver 并把指标注册到 reg
+// sessions 返回当前缓存的会话数（通常为 SessionCache.Len），可以为 nil
+func NewPrometheusSessionObserver(reg prometheus.Registerer, sessions func() int) (*PrometheusSessionObserver, error) {
+	o := &PrometheusSessionObserver{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "home_mixer",
+			Subsystem: "session_cache",
+			Name:      "requests_total",
+			Help:      "分页请求查询会话缓存的次数，按结果区分",
+		}, []string{"outcome"}),
+	}
+	collectors := []prometheus.Collector{o.requests}
+	if sessions != nil {
+		collectors = append(collectors, prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "home_mixer",
+			Subsystem: "session_cache",
+			Name:      "sessions",
+			Help:      "会话缓存中的会话数",
+		}, func() float64 { return float64(sessions()) }))
+	}
+	for _, c := range collectors {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// SessionLookup 实现 mixer.SessionObserver
+func (o *PrometheusSessionObserver) SessionLookup(outcome mixer.SessionOutcome) {
+	o.requests.WithLabelValues(string(outcome)).Inc()
+}
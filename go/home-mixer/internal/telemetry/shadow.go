@@ -0,0 +1,110 @@
+package telemetry
+
+import (
+	"math"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"x-algorithm-go/candidate-pipeline/pipeline"
+)
+
+// PrometheusShadowObserver 把影子执行的比较结果记录为 Prometheus 指标
+//
+//   - home_mixer_shadow_requests_total{outcome="compared|failed|dropped"}
+//   - home_mixer_shadow_overlap_at_k
+//   - home_mixer_shadow_rank_correlation（共同帖子少于 2 条时不记录）
+//   - home_mixer_shadow_mean_abs_score_delta
+//   - home_mixer_shadow_selected_candidates_total{pipeline="primary|shadow"}
+//   - home_mixer_shadow_candidates_removed_total{pipeline="primary|shadow",stage,component}
+//
+// 各过滤器移除数的差异由两个 pipeline 标签的 candidates_removed_total 相减得到
+type PrometheusShadowObserver struct {
+	requests        *prometheus.CounterVec
+	overlapAtK      prometheus.Histogram
+	rankCorrelation prometheus.Histogram
+	scoreDelta      prometheus.Histogram
+	selected        *prometheus.CounterVec
+	removed         *prometheus.CounterVec
+}
+
+// NewPrometheusShadowObserver 创建 PrometheusShadowObserver 并把指标注册到 reg
+func NewPrometheusShadowObserver(reg prometheus.Registerer) (*PrometheusShadowObserver, error) {
+	o := &PrometheusShadowObserver{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "home_mixer",
+			Subsystem: "shadow",
+			Name:      "requests_total",
+			Help:      "被采样执行影子管道的请求数，按结果区分",
+		}, []string{"outcome"}),
+		overlapAtK: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "home_mixer",
+			Subsystem: "shadow",
+			Name:      "overlap_at_k",
+			Help:      "主管道和影子管道前 K 个结果的重合比例",
+			Buckets:   prometheus.LinearBuckets(0.1, 0.1, 10),
+		}),
+		rankCorrelation: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "home_mixer",
+			Subsystem: "shadow",
+			Name:      "rank_correlation",
+			Help:      "共同帖子在两个管道中排名的 Spearman 相关系数",
+			Buckets:   prometheus.LinearBuckets(-0.8, 0.2, 10),
+		}),
+		scoreDelta: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "home_mixer",
+			Subsystem: "shadow",
+			Name:      "mean_abs_score_delta",
+			Help:      "共同帖子分数差的绝对值的均值",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 4, 8),
+		}),
+		selected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "home_mixer",
+			Subsystem: "shadow",
+			Name:      "selected_candidates_total",
+			Help:      "被比较的请求中各管道选中的候选数",
+		}, []string{"pipeline"}),
+		removed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "home_mixer",
+			Subsystem: "shadow",
+			Name:      "candidates_removed_total",
+			Help:      "被比较的请求中各管道每个组件移除的候选数",
+		}, []string{"pipeline", "stage", "component"}),
+	}
+
+	for _, c := range []prometheus.Collector{
+		o.requests, o.overlapAtK, o.rankCorrelation, o.scoreDelta, o.selected, o.removed,
+	} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// ShadowCompared 实现 mixer.ShadowObserver
+func (o *PrometheusShadowObserver) ShadowCompared(c pipeline.ResultComparison) {
+	o.requests.WithLabelValues("compared").Inc()
+	o.overlapAtK.Observe(c.OverlapAtK)
+	if !math.IsNaN(c.RankCorrelation) {
+		o.rankCorrelation.Observe(c.RankCorrelation)
+	}
+	o.scoreDelta.Observe(c.MeanAbsScoreDelta)
+	o.selected.WithLabelValues("primary").Add(float64(c.PrimarySize))
+	o.selected.WithLabelValues("shadow").Add(float64(c.ShadowSize))
+	for key, n := range c.PrimaryRemovals {
+		o.removed.WithLabelValues("primary", key.Stage, key.Component).Add(float64(n))
+	}
+	for key, n := range c.ShadowRemovals {
+		o.removed.WithLabelValues("shadow", key.Stage, key.Component).Add(float64(n))
+	}
+}
+
+// ShadowFailed 实现 mixer.ShadowObserver
+func (o *PrometheusShadowObserver) ShadowFailed(error) {
+	o.requests.WithLabelValues("failed").Inc()
+}
+
+// ShadowDropped 实现 mixer.ShadowObserver
+func (o *PrometheusShadowObserver) ShadowDropped() {
+	o.requests.WithLabelValues("dropped").Inc()
+}
@@ -0,0 +1,106 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"x-algorithm-go/candidate-pipeline/pipeline"
+)
+
+// tracerName 是管道 span 的 instrumentation 名称
+const tracerName = "x-algorithm-go/candidate-pipeline"
+
+// TracingObserver 为每个阶段和组件创建 OpenTelemetry span
+// 组件 span 是阶段 span 的子 span，组件收到的 ctx 中带有自己的 span，
+// 组件内部发起的 RPC 可以继续向下传播。
+type TracingObserver struct {
+	tracer trace.Tracer
+}
+
+// NewTracingObserver 创建 TracingObserver
+// tracer 为 nil 时使用全局 TracerProvider（otel.SetTracerProvider）
+func NewTracingObserver(tracer trace.Tracer) *TracingObserver {
+	if tracer == nil {
+		tracer = otel.Tracer(tracerName)
+	}
+	return &TracingObserver{tracer: tracer}
+}
+
+// StageStart 实现 pipeline.Observer
+func (o *TracingObserver) StageStart(ctx context.Context, e pipeline.StageEvent) context.Context {
+	ctx, _ = o.tracer.Start(ctx, "pipeline."+e.Stage, trace.WithAttributes(
+		attribute.String("request_id", e.RequestID),
+		attribute.String("pipeline.stage", e.Stage),
+		attribute.Int("pipeline.candidates_in", e.CandidatesIn),
+	))
+	return ctx
+}
+
+// StageEnd 实现 pipeline.Observer
+func (o *TracingObserver) StageEnd(ctx context.Context, e pipeline.StageEvent) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.Int("pipeline.candidates_out", e.CandidatesOut))
+	if e.Err != nil {
+		span.RecordError(e.Err)
+		span.SetStatus(codes.Error, e.Err.Error())
+	}
+	span.End()
+}
+
+// ComponentStart 实现 pipeline.Observer
+func (o *TracingObserver) ComponentStart(ctx context.Context, e pipeline.ComponentEvent) context.Context {
+	ctx, _ = o.tracer.Start(ctx, fmt.Sprintf("pipeline.%s/%s", e.Stage, e.Component), trace.WithAttributes(
+		attribute.String("request_id", e.RequestID),
+		attribute.String("pipeline.stage", e.Stage),
+		attribute.String("pipeline.component", e.Component),
+		attribute.Int("pipeline.candidates_in", e.CandidatesIn),
+	))
+	return ctx
+}
+
+// ComponentEnd 实现 pipeline.Observer
+func (o *TracingObserver) ComponentEnd(ctx context.Context, e pipeline.ComponentEvent) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.Int("pipeline.candidates_out", e.CandidatesOut),
+		attribute.Int("pipeline.removed", e.Removed),
+	)
+	if e.Err != nil {
+		span.SetAttributes(
+			attribute.Bool("pipeline.timed_out", e.TimedOut),
+			attribute.String("pipeline.failure_policy", e.Policy.String()),
+		)
+		span.RecordError(e.Err)
+		span.SetStatus(codes.Error, e.Err.Error())
+	}
+	span.End()
+}
+
+// LengthMismatch 实现 pipeline.Observer
+func (o *TracingObserver) LengthMismatch(ctx context.Context, _ pipeline.ComponentEvent, expected, got int) {
+	trace.SpanFromContext(ctx).AddEvent("length_mismatch", trace.WithAttributes(
+		attribute.Int("expected", expected),
+		attribute.Int("got", got),
+	))
+}
+
+// NewStdoutTracerProvider 创建把 span 以 JSON 输出到 w 的 TracerProvider（用于本地调试）
+// 调用方负责在关闭时调用 Shutdown，确保缓冲的 span 被导出
+func NewStdoutTracerProvider(w io.Writer, sampleRatio float64) (*sdktrace.TracerProvider, error) {
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(w))
+	if err != nil {
+		return nil, err
+	}
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	), nil
+}
@@ -0,0 +1,60 @@
+package telemetry
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PrometheusWeightsObserver 把动作权重的加载情况记录为 Prometheus 指标
+//
+//   - home_mixer_action_weights_loads_total{result="ok|error"}
+//   - home_mixer_action_weights_info{version}：当前生效的权重版本为 1
+type PrometheusWeightsObserver struct {
+	loads *prometheus.CounterVec
+	info  *prometheus.GaugeVec
+
+	mu      sync.Mutex
+	version string // 当前生效的版本，切换时删除旧版本的 info 序列
+}
+
+// NewPrometheusWeightsObserver 创建 PrometheusWeightsObserver 并把指标注册到 reg
+func NewPrometheusWeightsObserver(reg prometheus.Registerer) (*PrometheusWeightsObserver, error) {
+	o := &PrometheusWeightsObserver{
+		loads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "home_mixer",
+			Subsystem: "action_weights",
+			Name:      "loads_total",
+			Help:      "权重文件的加载次数，按是否生效区分",
+		}, []string{"result"}),
+		info: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "home_mixer",
+			Subsystem: "action_weights",
+			Name:      "info",
+			Help:      "当前生效的动作权重版本",
+		}, []string{"version"}),
+	}
+	for _, c := range []prometheus.Collector{o.loads, o.info} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// WeightsActivated 实现 mixer.WeightsObserver
+func (o *PrometheusWeightsObserver) WeightsActivated(version string) {
+	o.loads.WithLabelValues("ok").Inc()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.version != "" {
+		o.info.DeleteLabelValues(o.version)
+	}
+	o.version = version
+	o.info.WithLabelValues(version).Set(1)
+}
+
+// WeightsReloadFailed 实现 mixer.WeightsObserver
+func (o *PrometheusWeightsObserver) WeightsReloadFailed(error) {
+	o.loads.WithLabelValues("error").Inc()
+}
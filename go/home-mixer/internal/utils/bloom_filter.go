@@ -4,7 +4,7 @@ import (
 	"hash/fnv"
 	"math"
 
-	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 )
 
 // BloomFilter 表示一个布隆过滤器
@@ -18,7 +18,7 @@ type BloomFilter struct {
 // NewBloomFilter 从 BloomFilterEntry 创建 BloomFilter
 // 根据实际实现，entry.Data 应该包含序列化的布隆过滤器数据
 // 这里假设 entry.Data 包含位数组数据，以及可选的元数据
-func NewBloomFilterFromEntry(entry pipeline.BloomFilterEntry) *BloomFilter {
+func NewBloomFilterFromEntry(entry home.BloomFilterEntry) *BloomFilter {
 	// 默认参数：假设从字节数据中解析
 	// 如果 entry.Data 为空，创建一个空的布隆过滤器
 	if len(entry.Data) == 0 {
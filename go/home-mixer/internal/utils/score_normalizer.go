@@ -3,13 +3,13 @@ package utils
 import (
 	"math"
 
-	"x-algorithm-go/candidate-pipeline/pipeline"
+	"x-algorithm-go/candidate-pipeline/pipeline/home"
 )
 
 // NormalizeScore 归一化加权分数
 // 这是推荐系统中常用的分数归一化方法，使用对数变换来压缩分数范围
 // 参考 Rust 版本的实现逻辑
-func NormalizeScore(candidate *pipeline.Candidate, score float64) float64 {
+func NormalizeScore(candidate *home.Candidate, score float64) float64 {
 	// 如果分数为0或负数，返回0
 	if score <= 0.0 {
 		return 0.0
@@ -11,12 +11,17 @@ const twitterEpoch int64 = 1142974214000 // 2006-03-21 20:50:14 UTC 的毫秒时
 // DurationSinceCreation 从雪花ID提取创建时间，返回距离现在的时间
 // 如果提取失败，返回 nil
 func DurationSinceCreation(snowflakeID int64) *time.Duration {
+	return DurationSinceCreationAt(snowflakeID, time.Now())
+}
+
+// DurationSinceCreationAt 从雪花ID提取创建时间，返回距离 now 的时间
+// 管道中的组件应使用请求时间（Query.RequestTime）作为 now，保证回放时结果一致
+func DurationSinceCreationAt(snowflakeID int64, now time.Time) *time.Duration {
 	timestamp := CreationTime(snowflakeID)
 	if timestamp == nil {
 		return nil
 	}
 	
-	now := time.Now()
 	duration := now.Sub(*timestamp)
 	return &duration
 }
@@ -39,7 +44,12 @@ func CreationTime(snowflakeID int64) *time.Time {
 
 // IsWithinAge 检查雪花ID对应的帖子是否在指定年龄内
 func IsWithinAge(snowflakeID int64, maxAge time.Duration) bool {
-	duration := DurationSinceCreation(snowflakeID)
+	return IsWithinAgeAt(snowflakeID, maxAge, time.Now())
+}
+
+// IsWithinAgeAt 检查雪花ID对应的帖子在 now 时是否在指定年龄内
+func IsWithinAgeAt(snowflakeID int64, maxAge time.Duration, now time.Time) bool {
+	duration := DurationSinceCreationAt(snowflakeID, now)
 	if duration == nil {
 		return false
 	}
@@ -0,0 +1,136 @@
+// Package gateway 把一元 gRPC 方法暴露为 HTTP/JSON 接口，供无法直接使用 gRPC 的工具和脚本调用
+//
+// 所有服务的 HTTP/JSON 接口使用相同的约定：
+//   - 只接受 POST，请求体是请求消息的 proto3 JSON 编码，空请求体等价于空消息
+//   - 响应字段使用 proto 中的字段名（snake_case），请求同时接受 snake_case 和 lowerCamelCase
+//   - int64 / uint64 按 proto3 JSON 约定编码为字符串，请求中字符串和数字都可以
+//   - 失败时 HTTP 状态码由 gRPC 状态码映射（见 HTTPStatusFromCode），响应体是 google.rpc.Status 的 JSON 编码；
+//     状态详情中带有 RetryInfo 时同时设置 Retry-After 头
+//   - Authorization 头作为 gRPC metadata 中的 authorization 传给服务实现，需要授权的方法与 gRPC 调用使用同样的凭证
+package gateway
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// MaxRequestBytes 是请求体的大小上限，与 gRPC 服务端默认的最大接收消息一致
+const MaxRequestBytes = 4 << 20
+
+var (
+	unmarshalOptions = protojson.UnmarshalOptions{}
+	marshalOptions   = protojson.MarshalOptions{UseProtoNames: true}
+)
+
+// Unary 返回调用 call 的 HTTP 处理器，call 通常是 gRPC 服务实现的方法
+// 请求直接在进程内调用服务实现，不经过 gRPC 传输
+func Unary[Req, Resp proto.Message](call func(context.Context, Req) (Resp, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeError(w, http.StatusMethodNotAllowed, status.New(codes.Unimplemented, "method "+r.Method+" not allowed, use POST"))
+			return
+		}
+
+		var zero Req
+		req := zero.ProtoReflect().Type().New().Interface().(Req)
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxRequestBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				WriteStatus(w, status.Newf(codes.ResourceExhausted, "request body larger than %d bytes", MaxRequestBytes))
+				return
+			}
+			WriteStatus(w, status.Newf(codes.InvalidArgument, "read request body: %v", err))
+			return
+		}
+		if len(body) > 0 {
+			if err := unmarshalOptions.Unmarshal(body, req); err != nil {
+				WriteStatus(w, status.Newf(codes.InvalidArgument, "invalid request json: %v", err))
+				return
+			}
+		}
+
+		ctx := r.Context()
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", auth))
+		}
+		resp, err := call(ctx, req)
+		if err != nil {
+			WriteStatus(w, status.Convert(err))
+			return
+		}
+		out, err := marshalOptions.Marshal(resp)
+		if err != nil {
+			WriteStatus(w, status.Newf(codes.Internal, "encode response: %v", err))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(out)
+	})
+}
+
+// WriteStatus 按 gRPC 状态写出错误响应
+func WriteStatus(w http.ResponseWriter, st *status.Status) {
+	writeError(w, HTTPStatusFromCode(st.Code()), st)
+}
+
+func writeError(w http.ResponseWriter, httpStatus int, st *status.Status) {
+	for _, detail := range st.Details() {
+		if retry, ok := detail.(*errdetails.RetryInfo); ok && retry.GetRetryDelay() != nil {
+			// Retry-After 以秒为单位，向上取整，避免客户端过早重试
+			seconds := math.Ceil(retry.GetRetryDelay().AsDuration().Seconds())
+			w.Header().Set("Retry-After", strconv.Itoa(int(seconds)))
+		}
+	}
+	out, err := marshalOptions.Marshal(st.Proto())
+	if err != nil {
+		out = []byte(`{"code":13,"message":"encode error status"}`)
+		httpStatus = http.StatusInternalServerError
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	w.Write(out)
+}
+
+// HTTPStatusFromCode 返回 gRPC 状态码对应的 HTTP 状态码（与 google.rpc.Code 文档中的映射一致）
+func HTTPStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499 // Client Closed Request
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default: // Unknown, Internal, DataLoss
+		return http.StatusInternalServerError
+	}
+}
@@ -1,61 +1,1771 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
-// This is a placeholder file. Run protoc to generate the actual code.
-// See PROTO_SETUP.md for instructions.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: scored_posts.proto
 
 package proto
 
-import "context"
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
 
-// Placeholder types - replace with actual generated code
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ScoredPostsQuery 表示推荐请求
 type ScoredPostsQuery struct {
-	ViewerId           int64
-	ClientAppId        int32
-	CountryCode        string
-	LanguageCode       string
-	SeenIds            []int64
-	ServedIds          []int64
-	InNetworkOnly      bool
-	IsBottomRequest    bool
-	BloomFilterEntries []*BloomFilterEntry
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	ViewerId           int64                  `protobuf:"varint,1,opt,name=viewer_id,json=viewerId,proto3" json:"viewer_id,omitempty"`                                // 用户 ID
+	ClientAppId        int32                  `protobuf:"varint,2,opt,name=client_app_id,json=clientAppId,proto3" json:"client_app_id,omitempty"`                     // 客户端应用 ID
+	CountryCode        string                 `protobuf:"bytes,3,opt,name=country_code,json=countryCode,proto3" json:"country_code,omitempty"`                        // 国家代码
+	LanguageCode       string                 `protobuf:"bytes,4,opt,name=language_code,json=languageCode,proto3" json:"language_code,omitempty"`                     // 语言代码
+	SeenIds            []int64                `protobuf:"varint,5,rep,packed,name=seen_ids,json=seenIds,proto3" json:"seen_ids,omitempty"`                            // 已看过的帖子 ID 列表
+	ServedIds          []int64                `protobuf:"varint,6,rep,packed,name=served_ids,json=servedIds,proto3" json:"served_ids,omitempty"`                      // 本次会话已服务的帖子 ID 列表
+	InNetworkOnly      bool                   `protobuf:"varint,7,opt,name=in_network_only,json=inNetworkOnly,proto3" json:"in_network_only,omitempty"`               // 是否只要站内内容
+	IsBottomRequest    bool                   `protobuf:"varint,8,opt,name=is_bottom_request,json=isBottomRequest,proto3" json:"is_bottom_request,omitempty"`         // 是否是底部请求（用于分页）
+	BloomFilterEntries []*BloomFilterEntry    `protobuf:"bytes,9,rep,name=bloom_filter_entries,json=bloomFilterEntries,proto3" json:"bloom_filter_entries,omitempty"` // 布隆过滤器条目（用于去重）
+	PreRankSize        int32                  `protobuf:"varint,10,opt,name=pre_rank_size,json=preRankSize,proto3" json:"pre_rank_size,omitempty"`                    // 进入重排的候选上限，0 表示使用服务端默认值
+	SessionId          string                 `protobuf:"bytes,11,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`                             // 浏览会话 ID，同一会话的分页请求复用第一页的排序结果
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *ScoredPostsQuery) Reset() {
+	*x = ScoredPostsQuery{}
+	mi := &file_scored_posts_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
+func (x *ScoredPostsQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScoredPostsQuery) ProtoMessage() {}
+
+func (x *ScoredPostsQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_scored_posts_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScoredPostsQuery.ProtoReflect.Descriptor instead.
+func (*ScoredPostsQuery) Descriptor() ([]byte, []int) {
+	return file_scored_posts_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ScoredPostsQuery) GetViewerId() int64 {
+	if x != nil {
+		return x.ViewerId
+	}
+	return 0
+}
+
+func (x *ScoredPostsQuery) GetClientAppId() int32 {
+	if x != nil {
+		return x.ClientAppId
+	}
+	return 0
+}
+
+func (x *ScoredPostsQuery) GetCountryCode() string {
+	if x != nil {
+		return x.CountryCode
+	}
+	return ""
+}
+
+func (x *ScoredPostsQuery) GetLanguageCode() string {
+	if x != nil {
+		return x.LanguageCode
+	}
+	return ""
+}
+
+func (x *ScoredPostsQuery) GetSeenIds() []int64 {
+	if x != nil {
+		return x.SeenIds
+	}
+	return nil
+}
+
+func (x *ScoredPostsQuery) GetServedIds() []int64 {
+	if x != nil {
+		return x.ServedIds
+	}
+	return nil
+}
+
+func (x *ScoredPostsQuery) GetInNetworkOnly() bool {
+	if x != nil {
+		return x.InNetworkOnly
+	}
+	return false
+}
+
+func (x *ScoredPostsQuery) GetIsBottomRequest() bool {
+	if x != nil {
+		return x.IsBottomRequest
+	}
+	return false
+}
+
+func (x *ScoredPostsQuery) GetBloomFilterEntries() []*BloomFilterEntry {
+	if x != nil {
+		return x.BloomFilterEntries
+	}
+	return nil
+}
+
+func (x *ScoredPostsQuery) GetPreRankSize() int32 {
+	if x != nil {
+		return x.PreRankSize
+	}
+	return 0
+}
+
+func (x *ScoredPostsQuery) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+// BloomFilterEntry 表示布隆过滤器条目
 type BloomFilterEntry struct {
-	Data []byte
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 根据实际需求定义字段
+	// 这里先定义基本结构
+	Data          []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BloomFilterEntry) Reset() {
+	*x = BloomFilterEntry{}
+	mi := &file_scored_posts_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BloomFilterEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BloomFilterEntry) ProtoMessage() {}
+
+func (x *BloomFilterEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_scored_posts_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BloomFilterEntry.ProtoReflect.Descriptor instead.
+func (*BloomFilterEntry) Descriptor() ([]byte, []int) {
+	return file_scored_posts_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BloomFilterEntry) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
 }
 
+// ScoredPostsResponse 表示推荐响应
 type ScoredPostsResponse struct {
-	ScoredPosts []*ScoredPost
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ScoredPosts     []*ScoredPost          `protobuf:"bytes,1,rep,name=scored_posts,json=scoredPosts,proto3" json:"scored_posts,omitempty"`              // 排序后的帖子列表
+	RankingDegraded bool                   `protobuf:"varint,2,opt,name=ranking_degraded,json=rankingDegraded,proto3" json:"ranking_degraded,omitempty"` // 部分或全部帖子没有 Phoenix 预测，按启发式分数排序（见 ScoredPost.fallback_ranked）
+	WeightsVersion  string                 `protobuf:"bytes,3,opt,name=weights_version,json=weightsVersion,proto3" json:"weights_version,omitempty"`     // 请求执行开始时生效的动作权重版本（加权分数按该版本计算），总是有值
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ScoredPostsResponse) Reset() {
+	*x = ScoredPostsResponse{}
+	mi := &file_scored_posts_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScoredPostsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScoredPostsResponse) ProtoMessage() {}
+
+func (x *ScoredPostsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_scored_posts_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScoredPostsResponse.ProtoReflect.Descriptor instead.
+func (*ScoredPostsResponse) Descriptor() ([]byte, []int) {
+	return file_scored_posts_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ScoredPostsResponse) GetScoredPosts() []*ScoredPost {
+	if x != nil {
+		return x.ScoredPosts
+	}
+	return nil
+}
+
+func (x *ScoredPostsResponse) GetRankingDegraded() bool {
+	if x != nil {
+		return x.RankingDegraded
+	}
+	return false
 }
 
+func (x *ScoredPostsResponse) GetWeightsVersion() string {
+	if x != nil {
+		return x.WeightsVersion
+	}
+	return ""
+}
+
+// ScoredPost 表示一个排序后的帖子
 type ScoredPost struct {
-	TweetId               uint64
-	AuthorId              uint64
-	RetweetedTweetId      uint64
-	RetweetedUserId       uint64
-	InReplyToTweetId      uint64
-	Score                 float32
-	InNetwork             bool
-	ServedType            int32
-	LastScoredTimestampMs uint64
-	PredictionRequestId   uint64
-	Ancestors             []uint64
-	ScreenNames           map[string]string
-	VisibilityReason      string
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	TweetId               uint64                 `protobuf:"varint,1,opt,name=tweet_id,json=tweetId,proto3" json:"tweet_id,omitempty"`                                                                                        // 帖子 ID
+	AuthorId              uint64                 `protobuf:"varint,2,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`                                                                                     // 作者 ID
+	RetweetedTweetId      uint64                 `protobuf:"varint,3,opt,name=retweeted_tweet_id,json=retweetedTweetId,proto3" json:"retweeted_tweet_id,omitempty"`                                                           // 转发的原帖 ID（如果是转发）
+	RetweetedUserId       uint64                 `protobuf:"varint,4,opt,name=retweeted_user_id,json=retweetedUserId,proto3" json:"retweeted_user_id,omitempty"`                                                              // 转发的原帖作者 ID
+	InReplyToTweetId      uint64                 `protobuf:"varint,5,opt,name=in_reply_to_tweet_id,json=inReplyToTweetId,proto3" json:"in_reply_to_tweet_id,omitempty"`                                                       // 回复的帖子 ID（如果是回复）
+	Score                 float32                `protobuf:"fixed32,6,opt,name=score,proto3" json:"score,omitempty"`                                                                                                          // 最终分数
+	InNetwork             bool                   `protobuf:"varint,7,opt,name=in_network,json=inNetwork,proto3" json:"in_network,omitempty"`                                                                                  // 是否站内内容
+	ServedType            int32                  `protobuf:"varint,8,opt,name=served_type,json=servedType,proto3" json:"served_type,omitempty"`                                                                               // 服务类型
+	LastScoredTimestampMs uint64                 `protobuf:"varint,9,opt,name=last_scored_timestamp_ms,json=lastScoredTimestampMs,proto3" json:"last_scored_timestamp_ms,omitempty"`                                          // 最后打分时间戳（毫秒）
+	PredictionRequestId   uint64                 `protobuf:"varint,10,opt,name=prediction_request_id,json=predictionRequestId,proto3" json:"prediction_request_id,omitempty"`                                                 // 预测请求 ID
+	Ancestors             []uint64               `protobuf:"varint,11,rep,packed,name=ancestors,proto3" json:"ancestors,omitempty"`                                                                                           // 祖先帖子 ID 列表
+	ScreenNames           map[uint64]string      `protobuf:"bytes,12,rep,name=screen_names,json=screenNames,proto3" json:"screen_names,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // 用户名映射（author_id -> screen_name）
+	VisibilityReason      string                 `protobuf:"bytes,13,opt,name=visibility_reason,json=visibilityReason,proto3" json:"visibility_reason,omitempty"`                                                             // 可见性原因（如果被过滤）
+	FallbackRanked        bool                   `protobuf:"varint,14,opt,name=fallback_ranked,json=fallbackRanked,proto3" json:"fallback_ranked,omitempty"`                                                                  // 该帖子没有 Phoenix 预测（PhoenixScorer 失败、超时或被熔断，冷启动用户，或响应中缺少该帖子），分数来自降级的启发式打分
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *ScoredPost) Reset() {
+	*x = ScoredPost{}
+	mi := &file_scored_posts_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-// Placeholder service - replace with actual generated code
-type ScoredPostsServiceServer interface {
-	GetScoredPosts(ctx context.Context, req *ScoredPostsQuery) (*ScoredPostsResponse, error)
+func (x *ScoredPost) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-type UnimplementedScoredPostsServiceServer struct{}
+func (*ScoredPost) ProtoMessage() {}
+
+func (x *ScoredPost) ProtoReflect() protoreflect.Message {
+	mi := &file_scored_posts_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScoredPost.ProtoReflect.Descriptor instead.
+func (*ScoredPost) Descriptor() ([]byte, []int) {
+	return file_scored_posts_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ScoredPost) GetTweetId() uint64 {
+	if x != nil {
+		return x.TweetId
+	}
+	return 0
+}
+
+func (x *ScoredPost) GetAuthorId() uint64 {
+	if x != nil {
+		return x.AuthorId
+	}
+	return 0
+}
+
+func (x *ScoredPost) GetRetweetedTweetId() uint64 {
+	if x != nil {
+		return x.RetweetedTweetId
+	}
+	return 0
+}
+
+func (x *ScoredPost) GetRetweetedUserId() uint64 {
+	if x != nil {
+		return x.RetweetedUserId
+	}
+	return 0
+}
+
+func (x *ScoredPost) GetInReplyToTweetId() uint64 {
+	if x != nil {
+		return x.InReplyToTweetId
+	}
+	return 0
+}
+
+func (x *ScoredPost) GetScore() float32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *ScoredPost) GetInNetwork() bool {
+	if x != nil {
+		return x.InNetwork
+	}
+	return false
+}
+
+func (x *ScoredPost) GetServedType() int32 {
+	if x != nil {
+		return x.ServedType
+	}
+	return 0
+}
+
+func (x *ScoredPost) GetLastScoredTimestampMs() uint64 {
+	if x != nil {
+		return x.LastScoredTimestampMs
+	}
+	return 0
+}
+
+func (x *ScoredPost) GetPredictionRequestId() uint64 {
+	if x != nil {
+		return x.PredictionRequestId
+	}
+	return 0
+}
+
+func (x *ScoredPost) GetAncestors() []uint64 {
+	if x != nil {
+		return x.Ancestors
+	}
+	return nil
+}
+
+func (x *ScoredPost) GetScreenNames() map[uint64]string {
+	if x != nil {
+		return x.ScreenNames
+	}
+	return nil
+}
+
+func (x *ScoredPost) GetVisibilityReason() string {
+	if x != nil {
+		return x.VisibilityReason
+	}
+	return ""
+}
+
+func (x *ScoredPost) GetFallbackRanked() bool {
+	if x != nil {
+		return x.FallbackRanked
+	}
+	return false
+}
+
+// ScoredPostsDebugRequest 表示调试请求
+type ScoredPostsDebugRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         *ScoredPostsQuery      `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"` // 要排查的请求，viewer_id 可以是任意用户
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScoredPostsDebugRequest) Reset() {
+	*x = ScoredPostsDebugRequest{}
+	mi := &file_scored_posts_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScoredPostsDebugRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
 
-func (UnimplementedScoredPostsServiceServer) GetScoredPosts(ctx context.Context, req *ScoredPostsQuery) (*ScoredPostsResponse, error) {
-	return nil, nil
+func (*ScoredPostsDebugRequest) ProtoMessage() {}
+
+func (x *ScoredPostsDebugRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scored_posts_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScoredPostsDebugRequest.ProtoReflect.Descriptor instead.
+func (*ScoredPostsDebugRequest) Descriptor() ([]byte, []int) {
+	return file_scored_posts_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ScoredPostsDebugRequest) GetQuery() *ScoredPostsQuery {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+// ScoredPostsDebugResponse 表示一次请求的完整管道结果
+type ScoredPostsDebugResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	RequestId      string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`                // 本次执行的请求 ID
+	Query          *DebugQuery            `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`                                         // 增强后的查询
+	Candidates     []*DebugCandidate      `protobuf:"bytes,3,rep,name=candidates,proto3" json:"candidates,omitempty"`                               // 检索到的全部候选，按检索顺序排列
+	Stages         []*StageTiming         `protobuf:"bytes,4,rep,name=stages,proto3" json:"stages,omitempty"`                                       // 各阶段耗时，按结束顺序排列
+	Components     []*ComponentTiming     `protobuf:"bytes,5,rep,name=components,proto3" json:"components,omitempty"`                               // 各组件耗时，按结束顺序排列
+	ScoredPosts    []*ScoredPost          `protobuf:"bytes,6,rep,name=scored_posts,json=scoredPosts,proto3" json:"scored_posts,omitempty"`          // 与 GetScoredPosts 相同的响应
+	WeightsVersion string                 `protobuf:"bytes,7,opt,name=weights_version,json=weightsVersion,proto3" json:"weights_version,omitempty"` // 与 ScoredPostsResponse.weights_version 相同
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ScoredPostsDebugResponse) Reset() {
+	*x = ScoredPostsDebugResponse{}
+	mi := &file_scored_posts_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScoredPostsDebugResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScoredPostsDebugResponse) ProtoMessage() {}
+
+func (x *ScoredPostsDebugResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_scored_posts_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScoredPostsDebugResponse.ProtoReflect.Descriptor instead.
+func (*ScoredPostsDebugResponse) Descriptor() ([]byte, []int) {
+	return file_scored_posts_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ScoredPostsDebugResponse) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *ScoredPostsDebugResponse) GetQuery() *DebugQuery {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+func (x *ScoredPostsDebugResponse) GetCandidates() []*DebugCandidate {
+	if x != nil {
+		return x.Candidates
+	}
+	return nil
+}
+
+func (x *ScoredPostsDebugResponse) GetStages() []*StageTiming {
+	if x != nil {
+		return x.Stages
+	}
+	return nil
+}
+
+func (x *ScoredPostsDebugResponse) GetComponents() []*ComponentTiming {
+	if x != nil {
+		return x.Components
+	}
+	return nil
+}
+
+func (x *ScoredPostsDebugResponse) GetScoredPosts() []*ScoredPost {
+	if x != nil {
+		return x.ScoredPosts
+	}
+	return nil
+}
+
+func (x *ScoredPostsDebugResponse) GetWeightsVersion() string {
+	if x != nil {
+		return x.WeightsVersion
+	}
+	return ""
+}
+
+// DebugQuery 表示增强后的查询
+type DebugQuery struct {
+	state             protoimpl.MessageState  `protogen:"open.v1"`
+	UserId            int64                   `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ClientAppId       int32                   `protobuf:"varint,2,opt,name=client_app_id,json=clientAppId,proto3" json:"client_app_id,omitempty"`
+	CountryCode       string                  `protobuf:"bytes,3,opt,name=country_code,json=countryCode,proto3" json:"country_code,omitempty"`
+	LanguageCode      string                  `protobuf:"bytes,4,opt,name=language_code,json=languageCode,proto3" json:"language_code,omitempty"`
+	RequestTimeMs     int64                   `protobuf:"varint,5,opt,name=request_time_ms,json=requestTimeMs,proto3" json:"request_time_ms,omitempty"` // 请求时间（Unix 毫秒），帖子年龄等以它为准
+	PreRankSize       int32                   `protobuf:"varint,6,opt,name=pre_rank_size,json=preRankSize,proto3" json:"pre_rank_size,omitempty"`       // 本次请求进入重排的候选上限，0 表示使用管道默认值
+	InNetworkOnly     bool                    `protobuf:"varint,7,opt,name=in_network_only,json=inNetworkOnly,proto3" json:"in_network_only,omitempty"`
+	IsBottomRequest   bool                    `protobuf:"varint,8,opt,name=is_bottom_request,json=isBottomRequest,proto3" json:"is_bottom_request,omitempty"`
+	SessionId         string                  `protobuf:"bytes,9,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	SeenIds           []int64                 `protobuf:"varint,10,rep,packed,name=seen_ids,json=seenIds,proto3" json:"seen_ids,omitempty"`
+	ServedIds         []int64                 `protobuf:"varint,11,rep,packed,name=served_ids,json=servedIds,proto3" json:"served_ids,omitempty"`
+	Experiments       []*ExperimentAssignment `protobuf:"bytes,12,rep,name=experiments,proto3" json:"experiments,omitempty"`                                      // 实验分组
+	MissingHydrations []string                `protobuf:"bytes,13,rep,name=missing_hydrations,json=missingHydrations,proto3" json:"missing_hydrations,omitempty"` // 失败的 Query Hydrator
+	FollowedUserIds   []int64                 `protobuf:"varint,14,rep,packed,name=followed_user_ids,json=followedUserIds,proto3" json:"followed_user_ids,omitempty"`
+	BlockedUserIds    []int64                 `protobuf:"varint,15,rep,packed,name=blocked_user_ids,json=blockedUserIds,proto3" json:"blocked_user_ids,omitempty"`
+	MutedUserIds      []int64                 `protobuf:"varint,16,rep,packed,name=muted_user_ids,json=mutedUserIds,proto3" json:"muted_user_ids,omitempty"`
+	SubscribedUserIds []int64                 `protobuf:"varint,17,rep,packed,name=subscribed_user_ids,json=subscribedUserIds,proto3" json:"subscribed_user_ids,omitempty"`
+	MutedKeywords     []string                `protobuf:"bytes,18,rep,name=muted_keywords,json=mutedKeywords,proto3" json:"muted_keywords,omitempty"`
+	UserActionCount   int32                   `protobuf:"varint,19,opt,name=user_action_count,json=userActionCount,proto3" json:"user_action_count,omitempty"` // 用户动作序列长度
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *DebugQuery) Reset() {
+	*x = DebugQuery{}
+	mi := &file_scored_posts_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DebugQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DebugQuery) ProtoMessage() {}
+
+func (x *DebugQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_scored_posts_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DebugQuery.ProtoReflect.Descriptor instead.
+func (*DebugQuery) Descriptor() ([]byte, []int) {
+	return file_scored_posts_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DebugQuery) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *DebugQuery) GetClientAppId() int32 {
+	if x != nil {
+		return x.ClientAppId
+	}
+	return 0
+}
+
+func (x *DebugQuery) GetCountryCode() string {
+	if x != nil {
+		return x.CountryCode
+	}
+	return ""
+}
+
+func (x *DebugQuery) GetLanguageCode() string {
+	if x != nil {
+		return x.LanguageCode
+	}
+	return ""
+}
+
+func (x *DebugQuery) GetRequestTimeMs() int64 {
+	if x != nil {
+		return x.RequestTimeMs
+	}
+	return 0
+}
+
+func (x *DebugQuery) GetPreRankSize() int32 {
+	if x != nil {
+		return x.PreRankSize
+	}
+	return 0
+}
+
+func (x *DebugQuery) GetInNetworkOnly() bool {
+	if x != nil {
+		return x.InNetworkOnly
+	}
+	return false
+}
+
+func (x *DebugQuery) GetIsBottomRequest() bool {
+	if x != nil {
+		return x.IsBottomRequest
+	}
+	return false
+}
+
+func (x *DebugQuery) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *DebugQuery) GetSeenIds() []int64 {
+	if x != nil {
+		return x.SeenIds
+	}
+	return nil
+}
+
+func (x *DebugQuery) GetServedIds() []int64 {
+	if x != nil {
+		return x.ServedIds
+	}
+	return nil
+}
+
+func (x *DebugQuery) GetExperiments() []*ExperimentAssignment {
+	if x != nil {
+		return x.Experiments
+	}
+	return nil
+}
+
+func (x *DebugQuery) GetMissingHydrations() []string {
+	if x != nil {
+		return x.MissingHydrations
+	}
+	return nil
+}
+
+func (x *DebugQuery) GetFollowedUserIds() []int64 {
+	if x != nil {
+		return x.FollowedUserIds
+	}
+	return nil
+}
+
+func (x *DebugQuery) GetBlockedUserIds() []int64 {
+	if x != nil {
+		return x.BlockedUserIds
+	}
+	return nil
+}
+
+func (x *DebugQuery) GetMutedUserIds() []int64 {
+	if x != nil {
+		return x.MutedUserIds
+	}
+	return nil
+}
+
+func (x *DebugQuery) GetSubscribedUserIds() []int64 {
+	if x != nil {
+		return x.SubscribedUserIds
+	}
+	return nil
+}
+
+func (x *DebugQuery) GetMutedKeywords() []string {
+	if x != nil {
+		return x.MutedKeywords
+	}
+	return nil
+}
+
+func (x *DebugQuery) GetUserActionCount() int32 {
+	if x != nil {
+		return x.UserActionCount
+	}
+	return 0
+}
+
+// ExperimentAssignment 表示一个实验分组
+type ExperimentAssignment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Experiment    string                 `protobuf:"bytes,1,opt,name=experiment,proto3" json:"experiment,omitempty"`
+	Treatment     string                 `protobuf:"bytes,2,opt,name=treatment,proto3" json:"treatment,omitempty"`
+	Bucket        int32                  `protobuf:"varint,3,opt,name=bucket,proto3" json:"bucket,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExperimentAssignment) Reset() {
+	*x = ExperimentAssignment{}
+	mi := &file_scored_posts_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExperimentAssignment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExperimentAssignment) ProtoMessage() {}
+
+func (x *ExperimentAssignment) ProtoReflect() protoreflect.Message {
+	mi := &file_scored_posts_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExperimentAssignment.ProtoReflect.Descriptor instead.
+func (*ExperimentAssignment) Descriptor() ([]byte, []int) {
+	return file_scored_posts_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ExperimentAssignment) GetExperiment() string {
+	if x != nil {
+		return x.Experiment
+	}
+	return ""
+}
+
+func (x *ExperimentAssignment) GetTreatment() string {
+	if x != nil {
+		return x.Treatment
+	}
+	return ""
+}
+
+func (x *ExperimentAssignment) GetBucket() int32 {
+	if x != nil {
+		return x.Bucket
+	}
+	return 0
+}
+
+// DebugCandidate 表示一个候选在管道中的完整状态和轨迹
+type DebugCandidate struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	TweetId              int64                  `protobuf:"varint,1,opt,name=tweet_id,json=tweetId,proto3" json:"tweet_id,omitempty"`
+	AuthorId             uint64                 `protobuf:"varint,2,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	TweetText            string                 `protobuf:"bytes,3,opt,name=tweet_text,json=tweetText,proto3" json:"tweet_text,omitempty"`
+	InReplyToTweetId     *uint64                `protobuf:"varint,4,opt,name=in_reply_to_tweet_id,json=inReplyToTweetId,proto3,oneof" json:"in_reply_to_tweet_id,omitempty"`
+	RetweetedTweetId     *uint64                `protobuf:"varint,5,opt,name=retweeted_tweet_id,json=retweetedTweetId,proto3,oneof" json:"retweeted_tweet_id,omitempty"`
+	RetweetedUserId      *uint64                `protobuf:"varint,6,opt,name=retweeted_user_id,json=retweetedUserId,proto3,oneof" json:"retweeted_user_id,omitempty"`
+	Ancestors            []uint64               `protobuf:"varint,7,rep,packed,name=ancestors,proto3" json:"ancestors,omitempty"`
+	InNetwork            *bool                  `protobuf:"varint,8,opt,name=in_network,json=inNetwork,proto3,oneof" json:"in_network,omitempty"`
+	ServedType           *int32                 `protobuf:"varint,9,opt,name=served_type,json=servedType,proto3,oneof" json:"served_type,omitempty"`
+	VideoDurationMs      *int32                 `protobuf:"varint,10,opt,name=video_duration_ms,json=videoDurationMs,proto3,oneof" json:"video_duration_ms,omitempty"`
+	AuthorFollowersCount *int32                 `protobuf:"varint,11,opt,name=author_followers_count,json=authorFollowersCount,proto3,oneof" json:"author_followers_count,omitempty"`
+	AuthorScreenName     *string                `protobuf:"bytes,12,opt,name=author_screen_name,json=authorScreenName,proto3,oneof" json:"author_screen_name,omitempty"`
+	RetweetedScreenName  *string                `protobuf:"bytes,13,opt,name=retweeted_screen_name,json=retweetedScreenName,proto3,oneof" json:"retweeted_screen_name,omitempty"`
+	VisibilityReason     *string                `protobuf:"bytes,14,opt,name=visibility_reason,json=visibilityReason,proto3,oneof" json:"visibility_reason,omitempty"`
+	SubscriptionAuthorId *uint64                `protobuf:"varint,15,opt,name=subscription_author_id,json=subscriptionAuthorId,proto3,oneof" json:"subscription_author_id,omitempty"`
+	PredictionRequestId  *uint64                `protobuf:"varint,16,opt,name=prediction_request_id,json=predictionRequestId,proto3,oneof" json:"prediction_request_id,omitempty"`
+	LastScoredAtMs       *uint64                `protobuf:"varint,17,opt,name=last_scored_at_ms,json=lastScoredAtMs,proto3,oneof" json:"last_scored_at_ms,omitempty"`
+	Source               string                 `protobuf:"bytes,18,opt,name=source,proto3" json:"source,omitempty"`                                                                                                                // 产生该候选的 Source
+	Provenance           []*SourceProvenance    `protobuf:"bytes,19,rep,name=provenance,proto3" json:"provenance,omitempty"`                                                                                                        // 返回该候选的所有 Source
+	MissingHydrations    []string               `protobuf:"bytes,20,rep,name=missing_hydrations,json=missingHydrations,proto3" json:"missing_hydrations,omitempty"`                                                                 // 失败的 Hydrator 和 Scorer
+	PhoenixScores        map[string]float64     `protobuf:"bytes,21,rep,name=phoenix_scores,json=phoenixScores,proto3" json:"phoenix_scores,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"` // Phoenix 各动作的预测分数（动作名 -> 分数）
+	PreRankScore         *float64               `protobuf:"fixed64,22,opt,name=pre_rank_score,json=preRankScore,proto3,oneof" json:"pre_rank_score,omitempty"`                                                                      // 轻量预排序分数
+	WeightedScore        *float64               `protobuf:"fixed64,23,opt,name=weighted_score,json=weightedScore,proto3,oneof" json:"weighted_score,omitempty"`                                                                     // 加权组合后的分数
+	Score                *float64               `protobuf:"fixed64,24,opt,name=score,proto3,oneof" json:"score,omitempty"`                                                                                                          // 最终分数
+	Selected             bool                   `protobuf:"varint,25,opt,name=selected,proto3" json:"selected,omitempty"`                                                                                                           // 是否出现在最终结果中
+	Removal              *CandidateRemoval      `protobuf:"bytes,26,opt,name=removal,proto3" json:"removal,omitempty"`                                                                                                              // 被移除的位置，未被移除时为空
+	Hydrations           []*HydrationStep       `protobuf:"bytes,27,rep,name=hydrations,proto3" json:"hydrations,omitempty"`                                                                                                        // 每个 Hydrator 填充的字段
+	Scores               []*ScoreStep           `protobuf:"bytes,28,rep,name=scores,proto3" json:"scores,omitempty"`                                                                                                                // 每个 Scorer 执行后的分数
+	WeightsVersion       *string                `protobuf:"bytes,29,opt,name=weights_version,json=weightsVersion,proto3,oneof" json:"weights_version,omitempty"`                                                                    // 计算 weighted_score 使用的动作权重版本
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *DebugCandidate) Reset() {
+	*x = DebugCandidate{}
+	mi := &file_scored_posts_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DebugCandidate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DebugCandidate) ProtoMessage() {}
+
+func (x *DebugCandidate) ProtoReflect() protoreflect.Message {
+	mi := &file_scored_posts_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DebugCandidate.ProtoReflect.Descriptor instead.
+func (*DebugCandidate) Descriptor() ([]byte, []int) {
+	return file_scored_posts_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DebugCandidate) GetTweetId() int64 {
+	if x != nil {
+		return x.TweetId
+	}
+	return 0
+}
+
+func (x *DebugCandidate) GetAuthorId() uint64 {
+	if x != nil {
+		return x.AuthorId
+	}
+	return 0
+}
+
+func (x *DebugCandidate) GetTweetText() string {
+	if x != nil {
+		return x.TweetText
+	}
+	return ""
+}
+
+func (x *DebugCandidate) GetInReplyToTweetId() uint64 {
+	if x != nil && x.InReplyToTweetId != nil {
+		return *x.InReplyToTweetId
+	}
+	return 0
+}
+
+func (x *DebugCandidate) GetRetweetedTweetId() uint64 {
+	if x != nil && x.RetweetedTweetId != nil {
+		return *x.RetweetedTweetId
+	}
+	return 0
+}
+
+func (x *DebugCandidate) GetRetweetedUserId() uint64 {
+	if x != nil && x.RetweetedUserId != nil {
+		return *x.RetweetedUserId
+	}
+	return 0
+}
+
+func (x *DebugCandidate) GetAncestors() []uint64 {
+	if x != nil {
+		return x.Ancestors
+	}
+	return nil
+}
+
+func (x *DebugCandidate) GetInNetwork() bool {
+	if x != nil && x.InNetwork != nil {
+		return *x.InNetwork
+	}
+	return false
+}
+
+func (x *DebugCandidate) GetServedType() int32 {
+	if x != nil && x.ServedType != nil {
+		return *x.ServedType
+	}
+	return 0
+}
+
+func (x *DebugCandidate) GetVideoDurationMs() int32 {
+	if x != nil && x.VideoDurationMs != nil {
+		return *x.VideoDurationMs
+	}
+	return 0
+}
+
+func (x *DebugCandidate) GetAuthorFollowersCount() int32 {
+	if x != nil && x.AuthorFollowersCount != nil {
+		return *x.AuthorFollowersCount
+	}
+	return 0
+}
+
+func (x *DebugCandidate) GetAuthorScreenName() string {
+	if x != nil && x.AuthorScreenName != nil {
+		return *x.AuthorScreenName
+	}
+	return ""
+}
+
+func (x *DebugCandidate) GetRetweetedScreenName() string {
+	if x != nil && x.RetweetedScreenName != nil {
+		return *x.RetweetedScreenName
+	}
+	return ""
+}
+
+func (x *DebugCandidate) GetVisibilityReason() string {
+	if x != nil && x.VisibilityReason != nil {
+		return *x.VisibilityReason
+	}
+	return ""
+}
+
+func (x *DebugCandidate) GetSubscriptionAuthorId() uint64 {
+	if x != nil && x.SubscriptionAuthorId != nil {
+		return *x.SubscriptionAuthorId
+	}
+	return 0
+}
+
+func (x *DebugCandidate) GetPredictionRequestId() uint64 {
+	if x != nil && x.PredictionRequestId != nil {
+		return *x.PredictionRequestId
+	}
+	return 0
+}
+
+func (x *DebugCandidate) GetLastScoredAtMs() uint64 {
+	if x != nil && x.LastScoredAtMs != nil {
+		return *x.LastScoredAtMs
+	}
+	return 0
+}
+
+func (x *DebugCandidate) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *DebugCandidate) GetProvenance() []*SourceProvenance {
+	if x != nil {
+		return x.Provenance
+	}
+	return nil
+}
+
+func (x *DebugCandidate) GetMissingHydrations() []string {
+	if x != nil {
+		return x.MissingHydrations
+	}
+	return nil
+}
+
+func (x *DebugCandidate) GetPhoenixScores() map[string]float64 {
+	if x != nil {
+		return x.PhoenixScores
+	}
+	return nil
+}
+
+func (x *DebugCandidate) GetPreRankScore() float64 {
+	if x != nil && x.PreRankScore != nil {
+		return *x.PreRankScore
+	}
+	return 0
+}
+
+func (x *DebugCandidate) GetWeightedScore() float64 {
+	if x != nil && x.WeightedScore != nil {
+		return *x.WeightedScore
+	}
+	return 0
+}
+
+func (x *DebugCandidate) GetScore() float64 {
+	if x != nil && x.Score != nil {
+		return *x.Score
+	}
+	return 0
+}
+
+func (x *DebugCandidate) GetSelected() bool {
+	if x != nil {
+		return x.Selected
+	}
+	return false
+}
+
+func (x *DebugCandidate) GetRemoval() *CandidateRemoval {
+	if x != nil {
+		return x.Removal
+	}
+	return nil
+}
+
+func (x *DebugCandidate) GetHydrations() []*HydrationStep {
+	if x != nil {
+		return x.Hydrations
+	}
+	return nil
+}
+
+func (x *DebugCandidate) GetScores() []*ScoreStep {
+	if x != nil {
+		return x.Scores
+	}
+	return nil
+}
+
+func (x *DebugCandidate) GetWeightsVersion() string {
+	if x != nil && x.WeightsVersion != nil {
+		return *x.WeightsVersion
+	}
+	return ""
+}
+
+// SourceProvenance 表示候选的一个来源
+type SourceProvenance struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Rank          int32                  `protobuf:"varint,2,opt,name=rank,proto3" json:"rank,omitempty"`          // 在该 Source 结果中的位置（从 0 开始）
+	Score         *float64               `protobuf:"fixed64,3,opt,name=score,proto3,oneof" json:"score,omitempty"` // Source 给出的检索分数
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SourceProvenance) Reset() {
+	*x = SourceProvenance{}
+	mi := &file_scored_posts_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SourceProvenance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SourceProvenance) ProtoMessage() {}
+
+func (x *SourceProvenance) ProtoReflect() protoreflect.Message {
+	mi := &file_scored_posts_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SourceProvenance.ProtoReflect.Descriptor instead.
+func (*SourceProvenance) Descriptor() ([]byte, []int) {
+	return file_scored_posts_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SourceProvenance) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *SourceProvenance) GetRank() int32 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *SourceProvenance) GetScore() float64 {
+	if x != nil && x.Score != nil {
+		return *x.Score
+	}
+	return 0
+}
+
+// CandidateRemoval 表示候选被移除的位置和原因
+type CandidateRemoval struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stage         string                 `protobuf:"bytes,1,opt,name=stage,proto3" json:"stage,omitempty"`
+	Component     string                 `protobuf:"bytes,2,opt,name=component,proto3" json:"component,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CandidateRemoval) Reset() {
+	*x = CandidateRemoval{}
+	mi := &file_scored_posts_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CandidateRemoval) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CandidateRemoval) ProtoMessage() {}
+
+func (x *CandidateRemoval) ProtoReflect() protoreflect.Message {
+	mi := &file_scored_posts_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CandidateRemoval.ProtoReflect.Descriptor instead.
+func (*CandidateRemoval) Descriptor() ([]byte, []int) {
+	return file_scored_posts_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CandidateRemoval) GetStage() string {
+	if x != nil {
+		return x.Stage
+	}
+	return ""
+}
+
+func (x *CandidateRemoval) GetComponent() string {
+	if x != nil {
+		return x.Component
+	}
+	return ""
+}
+
+func (x *CandidateRemoval) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// HydrationStep 表示一个 Hydrator 对候选的一次增强
+type HydrationStep struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stage         string                 `protobuf:"bytes,1,opt,name=stage,proto3" json:"stage,omitempty"`
+	Component     string                 `protobuf:"bytes,2,opt,name=component,proto3" json:"component,omitempty"`
+	Fields        []string               `protobuf:"bytes,3,rep,name=fields,proto3" json:"fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HydrationStep) Reset() {
+	*x = HydrationStep{}
+	mi := &file_scored_posts_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HydrationStep) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HydrationStep) ProtoMessage() {}
+
+func (x *HydrationStep) ProtoReflect() protoreflect.Message {
+	mi := &file_scored_posts_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HydrationStep.ProtoReflect.Descriptor instead.
+func (*HydrationStep) Descriptor() ([]byte, []int) {
+	return file_scored_posts_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *HydrationStep) GetStage() string {
+	if x != nil {
+		return x.Stage
+	}
+	return ""
+}
+
+func (x *HydrationStep) GetComponent() string {
+	if x != nil {
+		return x.Component
+	}
+	return ""
+}
+
+func (x *HydrationStep) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+// ScoreStep 表示一个 Scorer 执行后的分数快照
+type ScoreStep struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Component     string                 `protobuf:"bytes,1,opt,name=component,proto3" json:"component,omitempty"`
+	PreRankScore  *float64               `protobuf:"fixed64,2,opt,name=pre_rank_score,json=preRankScore,proto3,oneof" json:"pre_rank_score,omitempty"`
+	Score         *float64               `protobuf:"fixed64,3,opt,name=score,proto3,oneof" json:"score,omitempty"`
+	WeightedScore *float64               `protobuf:"fixed64,4,opt,name=weighted_score,json=weightedScore,proto3,oneof" json:"weighted_score,omitempty"` // 加权组合后的分数，WeightedScorer 执行之前为空
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScoreStep) Reset() {
+	*x = ScoreStep{}
+	mi := &file_scored_posts_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScoreStep) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScoreStep) ProtoMessage() {}
+
+func (x *ScoreStep) ProtoReflect() protoreflect.Message {
+	mi := &file_scored_posts_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScoreStep.ProtoReflect.Descriptor instead.
+func (*ScoreStep) Descriptor() ([]byte, []int) {
+	return file_scored_posts_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ScoreStep) GetComponent() string {
+	if x != nil {
+		return x.Component
+	}
+	return ""
+}
+
+func (x *ScoreStep) GetPreRankScore() float64 {
+	if x != nil && x.PreRankScore != nil {
+		return *x.PreRankScore
+	}
+	return 0
+}
+
+func (x *ScoreStep) GetScore() float64 {
+	if x != nil && x.Score != nil {
+		return *x.Score
+	}
+	return 0
+}
+
+func (x *ScoreStep) GetWeightedScore() float64 {
+	if x != nil && x.WeightedScore != nil {
+		return *x.WeightedScore
+	}
+	return 0
+}
+
+// StageTiming 表示一个阶段的执行情况
+type StageTiming struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stage         string                 `protobuf:"bytes,1,opt,name=stage,proto3" json:"stage,omitempty"`
+	CandidatesIn  int32                  `protobuf:"varint,2,opt,name=candidates_in,json=candidatesIn,proto3" json:"candidates_in,omitempty"`
+	CandidatesOut int32                  `protobuf:"varint,3,opt,name=candidates_out,json=candidatesOut,proto3" json:"candidates_out,omitempty"`
+	DurationMs    float64                `protobuf:"fixed64,4,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"` // 导致请求终止的错误
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StageTiming) Reset() {
+	*x = StageTiming{}
+	mi := &file_scored_posts_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StageTiming) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StageTiming) ProtoMessage() {}
+
+func (x *StageTiming) ProtoReflect() protoreflect.Message {
+	mi := &file_scored_posts_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StageTiming.ProtoReflect.Descriptor instead.
+func (*StageTiming) Descriptor() ([]byte, []int) {
+	return file_scored_posts_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *StageTiming) GetStage() string {
+	if x != nil {
+		return x.Stage
+	}
+	return ""
+}
+
+func (x *StageTiming) GetCandidatesIn() int32 {
+	if x != nil {
+		return x.CandidatesIn
+	}
+	return 0
+}
+
+func (x *StageTiming) GetCandidatesOut() int32 {
+	if x != nil {
+		return x.CandidatesOut
+	}
+	return 0
+}
+
+func (x *StageTiming) GetDurationMs() float64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *StageTiming) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// ComponentTiming 表示一个组件调用的执行情况
+type ComponentTiming struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stage         string                 `protobuf:"bytes,1,opt,name=stage,proto3" json:"stage,omitempty"`
+	Component     string                 `protobuf:"bytes,2,opt,name=component,proto3" json:"component,omitempty"`
+	CandidatesIn  int32                  `protobuf:"varint,3,opt,name=candidates_in,json=candidatesIn,proto3" json:"candidates_in,omitempty"`
+	CandidatesOut int32                  `protobuf:"varint,4,opt,name=candidates_out,json=candidatesOut,proto3" json:"candidates_out,omitempty"`
+	Removed       int32                  `protobuf:"varint,5,opt,name=removed,proto3" json:"removed,omitempty"`
+	DurationMs    float64                `protobuf:"fixed64,6,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	Error         string                 `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"` // 组件失败（错误、超时、panic）
+	TimedOut      bool                   `protobuf:"varint,8,opt,name=timed_out,json=timedOut,proto3" json:"timed_out,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComponentTiming) Reset() {
+	*x = ComponentTiming{}
+	mi := &file_scored_posts_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComponentTiming) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComponentTiming) ProtoMessage() {}
+
+func (x *ComponentTiming) ProtoReflect() protoreflect.Message {
+	mi := &file_scored_posts_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComponentTiming.ProtoReflect.Descriptor instead.
+func (*ComponentTiming) Descriptor() ([]byte, []int) {
+	return file_scored_posts_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ComponentTiming) GetStage() string {
+	if x != nil {
+		return x.Stage
+	}
+	return ""
+}
+
+func (x *ComponentTiming) GetComponent() string {
+	if x != nil {
+		return x.Component
+	}
+	return ""
+}
+
+func (x *ComponentTiming) GetCandidatesIn() int32 {
+	if x != nil {
+		return x.CandidatesIn
+	}
+	return 0
+}
+
+func (x *ComponentTiming) GetCandidatesOut() int32 {
+	if x != nil {
+		return x.CandidatesOut
+	}
+	return 0
+}
+
+func (x *ComponentTiming) GetRemoved() int32 {
+	if x != nil {
+		return x.Removed
+	}
+	return 0
+}
+
+func (x *ComponentTiming) GetDurationMs() float64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *ComponentTiming) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ComponentTiming) GetTimedOut() bool {
+	if x != nil {
+		return x.TimedOut
+	}
+	return false
+}
+
+var File_scored_posts_proto protoreflect.FileDescriptor
+
+const file_scored_posts_proto_rawDesc = "" +
+	"\n" +
+	"\x12scored_posts.proto\x12\vscoredposts\"\xbd\x03\n" +
+	"\x10ScoredPostsQuery\x12\x1b\n" +
+	"\tviewer_id\x18\x01 \x01(\x03R\bviewerId\x12\"\n" +
+	"\rclient_app_id\x18\x02 \x01(\x05R\vclientAppId\x12!\n" +
+	"\fcountry_code\x18\x03 \x01(\tR\vcountryCode\x12#\n" +
+	"\rlanguage_code\x18\x04 \x01(\tR\flanguageCode\x12\x19\n" +
+	"\bseen_ids\x18\x05 \x03(\x03R\aseenIds\x12\x1d\n" +
+	"\n" +
+	"served_ids\x18\x06 \x03(\x03R\tservedIds\x12&\n" +
+	"\x0fin_network_only\x18\a \x01(\bR\rinNetworkOnly\x12*\n" +
+	"\x11is_bottom_request\x18\b \x01(\bR\x0fisBottomRequest\x12O\n" +
+	"\x14bloom_filter_entries\x18\t \x03(\v2\x1d.scoredposts.BloomFilterEntryR\x12bloomFilterEntries\x12\"\n" +
+	"\rpre_rank_size\x18\n" +
+	" \x01(\x05R\vpreRankSize\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\v \x01(\tR\tsessionId\"&\n" +
+	"\x10BloomFilterEntry\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\"\xa5\x01\n" +
+	"\x13ScoredPostsResponse\x12:\n" +
+	"\fscored_posts\x18\x01 \x03(\v2\x17.scoredposts.ScoredPostR\vscoredPosts\x12)\n" +
+	"\x10ranking_degraded\x18\x02 \x01(\bR\x0frankingDegraded\x12'\n" +
+	"\x0fweights_version\x18\x03 \x01(\tR\x0eweightsVersion\"\x92\x05\n" +
+	"\n" +
+	"ScoredPost\x12\x19\n" +
+	"\btweet_id\x18\x01 \x01(\x04R\atweetId\x12\x1b\n" +
+	"\tauthor_id\x18\x02 \x01(\x04R\bauthorId\x12,\n" +
+	"\x12retweeted_tweet_id\x18\x03 \x01(\x04R\x10retweetedTweetId\x12*\n" +
+	"\x11retweeted_user_id\x18\x04 \x01(\x04R\x0fretweetedUserId\x12.\n" +
+	"\x14in_reply_to_tweet_id\x18\x05 \x01(\x04R\x10inReplyToTweetId\x12\x14\n" +
+	"\x05score\x18\x06 \x01(\x02R\x05score\x12\x1d\n" +
+	"\n" +
+	"in_network\x18\a \x01(\bR\tinNetwork\x12\x1f\n" +
+	"\vserved_type\x18\b \x01(\x05R\n" +
+	"servedType\x127\n" +
+	"\x18last_scored_timestamp_ms\x18\t \x01(\x04R\x15lastScoredTimestampMs\x122\n" +
+	"\x15prediction_request_id\x18\n" +
+	" \x01(\x04R\x13predictionRequestId\x12\x1c\n" +
+	"\tancestors\x18\v \x03(\x04R\tancestors\x12K\n" +
+	"\fscreen_names\x18\f \x03(\v2(.scoredposts.ScoredPost.ScreenNamesEntryR\vscreenNames\x12+\n" +
+	"\x11visibility_reason\x18\r \x01(\tR\x10visibilityReason\x12'\n" +
+	"\x0ffallback_ranked\x18\x0e \x01(\bR\x0efallbackRanked\x1a>\n" +
+	"\x10ScreenNamesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\x04R\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"N\n" +
+	"\x17ScoredPostsDebugRequest\x123\n" +
+	"\x05query\x18\x01 \x01(\v2\x1d.scoredposts.ScoredPostsQueryR\x05query\"\xfa\x02\n" +
+	"\x18ScoredPostsDebugResponse\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12-\n" +
+	"\x05query\x18\x02 \x01(\v2\x17.scoredposts.DebugQueryR\x05query\x12;\n" +
+	"\n" +
+	"candidates\x18\x03 \x03(\v2\x1b.scoredposts.DebugCandidateR\n" +
+	"candidates\x120\n" +
+	"\x06stages\x18\x04 \x03(\v2\x18.scoredposts.StageTimingR\x06stages\x12<\n" +
+	"\n" +
+	"components\x18\x05 \x03(\v2\x1c.scoredposts.ComponentTimingR\n" +
+	"components\x12:\n" +
+	"\fscored_posts\x18\x06 \x03(\v2\x17.scoredposts.ScoredPostR\vscoredPosts\x12'\n" +
+	"\x0fweights_version\x18\a \x01(\tR\x0eweightsVersion\"\xfd\x05\n" +
+	"\n" +
+	"DebugQuery\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\"\n" +
+	"\rclient_app_id\x18\x02 \x01(\x05R\vclientAppId\x12!\n" +
+	"\fcountry_code\x18\x03 \x01(\tR\vcountryCode\x12#\n" +
+	"\rlanguage_code\x18\x04 \x01(\tR\flanguageCode\x12&\n" +
+	"\x0frequest_time_ms\x18\x05 \x01(\x03R\rrequestTimeMs\x12\"\n" +
+	"\rpre_rank_size\x18\x06 \x01(\x05R\vpreRankSize\x12&\n" +
+	"\x0fin_network_only\x18\a \x01(\bR\rinNetworkOnly\x12*\n" +
+	"\x11is_bottom_request\x18\b \x01(\bR\x0fisBottomRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\t \x01(\tR\tsessionId\x12\x19\n" +
+	"\bseen_ids\x18\n" +
+	" \x03(\x03R\aseenIds\x12\x1d\n" +
+	"\n" +
+	"served_ids\x18\v \x03(\x03R\tservedIds\x12C\n" +
+	"\vexperiments\x18\f \x03(\v2!.scoredposts.ExperimentAssignmentR\vexperiments\x12-\n" +
+	"\x12missing_hydrations\x18\r \x03(\tR\x11missingHydrations\x12*\n" +
+	"\x11followed_user_ids\x18\x0e \x03(\x03R\x0ffollowedUserIds\x12(\n" +
+	"\x10blocked_user_ids\x18\x0f \x03(\x03R\x0eblockedUserIds\x12$\n" +
+	"\x0emuted_user_ids\x18\x10 \x03(\x03R\fmutedUserIds\x12.\n" +
+	"\x13subscribed_user_ids\x18\x11 \x03(\x03R\x11subscribedUserIds\x12%\n" +
+	"\x0emuted_keywords\x18\x12 \x03(\tR\rmutedKeywords\x12*\n" +
+	"\x11user_action_count\x18\x13 \x01(\x05R\x0fuserActionCount\"l\n" +
+	"\x14ExperimentAssignment\x12\x1e\n" +
+	"\n" +
+	"experiment\x18\x01 \x01(\tR\n" +
+	"experiment\x12\x1c\n" +
+	"\ttreatment\x18\x02 \x01(\tR\ttreatment\x12\x16\n" +
+	"\x06bucket\x18\x03 \x01(\x05R\x06bucket\"\x82\x0e\n" +
+	"\x0eDebugCandidate\x12\x19\n" +
+	"\btweet_id\x18\x01 \x01(\x03R\atweetId\x12\x1b\n" +
+	"\tauthor_id\x18\x02 \x01(\x04R\bauthorId\x12\x1d\n" +
+	"\n" +
+	"tweet_text\x18\x03 \x01(\tR\ttweetText\x123\n" +
+	"\x14in_reply_to_tweet_id\x18\x04 \x01(\x04H\x00R\x10inReplyToTweetId\x88\x01\x01\x121\n" +
+	"\x12retweeted_tweet_id\x18\x05 \x01(\x04H\x01R\x10retweetedTweetId\x88\x01\x01\x12/\n" +
+	"\x11retweeted_user_id\x18\x06 \x01(\x04H\x02R\x0fretweetedUserId\x88\x01\x01\x12\x1c\n" +
+	"\tancestors\x18\a \x03(\x04R\tancestors\x12\"\n" +
+	"\n" +
+	"in_network\x18\b \x01(\bH\x03R\tinNetwork\x88\x01\x01\x12$\n" +
+	"\vserved_type\x18\t \x01(\x05H\x04R\n" +
+	"servedType\x88\x01\x01\x12/\n" +
+	"\x11video_duration_ms\x18\n" +
+	" \x01(\x05H\x05R\x0fvideoDurationMs\x88\x01\x01\x129\n" +
+	"\x16author_followers_count\x18\v \x01(\x05H\x06R\x14authorFollowersCount\x88\x01\x01\x121\n" +
+	"\x12author_screen_name\x18\f \x01(\tH\aR\x10authorScreenName\x88\x01\x01\x127\n" +
+	"\x15retweeted_screen_name\x18\r \x01(\tH\bR\x13retweetedScreenName\x88\x01\x01\x120\n" +
+	"\x11visibility_reason\x18\x0e \x01(\tH\tR\x10visibilityReason\x88\x01\x01\x129\n" +
+	"\x16subscription_author_id\x18\x0f \x01(\x04H\n" +
+	"R\x14subscriptionAuthorId\x88\x01\x01\x127\n" +
+	"\x15prediction_request_id\x18\x10 \x01(\x04H\vR\x13predictionRequestId\x88\x01\x01\x12.\n" +
+	"\x11last_scored_at_ms\x18\x11 \x01(\x04H\fR\x0elastScoredAtMs\x88\x01\x01\x12\x16\n" +
+	"\x06source\x18\x12 \x01(\tR\x06source\x12=\n" +
+	"\n" +
+	"provenance\x18\x13 \x03(\v2\x1d.scoredposts.SourceProvenanceR\n" +
+	"provenance\x12-\n" +
+	"\x12missing_hydrations\x18\x14 \x03(\tR\x11missingHydrations\x12U\n" +
+	"\x0ephoenix_scores\x18\x15 \x03(\v2..scoredposts.DebugCandidate.PhoenixScoresEntryR\rphoenixScores\x12)\n" +
+	"\x0epre_rank_score\x18\x16 \x01(\x01H\rR\fpreRankScore\x88\x01\x01\x12*\n" +
+	"\x0eweighted_score\x18\x17 \x01(\x01H\x0eR\rweightedScore\x88\x01\x01\x12\x19\n" +
+	"\x05score\x18\x18 \x01(\x01H\x0fR\x05score\x88\x01\x01\x12\x1a\n" +
+	"\bselected\x18\x19 \x01(\bR\bselected\x127\n" +
+	"\aremoval\x18\x1a \x01(\v2\x1d.scoredposts.CandidateRemovalR\aremoval\x12:\n" +
+	"\n" +
+	"hydrations\x18\x1b \x03(\v2\x1a.scoredposts.HydrationStepR\n" +
+	"hydrations\x12.\n" +
+	"\x06scores\x18\x1c \x03(\v2\x16.scoredposts.ScoreStepR\x06scores\x12,\n" +
+	"\x0fweights_version\x18\x1d \x01(\tH\x10R\x0eweightsVersion\x88\x01\x01\x1a@\n" +
+	"\x12PhoenixScoresEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01B\x17\n" +
+	"\x15_in_reply_to_tweet_idB\x15\n" +
+	"\x13_retweeted_tweet_idB\x14\n" +
+	"\x12_retweeted_user_idB\r\n" +
+	"\v_in_networkB\x0e\n" +
+	"\f_served_typeB\x14\n" +
+	"\x12_video_duration_msB\x19\n" +
+	"\x17_author_followers_countB\x15\n" +
+	"\x13_author_screen_nameB\x18\n" +
+	"\x16_retweeted_screen_nameB\x14\n" +
+	"\x12_visibility_reasonB\x19\n" +
+	"\x17_subscription_author_idB\x18\n" +
+	"\x16_prediction_request_idB\x14\n" +
+	"\x12_last_scored_at_msB\x11\n" +
+	"\x0f_pre_rank_scoreB\x11\n" +
+	"\x0f_weighted_scoreB\b\n" +
+	"\x06_scoreB\x12\n" +
+	"\x10_weights_version\"c\n" +
+	"\x10SourceProvenance\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x12\n" +
+	"\x04rank\x18\x02 \x01(\x05R\x04rank\x12\x19\n" +
+	"\x05score\x18\x03 \x01(\x01H\x00R\x05score\x88\x01\x01B\b\n" +
+	"\x06_score\"^\n" +
+	"\x10CandidateRemoval\x12\x14\n" +
+	"\x05stage\x18\x01 \x01(\tR\x05stage\x12\x1c\n" +
+	"\tcomponent\x18\x02 \x01(\tR\tcomponent\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"[\n" +
+	"\rHydrationStep\x12\x14\n" +
+	"\x05stage\x18\x01 \x01(\tR\x05stage\x12\x1c\n" +
+	"\tcomponent\x18\x02 \x01(\tR\tcomponent\x12\x16\n" +
+	"\x06fields\x18\x03 \x03(\tR\x06fields\"\xcb\x01\n" +
+	"\tScoreStep\x12\x1c\n" +
+	"\tcomponent\x18\x01 \x01(\tR\tcomponent\x12)\n" +
+	"\x0epre_rank_score\x18\x02 \x01(\x01H\x00R\fpreRankScore\x88\x01\x01\x12\x19\n" +
+	"\x05score\x18\x03 \x01(\x01H\x01R\x05score\x88\x01\x01\x12*\n" +
+	"\x0eweighted_score\x18\x04 \x01(\x01H\x02R\rweightedScore\x88\x01\x01B\x11\n" +
+	"\x0f_pre_rank_scoreB\b\n" +
+	"\x06_scoreB\x11\n" +
+	"\x0f_weighted_score\"\xa6\x01\n" +
+	"\vStageTiming\x12\x14\n" +
+	"\x05stage\x18\x01 \x01(\tR\x05stage\x12#\n" +
+	"\rcandidates_in\x18\x02 \x01(\x05R\fcandidatesIn\x12%\n" +
+	"\x0ecandidates_out\x18\x03 \x01(\x05R\rcandidatesOut\x12\x1f\n" +
+	"\vduration_ms\x18\x04 \x01(\x01R\n" +
+	"durationMs\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\"\xff\x01\n" +
+	"\x0fComponentTiming\x12\x14\n" +
+	"\x05stage\x18\x01 \x01(\tR\x05stage\x12\x1c\n" +
+	"\tcomponent\x18\x02 \x01(\tR\tcomponent\x12#\n" +
+	"\rcandidates_in\x18\x03 \x01(\x05R\fcandidatesIn\x12%\n" +
+	"\x0ecandidates_out\x18\x04 \x01(\x05R\rcandidatesOut\x12\x18\n" +
+	"\aremoved\x18\x05 \x01(\x05R\aremoved\x12\x1f\n" +
+	"\vduration_ms\x18\x06 \x01(\x01R\n" +
+	"durationMs\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\x12\x1b\n" +
+	"\ttimed_out\x18\b \x01(\bR\btimedOut2\xcb\x01\n" +
+	"\x12ScoredPostsService\x12Q\n" +
+	"\x0eGetScoredPosts\x12\x1d.scoredposts.ScoredPostsQuery\x1a .scoredposts.ScoredPostsResponse\x12b\n" +
+	"\x13GetScoredPostsDebug\x12$.scoredposts.ScoredPostsDebugRequest\x1a%.scoredposts.ScoredPostsDebugResponseB\x16Z\x14x-algorithm-go/protob\x06proto3"
+
+var (
+	file_scored_posts_proto_rawDescOnce sync.Once
+	file_scored_posts_proto_rawDescData []byte
+)
+
+func file_scored_posts_proto_rawDescGZIP() []byte {
+	file_scored_posts_proto_rawDescOnce.Do(func() {
+		file_scored_posts_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_scored_posts_proto_rawDesc), len(file_scored_posts_proto_rawDesc)))
+	})
+	return file_scored_posts_proto_rawDescData
+}
+
+var file_scored_posts_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_scored_posts_proto_goTypes = []any{
+	(*ScoredPostsQuery)(nil),         // 0: scoredposts.ScoredPostsQuery
+	(*BloomFilterEntry)(nil),         // 1: scoredposts.BloomFilterEntry
+	(*ScoredPostsResponse)(nil),      // 2: scoredposts.ScoredPostsResponse
+	(*ScoredPost)(nil),               // 3: scoredposts.ScoredPost
+	(*ScoredPostsDebugRequest)(nil),  // 4: scoredposts.ScoredPostsDebugRequest
+	(*ScoredPostsDebugResponse)(nil), // 5: scoredposts.ScoredPostsDebugResponse
+	(*DebugQuery)(nil),               // 6: scoredposts.DebugQuery
+	(*ExperimentAssignment)(nil),     // 7: scoredposts.ExperimentAssignment
+	(*DebugCandidate)(nil),           // 8: scoredposts.DebugCandidate
+	(*SourceProvenance)(nil),         // 9: scoredposts.SourceProvenance
+	(*CandidateRemoval)(nil),         // 10: scoredposts.CandidateRemoval
+	(*HydrationStep)(nil),            // 11: scoredposts.HydrationStep
+	(*ScoreStep)(nil),                // 12: scoredposts.ScoreStep
+	(*StageTiming)(nil),              // 13: scoredposts.StageTiming
+	(*ComponentTiming)(nil),          // 14: scoredposts.ComponentTiming
+	nil,                              // 15: scoredposts.ScoredPost.ScreenNamesEntry
+	nil,                              // 16: scoredposts.DebugCandidate.PhoenixScoresEntry
+}
+var file_scored_posts_proto_depIdxs = []int32{
+	1,  // 0: scoredposts.ScoredPostsQuery.bloom_filter_entries:type_name -> scoredposts.BloomFilterEntry
+	3,  // 1: scoredposts.ScoredPostsResponse.scored_posts:type_name -> scoredposts.ScoredPost
+	15, // 2: scoredposts.ScoredPost.screen_names:type_name -> scoredposts.ScoredPost.ScreenNamesEntry
+	0,  // 3: scoredposts.ScoredPostsDebugRequest.query:type_name -> scoredposts.ScoredPostsQuery
+	6,  // 4: scoredposts.ScoredPostsDebugResponse.query:type_name -> scoredposts.DebugQuery
+	8,  // 5: scoredposts.ScoredPostsDebugResponse.candidates:type_name -> scoredposts.DebugCandidate
+	13, // 6: scoredposts.ScoredPostsDebugResponse.stages:type_name -> scoredposts.StageTiming
+	14, // 7: scoredposts.ScoredPostsDebugResponse.components:type_name -> scoredposts.ComponentTiming
+	3,  // 8: scoredposts.ScoredPostsDebugResponse.scored_posts:type_name -> scoredposts.ScoredPost
+	7,  // 9: scoredposts.DebugQuery.experiments:type_name -> scoredposts.ExperimentAssignment
+	9,  // 10: scoredposts.DebugCandidate.provenance:type_name -> scoredposts.SourceProvenance
+	16, // 11: scoredposts.DebugCandidate.phoenix_scores:type_name -> scoredposts.DebugCandidate.PhoenixScoresEntry
+	10, // 12: scoredposts.DebugCandidate.removal:type_name -> scoredposts.CandidateRemoval
+	11, // 13: scoredposts.DebugCandidate.hydrations:type_name -> scoredposts.HydrationStep
+	12, // 14: scoredposts.DebugCandidate.scores:type_name -> scoredposts.ScoreStep
+	0,  // 15: scoredposts.ScoredPostsService.GetScoredPosts:input_type -> scoredposts.ScoredPostsQuery
+	4,  // 16: scoredposts.ScoredPostsService.GetScoredPostsDebug:input_type -> scoredposts.ScoredPostsDebugRequest
+	2,  // 17: scoredposts.ScoredPostsService.GetScoredPosts:output_type -> scoredposts.ScoredPostsResponse
+	5,  // 18: scoredposts.ScoredPostsService.GetScoredPostsDebug:output_type -> scoredposts.ScoredPostsDebugResponse
+	17, // [17:19] is the sub-list for method output_type
+	15, // [15:17] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
 }
 
-// RegisterScoredPostsServiceServer 注册服务（占位实现）
-// 实际应该由 protoc-gen-go-grpc 生成
-func RegisterScoredPostsServiceServer(s interface{}, srv ScoredPostsServiceServer) {
-	// 占位实现，实际应该注册到 gRPC 服务器
+func init() { file_scored_posts_proto_init() }
+func file_scored_posts_proto_init() {
+	if File_scored_posts_proto != nil {
+		return
+	}
+	file_scored_posts_proto_msgTypes[8].OneofWrappers = []any{}
+	file_scored_posts_proto_msgTypes[9].OneofWrappers = []any{}
+	file_scored_posts_proto_msgTypes[12].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_scored_posts_proto_rawDesc), len(file_scored_posts_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_scored_posts_proto_goTypes,
+		DependencyIndexes: file_scored_posts_proto_depIdxs,
+		MessageInfos:      file_scored_posts_proto_msgTypes,
+	}.Build()
+	File_scored_posts_proto = out.File
+	file_scored_posts_proto_goTypes = nil
+	file_scored_posts_proto_depIdxs = nil
 }
@@ -0,0 +1,169 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: scored_posts.proto
+
+package proto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ScoredPostsService_GetScoredPosts_FullMethodName      = "/scoredposts.ScoredPostsService/GetScoredPosts"
+	ScoredPostsService_GetScoredPostsDebug_FullMethodName = "/scoredposts.ScoredPostsService/GetScoredPostsDebug"
+)
+
+// ScoredPostsServiceClient is the client API for ScoredPostsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ScoredPostsService 提供推荐帖子服务
+type ScoredPostsServiceClient interface {
+	// GetScoredPosts 获取排序后的帖子列表
+	GetScoredPosts(ctx context.Context, in *ScoredPostsQuery, opts ...grpc.CallOption) (*ScoredPostsResponse, error)
+	// GetScoredPostsDebug 以 explain 模式执行一次请求，返回完整的管道结果，供排序工程师排查线上请求
+	// 需要授权（metadata 中的 authorization: Bearer <token>），不合并请求、不提交 Side Effects、不读写会话缓存
+	GetScoredPostsDebug(ctx context.Context, in *ScoredPostsDebugRequest, opts ...grpc.CallOption) (*ScoredPostsDebugResponse, error)
+}
+
+type scoredPostsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewScoredPostsServiceClient(cc grpc.ClientConnInterface) ScoredPostsServiceClient {
+	return &scoredPostsServiceClient{cc}
+}
+
+func (c *scoredPostsServiceClient) GetScoredPosts(ctx context.Context, in *ScoredPostsQuery, opts ...grpc.CallOption) (*ScoredPostsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ScoredPostsResponse)
+	err := c.cc.Invoke(ctx, ScoredPostsService_GetScoredPosts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scoredPostsServiceClient) GetScoredPostsDebug(ctx context.Context, in *ScoredPostsDebugRequest, opts ...grpc.CallOption) (*ScoredPostsDebugResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ScoredPostsDebugResponse)
+	err := c.cc.Invoke(ctx, ScoredPostsService_GetScoredPostsDebug_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ScoredPostsServiceServer is the server API for ScoredPostsService service.
+// All implementations must embed UnimplementedScoredPostsServiceServer
+// for forward compatibility.
+//
+// ScoredPostsService 提供推荐帖子服务
+type ScoredPostsServiceServer interface {
+	// GetScoredPosts 获取排序后的帖子列表
+	GetScoredPosts(context.Context, *ScoredPostsQuery) (*ScoredPostsResponse, error)
+	// GetScoredPostsDebug 以 explain 模式执行一次请求，返回完整的管道结果，供排序工程师排查线上请求
+	// 需要授权（metadata 中的 authorization: Bearer <token>），不合并请求、不提交 Side Effects、不读写会话缓存
+	GetScoredPostsDebug(context.Context, *ScoredPostsDebugRequest) (*ScoredPostsDebugResponse, error)
+	mustEmbedUnimplementedScoredPostsServiceServer()
+}
+
+// UnimplementedScoredPostsServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedScoredPostsServiceServer struct{}
+
+func (UnimplementedScoredPostsServiceServer) GetScoredPosts(context.Context, *ScoredPostsQuery) (*ScoredPostsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetScoredPosts not implemented")
+}
+func (UnimplementedScoredPostsServiceServer) GetScoredPostsDebug(context.Context, *ScoredPostsDebugRequest) (*ScoredPostsDebugResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetScoredPostsDebug not implemented")
+}
+func (UnimplementedScoredPostsServiceServer) mustEmbedUnimplementedScoredPostsServiceServer() {}
+func (UnimplementedScoredPostsServiceServer) testEmbeddedByValue()                            {}
+
+// UnsafeScoredPostsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ScoredPostsServiceServer will
+// result in compilation errors.
+type UnsafeScoredPostsServiceServer interface {
+	mustEmbedUnimplementedScoredPostsServiceServer()
+}
+
+func RegisterScoredPostsServiceServer(s grpc.ServiceRegistrar, srv ScoredPostsServiceServer) {
+	// If the following call panics, it indicates UnimplementedScoredPostsServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ScoredPostsService_ServiceDesc, srv)
+}
+
+func _ScoredPostsService_GetScoredPosts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScoredPostsQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScoredPostsServiceServer).GetScoredPosts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ScoredPostsService_GetScoredPosts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScoredPostsServiceServer).GetScoredPosts(ctx, req.(*ScoredPostsQuery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScoredPostsService_GetScoredPostsDebug_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScoredPostsDebugRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScoredPostsServiceServer).GetScoredPostsDebug(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ScoredPostsService_GetScoredPostsDebug_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScoredPostsServiceServer).GetScoredPostsDebug(ctx, req.(*ScoredPostsDebugRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ScoredPostsService_ServiceDesc is the grpc.ServiceDesc for ScoredPostsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ScoredPostsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "scoredposts.ScoredPostsService",
+	HandlerType: (*ScoredPostsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetScoredPosts",
+			Handler:    _ScoredPostsService_GetScoredPosts_Handler,
+		},
+		{
+			MethodName: "GetScoredPostsDebug",
+			Handler:    _ScoredPostsService_GetScoredPostsDebug_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "scored_posts.proto",
+}
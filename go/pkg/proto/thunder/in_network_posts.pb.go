@@ -1,90 +1,671 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
-// This is a placeholder file for compilation purposes.
-// To generate actual code, run: protoc --go_out=. --go-grpc_out=. in_network_posts.proto
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: thunder/in_network_posts.proto
 
 package thunder
 
 import (
-	"context"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
 )
 
-// LightPost represents a lightweight post structure
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetInNetworkPostsRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	UserId           uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	FollowingUserIds []uint64               `protobuf:"varint,2,rep,packed,name=following_user_ids,json=followingUserIds,proto3" json:"following_user_ids,omitempty"`
+	MaxResults       uint32                 `protobuf:"varint,3,opt,name=max_results,json=maxResults,proto3" json:"max_results,omitempty"`
+	ExcludeTweetIds  []uint64               `protobuf:"varint,4,rep,packed,name=exclude_tweet_ids,json=excludeTweetIds,proto3" json:"exclude_tweet_ids,omitempty"`
+	Algorithm        string                 `protobuf:"bytes,5,opt,name=algorithm,proto3" json:"algorithm,omitempty"`
+	Debug            bool                   `protobuf:"varint,6,opt,name=debug,proto3" json:"debug,omitempty"`
+	IsVideoRequest   bool                   `protobuf:"varint,7,opt,name=is_video_request,json=isVideoRequest,proto3" json:"is_video_request,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *GetInNetworkPostsRequest) Reset() {
+	*x = GetInNetworkPostsRequest{}
+	mi := &file_thunder_in_network_posts_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetInNetworkPostsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInNetworkPostsRequest) ProtoMessage() {}
+
+func (x *GetInNetworkPostsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_thunder_in_network_posts_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInNetworkPostsRequest.ProtoReflect.Descriptor instead.
+func (*GetInNetworkPostsRequest) Descriptor() ([]byte, []int) {
+	return file_thunder_in_network_posts_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetInNetworkPostsRequest) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *GetInNetworkPostsRequest) GetFollowingUserIds() []uint64 {
+	if x != nil {
+		return x.FollowingUserIds
+	}
+	return nil
+}
+
+func (x *GetInNetworkPostsRequest) GetMaxResults() uint32 {
+	if x != nil {
+		return x.MaxResults
+	}
+	return 0
+}
+
+func (x *GetInNetworkPostsRequest) GetExcludeTweetIds() []uint64 {
+	if x != nil {
+		return x.ExcludeTweetIds
+	}
+	return nil
+}
+
+func (x *GetInNetworkPostsRequest) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+func (x *GetInNetworkPostsRequest) GetDebug() bool {
+	if x != nil {
+		return x.Debug
+	}
+	return false
+}
+
+func (x *GetInNetworkPostsRequest) GetIsVideoRequest() bool {
+	if x != nil {
+		return x.IsVideoRequest
+	}
+	return false
+}
+
+type GetInNetworkPostsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Posts         []*LightPost           `protobuf:"bytes,1,rep,name=posts,proto3" json:"posts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetInNetworkPostsResponse) Reset() {
+	*x = GetInNetworkPostsResponse{}
+	mi := &file_thunder_in_network_posts_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetInNetworkPostsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInNetworkPostsResponse) ProtoMessage() {}
+
+func (x *GetInNetworkPostsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_thunder_in_network_posts_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInNetworkPostsResponse.ProtoReflect.Descriptor instead.
+func (*GetInNetworkPostsResponse) Descriptor() ([]byte, []int) {
+	return file_thunder_in_network_posts_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetInNetworkPostsResponse) GetPosts() []*LightPost {
+	if x != nil {
+		return x.Posts
+	}
+	return nil
+}
+
 type LightPost struct {
-	PostID            int64
-	AuthorID          int64
-	CreatedAt         int64
-	InReplyToPostID   *int64
-	InReplyToUserID   *int64
-	IsRetweet         bool
-	IsReply           bool
-	SourcePostID      *int64
-	SourceUserID      *int64
-	HasVideo          bool
-	ConversationID    *int64
-}
-
-// TweetDeleteEvent represents a tweet deletion event
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	PostId          int64                  `protobuf:"varint,1,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
+	AuthorId        int64                  `protobuf:"varint,2,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	CreatedAt       int64                  `protobuf:"varint,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	InReplyToPostId *int64                 `protobuf:"varint,4,opt,name=in_reply_to_post_id,json=inReplyToPostId,proto3,oneof" json:"in_reply_to_post_id,omitempty"`
+	InReplyToUserId *int64                 `protobuf:"varint,5,opt,name=in_reply_to_user_id,json=inReplyToUserId,proto3,oneof" json:"in_reply_to_user_id,omitempty"`
+	IsRetweet       bool                   `protobuf:"varint,6,opt,name=is_retweet,json=isRetweet,proto3" json:"is_retweet,omitempty"`
+	IsReply         bool                   `protobuf:"varint,7,opt,name=is_reply,json=isReply,proto3" json:"is_reply,omitempty"`
+	SourcePostId    *int64                 `protobuf:"varint,8,opt,name=source_post_id,json=sourcePostId,proto3,oneof" json:"source_post_id,omitempty"`
+	SourceUserId    *int64                 `protobuf:"varint,9,opt,name=source_user_id,json=sourceUserId,proto3,oneof" json:"source_user_id,omitempty"`
+	HasVideo        bool                   `protobuf:"varint,10,opt,name=has_video,json=hasVideo,proto3" json:"has_video,omitempty"`
+	ConversationId  *int64                 `protobuf:"varint,11,opt,name=conversation_id,json=conversationId,proto3,oneof" json:"conversation_id,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *LightPost) Reset() {
+	*x = LightPost{}
+	mi := &file_thunder_in_network_posts_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LightPost) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LightPost) ProtoMessage() {}
+
+func (x *LightPost) ProtoReflect() protoreflect.Message {
+	mi := &file_thunder_in_network_posts_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LightPost.ProtoReflect.Descriptor instead.
+func (*LightPost) Descriptor() ([]byte, []int) {
+	return file_thunder_in_network_posts_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *LightPost) GetPostId() int64 {
+	if x != nil {
+		return x.PostId
+	}
+	return 0
+}
+
+func (x *LightPost) GetAuthorId() int64 {
+	if x != nil {
+		return x.AuthorId
+	}
+	return 0
+}
+
+func (x *LightPost) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *LightPost) GetInReplyToPostId() int64 {
+	if x != nil && x.InReplyToPostId != nil {
+		return *x.InReplyToPostId
+	}
+	return 0
+}
+
+func (x *LightPost) GetInReplyToUserId() int64 {
+	if x != nil && x.InReplyToUserId != nil {
+		return *x.InReplyToUserId
+	}
+	return 0
+}
+
+func (x *LightPost) GetIsRetweet() bool {
+	if x != nil {
+		return x.IsRetweet
+	}
+	return false
+}
+
+func (x *LightPost) GetIsReply() bool {
+	if x != nil {
+		return x.IsReply
+	}
+	return false
+}
+
+func (x *LightPost) GetSourcePostId() int64 {
+	if x != nil && x.SourcePostId != nil {
+		return *x.SourcePostId
+	}
+	return 0
+}
+
+func (x *LightPost) GetSourceUserId() int64 {
+	if x != nil && x.SourceUserId != nil {
+		return *x.SourceUserId
+	}
+	return 0
+}
+
+func (x *LightPost) GetHasVideo() bool {
+	if x != nil {
+		return x.HasVideo
+	}
+	return false
+}
+
+func (x *LightPost) GetConversationId() int64 {
+	if x != nil && x.ConversationId != nil {
+		return *x.ConversationId
+	}
+	return 0
+}
+
 type TweetDeleteEvent struct {
-	PostID    int64
-	DeletedAt int64
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PostId        int64                  `protobuf:"varint,1,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
+	DeletedAt     int64                  `protobuf:"varint,2,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TweetDeleteEvent) Reset() {
+	*x = TweetDeleteEvent{}
+	mi := &file_thunder_in_network_posts_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TweetDeleteEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TweetDeleteEvent) ProtoMessage() {}
+
+func (x *TweetDeleteEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_thunder_in_network_posts_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TweetDeleteEvent.ProtoReflect.Descriptor instead.
+func (*TweetDeleteEvent) Descriptor() ([]byte, []int) {
+	return file_thunder_in_network_posts_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *TweetDeleteEvent) GetPostId() int64 {
+	if x != nil {
+		return x.PostId
+	}
+	return 0
+}
+
+func (x *TweetDeleteEvent) GetDeletedAt() int64 {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return 0
 }
 
-// TweetCreateEvent represents a tweet creation event
-type TweetCreateEvent struct {
-	PostID            int64
-	AuthorID          int64
-	CreatedAt         int64
-	InReplyToPostID   *int64
-	InReplyToUserID   *int64
-	IsRetweet         bool
-	IsReply           bool
-	SourcePostID      *int64
-	SourceUserID      *int64
-	HasVideo          bool
-	ConversationID    *int64
-}
-
-// InNetworkEvent represents an in-network event (create or delete)
 type InNetworkEvent struct {
-	EventVariant interface{} // Can be *TweetCreateEvent or *TweetDeleteEvent
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to EventVariant:
+	//
+	//	*InNetworkEvent_TweetCreateEvent
+	//	*InNetworkEvent_TweetDeleteEvent
+	EventVariant  isInNetworkEvent_EventVariant `protobuf_oneof:"event_variant"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-// GetInNetworkPostsRequest is the request for GetInNetworkPosts
-type GetInNetworkPostsRequest struct {
-	UserID           uint64
-	FollowingUserIDs []uint64
-	MaxResults       uint32
-	ExcludeTweetIDs  []uint64
-	Algorithm        string
-	Debug            bool
-	IsVideoRequest   bool
+func (x *InNetworkEvent) Reset() {
+	*x = InNetworkEvent{}
+	mi := &file_thunder_in_network_posts_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-// GetInNetworkPostsResponse is the response for GetInNetworkPosts
-type GetInNetworkPostsResponse struct {
-	Posts []*LightPost
+func (x *InNetworkEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InNetworkEvent) ProtoMessage() {}
+
+func (x *InNetworkEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_thunder_in_network_posts_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InNetworkEvent.ProtoReflect.Descriptor instead.
+func (*InNetworkEvent) Descriptor() ([]byte, []int) {
+	return file_thunder_in_network_posts_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *InNetworkEvent) GetEventVariant() isInNetworkEvent_EventVariant {
+	if x != nil {
+		return x.EventVariant
+	}
+	return nil
+}
+
+func (x *InNetworkEvent) GetTweetCreateEvent() *TweetCreateEvent {
+	if x != nil {
+		if x, ok := x.EventVariant.(*InNetworkEvent_TweetCreateEvent); ok {
+			return x.TweetCreateEvent
+		}
+	}
+	return nil
+}
+
+func (x *InNetworkEvent) GetTweetDeleteEvent() *TweetDeleteEvent {
+	if x != nil {
+		if x, ok := x.EventVariant.(*InNetworkEvent_TweetDeleteEvent); ok {
+			return x.TweetDeleteEvent
+		}
+	}
+	return nil
+}
+
+type isInNetworkEvent_EventVariant interface {
+	isInNetworkEvent_EventVariant()
+}
+
+type InNetworkEvent_TweetCreateEvent struct {
+	TweetCreateEvent *TweetCreateEvent `protobuf:"bytes,1,opt,name=tweet_create_event,json=tweetCreateEvent,proto3,oneof"`
+}
+
+type InNetworkEvent_TweetDeleteEvent struct {
+	TweetDeleteEvent *TweetDeleteEvent `protobuf:"bytes,2,opt,name=tweet_delete_event,json=tweetDeleteEvent,proto3,oneof"`
+}
+
+func (*InNetworkEvent_TweetCreateEvent) isInNetworkEvent_EventVariant() {}
+
+func (*InNetworkEvent_TweetDeleteEvent) isInNetworkEvent_EventVariant() {}
+
+type TweetCreateEvent struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	PostId          int64                  `protobuf:"varint,1,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
+	AuthorId        int64                  `protobuf:"varint,2,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	CreatedAt       int64                  `protobuf:"varint,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	InReplyToPostId *int64                 `protobuf:"varint,4,opt,name=in_reply_to_post_id,json=inReplyToPostId,proto3,oneof" json:"in_reply_to_post_id,omitempty"`
+	InReplyToUserId *int64                 `protobuf:"varint,5,opt,name=in_reply_to_user_id,json=inReplyToUserId,proto3,oneof" json:"in_reply_to_user_id,omitempty"`
+	IsRetweet       bool                   `protobuf:"varint,6,opt,name=is_retweet,json=isRetweet,proto3" json:"is_retweet,omitempty"`
+	IsReply         bool                   `protobuf:"varint,7,opt,name=is_reply,json=isReply,proto3" json:"is_reply,omitempty"`
+	SourcePostId    *int64                 `protobuf:"varint,8,opt,name=source_post_id,json=sourcePostId,proto3,oneof" json:"source_post_id,omitempty"`
+	SourceUserId    *int64                 `protobuf:"varint,9,opt,name=source_user_id,json=sourceUserId,proto3,oneof" json:"source_user_id,omitempty"`
+	HasVideo        bool                   `protobuf:"varint,10,opt,name=has_video,json=hasVideo,proto3" json:"has_video,omitempty"`
+	ConversationId  *int64                 `protobuf:"varint,11,opt,name=conversation_id,json=conversationId,proto3,oneof" json:"conversation_id,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *TweetCreateEvent) Reset() {
+	*x = TweetCreateEvent{}
+	mi := &file_thunder_in_network_posts_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TweetCreateEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-// InNetworkPostsServiceServer is the server interface for InNetworkPostsService
-type InNetworkPostsServiceServer interface {
-	GetInNetworkPosts(context.Context, *GetInNetworkPostsRequest) (*GetInNetworkPostsResponse, error)
+func (*TweetCreateEvent) ProtoMessage() {}
+
+func (x *TweetCreateEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_thunder_in_network_posts_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-// UnimplementedInNetworkPostsServiceServer must be embedded to have forward compatible implementations
-type UnimplementedInNetworkPostsServiceServer struct{}
+// Deprecated: Use TweetCreateEvent.ProtoReflect.Descriptor instead.
+func (*TweetCreateEvent) Descriptor() ([]byte, []int) {
+	return file_thunder_in_network_posts_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TweetCreateEvent) GetPostId() int64 {
+	if x != nil {
+		return x.PostId
+	}
+	return 0
+}
 
-func (UnimplementedInNetworkPostsServiceServer) GetInNetworkPosts(context.Context, *GetInNetworkPostsRequest) (*GetInNetworkPostsResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetInNetworkPosts not implemented")
+func (x *TweetCreateEvent) GetAuthorId() int64 {
+	if x != nil {
+		return x.AuthorId
+	}
+	return 0
+}
+
+func (x *TweetCreateEvent) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *TweetCreateEvent) GetInReplyToPostId() int64 {
+	if x != nil && x.InReplyToPostId != nil {
+		return *x.InReplyToPostId
+	}
+	return 0
+}
+
+func (x *TweetCreateEvent) GetInReplyToUserId() int64 {
+	if x != nil && x.InReplyToUserId != nil {
+		return *x.InReplyToUserId
+	}
+	return 0
+}
+
+func (x *TweetCreateEvent) GetIsRetweet() bool {
+	if x != nil {
+		return x.IsRetweet
+	}
+	return false
+}
+
+func (x *TweetCreateEvent) GetIsReply() bool {
+	if x != nil {
+		return x.IsReply
+	}
+	return false
+}
+
+func (x *TweetCreateEvent) GetSourcePostId() int64 {
+	if x != nil && x.SourcePostId != nil {
+		return *x.SourcePostId
+	}
+	return 0
+}
+
+func (x *TweetCreateEvent) GetSourceUserId() int64 {
+	if x != nil && x.SourceUserId != nil {
+		return *x.SourceUserId
+	}
+	return 0
+}
+
+func (x *TweetCreateEvent) GetHasVideo() bool {
+	if x != nil {
+		return x.HasVideo
+	}
+	return false
+}
+
+func (x *TweetCreateEvent) GetConversationId() int64 {
+	if x != nil && x.ConversationId != nil {
+		return *x.ConversationId
+	}
+	return 0
+}
+
+var File_thunder_in_network_posts_proto protoreflect.FileDescriptor
+
+const file_thunder_in_network_posts_proto_rawDesc = "" +
+	"\n" +
+	"\x1ethunder/in_network_posts.proto\x12\athunder\"\x8c\x02\n" +
+	"\x18GetInNetworkPostsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\x12,\n" +
+	"\x12following_user_ids\x18\x02 \x03(\x04R\x10followingUserIds\x12\x1f\n" +
+	"\vmax_results\x18\x03 \x01(\rR\n" +
+	"maxResults\x12*\n" +
+	"\x11exclude_tweet_ids\x18\x04 \x03(\x04R\x0fexcludeTweetIds\x12\x1c\n" +
+	"\talgorithm\x18\x05 \x01(\tR\talgorithm\x12\x14\n" +
+	"\x05debug\x18\x06 \x01(\bR\x05debug\x12(\n" +
+	"\x10is_video_request\x18\a \x01(\bR\x0eisVideoRequest\"E\n" +
+	"\x19GetInNetworkPostsResponse\x12(\n" +
+	"\x05posts\x18\x01 \x03(\v2\x12.thunder.LightPostR\x05posts\"\x8b\x04\n" +
+	"\tLightPost\x12\x17\n" +
+	"\apost_id\x18\x01 \x01(\x03R\x06postId\x12\x1b\n" +
+	"\tauthor_id\x18\x02 \x01(\x03R\bauthorId\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\x03R\tcreatedAt\x121\n" +
+	"\x13in_reply_to_post_id\x18\x04 \x01(\x03H\x00R\x0finReplyToPostId\x88\x01\x01\x121\n" +
+	"\x13in_reply_to_user_id\x18\x05 \x01(\x03H\x01R\x0finReplyToUserId\x88\x01\x01\x12\x1d\n" +
+	"\n" +
+	"is_retweet\x18\x06 \x01(\bR\tisRetweet\x12\x19\n" +
+	"\bis_reply\x18\a \x01(\bR\aisReply\x12)\n" +
+	"\x0esource_post_id\x18\b \x01(\x03H\x02R\fsourcePostId\x88\x01\x01\x12)\n" +
+	"\x0esource_user_id\x18\t \x01(\x03H\x03R\fsourceUserId\x88\x01\x01\x12\x1b\n" +
+	"\thas_video\x18\n" +
+	" \x01(\bR\bhasVideo\x12,\n" +
+	"\x0fconversation_id\x18\v \x01(\x03H\x04R\x0econversationId\x88\x01\x01B\x16\n" +
+	"\x14_in_reply_to_post_idB\x16\n" +
+	"\x14_in_reply_to_user_idB\x11\n" +
+	"\x0f_source_post_idB\x11\n" +
+	"\x0f_source_user_idB\x12\n" +
+	"\x10_conversation_id\"J\n" +
+	"\x10TweetDeleteEvent\x12\x17\n" +
+	"\apost_id\x18\x01 \x01(\x03R\x06postId\x12\x1d\n" +
+	"\n" +
+	"deleted_at\x18\x02 \x01(\x03R\tdeletedAt\"\xb7\x01\n" +
+	"\x0eInNetworkEvent\x12I\n" +
+	"\x12tweet_create_event\x18\x01 \x01(\v2\x19.thunder.TweetCreateEventH\x00R\x10tweetCreateEvent\x12I\n" +
+	"\x12tweet_delete_event\x18\x02 \x01(\v2\x19.thunder.TweetDeleteEventH\x00R\x10tweetDeleteEventB\x0f\n" +
+	"\revent_variant\"\x92\x04\n" +
+	"\x10TweetCreateEvent\x12\x17\n" +
+	"\apost_id\x18\x01 \x01(\x03R\x06postId\x12\x1b\n" +
+	"\tauthor_id\x18\x02 \x01(\x03R\bauthorId\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\x03R\tcreatedAt\x121\n" +
+	"\x13in_reply_to_post_id\x18\x04 \x01(\x03H\x00R\x0finReplyToPostId\x88\x01\x01\x121\n" +
+	"\x13in_reply_to_user_id\x18\x05 \x01(\x03H\x01R\x0finReplyToUserId\x88\x01\x01\x12\x1d\n" +
+	"\n" +
+	"is_retweet\x18\x06 \x01(\bR\tisRetweet\x12\x19\n" +
+	"\bis_reply\x18\a \x01(\bR\aisReply\x12)\n" +
+	"\x0esource_post_id\x18\b \x01(\x03H\x02R\fsourcePostId\x88\x01\x01\x12)\n" +
+	"\x0esource_user_id\x18\t \x01(\x03H\x03R\fsourceUserId\x88\x01\x01\x12\x1b\n" +
+	"\thas_video\x18\n" +
+	" \x01(\bR\bhasVideo\x12,\n" +
+	"\x0fconversation_id\x18\v \x01(\x03H\x04R\x0econversationId\x88\x01\x01B\x16\n" +
+	"\x14_in_reply_to_post_idB\x16\n" +
+	"\x14_in_reply_to_user_idB\x11\n" +
+	"\x0f_source_post_idB\x11\n" +
+	"\x0f_source_user_idB\x12\n" +
+	"\x10_conversation_id2s\n" +
+	"\x15InNetworkPostsService\x12Z\n" +
+	"\x11GetInNetworkPosts\x12!.thunder.GetInNetworkPostsRequest\x1a\".thunder.GetInNetworkPostsResponseB\x1eZ\x1cx-algorithm-go/proto/thunderb\x06proto3"
+
+var (
+	file_thunder_in_network_posts_proto_rawDescOnce sync.Once
+	file_thunder_in_network_posts_proto_rawDescData []byte
+)
+
+func file_thunder_in_network_posts_proto_rawDescGZIP() []byte {
+	file_thunder_in_network_posts_proto_rawDescOnce.Do(func() {
+		file_thunder_in_network_posts_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_thunder_in_network_posts_proto_rawDesc), len(file_thunder_in_network_posts_proto_rawDesc)))
+	})
+	return file_thunder_in_network_posts_proto_rawDescData
+}
+
+var file_thunder_in_network_posts_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_thunder_in_network_posts_proto_goTypes = []any{
+	(*GetInNetworkPostsRequest)(nil),  // 0: thunder.GetInNetworkPostsRequest
+	(*GetInNetworkPostsResponse)(nil), // 1: thunder.GetInNetworkPostsResponse
+	(*LightPost)(nil),                 // 2: thunder.LightPost
+	(*TweetDeleteEvent)(nil),          // 3: thunder.TweetDeleteEvent
+	(*InNetworkEvent)(nil),            // 4: thunder.InNetworkEvent
+	(*TweetCreateEvent)(nil),          // 5: thunder.TweetCreateEvent
+}
+var file_thunder_in_network_posts_proto_depIdxs = []int32{
+	2, // 0: thunder.GetInNetworkPostsResponse.posts:type_name -> thunder.LightPost
+	5, // 1: thunder.InNetworkEvent.tweet_create_event:type_name -> thunder.TweetCreateEvent
+	3, // 2: thunder.InNetworkEvent.tweet_delete_event:type_name -> thunder.TweetDeleteEvent
+	0, // 3: thunder.InNetworkPostsService.GetInNetworkPosts:input_type -> thunder.GetInNetworkPostsRequest
+	1, // 4: thunder.InNetworkPostsService.GetInNetworkPosts:output_type -> thunder.GetInNetworkPostsResponse
+	4, // [4:5] is the sub-list for method output_type
+	3, // [3:4] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
 }
 
-// RegisterInNetworkPostsServiceServer registers the service server
-// This is a placeholder - in real generated code this would properly register the service
-func RegisterInNetworkPostsServiceServer(s *grpc.Server, srv InNetworkPostsServiceServer) {
-	// Placeholder implementation - in real generated code this would register the service
-	// For now, we'll use a simple approach: store the server implementation
-	// In actual protoc-generated code, this would be handled automatically
-	_ = srv
+func init() { file_thunder_in_network_posts_proto_init() }
+func file_thunder_in_network_posts_proto_init() {
+	if File_thunder_in_network_posts_proto != nil {
+		return
+	}
+	file_thunder_in_network_posts_proto_msgTypes[2].OneofWrappers = []any{}
+	file_thunder_in_network_posts_proto_msgTypes[4].OneofWrappers = []any{
+		(*InNetworkEvent_TweetCreateEvent)(nil),
+		(*InNetworkEvent_TweetDeleteEvent)(nil),
+	}
+	file_thunder_in_network_posts_proto_msgTypes[5].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_thunder_in_network_posts_proto_rawDesc), len(file_thunder_in_network_posts_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_thunder_in_network_posts_proto_goTypes,
+		DependencyIndexes: file_thunder_in_network_posts_proto_depIdxs,
+		MessageInfos:      file_thunder_in_network_posts_proto_msgTypes,
+	}.Build()
+	File_thunder_in_network_posts_proto = out.File
+	file_thunder_in_network_posts_proto_goTypes = nil
+	file_thunder_in_network_posts_proto_depIdxs = nil
 }
@@ -0,0 +1,125 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: thunder/in_network_posts.proto
+
+package thunder
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	InNetworkPostsService_GetInNetworkPosts_FullMethodName = "/thunder.InNetworkPostsService/GetInNetworkPosts"
+)
+
+// InNetworkPostsServiceClient is the client API for InNetworkPostsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// InNetworkPostsService provides access to in-network posts
+type InNetworkPostsServiceClient interface {
+	GetInNetworkPosts(ctx context.Context, in *GetInNetworkPostsRequest, opts ...grpc.CallOption) (*GetInNetworkPostsResponse, error)
+}
+
+type inNetworkPostsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewInNetworkPostsServiceClient(cc grpc.ClientConnInterface) InNetworkPostsServiceClient {
+	return &inNetworkPostsServiceClient{cc}
+}
+
+func (c *inNetworkPostsServiceClient) GetInNetworkPosts(ctx context.Context, in *GetInNetworkPostsRequest, opts ...grpc.CallOption) (*GetInNetworkPostsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetInNetworkPostsResponse)
+	err := c.cc.Invoke(ctx, InNetworkPostsService_GetInNetworkPosts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// InNetworkPostsServiceServer is the server API for InNetworkPostsService service.
+// All implementations must embed UnimplementedInNetworkPostsServiceServer
+// for forward compatibility.
+//
+// InNetworkPostsService provides access to in-network posts
+type InNetworkPostsServiceServer interface {
+	GetInNetworkPosts(context.Context, *GetInNetworkPostsRequest) (*GetInNetworkPostsResponse, error)
+	mustEmbedUnimplementedInNetworkPostsServiceServer()
+}
+
+// UnimplementedInNetworkPostsServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedInNetworkPostsServiceServer struct{}
+
+func (UnimplementedInNetworkPostsServiceServer) GetInNetworkPosts(context.Context, *GetInNetworkPostsRequest) (*GetInNetworkPostsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetInNetworkPosts not implemented")
+}
+func (UnimplementedInNetworkPostsServiceServer) mustEmbedUnimplementedInNetworkPostsServiceServer() {}
+func (UnimplementedInNetworkPostsServiceServer) testEmbeddedByValue()                               {}
+
+// UnsafeInNetworkPostsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to InNetworkPostsServiceServer will
+// result in compilation errors.
+type UnsafeInNetworkPostsServiceServer interface {
+	mustEmbedUnimplementedInNetworkPostsServiceServer()
+}
+
+func RegisterInNetworkPostsServiceServer(s grpc.ServiceRegistrar, srv InNetworkPostsServiceServer) {
+	// If the following call panics, it indicates UnimplementedInNetworkPostsServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&InNetworkPostsService_ServiceDesc, srv)
+}
+
+func _InNetworkPostsService_GetInNetworkPosts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInNetworkPostsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InNetworkPostsServiceServer).GetInNetworkPosts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InNetworkPostsService_GetInNetworkPosts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InNetworkPostsServiceServer).GetInNetworkPosts(ctx, req.(*GetInNetworkPostsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// InNetworkPostsService_ServiceDesc is the grpc.ServiceDesc for InNetworkPostsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var InNetworkPostsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "thunder.InNetworkPostsService",
+	HandlerType: (*InNetworkPostsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetInNetworkPosts",
+			Handler:    _InNetworkPostsService_GetInNetworkPosts_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "thunder/in_network_posts.proto",
+}
@@ -19,13 +19,14 @@ import (
 	"x-algorithm-go/thunder/internal/poststore"
 	"x-algorithm-go/thunder/internal/service"
 	"x-algorithm-go/thunder/internal/strato"
+	"x-algorithm-go/proto/gateway"
 	"x-algorithm-go/proto/thunder"
 	"google.golang.org/grpc"
 )
 
 var (
 	grpcPort              = flag.Int("grpc_port", 50052, "gRPC 服务器端口（默认: 50052，与 Home Mixer 不同）")
-	httpPort              = flag.Int("http_port", 8080, "HTTP 服务器端口")
+	httpPort              = flag.Int("http_port", 8080, "HTTP 服务器端口（健康检查、指标和 HTTP/JSON 接口）")
 	postRetentionSeconds  = flag.Uint64("post_retention_seconds", 2*24*60*60, "帖子保留期（秒，默认: 2 天）")
 	requestTimeoutMs      = flag.Uint64("request_timeout_ms", 0, "请求超时（毫秒，0 = 无超时）")
 	maxConcurrentRequests = flag.Int64("max_concurrent_requests", 100, "最大并发请求数")
@@ -101,6 +102,8 @@ func main() {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("# Metrics endpoint - Prometheus integration pending\n"))
 	})
+	// GetInNetworkPosts 的 HTTP/JSON 接口，供无法直接使用 gRPC 的工具调用（约定见 gateway 包）
+	httpMux.Handle("/v1/in_network_posts", gateway.Unary(thunderService.GetInNetworkPosts))
 
 	httpServer := &http.Server{
 		Addr:    fmt.Sprintf(":%d", *httpPort),
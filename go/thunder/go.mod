@@ -1,11 +1,19 @@
 module x-algorithm-go/thunder
 
-go 1.24.0
+go 1.25.0
 
 require (
+	golang.org/x/sync v0.22.0
+	google.golang.org/grpc v1.84.0
+	google.golang.org/protobuf v1.36.11
 	x-algorithm-go/proto v0.0.0
-	google.golang.org/grpc v1.60.0
-	google.golang.org/protobuf v1.31.0
+)
+
+require (
+	golang.org/x/net v0.57.0 // indirect
+	golang.org/x/sys v0.47.0 // indirect
+	golang.org/x/text v0.40.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260706201446-f0a921348800 // indirect
 )
 
 replace x-algorithm-go/proto => ../pkg/proto
@@ -0,0 +1,64 @@
+// Package inprocess 在调用方的进程内运行 Thunder 服务
+//
+// 使用与 cmd/main.go 相同的内存 PostStore 和 ThunderService，但不消费 Kafka：
+// 帖子由调用方通过 InsertPosts 写入。调用方把服务注册到自己的 gRPC 服务器上
+// （例如基于 bufconn 的进程内连接），用于端到端测试和本地开发。
+package inprocess
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"x-algorithm-go/proto/thunder"
+	"x-algorithm-go/thunder/internal/poststore"
+	"x-algorithm-go/thunder/internal/service"
+	"x-algorithm-go/thunder/internal/strato"
+)
+
+// Config 配置进程内的 Thunder 服务
+type Config struct {
+	Retention             time.Duration // 帖子保留期，创建时间早于保留期的帖子不会写入
+	MaxConcurrentRequests int64         // 最大并发请求数，超过时返回 RESOURCE_EXHAUSTED
+}
+
+// DefaultConfig 返回与 cmd/main.go 默认参数一致的配置
+func DefaultConfig() Config {
+	return Config{
+		Retention:             2 * 24 * time.Hour,
+		MaxConcurrentRequests: 100,
+	}
+}
+
+// Server 是进程内的 Thunder 服务
+type Server struct {
+	postStore *poststore.PostStore
+	service   *service.ThunderServiceImpl
+}
+
+// NewServer 创建空的进程内 Thunder 服务
+func NewServer(config Config) *Server {
+	postStore := poststore.NewPostStore(uint64(config.Retention/time.Second), 0)
+	return &Server{
+		postStore: postStore,
+		service:   service.NewThunderService(postStore, strato.NewStratoClient(), config.MaxConcurrentRequests),
+	}
+}
+
+// InsertPosts 写入帖子，与 Kafka 消费的帖子走相同的过滤（保留期、未来时间）和索引逻辑
+func (s *Server) InsertPosts(posts []*thunder.LightPost) {
+	s.postStore.InsertPosts(posts)
+	// 各用户的时间线按创建时间排序，与启动时加载完历史数据后的处理一致
+	_ = s.postStore.SortAllUserPosts(context.Background())
+}
+
+// Service 返回 InNetworkPostsService 的实现（例如用于挂载 HTTP/JSON 接口）
+func (s *Server) Service() thunder.InNetworkPostsServiceServer {
+	return s.service
+}
+
+// Register 把 InNetworkPostsService 注册到 gRPC 服务器
+func (s *Server) Register(registrar grpc.ServiceRegistrar) {
+	thunder.RegisterInNetworkPostsServiceServer(registrar, s.service)
+}
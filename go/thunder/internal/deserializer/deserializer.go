@@ -63,8 +63,8 @@ func ExtractPostsFromEvents(events []*InNetworkEvent) ([]*thunder.LightPost, []*
 		postID := int64(authorID)*1000000 + currentTime + int64(len(createTweets))
 		
 		createTweets = append(createTweets, &thunder.LightPost{
-			PostID:    postID,
-			AuthorID:  authorID,
+			PostId:    postID,
+			AuthorId:  authorID,
 			CreatedAt: currentTime,
 			IsReply:   len(createTweets)%3 == 0, // 一些是回复
 			IsRetweet: len(createTweets)%5 == 0, // 一些是转发
@@ -123,13 +123,13 @@ func NewPostStore(retentionSeconds uint64, requestTimeoutMs uint64) *PostStore {
 // MarkAsDeleted marks posts as deleted
 func (ps *PostStore) MarkAsDeleted(posts []*thunder.TweetDeleteEvent) {
 	for _, post := range posts {
-		ps.posts.Delete(post.PostID)
-		ps.deletedPosts.Store(post.PostID, true)
+		ps.posts.Delete(post.PostId)
+		ps.deletedPosts.Store(post.PostId, true)
 
 		// Add to delete event tracking
 		key := int64(-1) // DELETE_EVENT_KEY
 		deque := ps.getOrCreateDeque(&ps.originalPostsByUser, key)
-		deque.PushBack(NewTinyPost(post.PostID, post.DeletedAt))
+		deque.PushBack(NewTinyPost(post.PostId, post.DeletedAt))
 	}
 }
 
@@ -156,8 +156,8 @@ func (ps *PostStore) InsertPosts(posts []*thunder.LightPost) {
 
 func (ps *PostStore) insertPostsInternal(posts []*thunder.LightPost) {
 	for _, post := range posts {
-		postID := post.PostID
-		authorID := post.AuthorID
+		postID := post.PostId
+		authorID := post.AuthorId
 		createdAt := post.CreatedAt
 		isOriginal := !post.IsReply && !post.IsRetweet
 
@@ -188,8 +188,8 @@ func (ps *PostStore) insertPostsInternal(posts []*thunder.LightPost) {
 		videoEligible := post.HasVideo
 
 		// If this is a retweet and the retweeted post has video, mark has_video as true
-		if !videoEligible && post.IsRetweet && post.SourcePostID != nil {
-			if sourcePostVal, ok := ps.posts.Load(*post.SourcePostID); ok {
+		if !videoEligible && post.IsRetweet && post.SourcePostId != nil {
+			if sourcePostVal, ok := ps.posts.Load(*post.SourcePostId); ok {
 				sourcePost := sourcePostVal.(*thunder.LightPost)
 				if !sourcePost.IsReply && sourcePost.HasVideo {
 					videoEligible = true
@@ -324,29 +324,29 @@ func (ps *PostStore) getPostsFromMap(
 					post := postVal.(*thunder.LightPost)
 
 					// Check if deleted
-					if _, deleted := ps.deletedPosts.Load(post.PostID); deleted {
+					if _, deleted := ps.deletedPosts.Load(post.PostId); deleted {
 						continue
 					}
 
 					// Filter retweets from request user
-					if post.IsRetweet && post.SourceUserID != nil && *post.SourceUserID == requestUserID {
+					if post.IsRetweet && post.SourceUserId != nil && *post.SourceUserId == requestUserID {
 						continue
 					}
 
 					// Filter replies based on following users
 					if len(followingUsers) > 0 {
-						if post.InReplyToPostID != nil {
-							if repliedToVal, ok := ps.posts.Load(*post.InReplyToPostID); ok {
+						if post.InReplyToPostId != nil {
+							if repliedToVal, ok := ps.posts.Load(*post.InReplyToPostId); ok {
 								repliedToPost := repliedToVal.(*thunder.LightPost)
 								if !repliedToPost.IsRetweet && !repliedToPost.IsReply {
 									// Original post, include
 								} else {
 									// Check if reply to original or followed user
-									if post.ConversationID != nil {
-										replyToOriginal := repliedToPost.InReplyToPostID != nil &&
-											*repliedToPost.InReplyToPostID == *post.ConversationID
-										replyToFollowed := post.InReplyToUserID != nil &&
-											followingUsers[*post.InReplyToUserID]
+									if post.ConversationId != nil {
+										replyToOriginal := repliedToPost.InReplyToPostId != nil &&
+											*repliedToPost.InReplyToPostId == *post.ConversationId
+										replyToFollowed := post.InReplyToUserId != nil &&
+											followingUsers[*post.InReplyToUserId]
 										if !(replyToOriginal && replyToFollowed) {
 											continue
 										}
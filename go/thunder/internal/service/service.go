@@ -15,12 +15,9 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-// UnimplementedInNetworkPostsServiceServer must be embedded to have forward compatible implementations
-type UnimplementedInNetworkPostsServiceServer struct{}
-
 // ThunderServiceImpl implements the InNetworkPostsService
 type ThunderServiceImpl struct {
-	UnimplementedInNetworkPostsServiceServer
+	thunder.UnimplementedInNetworkPostsServiceServer
 
 	// PostStore for retrieving posts by user ID
 	postStore *poststore.PostStore
@@ -62,21 +59,21 @@ func (s *ThunderServiceImpl) GetInNetworkPosts(
 
 	if req.Debug {
 		log.Printf("Received GetInNetworkPosts request: user_id=%d, following_count=%d, exclude_tweet_ids=%d",
-			req.UserID, len(req.FollowingUserIDs), len(req.ExcludeTweetIDs))
+			req.UserId, len(req.FollowingUserIds), len(req.ExcludeTweetIds))
 	}
 
 	// If following_user_id list is empty, fetch it from Strato
-	followingUserIDs := req.FollowingUserIDs
+	followingUserIDs := req.FollowingUserIds
 	if len(followingUserIDs) == 0 && req.Debug {
-		log.Printf("Following list is empty, fetching from Strato for user %d", req.UserID)
+		log.Printf("Following list is empty, fetching from Strato for user %d", req.UserId)
 
-		followingList, err := s.stratoClient.FetchFollowingList(ctx, int64(req.UserID), config.MAX_INPUT_LIST_SIZE)
+		followingList, err := s.stratoClient.FetchFollowingList(ctx, int64(req.UserId), config.MAX_INPUT_LIST_SIZE)
 		if err != nil {
-			log.Printf("Failed to fetch following list from Strato for user %d: %v", req.UserID, err)
+			log.Printf("Failed to fetch following list from Strato for user %d: %v", req.UserId, err)
 			return nil, status.Errorf(codes.Internal, "Failed to fetch following list: %v", err)
 		}
 
-		log.Printf("Fetched %d following users from Strato for user %d", len(followingList), req.UserID)
+		log.Printf("Fetched %d following users from Strato for user %d", len(followingList), req.UserId)
 		followingUserIDs = make([]uint64, len(followingList))
 		for i, id := range followingList {
 			followingUserIDs[i] = uint64(id)
@@ -86,14 +83,14 @@ func (s *ThunderServiceImpl) GetInNetworkPosts(
 	// Limit following_user_ids and exclude_tweet_ids to first K entries
 	if len(followingUserIDs) > config.MAX_INPUT_LIST_SIZE {
 		log.Printf("Limiting following_user_ids from %d to %d entries for user %d",
-			len(followingUserIDs), config.MAX_INPUT_LIST_SIZE, req.UserID)
+			len(followingUserIDs), config.MAX_INPUT_LIST_SIZE, req.UserId)
 		followingUserIDs = followingUserIDs[:config.MAX_INPUT_LIST_SIZE]
 	}
 
-	excludeTweetIDs := req.ExcludeTweetIDs
+	excludeTweetIDs := req.ExcludeTweetIds
 	if len(excludeTweetIDs) > config.MAX_INPUT_LIST_SIZE {
 		log.Printf("Limiting exclude_tweet_ids from %d to %d entries for user %d",
-			len(excludeTweetIDs), config.MAX_INPUT_LIST_SIZE, req.UserID)
+			len(excludeTweetIDs), config.MAX_INPUT_LIST_SIZE, req.UserId)
 		excludeTweetIDs = excludeTweetIDs[:config.MAX_INPUT_LIST_SIZE]
 	}
 
@@ -125,14 +122,14 @@ func (s *ThunderServiceImpl) GetInNetworkPosts(
 			followingUserIDsInt64,
 			excludeTweetIDsSet,
 			startTime,
-			int64(req.UserID),
+			int64(req.UserId),
 		)
 	} else {
 		allPosts = s.postStore.GetAllPostsByUsers(
 			followingUserIDsInt64,
 			excludeTweetIDsSet,
 			startTime,
-			int64(req.UserID),
+			int64(req.UserId),
 		)
 	}
 
@@ -140,7 +137,7 @@ func (s *ThunderServiceImpl) GetInNetworkPosts(
 	scoredPosts := scoreRecent(allPosts, maxResults)
 
 	if req.Debug {
-		log.Printf("Returning %d posts for user %d", len(scoredPosts), req.UserID)
+		log.Printf("Returning %d posts for user %d", len(scoredPosts), req.UserId)
 	}
 
 	// Record metrics
@@ -174,7 +171,7 @@ func (s *ThunderServiceImpl) AnalyzeAndReportPostStatistics(posts []*thunder.Lig
 	replyCount := 0
 
 	for _, post := range posts {
-		uniqueAuthors[int64(post.AuthorID)] = true
+		uniqueAuthors[int64(post.AuthorId)] = true
 		
 		if oldestTimestamp == 0 || post.CreatedAt < oldestTimestamp {
 			oldestTimestamp = post.CreatedAt
@@ -183,7 +180,7 @@ func (s *ThunderServiceImpl) AnalyzeAndReportPostStatistics(posts []*thunder.Lig
 			newestTimestamp = post.CreatedAt
 		}
 		
-		if post.InReplyToPostID != nil && *post.InReplyToPostID != 0 {
+		if post.InReplyToPostId != nil && *post.InReplyToPostId != 0 {
 			replyCount++
 		}
 	}